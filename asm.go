@@ -0,0 +1,1325 @@
+package m68k
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Assemble encodes a single instruction written in the syntax Disassemble
+// produces, such as "MOVE.W D0,(A1)", and returns its big-endian bytes.
+// Branch and DBcc targets are absolute addresses resolved as if the
+// instruction were at address 0; use AssembleAt for code placed elsewhere.
+//
+// Numbers may be decimal, $hex or 0xhex. Unsized instructions default to
+// word size, an unsized absolute address uses the short form when it fits,
+// and an unsized branch the short form when the displacement allows.
+func Assemble(text string) ([]byte, error) {
+	return AssembleAt(0, text)
+}
+
+// AssembleAt is Assemble for an instruction located at addr, which
+// branch and DBcc targets are taken relative to.
+func AssembleAt(addr uint32, text string) ([]byte, error) {
+	a := assembler{pc: addr}
+	if err := a.assemble(text); err != nil {
+		return nil, fmt.Errorf("m68k: assemble %q: %w", text, err)
+	}
+	buf := make([]byte, 0, len(a.words)*2)
+	for _, w := range a.words {
+		buf = append(buf, byte(w>>8), byte(w))
+	}
+	return buf, nil
+}
+
+// Operand kinds beyond an ordinary effective address.
+const (
+	argEA   = iota
+	argSR   // SR
+	argCCR  // CCR
+	argUSP  // USP
+	argCtrl // MOVEC control register
+	argList // MOVEM register list
+	argPair // Dh:Dl register pair
+)
+
+// operand is one parsed instruction operand.
+type operand struct {
+	kind  int
+	mode  uint16 // EA mode, for argEA
+	reg   uint16 // EA register, or the MOVEC selector for argCtrl
+	val   int64  // displacement, absolute address or immediate
+	ext   uint16 // brief extension word less displacement, for indexed modes
+	bf    uint16 // offset and width fields of a {offset:width} bit field
+	hasBF bool   // the operand carries a bit field
+	list  uint16 // register mask, bit 0 = D0 ... bit 15 = A7
+	hi    uint16 // first register of a Dh:Dl pair, reg holding the second
+	bare  bool   // an absolute address written without .W/.L
+}
+
+// isReg reports whether o is a data (mode 0) or address (mode 1) register.
+func (o operand) isReg(mode uint16) bool {
+	return o.kind == argEA && o.mode == mode
+}
+
+// isMem reports whether o is a memory or immediate effective address.
+func (o operand) isMem() bool {
+	return o.kind == argEA && o.mode >= 2
+}
+
+// assembler accumulates the words of one instruction.
+type assembler struct {
+	pc    uint32
+	words []uint16
+	name  string // mnemonic the encoding must disassemble to
+}
+
+func (a *assembler) emit(w ...uint16) {
+	a.words = append(a.words, w...)
+}
+
+// emitEA appends the extension words of an effective address operand.
+func (a *assembler) emitEA(o operand, sz size) error {
+	switch o.mode {
+	case 5:
+		if err := checkRange(o.val, 16, "displacement"); err != nil {
+			return err
+		}
+		a.emit(uint16(o.val))
+	case 6:
+		if err := checkRange(o.val, 8, "displacement"); err != nil {
+			return err
+		}
+		a.emit(o.ext | uint16(uint8(o.val)))
+	case 7:
+		switch o.reg {
+		case 0:
+			a.emit(uint16(o.val))
+		case 1:
+			a.emit(uint16(o.val>>16), uint16(o.val))
+		case 2:
+			if err := checkRange(o.val, 16, "displacement"); err != nil {
+				return err
+			}
+			a.emit(uint16(o.val))
+		case 3:
+			if err := checkRange(o.val, 8, "displacement"); err != nil {
+				return err
+			}
+			a.emit(o.ext | uint16(uint8(o.val)))
+		case 4:
+			return a.emitImm(o.val, sz)
+		}
+	}
+	return nil
+}
+
+// emitImm appends an immediate operand of the given size.
+func (a *assembler) emitImm(v int64, sz size) error {
+	if v < -int64(sz.Mask()>>1)-1 || v > int64(sz.Mask()) {
+		return fmt.Errorf("immediate %d out of range for %s", v, sizeSuffix(sz))
+	}
+	if sz == sizeLong {
+		a.emit(uint16(v>>16), uint16(v))
+		return nil
+	}
+	a.emit(uint16(v) & uint16(sz.Mask()))
+	return nil
+}
+
+// checkRange reports an error if v does not fit a signed field of bits.
+func checkRange(v int64, bits uint, what string) error {
+	if v < -(1<<(bits-1)) || v >= 1<<(bits-1) {
+		return fmt.Errorf("%s %d out of range", what, v)
+	}
+	return nil
+}
+
+// eaField returns the 6-bit mode/register field of an EA operand.
+func eaField(o operand) uint16 {
+	return o.mode<<3 | o.reg
+}
+
+// sizeField returns the standard 2-bit size field (bits 7-6).
+func sizeField(sz size) uint16 {
+	switch sz {
+	case sizeByte:
+		return 0 << 6
+	case sizeLong:
+		return 2 << 6
+	}
+	return 1 << 6
+}
+
+// condCode looks up a condition mnemonic, including the HS/LO aliases.
+func condCode(s string) (uint16, bool) {
+	switch s {
+	case "HS":
+		return 4, true
+	case "LO":
+		return 5, true
+	}
+	for i, n := range condNames {
+		if n == s {
+			return uint16(i), true
+		}
+	}
+	return 0, false
+}
+
+func (a *assembler) assemble(text string) error {
+	text = strings.TrimSpace(text)
+	mnem, rest, _ := strings.Cut(text, " ")
+	mnem = strings.ToUpper(mnem)
+	name, suffix, hasSize := strings.Cut(mnem, ".")
+
+	if name == "DC" && suffix == "W" {
+		v, err := parseNumber(strings.TrimSpace(rest))
+		if err != nil {
+			return err
+		}
+		a.emit(uint16(v))
+		return nil
+	}
+
+	sz := sizeWord
+	if hasSize {
+		switch suffix {
+		case "B":
+			sz = sizeByte
+		case "W":
+			sz = sizeWord
+		case "L":
+			sz = sizeLong
+		case "S":
+			// Short branch; checked by encodeBranch
+		default:
+			return fmt.Errorf("unknown size .%s", suffix)
+		}
+	}
+
+	var ops []operand
+	for _, f := range splitOperands(rest) {
+		o, err := parseOperand(f)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, o)
+	}
+
+	if !strings.HasPrefix(name, "BF") {
+		for _, o := range ops {
+			if o.hasBF {
+				return errors.New("unexpected bit field")
+			}
+		}
+	}
+
+	a.name = name
+	if err := a.encode(name, suffix, sz, ops); err != nil {
+		return err
+	}
+	if suffix == "S" && a.name[0] != 'B' {
+		return errors.New("unknown size .S")
+	}
+	return a.verify()
+}
+
+// verify checks the encoding against the CPU's opcode table and the
+// disassembler: the opcode must be implemented, decode to the intended
+// mnemonic, and span exactly the words emitted. The assembler takes every
+// variant's instructions, so it decodes them as the MC68020 does.
+func (a *assembler) verify() error {
+	op := a.words[0]
+	if !IsImplemented(op) && op != 0x4AFC {
+		return errors.New("invalid operand combination")
+	}
+	d := disassembler{bus: wordBus{base: a.pc, words: a.words}, pc: a.pc, variant: MC68020}
+	text := d.decode()
+	got, _, _ := strings.Cut(text, " ")
+	got, _, _ = strings.Cut(got, ".")
+	if got != a.name || d.pc-a.pc != uint32(len(a.words)*2) {
+		return fmt.Errorf("invalid operand combination (encodes %s)", text)
+	}
+	return nil
+}
+
+// expect checks the operand count.
+func expect(ops []operand, n int) error {
+	if len(ops) != n {
+		return fmt.Errorf("want %d operands, have %d", n, len(ops))
+	}
+	return nil
+}
+
+// needEA checks that o is an effective address operand.
+func needEA(o operand) error {
+	if o.kind != argEA {
+		return errors.New("effective address expected")
+	}
+	return nil
+}
+
+// needReg checks that o is a data (mode 0) or address (mode 1) register.
+func needReg(o operand, mode uint16) error {
+	if !o.isReg(mode) {
+		return fmt.Errorf("%c register expected", "DA"[mode])
+	}
+	return nil
+}
+
+// needImm checks that o is an immediate and returns its value.
+func needImm(o operand) (int64, error) {
+	if o.kind != argEA || o.mode != 7 || o.reg != 4 {
+		return 0, errors.New("immediate expected")
+	}
+	return o.val, nil
+}
+
+// opEA emits op combined with a single EA operand and its extension words.
+func (a *assembler) opEA(op uint16, o operand, sz size) error {
+	if err := needEA(o); err != nil {
+		return err
+	}
+	a.emit(op | eaField(o))
+	return a.emitEA(o, sz)
+}
+
+func (a *assembler) encode(name, suffix string, sz size, ops []operand) error {
+	switch name {
+	case "NOP", "RESET", "RTE", "RTS", "TRAPV", "RTR", "ILLEGAL":
+		if err := expect(ops, 0); err != nil {
+			return err
+		}
+		a.emit(map[string]uint16{
+			"NOP": 0x4E71, "RESET": 0x4E70, "RTE": 0x4E73, "RTS": 0x4E75,
+			"TRAPV": 0x4E76, "RTR": 0x4E77, "ILLEGAL": 0x4AFC,
+		}[name])
+		return nil
+
+	case "STOP", "RTD":
+		if err := expect(ops, 1); err != nil {
+			return err
+		}
+		v, err := needImm(ops[0])
+		if err != nil {
+			return err
+		}
+		if name == "STOP" {
+			a.emit(0x4E72)
+			return a.emitImm(v, sizeWord)
+		}
+		if err := checkRange(v, 16, "displacement"); err != nil {
+			return err
+		}
+		a.emit(0x4E74, uint16(v))
+		return nil
+
+	case "TRAP":
+		if err := expect(ops, 1); err != nil {
+			return err
+		}
+		v, err := needImm(ops[0])
+		if err != nil {
+			return err
+		}
+		if v < 0 || v > 15 {
+			return fmt.Errorf("trap vector %d out of range", v)
+		}
+		a.emit(0x4E40 | uint16(v))
+		return nil
+
+	case "SWAP", "UNLK", "EXT":
+		if err := expect(ops, 1); err != nil {
+			return err
+		}
+		switch name {
+		case "SWAP":
+			if err := needReg(ops[0], 0); err != nil {
+				return err
+			}
+			a.emit(0x4840 | ops[0].reg)
+		case "UNLK":
+			if err := needReg(ops[0], 1); err != nil {
+				return err
+			}
+			a.emit(0x4E58 | ops[0].reg)
+		case "EXT":
+			if err := needReg(ops[0], 0); err != nil {
+				return err
+			}
+			op := uint16(0x4880)
+			if sz == sizeLong {
+				op = 0x48C0
+			}
+			a.emit(op | ops[0].reg)
+		}
+		return nil
+
+	case "LINK":
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		if err := needReg(ops[0], 1); err != nil {
+			return err
+		}
+		v, err := needImm(ops[1])
+		if err != nil {
+			return err
+		}
+		if err := checkRange(v, 16, "displacement"); err != nil {
+			return err
+		}
+		a.emit(0x4E50|ops[0].reg, uint16(v))
+		return nil
+
+	case "MOVEC":
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		for i := range ops {
+			if ops[i].kind == argUSP {
+				ops[i] = operand{kind: argCtrl, reg: ctrlUSP}
+			}
+		}
+		op, rc, rn := uint16(0x4E7A), ops[0], ops[1]
+		if rn.kind == argCtrl {
+			op, rc, rn = 0x4E7B, ops[1], ops[0]
+		}
+		if rc.kind != argCtrl || rn.kind != argEA || rn.mode > 1 {
+			return errors.New("MOVEC needs a control register and a general register")
+		}
+		a.emit(op, rn.mode<<15|rn.reg<<12|rc.reg)
+		return nil
+
+	case "MOVE", "MOVEA":
+		return a.encodeMOVE(sz, ops)
+
+	case "MOVES":
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		mem, rn, dr := ops[0], ops[1], uint16(0)
+		if mem.kind == argEA && mem.mode <= 1 {
+			mem, rn, dr = ops[1], ops[0], 0x0800
+		}
+		if !mem.isMem() || rn.kind != argEA || rn.mode > 1 {
+			return errors.New("MOVES needs a memory operand and a general register")
+		}
+		szBits := map[size]uint16{sizeByte: 0, sizeWord: 1, sizeLong: 2}[sz]
+		a.emit(0x0E00|szBits<<6|eaField(mem), rn.mode<<15|rn.reg<<12|dr)
+		return a.emitEA(mem, sz)
+
+	case "MOVEQ":
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		v, err := needImm(ops[0])
+		if err != nil {
+			return err
+		}
+		if err := needReg(ops[1], 0); err != nil {
+			return err
+		}
+		if v < -128 || v > 255 {
+			return fmt.Errorf("MOVEQ immediate %d out of range", v)
+		}
+		a.emit(0x7000 | ops[1].reg<<9 | uint16(uint8(v)))
+		return nil
+
+	case "MOVEM":
+		return a.encodeMOVEM(sz, ops)
+
+	case "MOVEP":
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		op := uint16(0x0108)
+		if sz == sizeLong {
+			op |= 0x0040
+		}
+		dn, mem := ops[1], ops[0]
+		if ops[0].isReg(0) {
+			op |= 0x0080
+			dn, mem = ops[0], ops[1]
+		}
+		if !dn.isReg(0) || mem.kind != argEA || (mem.mode != 5 && mem.mode != 2) {
+			return errors.New("MOVEP needs Dn and d16(An)")
+		}
+		a.emit(op|dn.reg<<9|mem.reg, uint16(mem.val))
+		return nil
+
+	case "LEA", "CHK", "DIVU", "DIVS", "MULU", "MULS":
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		if sz == sizeLong && name != "LEA" && name != "CHK" {
+			return a.encodeLongMulDiv(name, ops)
+		}
+		base := map[string]uint16{
+			"LEA": 0x41C0, "CHK": 0x4180, "DIVU": 0x80C0,
+			"DIVS": 0x81C0, "MULU": 0xC0C0, "MULS": 0xC1C0,
+		}[name]
+		regMode := uint16(0)
+		opSize := sizeWord
+		if name == "LEA" {
+			regMode, opSize = 1, sizeLong
+		}
+		if err := needReg(ops[1], regMode); err != nil {
+			return err
+		}
+		return a.opEA(base|ops[1].reg<<9, ops[0], opSize)
+
+	case "DIVUL", "DIVSL":
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		if sz != sizeLong {
+			return errors.New(name + " is long only")
+		}
+		if ops[1].kind != argPair {
+			return errors.New("register pair expected")
+		}
+		return a.encodeLongMulDiv(name, ops)
+
+	case "PEA", "JMP", "JSR", "NBCD", "TAS":
+		if err := expect(ops, 1); err != nil {
+			return err
+		}
+		base := map[string]uint16{
+			"PEA": 0x4840, "JMP": 0x4EC0, "JSR": 0x4E80, "NBCD": 0x4800, "TAS": 0x4AC0,
+		}[name]
+		return a.opEA(base, ops[0], sizeLong)
+
+	case "NEGX", "CLR", "NEG", "NOT", "TST":
+		if err := expect(ops, 1); err != nil {
+			return err
+		}
+		base := map[string]uint16{
+			"NEGX": 0x4000, "CLR": 0x4200, "NEG": 0x4400, "NOT": 0x4600, "TST": 0x4A00,
+		}[name]
+		return a.opEA(base|sizeField(sz), ops[0], sz)
+
+	case "ORI", "ANDI", "SUBI", "ADDI", "EORI", "CMPI":
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		base := map[string]uint16{
+			"ORI": 0x0000, "ANDI": 0x0200, "SUBI": 0x0400,
+			"ADDI": 0x0600, "EORI": 0x0A00, "CMPI": 0x0C00,
+		}[name]
+		v, err := needImm(ops[0])
+		if err != nil {
+			return err
+		}
+		switch ops[1].kind {
+		case argCCR:
+			a.emit(base | 0x003C)
+			return a.emitImm(v, sizeByte)
+		case argSR:
+			a.emit(base | 0x007C)
+			return a.emitImm(v, sizeWord)
+		}
+		if err := needEA(ops[1]); err != nil {
+			return err
+		}
+		a.emit(base | sizeField(sz) | eaField(ops[1]))
+		if err := a.emitImm(v, sz); err != nil {
+			return err
+		}
+		return a.emitEA(ops[1], sz)
+
+	case "BTST", "BCHG", "BCLR", "BSET":
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		kind := map[string]uint16{"BTST": 0, "BCHG": 1, "BCLR": 2, "BSET": 3}[name] << 6
+		if ops[0].isReg(0) {
+			return a.opEA(0x0100|ops[0].reg<<9|kind, ops[1], sizeByte)
+		}
+		v, err := needImm(ops[0])
+		if err != nil {
+			return err
+		}
+		if err := needEA(ops[1]); err != nil {
+			return err
+		}
+		a.emit(0x0800|kind|eaField(ops[1]), uint16(v)&0xFF)
+		return a.emitEA(ops[1], sizeByte)
+
+	case "CAS":
+		if err := expect(ops, 3); err != nil {
+			return err
+		}
+		if err := needReg(ops[0], 0); err != nil {
+			return err
+		}
+		if err := needReg(ops[1], 0); err != nil {
+			return err
+		}
+		if !ops[2].isMem() {
+			return errors.New("memory destination expected")
+		}
+		szBits := map[size]uint16{sizeByte: 1, sizeWord: 2, sizeLong: 3}[sz]
+		a.emit(0x08C0|szBits<<9|eaField(ops[2]), ops[1].reg<<6|ops[0].reg)
+		return a.emitEA(ops[2], sz)
+
+	case "CHK2", "CMP2":
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		src, rn := ops[0], ops[1]
+		if !src.isMem() || src.mode == 3 || src.mode == 4 || src.mode == 7 && src.reg == 4 {
+			return errors.New("control addressing mode expected")
+		}
+		if rn.kind != argEA || rn.mode > 1 {
+			return errors.New("data or address register expected")
+		}
+		ext := rn.mode<<15 | rn.reg<<12
+		if name == "CHK2" {
+			ext |= 0x0800
+		}
+		szBits := map[size]uint16{sizeByte: 0, sizeWord: 1, sizeLong: 2}[sz]
+		a.emit(0x00C0|szBits<<9|eaField(src), ext)
+		return a.emitEA(src, sz)
+
+	case "ADDQ", "SUBQ":
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		v, err := needImm(ops[0])
+		if err != nil {
+			return err
+		}
+		if v < 1 || v > 8 {
+			return fmt.Errorf("quick immediate %d out of range", v)
+		}
+		op := uint16(0x5000)
+		if name == "SUBQ" {
+			op = 0x5100
+		}
+		return a.opEA(op|uint16(v&7)<<9|sizeField(sz), ops[1], sz)
+
+	case "ADD", "SUB", "ADDA", "SUBA", "AND", "OR":
+		return a.encodeALU(name, sz, ops)
+
+	case "CMP", "CMPA":
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		if ops[1].isReg(1) {
+			return a.encodeALU(name, sz, ops)
+		}
+		if err := needReg(ops[1], 0); err != nil {
+			return err
+		}
+		a.name = "CMP"
+		return a.opEA(0xB000|ops[1].reg<<9|sizeField(sz), ops[0], sz)
+
+	case "EOR":
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		if err := needReg(ops[0], 0); err != nil {
+			return err
+		}
+		return a.opEA(0xB100|ops[0].reg<<9|sizeField(sz), ops[1], sz)
+
+	case "CMPM":
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		if ops[0].kind != argEA || ops[0].mode != 3 || ops[1].kind != argEA || ops[1].mode != 3 {
+			return errors.New("CMPM needs (Ay)+,(Ax)+")
+		}
+		a.emit(0xB108 | ops[1].reg<<9 | sizeField(sz) | ops[0].reg)
+		return nil
+
+	case "ADDX", "SUBX", "ABCD", "SBCD":
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		op := map[string]uint16{"ADDX": 0xD100, "SUBX": 0x9100, "ABCD": 0xC100, "SBCD": 0x8100}[name]
+		if name == "ADDX" || name == "SUBX" {
+			op |= sizeField(sz)
+		}
+		x, y := ops[1], ops[0]
+		switch {
+		case x.isReg(0) && y.isReg(0):
+		case x.kind == argEA && x.mode == 4 && y.kind == argEA && y.mode == 4:
+			op |= 0x0008
+		default:
+			return errors.New("operands must be Dy,Dx or -(Ay),-(Ax)")
+		}
+		a.emit(op | x.reg<<9 | y.reg)
+		return nil
+
+	case "EXG":
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		x, y := ops[0], ops[1]
+		if x.isReg(1) && y.isReg(0) {
+			x, y = y, x
+		}
+		var op uint16
+		switch {
+		case x.isReg(0) && y.isReg(0):
+			op = 0xC140
+		case x.isReg(1) && y.isReg(1):
+			op = 0xC148
+		case x.isReg(0) && y.isReg(1):
+			op = 0xC188
+		default:
+			return errors.New("EXG needs two registers")
+		}
+		a.emit(op | x.reg<<9 | y.reg)
+		return nil
+	}
+
+	for i, n := range bitfieldNames {
+		if name == n {
+			return a.encodeBitfield(uint16(i), ops)
+		}
+	}
+
+	for i, stem := range shiftNames {
+		if len(name) == len(stem)+1 && strings.HasPrefix(name, stem) {
+			switch name[len(stem)] {
+			case 'L':
+				return a.encodeShift(uint16(i), 0x0100, sz, ops)
+			case 'R':
+				return a.encodeShift(uint16(i), 0, sz, ops)
+			}
+		}
+	}
+
+	if name == "BRA" || name == "BSR" {
+		return a.encodeBranch(map[string]uint16{"BRA": 0, "BSR": 1}[name], suffix, ops)
+	}
+	if name == "DBRA" {
+		a.name = "DBF"
+		return a.encodeDBcc(1, ops)
+	}
+	if cc, ok := condCode(strings.TrimPrefix(name, "DB")); ok && strings.HasPrefix(name, "DB") {
+		a.name = "DB" + condNames[cc]
+		return a.encodeDBcc(cc, ops)
+	}
+	if cc, ok := condCode(strings.TrimPrefix(name, "B")); ok && name[0] == 'B' && cc > 1 {
+		a.name = "B" + condNames[cc]
+		return a.encodeBranch(cc, suffix, ops)
+	}
+	if cc, ok := condCode(strings.TrimPrefix(name, "S")); ok && name[0] == 'S' {
+		if err := expect(ops, 1); err != nil {
+			return err
+		}
+		a.name = "S" + condNames[cc]
+		return a.opEA(0x50C0|cc<<8, ops[0], sizeByte)
+	}
+
+	return fmt.Errorf("unknown mnemonic %s", name)
+}
+
+// encodeMOVE handles MOVE, MOVEA and the SR, CCR and USP forms of MOVE.
+func (a *assembler) encodeMOVE(sz size, ops []operand) error {
+	if err := expect(ops, 2); err != nil {
+		return err
+	}
+	src, dst := ops[0], ops[1]
+	a.name = "MOVE"
+	switch {
+	case src.kind == argSR:
+		return a.opEA(0x40C0, dst, sizeWord)
+	case src.kind == argCCR:
+		return a.opEA(0x42C0, dst, sizeWord)
+	case dst.kind == argCCR:
+		return a.opEA(0x44C0, src, sizeWord)
+	case dst.kind == argSR:
+		return a.opEA(0x46C0, src, sizeWord)
+	case dst.kind == argUSP:
+		if err := needReg(src, 1); err != nil {
+			return err
+		}
+		a.emit(0x4E60 | src.reg)
+		return nil
+	case src.kind == argUSP:
+		if err := needReg(dst, 1); err != nil {
+			return err
+		}
+		a.emit(0x4E68 | dst.reg)
+		return nil
+	}
+	if err := needEA(src); err != nil {
+		return err
+	}
+	if err := needEA(dst); err != nil {
+		return err
+	}
+	if dst.mode == 1 {
+		a.name = "MOVEA"
+	}
+	var szBits uint16
+	for bits, s := range moveSizeMap {
+		if s == sz && bits != 0 {
+			szBits = uint16(bits)
+		}
+	}
+	a.emit(szBits<<12 | dst.reg<<9 | dst.mode<<6 | eaField(src))
+	if err := a.emitEA(src, sz); err != nil {
+		return err
+	}
+	return a.emitEA(dst, sz)
+}
+
+// encodeMOVEM handles both directions of MOVEM. A single register may be
+// given in place of a list.
+func (a *assembler) encodeMOVEM(sz size, ops []operand) error {
+	if err := expect(ops, 2); err != nil {
+		return err
+	}
+	asList := func(o operand) (uint16, bool) {
+		switch {
+		case o.kind == argList:
+			return o.list, true
+		case o.isReg(0):
+			return 1 << o.reg, true
+		case o.isReg(1):
+			return 1 << (8 + o.reg), true
+		}
+		return 0, false
+	}
+	op := uint16(0x4880)
+	if sz == sizeLong {
+		op |= 0x0040
+	}
+	if mask, ok := asList(ops[0]); ok && ops[1].isMem() {
+		mem := ops[1]
+		if mem.mode == 4 {
+			var r uint16
+			for i := 0; i < 16; i++ {
+				if mask&(1<<i) != 0 {
+					r |= 1 << (15 - i)
+				}
+			}
+			mask = r
+		}
+		a.emit(op|eaField(mem), mask)
+		return a.emitEA(mem, sz)
+	}
+	mask, ok := asList(ops[1])
+	if !ok || !ops[0].isMem() {
+		return errors.New("MOVEM needs a register list and a memory operand")
+	}
+	a.emit(op|0x0400|eaField(ops[0]), mask)
+	return a.emitEA(ops[0], sz)
+}
+
+// encodeALU handles ADD, SUB, AND and OR in both directions, and ADDA,
+// SUBA and CMPA (also selected by an address register destination).
+func (a *assembler) encodeALU(name string, sz size, ops []operand) error {
+	if err := expect(ops, 2); err != nil {
+		return err
+	}
+	base := map[string]uint16{
+		"ADD": 0xD000, "ADDA": 0xD000, "SUB": 0x9000, "SUBA": 0x9000,
+		"AND": 0xC000, "OR": 0x8000, "CMP": 0xB000, "CMPA": 0xB000,
+	}[name]
+	src, dst := ops[0], ops[1]
+	stem := strings.TrimSuffix(name, "A")
+	if dst.isReg(1) || name != stem {
+		if stem == "AND" || stem == "OR" {
+			return errors.New("address register destination")
+		}
+		if err := needReg(dst, 1); err != nil {
+			return err
+		}
+		if sz == sizeByte {
+			return errors.New("address register operations are word or long")
+		}
+		a.name = stem + "A"
+		op := base | dst.reg<<9 | 0x00C0
+		if sz == sizeLong {
+			op |= 0x0100
+		}
+		return a.opEA(op, src, sz)
+	}
+	a.name = stem
+	if dst.isReg(0) {
+		return a.opEA(base|dst.reg<<9|sizeField(sz), src, sz)
+	}
+	if err := needReg(src, 0); err != nil {
+		return err
+	}
+	if !dst.isMem() {
+		return errors.New("memory destination expected")
+	}
+	return a.opEA(base|src.reg<<9|0x0100|sizeField(sz), dst, sz)
+}
+
+// encodeShift handles the register and memory forms of the shifts and
+// rotates. kind selects AS/LS/ROX/RO and dir is 0x0100 for left.
+func (a *assembler) encodeShift(kind, dir uint16, sz size, ops []operand) error {
+	if len(ops) == 1 && ops[0].isMem() {
+		if sz != sizeWord {
+			return errors.New("memory shifts are word sized")
+		}
+		return a.opEA(0xE0C0|kind<<9|dir, ops[0], sizeWord)
+	}
+	if len(ops) == 1 {
+		// "ASL Dn" shifts by one
+		ops = []operand{{kind: argEA, mode: 7, reg: 4, val: 1}, ops[0]}
+	}
+	if err := expect(ops, 2); err != nil {
+		return err
+	}
+	if err := needReg(ops[1], 0); err != nil {
+		return err
+	}
+	op := 0xE000 | dir | sizeField(sz) | kind<<3 | ops[1].reg
+	if ops[0].isReg(0) {
+		a.emit(op | 0x0020 | ops[0].reg<<9)
+		return nil
+	}
+	v, err := needImm(ops[0])
+	if err != nil {
+		return err
+	}
+	if v < 1 || v > 8 {
+		return fmt.Errorf("shift count %d out of range", v)
+	}
+	a.emit(op | uint16(v&7)<<9)
+	return nil
+}
+
+// encodeBitfield handles the 68020 bit field instructions. kind is the
+// index into bitfieldNames.
+func (a *assembler) encodeBitfield(kind uint16, ops []operand) error {
+	var field, dn operand
+	switch kind {
+	case bfEXTU, bfEXTS, bfFFO:
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		field, dn = ops[0], ops[1]
+	case bfINS:
+		if err := expect(ops, 2); err != nil {
+			return err
+		}
+		field, dn = ops[1], ops[0]
+	default:
+		if err := expect(ops, 1); err != nil {
+			return err
+		}
+		field = ops[0]
+	}
+	if !field.hasBF {
+		return errors.New("bit field {offset:width} expected")
+	}
+	if err := needEA(field); err != nil {
+		return err
+	}
+	if len(ops) == 2 {
+		if err := needReg(dn, 0); err != nil {
+			return err
+		}
+	}
+	a.emit(0xE8C0|kind<<8|eaField(field), dn.reg<<12|field.bf)
+	return a.emitEA(field, sizeByte)
+}
+
+// branchTarget returns the displacement from the instruction's PC+2 to a
+// bare absolute target operand.
+func (a *assembler) branchTarget(o operand) (int64, error) {
+	if o.kind != argEA || o.mode != 7 || o.reg > 1 || !o.bare {
+		return 0, errors.New("branch target address expected")
+	}
+	// Addresses wrap at 24 bits, so $FFFE is two bytes before 0.
+	d := (uint32(o.val) - (a.pc + 2)) << 8
+	return int64(int32(d) >> 8), nil
+}
+
+// encodeBranch handles BRA, BSR and Bcc. suffix is "S", "W" or empty.
+func (a *assembler) encodeBranch(cc uint16, suffix string, ops []operand) error {
+	if err := expect(ops, 1); err != nil {
+		return err
+	}
+	disp, err := a.branchTarget(ops[0])
+	if err != nil {
+		return err
+	}
+	op := 0x6000 | cc<<8
+	short := disp != 0 && disp >= -128 && disp <= 127
+	switch suffix {
+	case "S", "B":
+		if !short {
+			return fmt.Errorf("displacement %d does not fit a short branch", disp)
+		}
+	case "W":
+		short = false
+	case "":
+	default:
+		return fmt.Errorf("unknown branch size .%s", suffix)
+	}
+	if short {
+		a.emit(op | uint16(uint8(disp)))
+		return nil
+	}
+	if err := checkRange(disp, 16, "branch displacement"); err != nil {
+		return err
+	}
+	a.emit(op, uint16(disp))
+	return nil
+}
+
+// encodeDBcc handles DBcc Dn,<target>.
+func (a *assembler) encodeDBcc(cc uint16, ops []operand) error {
+	if err := expect(ops, 2); err != nil {
+		return err
+	}
+	if err := needReg(ops[0], 0); err != nil {
+		return err
+	}
+	disp, err := a.branchTarget(ops[1])
+	if err != nil {
+		return err
+	}
+	if err := checkRange(disp, 16, "branch displacement"); err != nil {
+		return err
+	}
+	a.emit(0x50C8|cc<<8|ops[0].reg, uint16(disp))
+	return nil
+}
+
+// splitOperands splits an operand field on the commas outside parentheses.
+func splitOperands(s string) []string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	var out []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				out = append(out, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(out, strings.TrimSpace(s[start:]))
+}
+
+// parseNumber parses a decimal, $hex or 0xhex number with an optional sign.
+func parseNumber(s string) (int64, error) {
+	neg := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg, s = true, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	var v uint64
+	var err error
+	switch {
+	case strings.HasPrefix(s, "$"):
+		v, err = strconv.ParseUint(s[1:], 16, 32)
+	case strings.HasPrefix(s, "0X"), strings.HasPrefix(s, "0x"):
+		v, err = strconv.ParseUint(s[2:], 16, 32)
+	default:
+		v, err = strconv.ParseUint(s, 10, 32)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("bad number %q", s)
+	}
+	if neg {
+		return -int64(v), nil
+	}
+	return int64(v), nil
+}
+
+// parseRegister parses Dn, An or SP, returning the EA mode (0 or 1).
+func parseRegister(s string) (mode, reg uint16, ok bool) {
+	if s == "SP" {
+		return 1, 7, true
+	}
+	if len(s) != 2 || s[1] < '0' || s[1] > '7' {
+		return 0, 0, false
+	}
+	switch s[0] {
+	case 'D':
+		return 0, uint16(s[1] - '0'), true
+	case 'A':
+		return 1, uint16(s[1] - '0'), true
+	}
+	return 0, 0, false
+}
+
+// parseList parses a MOVEM register list such as D0-D3/A0/A6.
+func parseList(s string) (uint16, bool) {
+	var mask uint16
+	for _, part := range strings.Split(s, "/") {
+		lo, hi, isRange := strings.Cut(part, "-")
+		m1, r1, ok := parseRegister(lo)
+		if !ok {
+			return 0, false
+		}
+		first := m1*8 + r1
+		last := first
+		if isRange {
+			m2, r2, ok := parseRegister(hi)
+			if !ok || m2*8+r2 < first {
+				return 0, false
+			}
+			last = m2*8 + r2
+		}
+		for i := first; i <= last; i++ {
+			mask |= 1 << i
+		}
+	}
+	return mask, true
+}
+
+// parseBitfield parses the offset:width of a bit field operand into the
+// low 12 bits of its extension word. Each is a number or a data register;
+// a width of 32 is encoded as 0.
+func parseBitfield(s string) (uint16, error) {
+	offset, width, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, fmt.Errorf("bad bit field %q", s)
+	}
+	var bf uint16
+	for i, f := range []string{offset, width} {
+		hi, shift, dreg := int64(31), uint16(6), uint16(0x0800)
+		if i == 1 {
+			hi, shift, dreg = 32, 0, 0x0020
+		}
+		if mode, reg, ok := parseRegister(f); ok {
+			if mode != 0 {
+				return 0, fmt.Errorf("bad bit field register %q", f)
+			}
+			bf |= dreg | reg<<shift
+			continue
+		}
+		v, err := parseNumber(f)
+		if err != nil {
+			return 0, err
+		}
+		if v < int64(i) || v > hi {
+			return 0, fmt.Errorf("bit field %d out of range", v)
+		}
+		bf |= uint16(v&31) << shift
+	}
+	return bf, nil
+}
+
+// parseOperand parses a single operand in Disassemble's syntax.
+func parseOperand(s string) (operand, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if open := strings.IndexByte(s, '{'); open >= 0 && strings.HasSuffix(s, "}") {
+		bf, err := parseBitfield(s[open+1 : len(s)-1])
+		if err != nil {
+			return operand{}, err
+		}
+		o, err := parseOperand(s[:open])
+		o.bf, o.hasBF = bf, true
+		return o, err
+	}
+	switch s {
+	case "SR":
+		return operand{kind: argSR}, nil
+	case "CCR":
+		return operand{kind: argCCR}, nil
+	case "USP":
+		return operand{kind: argUSP}, nil
+	case "SFC":
+		return operand{kind: argCtrl, reg: ctrlSFC}, nil
+	case "DFC":
+		return operand{kind: argCtrl, reg: ctrlDFC}, nil
+	case "VBR":
+		return operand{kind: argCtrl, reg: ctrlVBR}, nil
+	}
+	if mode, reg, ok := parseRegister(s); ok {
+		return operand{mode: mode, reg: reg}, nil
+	}
+	if h, l, ok := strings.Cut(s, ":"); ok {
+		hm, hr, hok := parseRegister(h)
+		lm, lr, lok := parseRegister(l)
+		if !hok || !lok || hm != 0 || lm != 0 {
+			return operand{}, fmt.Errorf("bad register pair %q", s)
+		}
+		return operand{kind: argPair, hi: hr, reg: lr}, nil
+	}
+	if strings.ContainsAny(s, "/-") && !strings.ContainsAny(s, "($#") {
+		if mask, ok := parseList(s); ok {
+			return operand{kind: argList, list: mask}, nil
+		}
+	}
+	if strings.HasPrefix(s, "#") {
+		v, err := parseNumber(s[1:])
+		return operand{mode: 7, reg: 4, val: v}, err
+	}
+	if strings.HasPrefix(s, "-(") && strings.HasSuffix(s, ")") {
+		if mode, reg, ok := parseRegister(s[2 : len(s)-1]); ok && mode == 1 {
+			return operand{mode: 4, reg: reg}, nil
+		}
+	}
+	if strings.HasSuffix(s, ")+") {
+		if mode, reg, ok := parseRegister(s[1 : len(s)-2]); ok && mode == 1 && s[0] == '(' {
+			return operand{mode: 3, reg: reg}, nil
+		}
+	}
+
+	// Absolute: ($n).W, ($n).L, $n.W, $n.L or a bare $n
+	abs := s
+	absSize := ""
+	if strings.HasSuffix(abs, ".W") || strings.HasSuffix(abs, ".L") {
+		abs, absSize = abs[:len(abs)-2], abs[len(abs)-1:]
+	}
+	if strings.HasPrefix(abs, "(") && strings.HasSuffix(abs, ")") && !strings.Contains(abs, ",") {
+		inner := abs[1 : len(abs)-1]
+		if _, _, ok := parseRegister(inner); !ok && absSize != "" {
+			abs = inner
+		}
+	}
+	if v, err := parseNumber(abs); err == nil {
+		o := operand{mode: 7, val: v, bare: absSize == ""}
+		switch absSize {
+		case "W":
+			if v < -0x8000 || v > 0xFFFF {
+				return o, fmt.Errorf("address %d does not fit .W", v)
+			}
+		case "L":
+			o.reg = 1
+		default:
+			if v < -0x8000 || v > 0x7FFF {
+				o.reg = 1
+			}
+		}
+		return o, nil
+	}
+
+	// Register indirect with optional displacement and index: the
+	// displacement may be written before the parentheses or inside them.
+	open := strings.IndexByte(s, '(')
+	if open < 0 || !strings.HasSuffix(s, ")") {
+		return operand{}, fmt.Errorf("bad operand %q", s)
+	}
+	var disp int64
+	if open > 0 {
+		v, err := parseNumber(s[:open])
+		if err != nil {
+			return operand{}, err
+		}
+		disp = v
+	}
+	fields := strings.Split(s[open+1:len(s)-1], ",")
+	if len(fields) > 1 && open == 0 {
+		if v, err := parseNumber(fields[0]); err == nil {
+			disp, fields = v, fields[1:]
+		}
+	}
+	if len(fields) > 2 {
+		return operand{}, fmt.Errorf("bad operand %q", s)
+	}
+
+	o := operand{val: disp}
+	switch base := fields[0]; {
+	case base == "PC":
+		o.mode, o.reg = 7, 2
+	default:
+		mode, reg, ok := parseRegister(base)
+		if !ok || mode != 1 {
+			return operand{}, fmt.Errorf("bad base register %q", base)
+		}
+		o.mode, o.reg = 2, reg
+		if len(fields) == 1 && (open > 0 || disp != 0) {
+			o.mode = 5
+		}
+	}
+	if len(fields) == 1 {
+		return o, nil
+	}
+
+	xn, xs, _ := strings.Cut(fields[1], ".")
+	mode, reg, ok := parseRegister(xn)
+	if !ok {
+		return operand{}, fmt.Errorf("bad index register %q", fields[1])
+	}
+	o.ext = mode<<15 | reg<<12
+	switch xs {
+	case "", "W":
+	case "L":
+		o.ext |= 0x0800
+	default:
+		return operand{}, fmt.Errorf("bad index size %q", fields[1])
+	}
+	if o.mode == 7 {
+		o.reg = 3
+	} else {
+		o.mode = 6
+	}
+	return o, nil
+}
+
+// wordBus serves instruction words to the disassembler when verifying an
+// encoding. Only Read16 is used.
+type wordBus struct {
+	base  uint32
+	words []uint16
+}
+
+func (b wordBus) Read16(addr uint32) uint16 {
+	i := int(((addr - b.base) & 0xFFFFFF) / 2)
+	if i < len(b.words) {
+		return b.words[i]
+	}
+	return 0
+}
+
+func (wordBus) Read8(uint32) uint8     { return 0 }
+func (wordBus) Read32(uint32) uint32   { return 0 }
+func (wordBus) Write8(uint32, uint8)   {}
+func (wordBus) Write16(uint32, uint16) {}
+func (wordBus) Write32(uint32, uint32) {}
+func (wordBus) Reset()                 {}
+
+// encodeLongMulDiv encodes the 68020 MULU.L, MULS.L, DIVU.L, DIVS.L,
+// DIVUL.L and DIVSL.L forms. The destination is Dl (Dq), or a Dh:Dl
+// (Dr:Dq) pair, which selects the 64-bit form except for DIVUL and DIVSL.
+func (a *assembler) encodeLongMulDiv(name string, ops []operand) error {
+	src, dst := ops[0], ops[1]
+	if err := needEA(src); err != nil {
+		return err
+	}
+	if src.mode == 1 {
+		return errors.New("data addressing mode expected")
+	}
+	op, ext := uint16(0x4C00), uint16(0)
+	if strings.HasPrefix(name, "DIV") {
+		op = 0x4C40
+	}
+	if name[3] == 'S' {
+		ext |= 0x0800
+	}
+	switch {
+	case dst.kind == argPair:
+		if len(name) == 4 {
+			ext |= 0x0400
+		} else if dst.hi == dst.reg {
+			return errors.New(name + " needs two different registers")
+		}
+		ext |= dst.reg<<12 | dst.hi
+	case dst.isReg(0) && len(name) == 4:
+		ext |= dst.reg<<12 | dst.reg
+	default:
+		return errors.New("data register or register pair expected")
+	}
+	a.emit(op|eaField(src), ext)
+	return a.emitEA(src, sizeLong)
+}
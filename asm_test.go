@@ -0,0 +1,150 @@
+package m68k
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAssemble(t *testing.T) {
+	tests := []struct {
+		text  string
+		words []uint16
+	}{
+		// Addressing modes
+		{"MOVE.W D0,D1", []uint16{0x3200}},
+		{"MOVE.W D0,(A1)", []uint16{0x3280}},
+		{"MOVE.B (A0)+,(A1)+", []uint16{0x12D8}},
+		{"MOVE.L -(A0),-(A1)", []uint16{0x2320}},
+		{"MOVE.W -$4(A0),D0", []uint16{0x3028, 0xFFFC}},
+		{"MOVE.W (-4,A0),D0", []uint16{0x3028, 0xFFFC}},
+		{"MOVE.W $2(A0,D1.L),D0", []uint16{0x3030, 0x1802}},
+		{"MOVE.W (A0,A2),D0", []uint16{0x3030, 0xA000}},
+		{"MOVE.W ($8000).W,D0", []uint16{0x3038, 0x8000}},
+		{"MOVE.W $1000,D0", []uint16{0x3038, 0x1000}},
+		{"MOVE.W $FF0010,D0", []uint16{0x3039, 0x00FF, 0x0010}},
+		{"MOVE.W $10(PC),D0", []uint16{0x303A, 0x0010}},
+		{"MOVE.W $6(PC,D3.W),D0", []uint16{0x303B, 0x3006}},
+		{"MOVE.L #$DEADBEEF,D0", []uint16{0x203C, 0xDEAD, 0xBEEF}},
+		{"move.b #-1,d0", []uint16{0x103C, 0x00FF}},
+		{"MOVE D0,D1", []uint16{0x3200}},
+		{"MOVE.L A1,A6", []uint16{0x2C49}},
+		{"MOVE.L SP,-(SP)", []uint16{0x2F0F}},
+
+		// Aliases and defaults
+		{"ADD.L D0,A1", []uint16{0xD3C0}},
+		{"CMP.W (A0),A1", []uint16{0xB2D0}},
+		{"DBRA D0,$FFFFFE", []uint16{0x51C8, 0xFFFC}},
+		{"BHS $10", []uint16{0x640E}},
+		{"SLO D0", []uint16{0x55C0}},
+		{"ASL D1", []uint16{0xE341}},
+		{"MOVEM.L D0,-(A7)", []uint16{0x48E7, 0x8000}},
+
+		// Branches choose the short form when it fits
+		{"BRA $20", []uint16{0x601E}},
+		{"BRA $2", []uint16{0x6000, 0x0000}},
+		{"BNE.W $20", []uint16{0x6600, 0x001E}},
+		{"BSR $1000", []uint16{0x6100, 0x0FFE}},
+
+		// Miscellaneous
+		{"MOVEM.L D0-D1/A0-A1,-(A7)", []uint16{0x48E7, 0xC0C0}},
+		{"MOVEM.W (A0)+,D0/A1", []uint16{0x4C98, 0x0201}},
+		{"MOVEC VBR,D0", []uint16{0x4E7A, 0x0801}},
+		{"MOVEC A1,USP", []uint16{0x4E7B, 0x9800}},
+		{"MOVE.W SR,D0", []uint16{0x40C0}},
+		{"MOVE CCR,(A0)", []uint16{0x42D0}},
+		{"MOVE A0,USP", []uint16{0x4E60}},
+		{"EXG A1,D2", []uint16{0xC589}},
+		{"LINK A6,#-8", []uint16{0x4E56, 0xFFF8}},
+		{"BFEXTU (A0){4:12},D1", []uint16{0xE9D0, 0x110C}},
+		{"BFINS D3,$10(A1){D1:32}", []uint16{0xEFE9, 0x3840, 0x0010}},
+		{"BFTST D0{28:D2}", []uint16{0xE8C0, 0x0722}},
+		{"CHK2.W (A0),D3", []uint16{0x02D0, 0x3800}},
+		{"CMP2.L $10(A1),A2", []uint16{0x04E9, 0xA000, 0x0010}},
+		{"MULU.L D0,D1", []uint16{0x4C00, 0x1001}},
+		{"MULS.L (A0),D3:D2", []uint16{0x4C10, 0x2C03}},
+		{"DIVU.L #$10,D4", []uint16{0x4C7C, 0x4004, 0x0000, 0x0010}},
+		{"DIVS.L D7,D1:D0", []uint16{0x4C47, 0x0C01}},
+		{"DIVUL.L D7,D1:D0", []uint16{0x4C47, 0x0001}},
+		{"CAS.W D1,D2,(A0)", []uint16{0x0CD0, 0x0081}},
+		{"CAS.L D0,D7,$10(A1)", []uint16{0x0EE9, 0x01C0, 0x0010}},
+		{"MOVES.W D1,(A0)", []uint16{0x0E50, 0x1800}},
+		{"MOVES.L $10(A1),A2", []uint16{0x0EA9, 0xA000, 0x0010}},
+		{"ILLEGAL", []uint16{0x4AFC}},
+		{"DC.W $FFFF", []uint16{0xFFFF}},
+	}
+	for _, tt := range tests {
+		got, err := Assemble(tt.text)
+		if err != nil {
+			t.Errorf("Assemble(%q): %v", tt.text, err)
+			continue
+		}
+		var want []byte
+		for _, w := range tt.words {
+			want = append(want, byte(w>>8), byte(w))
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("Assemble(%q) = % X, want % X", tt.text, got, want)
+		}
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	for _, text := range []string{
+		"FOO D0",
+		"MOVE.B D0,A1",     // no MOVEA.B
+		"MOVE.B A0,D0",     // no byte access to An
+		"MOVE.W D0,$4(PC)", // PC-relative destination
+		"ADDQ.W #9,D0",
+		"ADD.W #1,(A0)", // needs ADDI
+		"EOR.W D0,A1",
+		"AND.W D0,A1",
+		"BRA.S $2",    // zero displacement has no short form
+		"BRA.S $1000", // out of short range
+		"LEA D0,A0",   // not a control mode
+		"MOVE.W $12345(A0),D0",
+		"NOP D0",
+		"MOVE.S D0,D1",
+	} {
+		if b, err := Assemble(text); err == nil {
+			t.Errorf("Assemble(%q) = % X, want an error", text, b)
+		}
+	}
+}
+
+// TestAssembleRoundTrip reassembles the disassembly of every implemented
+// opcode, with fixed extension words, and expects the original bytes.
+func TestAssembleRoundTrip(t *testing.T) {
+	bus := &testBus{}
+	cpu := New(bus)
+	const at = 0x1000
+	for i := 1; i < 5; i++ {
+		writeWord(bus, at+uint32(i*2), 0x0010)
+	}
+	for op := 0; op < 0x10000; op++ {
+		if !IsImplemented(uint16(op)) || op == 0x4E7A || op == 0x4E7B {
+			continue // MOVEC selector $010 is not a control register
+		}
+		if op&0xF9C0 == 0x08C0 && op&0x0600 != 0 {
+			continue // CAS extension $0010 sets a reserved bit
+		}
+		if op&0xF9C0 == 0x00C0 && op&0x0600 != 0x0600 {
+			continue // and so does the CHK2 and CMP2 one
+		}
+		if op&0xFF80 == 0x4C00 {
+			continue // and the long multiply and divide one
+		}
+		if op&0xFF00 == 0x0E00 && op&0x00C0 != 0x00C0 {
+			continue // and the MOVES one
+		}
+		writeWord(bus, at, uint16(op))
+		text, n := cpu.Disassemble(at)
+		got, err := AssembleAt(at, text)
+		if err != nil {
+			t.Errorf("%04X %q: %v", op, text, err)
+			continue
+		}
+		if want := bus.mem[at : at+n]; !bytes.Equal(got, want) {
+			t.Errorf("%04X %q: assembled % X, want % X", op, text, got, want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package m68k
+
+// WriteWord stores v at addr in big-endian order, as the CPU lays out a
+// word, using byte writes so that addr may be odd. Addresses are masked
+// to 24 bits and wrap from 0xFFFFFF to 0.
+func WriteWord(bus Bus, addr uint32, v uint16) {
+	bus.Write8(addr&0xFFFFFF, uint8(v>>8))
+	bus.Write8((addr+1)&0xFFFFFF, uint8(v))
+}
+
+// WriteLong stores v at addr in big-endian order, as WriteWord.
+func WriteLong(bus Bus, addr uint32, v uint32) {
+	WriteWord(bus, addr, uint16(v>>16))
+	WriteWord(bus, addr+2, uint16(v))
+}
+
+// ReadWord returns the big-endian word at addr, read as WriteWord writes it.
+func ReadWord(bus Bus, addr uint32) uint16 {
+	return uint16(bus.Read8(addr&0xFFFFFF))<<8 | uint16(bus.Read8((addr+1)&0xFFFFFF))
+}
+
+// ReadLong returns the big-endian long at addr, as ReadWord.
+func ReadLong(bus Bus, addr uint32) uint32 {
+	return uint32(ReadWord(bus, addr))<<16 | uint32(ReadWord(bus, addr+2))
+}
@@ -0,0 +1,76 @@
+package m68k
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// traceCPU builds a CPU at 0x1000 running prog with D0 and A0 preset and
+// a BusTracer attached.
+func traceCPU(prog []uint16, d0, a0 uint32) (*CPU, *BusTracer) {
+	cpu, bus := progCPU(MC68000, Registers{D: [8]uint32{d0}, A: [8]uint32{a0}, SR: 0x2700}, prog...)
+	bus.Write32(0x4000, 0x12345678)
+	tr := &BusTracer{}
+	cpu.SetBusTracer(tr)
+	return cpu, tr
+}
+
+func TestBusTracer(t *testing.T) {
+	// MOVE.W D0,(A0); MOVE.L (A0),D1; MOVE.B #$5A,$10(A0)
+	cpu, tr := traceCPU([]uint16{0x3080, 0x2210, 0x117C, 0x005A, 0x0010}, 0xBEEF, 0x4000)
+	cpu.RunInstructions(3)
+
+	want := []BusAccess{
+		{0, false, 2, 0x1000, 0x3080, FCSuperProgram},
+		{4, true, 2, 0x4000, 0xBEEF, FCSuperData},
+		{8, false, 2, 0x1002, 0x2210, FCSuperProgram},
+		{12, false, 4, 0x4000, 0xBEEF5678, FCSuperData},
+		{20, false, 2, 0x1004, 0x117C, FCSuperProgram},
+		{24, false, 2, 0x1006, 0x005A, FCSuperProgram},
+		{28, false, 2, 0x1008, 0x0010, FCSuperProgram},
+		{32, true, 1, 0x4010, 0x5A, FCSuperData},
+	}
+	if len(tr.Accesses) != len(want) {
+		t.Fatalf("recorded %d accesses, want %d: %+v", len(tr.Accesses), len(want), tr.Accesses)
+	}
+	for i, a := range tr.Accesses {
+		if a != want[i] {
+			t.Errorf("access %d = %+v, want %+v", i, a, want[i])
+		}
+	}
+
+	var csv bytes.Buffer
+	if err := tr.DumpCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	if len(lines) != len(want)+1 || lines[0] != "cycle,rw,size,addr,data,fc" {
+		t.Fatalf("CSV = %q", csv.String())
+	}
+	if lines[2] != "4,W,2,004000,BEEF,5" {
+		t.Errorf("CSV row = %q, want %q", lines[2], "4,W,2,004000,BEEF,5")
+	}
+
+	var vcd bytes.Buffer
+	if err := tr.DumpVCD(&vcd); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"$enddefinitions $end", "#4\n1!\n0\"\nb10 #\nb100000000000000 $\nb1011111011101111 %\nb101 &\n", "#7\n0!\n"} {
+		if !strings.Contains(vcd.String(), s) {
+			t.Errorf("VCD missing %q", s)
+		}
+	}
+
+	t.Run("detached", func(t *testing.T) {
+		tr.Reset()
+		cpu.SetBusTracer(nil)
+		cpu.Step()
+		if len(tr.Accesses) != 0 {
+			t.Errorf("recorded %d accesses after detaching", len(tr.Accesses))
+		}
+		if cpu.hooks&(hookBus|hookBusTrace) != 0 {
+			t.Errorf("hooks = %b, want the bus hooks off", cpu.hooks)
+		}
+	})
+}
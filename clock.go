@@ -0,0 +1,64 @@
+package m68k
+
+import "math/bits"
+
+// ClockFunc is called by a Clock with the whole number of ticks a
+// subsystem has advanced by since its last call.
+type ClockFunc func(ticks uint64)
+
+// Clock runs a CPU alongside subsystems clocked at other rates, such as a
+// sound chip at 3.58 MHz next to a 68000 at 7.67 MHz. Each Run advances
+// the machine by a number of CPU cycles and converts that time into each
+// subsystem's ticks with an exact remainder, so the rates never drift
+// apart however the cycles are sliced.
+type Clock struct {
+	cpu     *CPU
+	hz      uint64
+	devices []clockDevice
+}
+
+// clockDevice is a subsystem on a Clock. rem is the part of a tick owed
+// to it, in units of 1/Clock.hz of a tick.
+type clockDevice struct {
+	hz  uint64
+	fn  ClockFunc
+	rem uint64
+}
+
+// NewClock returns a Clock driving cpu, which runs at hz cycles per
+// second. hz must not be 0.
+func NewClock(cpu *CPU, hz uint64) *Clock {
+	return &Clock{cpu: cpu, hz: hz}
+}
+
+// Add attaches a subsystem running at hz ticks per second, called with
+// the ticks it has advanced by after each Run. Subsystems are called in
+// the order they were added.
+func (k *Clock) Add(hz uint64, fn ClockFunc) {
+	k.devices = append(k.devices, clockDevice{hz: hz, fn: fn})
+}
+
+// Run advances the machine by cycles CPU cycles: it runs the CPU with
+// RunCycles, then calls each subsystem with its share of the same time,
+// including any it has not yet been given in whole ticks. The subsystems
+// advance by the full slice even if the CPU halts or overruns it; the
+// overrun is carried into the next Run as RunCycles does. It returns the
+// cycles the CPU consumed.
+//
+// Subsystems see time only at slice boundaries, so an embedder that needs
+// finer interleaving, such as raising an interrupt part way through a
+// frame, calls Run with smaller slices.
+func (k *Clock) Run(cycles uint64) uint64 {
+	n := k.cpu.RunCycles(cycles)
+	for i := range k.devices {
+		d := &k.devices[i]
+		hi, lo := bits.Mul64(cycles, d.hz)
+		lo, carry := bits.Add64(lo, d.rem, 0)
+		var ticks uint64
+		ticks, d.rem = bits.Div64(hi+carry, lo, k.hz)
+		if ticks != 0 {
+			d.fn(ticks)
+		}
+	}
+	return n
+}
@@ -0,0 +1,65 @@
+package m68k
+
+import "testing"
+
+func TestClock(t *testing.T) {
+	const cpuHz, psgHz = 7670454, 3579545
+
+	t.Run("no drift", func(t *testing.T) {
+		cpu := loopCPU(0x4E71, 0x5280) // NOP; ADDQ.L #1,D0
+		k := NewClock(cpu, cpuHz)
+		var psg, same uint64
+		k.Add(psgHz, func(ticks uint64) { psg += ticks })
+		k.Add(cpuHz, func(ticks uint64) { same += ticks })
+
+		var total uint64
+		for i := 0; i < 10000; i++ {
+			slice := uint64(1 + i%37) // slices shorter than most instructions too
+			k.Run(slice)
+			total += slice
+			if want := total * psgHz / cpuHz; psg != want {
+				t.Fatalf("after %d cycles: %d ticks, want %d", total, psg, want)
+			}
+		}
+		if same != total {
+			t.Errorf("same-rate ticks = %d, want %d", same, total)
+		}
+		if got := cpu.Cycles() - uint64(cpu.Deficit()); got != total {
+			t.Errorf("CPU cycles less deficit = %d, want %d", got, total)
+		}
+	})
+
+	t.Run("one second", func(t *testing.T) {
+		cpu := loopCPU(0x4E71)
+		k := NewClock(cpu, cpuHz)
+		var psg uint64
+		calls := 0
+		k.Add(psgHz, func(ticks uint64) { psg += ticks; calls++ })
+		for i := 0; i < 60; i++ {
+			k.Run(cpuHz / 60)
+		}
+		k.Run(cpuHz % 60)
+		if psg != psgHz {
+			t.Errorf("ticks after one second = %d, want %d", psg, psgHz)
+		}
+		if calls != 61 {
+			t.Errorf("calls = %d, want 61", calls)
+		}
+	})
+
+	t.Run("halted CPU", func(t *testing.T) {
+		cpu, _ := newNOPCPU(1)
+		cpu.SetState(Registers{PC: 0x1001, SR: 0x2700, SSP: 0x10000})
+		k := NewClock(cpu, 8)
+		var ticks uint64
+		k.Add(3, func(n uint64) { ticks += n })
+		k.Run(1000)
+		k.Run(1000)
+		if !cpu.Halted() {
+			t.Fatal("CPU not halted")
+		}
+		if ticks != 750 {
+			t.Errorf("ticks = %d, want 750", ticks)
+		}
+	})
+}
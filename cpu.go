@@ -8,12 +8,14 @@
 //   - Dual stack pointers (USP for user mode, SSP for supervisor mode)
 package m68k
 
-import "log"
-
 // Bus provides word-aligned memory access for the CPU.
-// All addresses are 24-bit (masked by the CPU before calling).
+// All addresses are 24-bit (masked by the CPU before calling), and a long
+// access at 0xFFFFFE is made as two word accesses so none runs past the top
+// of the address space.
 // Word and long accesses to odd addresses are detected by the CPU
 // and cause an address error before reaching the bus.
+// A bus that needs to terminate an access with BERR (unmapped memory,
+// protection faults) calls CPU.BusError from within the Read or Write.
 type Bus interface {
 	Read8(addr uint32) uint8
 	Read16(addr uint32) uint16
@@ -24,6 +26,87 @@ type Bus interface {
 	Reset()
 }
 
+// RMWBus is an optional extension of Bus for devices that need to observe
+// indivisible read-modify-write cycles. On the 68000, TAS holds AS asserted
+// from its read through its write so no other bus master can intervene.
+// When the bus implements RMWBus, the CPU brackets such an access with
+// BeginRMW and EndRMW; the read and write themselves still go through
+// Read8 and Write8. A bus or address error inside the sequence ends it
+// with EndRMW before the exception is processed.
+type RMWBus interface {
+	Bus
+	BeginRMW(addr uint32)
+	EndRMW(addr uint32)
+}
+
+// FCBus is an optional extension of Bus for devices that decode the
+// function code outputs FC2-FC0, such as memory protection or MMU models.
+// When the bus implements FCBus, the CPU calls SetFunctionCode with the
+// function code of each bus cycle immediately before the Read or Write
+// that performs it, and with FCCPUSpace when it acknowledges an interrupt.
+// Plain Bus implementations never see function codes.
+type FCBus interface {
+	Bus
+	SetFunctionCode(fc uint8)
+}
+
+// SupervisorBus is an optional extension of Bus for devices that only
+// need to tell supervisor accesses from user ones, such as a simple
+// memory protection model, without decoding full function codes. When the
+// bus implements SupervisorBus, the CPU calls SetSupervisor immediately
+// before each Read or Write with whether the access is made in supervisor
+// mode; a protecting bus rejects an access with CPU.BusError.
+type SupervisorBus interface {
+	Bus
+	SetSupervisor(supervisor bool)
+}
+
+// CycleBus is an optional extension of Bus for devices whose state depends
+// on when within an instruction an access happens, such as a video chip
+// that changes state part way through one. When the bus implements
+// CycleBus, the CPU calls SetCycle immediately before each Read or Write
+// with the cycle, on the scale of Cycles, at which that access starts.
+// Successive stamps never decrease.
+type CycleBus interface {
+	Bus
+	SetCycle(cycle uint64)
+}
+
+// MC68000 function codes (FC2-FC0) reported to an FCBus.
+const (
+	FCUserData     = 1
+	FCUserProgram  = 2
+	FCSuperData    = 5
+	FCSuperProgram = 6
+	FCCPUSpace     = 7 // Interrupt acknowledge
+)
+
+// Variant selects the member of the 68000 family being emulated.
+type Variant int
+
+const (
+	MC68000 Variant = iota // 16-bit data bus
+	MC68008                // 8-bit data bus: words and longs take one byte cycle per byte
+	MC68010                // Adds the vector base register, SFC/DFC and MOVEC
+	MC68020                // Adds scaled and full-format indexing; addresses stay 24-bit
+)
+
+// String returns the part number of the variant.
+func (v Variant) String() string {
+	switch v {
+	case MC68000:
+		return "MC68000"
+	case MC68008:
+		return "MC68008"
+	case MC68010:
+		return "MC68010"
+	case MC68020:
+		return "MC68020"
+	default:
+		return "unknown"
+	}
+}
+
 // Registers holds the programmer-visible state of the MC68000.
 type Registers struct {
 	D   [8]uint32 // Data registers
@@ -33,112 +116,479 @@ type Registers struct {
 	USP uint32    // User stack pointer (shadowed)
 	SSP uint32    // Supervisor stack pointer (shadowed)
 	IR  uint16    // Instruction register (first word of executing instruction)
+	VBR uint32    // Vector base register (68010; always 0 on the 68000)
+	SFC uint8     // Source function code (68010)
+	DFC uint8     // Destination function code (68010)
 }
 
 // CPU is the MC68000 processor.
 type CPU struct {
 	reg    Registers
 	bus    Bus
+	fcBus  FCBus         // bus as an FCBus, or nil
+	svBus  SupervisorBus // bus as a SupervisorBus, or nil
+	rmwBus RMWBus        // bus as an RMWBus, or nil
 	cycles uint64
 
+	// altFC replaces the function code of data accesses while useAltFC
+	// is set, for MOVES.
+	altFC    uint8
+	useAltFC bool
+
+	// cycleBus is the bus as a CycleBus, or nil. busStamp is the earliest
+	// cycle the next access can start at, and accessStamp the cycle the
+	// current one started at.
+	cycleBus    CycleBus
+	busStamp    uint64
+	accessStamp uint64
+
+	// busTracer, if set, records every bus access.
+	busTracer *BusTracer
+
 	// The instruction register holds the first word of the currently
 	// executing instruction, latched at fetch time.
 	ir uint16
 
-	stopped bool   // Set by STOP, cleared by interrupt
+	variant Variant
+
+	stopped bool   // Set by STOP, cleared by interrupt or trace
 	halted  bool   // Set by double bus fault
 	prevPC  uint32 // PC of the previous instruction (for diagnostics)
 
+	// haltReason records why halted was set.
+	haltReason HaltReason
+
+	// strict halts the CPU on an MC68000 instruction missing from the
+	// opcode table.
+	strict bool
+
+	// undefFlags selects how undefined condition codes are set.
+	undefFlags UndefinedFlagsMode
+
+	// Trace state. trace latches the T bit at the start of an instruction
+	// and is cleared if the instruction does not complete; tracePending
+	// carries it to the start of the next Step, where the trace exception
+	// is taken.
+	trace        bool
+	tracePending bool
+
+	// Bus error state. berr is raised by BusError during a bus access and
+	// consumed by readBus/writeBus once the access returns. groupZero is
+	// set while a bus or address error frame is being stacked, and
+	// inException while any other exception is being processed; both feed
+	// the group 0 status word and double fault detection.
+	berr        bool
+	berrAddr    uint32
+	groupZero   bool
+	inException bool
+
+	// rmwLocked is set while TAS or CAS holds an RMWBus locked at
+	// rmwAddr, so that a bus or address error inside the sequence can
+	// release it.
+	rmwLocked bool
+	rmwAddr   uint32
+
+	// Optional prefetch queue model. When prefetch is enabled, pq holds the
+	// instruction words at pqAddr and pqAddr+2, read ahead of execution
+	// the way the 68000's IR/IRC pipeline reads them; pqValid is cleared
+	// whenever the queue must be refilled from memory.
+	prefetch bool
+	pqValid  bool
+	pqAddr   uint32
+	pq       [2]uint16
+
+	// faultAdj corrects the PC stacked by a bus or address error for the
+	// prefetch the 68000 would not yet (negative) or would already
+	// (positive) have performed at the faulting access. It is set by the
+	// EA helpers and reset at the start of each instruction.
+	faultAdj int32
+
+	// Watchpoints: watch maps byte addresses to watchRead/watchWrite
+	// flags, checked on data accesses only while it is non-empty.
+	watch     map[uint32]uint8
+	watchFunc WatchpointFunc
+
+	// hooks holds the hook bits of the optional features in use; see
+	// updateHooks.
+	hooks uint8
+
+	// traceFunc, if set, is called for each instruction before dispatch.
+	traceFunc TraceFunc
+
+	// stackFunc, if set, is called when an instruction leaves A7 odd;
+	// stackBefore holds A7 from the start of the instruction.
+	stackFunc   StackMisalignedFunc
+	stackBefore uint32
+
+	// instrFunc, if set, is called with the cycles of each Step.
+	instrFunc InstructionFunc
+
+	// illegalFunc, if set, is called before an illegal or Line A/F
+	// exception is taken.
+	illegalFunc IllegalFunc
+
+	// resetPinFunc, if set, is called when RESET asserts the reset output.
+	// resetCycles and resetPulse override the instruction's timing when
+	// non-zero.
+	resetPinFunc ResetPinFunc
+	resetCycles  int
+	resetPulse   int
+
+	// logFunc, if set, receives diagnostic messages; see SetLogf.
+	logFunc func(format string, args ...any)
+
+	// lastExc records the most recent exception or interrupt; see
+	// LastException.
+	lastExc lastException
+
 	// Interrupt state
 	pendingIPL uint8  // Pending interrupt priority level (1-7, 0=none)
 	pendingVec *uint8 // Pending interrupt vector (nil = auto-vector)
+	pulseIPL   uint8  // Pending PulseInterrupt level, separate from the IPL lines
+	pulseVec   *uint8 // Vector for pulseIPL (nil = auto-vector)
+	intAckFunc IntAckFunc
+
+	profile *profile // per-opcode counts, nil unless profiling
+	history *history // recent instructions, nil unless enabled
+
+	// 68010 loop mode. lastOp and lastOpPC record the previously executed
+	// instruction so a DBcc can recognise a one-word loop body; while
+	// loopMode is set the body at loopPC runs without opcode fetches.
+	lastOp   uint16
+	lastOpPC uint32
+	loopMode bool
+	loopPC   uint32
 
 	// Cycle deficit from StepCycles when an instruction's cost exceeded the budget.
 	deficit int
 }
 
-// New creates a CPU wired to the given bus and performs a hardware reset.
-// The reset reads the initial SSP from address 0 and PC from address 4.
+// New creates an MC68000 wired to the given bus and performs a hardware
+// reset. The reset reads the initial SSP from address 0 and PC from
+// address 4.
 func New(bus Bus) *CPU {
-	c := &CPU{bus: bus}
-	c.Reset()
+	return NewVariant(bus, MC68000)
+}
+
+// NewVariant creates a CPU of the given variant wired to bus and performs
+// a hardware reset of the CPU alone, as ResetCPU does.
+func NewVariant(bus Bus, v Variant) *CPU {
+	c := &CPU{bus: bus, variant: v}
+	c.ResetCPU()
 	return c
 }
 
-// Reset performs a hardware reset: loads SSP from address 0x000000 and
-// PC from address 0x000004, enters supervisor mode with interrupts masked.
+// Variant returns the variant the CPU was created as.
+func (c *CPU) Variant() Variant {
+	return c.variant
+}
+
+// Reset performs a system reset, as when the host asserts the RESET line
+// shared by the CPU and its peripherals: it calls Bus.Reset and then
+// resets the CPU with ResetCPU, so the vectors are read from the freshly
+// reset bus.
 func (c *CPU) Reset() {
+	c.bus.Reset()
+	c.ResetCPU()
+}
+
+// ResetCPU performs a hardware reset of the CPU alone: loads SSP from
+// address 0x000000 and PC from address 0x000004, enters supervisor mode
+// with interrupts masked, and clears the halted, stopped, pending
+// interrupt and trace state. Bus.Reset is not called, which suits a bus
+// shared with devices that must keep their state. This is also the only
+// way out of the halted state after a double bus fault; the RESET
+// instruction resets external devices but never the CPU itself.
+func (c *CPU) ResetCPU() {
+	c.resetState()
+	c.setResetVectors(c.bus.Read32(0), c.bus.Read32(4))
+}
+
+// ResetTo performs a hardware reset like ResetCPU, but takes the initial
+// SSP and PC from its arguments instead of reading the vector table, so
+// the bus is not accessed. It suits tests and systems that only map ROM
+// over the vectors while RESET is asserted.
+func (c *CPU) ResetTo(ssp, pc uint32) {
+	c.resetState()
+	c.setResetVectors(ssp, pc)
+}
+
+// resetState clears everything a hardware reset clears, leaving the
+// stack pointer and PC to the caller.
+func (c *CPU) resetState() {
+	c.clearLoop()
 	c.reg = Registers{SR: 0x2700}
+	c.fcBus, _ = c.bus.(FCBus)
+	c.svBus, _ = c.bus.(SupervisorBus)
+	c.rmwBus, _ = c.bus.(RMWBus)
+	c.cycleBus, _ = c.bus.(CycleBus)
+	c.updateHooks()
 	c.stopped = false
 	c.halted = false
+	c.haltReason = HaltNone
 	c.cycles = 0
+	c.busStamp = 0
 	c.deficit = 0
+	c.lastExc = lastException{}
 	c.pendingIPL = 0
 	c.pendingVec = nil
+	c.pulseIPL = 0
+	c.pulseVec = nil
+	c.trace = false
+	c.tracePending = false
+	c.clearFault()
+}
 
-	ssp := c.bus.Read32(0)
+func (c *CPU) setResetVectors(ssp, pc uint32) {
 	c.reg.A[7] = ssp
 	c.reg.SSP = ssp
-	c.reg.PC = c.bus.Read32(4)
+	c.reg.PC = pc
+	c.pqValid = false
 }
 
-// Halted returns true if the CPU is halted due to a double bus fault.
+// Halted returns true if the CPU is halted due to a double bus fault, or
+// in strict mode on an unimplemented instruction. HaltReason tells which.
 func (c *CPU) Halted() bool {
 	return c.halted
 }
 
+// HaltReason identifies why the CPU halted.
+type HaltReason uint8
+
+const (
+	HaltNone                HaltReason = iota // not halted
+	HaltDoubleBusFault                        // bus or address error during group 0 processing
+	HaltOddStack                              // address error stacking a group 0 frame on an odd SSP
+	HaltOddHandler                            // odd handler address for a bus or address error
+	HaltExceptionReentry                      // exception raised while stacking an exception frame
+	HaltUninitializedVector                   // zero vector and zero uninitialized-interrupt vector
+	HaltUnimplemented                         // unimplemented instruction in strict mode
+)
+
+var haltReasonNames = [...]string{
+	HaltNone:                "none",
+	HaltDoubleBusFault:      "double bus fault",
+	HaltOddStack:            "odd stack pointer during exception",
+	HaltOddHandler:          "odd exception handler address",
+	HaltExceptionReentry:    "exception during exception processing",
+	HaltUninitializedVector: "uninitialized vector",
+	HaltUnimplemented:       "unimplemented instruction",
+}
+
+// String returns a short description of the reason.
+func (r HaltReason) String() string {
+	if int(r) < len(haltReasonNames) {
+		return haltReasonNames[r]
+	}
+	return "unknown"
+}
+
+// HaltReason returns why the CPU halted, or HaltNone if it has not. The
+// reason is runtime-only: it is not part of a serialized snapshot, and a
+// CPU restored in the halted state reports HaltNone.
+func (c *CPU) HaltReason() HaltReason {
+	return c.haltReason
+}
+
+// halt stops the CPU for reason.
+func (c *CPU) halt(reason HaltReason) {
+	c.halted = true
+	c.haltReason = reason
+}
+
+// Unimplemented returns true if the CPU halted in strict mode because the
+// instruction at PrevPC is an MC68000 instruction the opcode table lacks.
+func (c *CPU) Unimplemented() bool {
+	return c.haltReason == HaltUnimplemented
+}
+
+// SetStrictUnimplemented enables or disables strict mode, for bringing up
+// new code. In strict mode an opcode word with no handler that is
+// nevertheless a valid MC68000 instruction halts the CPU, as reported by
+// Unimplemented, instead of taking an illegal instruction exception.
+// Words the MC68000 itself rejects still take their usual exceptions.
+// Disabled by default.
+func (c *CPU) SetStrictUnimplemented(enabled bool) {
+	c.strict = enabled
+}
+
+// Stopped returns true if the CPU has executed STOP and is waiting for an
+// interrupt or trace exception to resume.
+func (c *CPU) Stopped() bool {
+	return c.stopped
+}
+
+// PrevPC returns the address of the most recently started instruction.
+// While the CPU is stopped this is the STOP instruction itself.
+func (c *CPU) PrevPC() uint32 {
+	return c.prevPC
+}
+
+// Hook bits for the optional features that add work to every Step or
+// every bus access. With none in use, Step and the bus accesses pay a
+// single test of CPU.hooks.
+const (
+	hookTrace    = 1 << iota // traceFunc is set
+	hookProfile              // profiling is enabled
+	hookHistory              // the instruction history is enabled
+	hookWatch                // watchpoints are set
+	hookBus                  // an optional bus interface or busTracer is in use
+	hookStack                // stackFunc is set
+	hookBusTrace             // busTracer is set
+
+	hookStep = hookTrace | hookProfile | hookHistory | hookStack
+	hookEnd  = hookProfile | hookStack
+)
+
+// updateHooks recomputes hooks. Every method that installs or removes an
+// optional feature calls it.
+func (c *CPU) updateHooks() {
+	c.hooks = 0
+	if c.traceFunc != nil {
+		c.hooks |= hookTrace
+	}
+	if c.profile != nil {
+		c.hooks |= hookProfile
+	}
+	if c.history != nil {
+		c.hooks |= hookHistory
+	}
+	if len(c.watch) != 0 {
+		c.hooks |= hookWatch
+	}
+	if c.fcBus != nil || c.svBus != nil || c.cycleBus != nil || c.busTracer != nil {
+		c.hooks |= hookBus
+	}
+	if c.busTracer != nil {
+		c.hooks |= hookBusTrace
+	}
+	if c.stackFunc != nil {
+		c.hooks |= hookStack
+	}
+}
+
+// stepHooks runs the per-instruction work of the enabled Step hooks once
+// the opcode has been fetched.
+func (c *CPU) stepHooks() {
+	if c.profile != nil {
+		c.profile.count[c.ir]++
+	}
+	if c.history != nil {
+		c.history.record(c.prevPC, c.ir)
+	}
+	if c.traceFunc != nil {
+		c.traceFunc(c.prevPC, c.ir, c.Registers())
+	}
+	c.stackBefore = c.reg.A[7]
+}
+
+// endHooks runs the enabled hooks that follow an instruction which began
+// at cycle start.
+func (c *CPU) endHooks(start uint64) {
+	if c.profile != nil {
+		c.profile.cycles[c.ir] += c.cycles - start
+	}
+	if sp := c.reg.A[7]; c.stackFunc != nil && sp&1 != 0 && sp != c.stackBefore && !c.halted {
+		c.stackFunc(sp)
+	}
+}
+
 // Step executes a single instruction and returns the number of cycles consumed.
-// Returns 0 if the CPU is halted (double bus fault).
-func (c *CPU) Step() int {
+// Returns 0 if the CPU is halted (double bus fault or strict mode).
+func (c *CPU) Step() (n int) {
 	if c.halted {
 		return 0
 	}
 
 	before := c.cycles
 
+	// A bus or address error aborts the instruction part way through.
+	// The group 0 exception has already been taken when the abort
+	// unwinds to here; only the cycle total remains to be reported.
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(busAbort); !ok {
+				panic(r)
+			}
+			n = int(c.cycles - before)
+		}
+		if c.instrFunc != nil {
+			c.instrFunc(n)
+		}
+	}()
+
+	// A trace exception from the previous instruction is taken before
+	// anything else, including a STOP that was traced.
+	if c.tracePending {
+		c.tracePending = false
+		c.stopped = false
+		c.exception(vecTrace)
+	}
+
+	// A stopped CPU samples the interrupt lines before idling, so an
+	// interrupt that arrived since the last Step costs only its exception
+	// processing and resumes at the instruction after STOP.
 	if c.stopped {
-		c.cycles += 4
 		c.checkInterrupt()
+		if c.stopped {
+			c.cycles += 4
+		}
 		return int(c.cycles - before)
 	}
 
 	c.checkInterrupt()
+	c.trace = c.reg.SR&flagT != 0
+	mask := interruptMask(c.reg.SR)
 
-	// Address error: instruction fetch from odd PC
-	if c.reg.PC&1 != 0 {
-		log.Printf("[m68k] address error: odd PC=%06x prevPC=%06x prevIR=%04x",
-			c.reg.PC, c.prevPC, c.ir)
-		c.halted = true
-		return 0
-	}
-
+	start := c.cycles
 	c.prevPC = c.reg.PC
+	c.faultAdj = 0
 	c.ir = c.fetchPC()
 	c.reg.IR = c.ir
+	if c.hooks&hookStep != 0 {
+		c.stepHooks()
+	}
 
 	handler := opcodeTable[c.ir]
+	if handler == nil && c.strict && validMC68000(c.ir) {
+		c.logf("[m68k] unimplemented opcode %04x at %06x", c.ir, c.prevPC)
+		c.halt(HaltUnimplemented)
+		return int(c.cycles - before)
+	}
 	if handler == nil {
-		switch c.ir >> 12 {
-		case 0xA:
-			c.exception(vecLineA)
-		case 0xF:
-			c.exception(vecLineF)
-		default:
-			c.exception(vecIllegalInstruction)
-		}
+		c.exception(vecIllegalInstruction)
 	} else {
 		handler(c)
 	}
 
-	// Post-instruction odd-PC check: catch branches/jumps to odd addresses.
-	// On real hardware the prefetch pipeline would trigger this during the
-	// instruction; we don't model prefetch so check here instead.
+	if c.loopMode {
+		c.loopStep()
+	}
+	c.lastOp, c.lastOpPC = c.ir, c.prevPC
+	if c.hooks&hookEnd != 0 {
+		c.endHooks(start)
+	}
+
+	// Post-instruction odd-PC check: catch any transfer of control to an
+	// odd address that the instruction did not fault on itself (BSR does
+	// not, and stacks the odd target). On real hardware the prefetch from
+	// the target raises the address error.
 	if !c.halted && c.reg.PC&1 != 0 {
-		log.Printf("[m68k] address error: odd PC=%06x prevPC=%06x IR=%04x",
+		c.logf("[m68k] address error: odd PC=%06x prevPC=%06x IR=%04x",
 			c.reg.PC, c.prevPC, c.ir)
-		c.halted = true
+		c.faultAdj = 0
+		c.addressError(c.reg.PC, sizeWord, true, true)
+	}
+
+	// An instruction that lowers the interrupt mask (MOVE to SR, ANDI or
+	// EORI to SR, RTE, STOP) admits a pending interrupt at this boundary,
+	// before the next instruction starts. A pending trace is taken first,
+	// by the next Step.
+	if c.pendingIPL|c.pulseIPL != 0 && !c.trace && !c.halted && interruptMask(c.reg.SR) < mask {
+		c.checkInterrupt()
 	}
 
+	c.tracePending = c.trace
 	return int(c.cycles - before)
 }
 
@@ -174,6 +624,80 @@ func (c *CPU) StepCycles(budget int) int {
 	return budget
 }
 
+// RunInstructions executes up to n instructions with Step and returns the
+// cycles consumed. It stops early if the CPU halts. A STOPped CPU counts
+// each 4-cycle idle step as an instruction. The StepCycles deficit is not
+// consulted or changed.
+func (c *CPU) RunInstructions(n int) uint64 {
+	var total uint64
+	for i := 0; i < n && !c.halted; i++ {
+		total += uint64(c.Step())
+	}
+	return total
+}
+
+// RunCycles executes instructions with StepCycles until budget cycles have
+// been consumed or the CPU halts, and returns the cycles consumed. An
+// instruction that overruns the budget leaves a deficit that is paid down
+// first by the next RunCycles or StepCycles call, so consecutive calls
+// add up to exactly the budgets passed.
+func (c *CPU) RunCycles(budget uint64) uint64 {
+	var total uint64
+	for total < budget {
+		n := c.StepCycles(int(budget - total))
+		if n == 0 {
+			break
+		}
+		total += uint64(n)
+	}
+	return total
+}
+
+// RunUntil executes instructions with Step until stop returns true, the
+// CPU halts, or at least maxCycles cycles have been consumed. stop is
+// called before each instruction, so it sees the state the next
+// instruction will start from; if it is already true nothing runs. It
+// returns the cycles consumed and whether stop ended the run. The last
+// instruction may carry the total past maxCycles.
+func (c *CPU) RunUntil(stop func(c *CPU) bool, maxCycles uint64) (cycles uint64, stopped bool) {
+	for cycles < maxCycles && !c.halted {
+		if stop(c) {
+			return cycles, true
+		}
+		cycles += uint64(c.Step())
+	}
+	return cycles, !c.halted && stop(c)
+}
+
+// stepOverCycles is the cycle ceiling for StepOver, so a subroutine that
+// never returns cannot hang the caller.
+const stepOverCycles = 100_000_000
+
+// StepOver executes the next instruction like Step, except that a call
+// (BSR, JSR or TRAP) runs until its subroutine or handler returns: PC
+// reaches the instruction after the call with the stack unwound to at
+// least where it was. It stops early if the CPU halts or stepOverCycles
+// cycles pass, and returns the cycles consumed.
+func (c *CPU) StepOver() (cycles uint64) {
+	pc := c.reg.PC
+	if c.halted || c.stopped || !isCall(c.bus.Read16(pc&0xFFFFFF)) {
+		return uint64(c.Step())
+	}
+	ret := pc + uint32(c.InstructionLength(pc))
+	sp := c.reg.A[7]
+	cycles = uint64(c.Step())
+	n, _ := c.RunUntil(func(c *CPU) bool {
+		return c.reg.PC == ret && c.reg.A[7] >= sp
+	}, stepOverCycles)
+	return cycles + n
+}
+
+// isCall reports whether op is BSR, JSR or TRAP, the instructions that
+// StepOver runs to completion.
+func isCall(op uint16) bool {
+	return op&0xFF00 == 0x6100 || op&0xFFC0 == 0x4E80 || op&0xFFF0 == 0x4E40
+}
+
 // Deficit returns the remaining cycle deficit from a previous StepCycles
 // call where the instruction cost exceeded the budget.
 func (c *CPU) Deficit() int {
@@ -193,8 +717,133 @@ func (c *CPU) AddCycles(n uint64) {
 }
 
 // Registers returns a snapshot of the current register state.
+// With the prefetch model enabled, PC is the hardware program counter,
+// four bytes past the next instruction. While the CPU is stopped the
+// queue is empty and PC is the instruction after STOP in either mode.
 func (c *CPU) Registers() Registers {
-	return c.reg
+	r := c.reg
+	if c.queuePC() {
+		r.PC += 4
+	}
+	return r
+}
+
+// queuePC reports whether the hardware PC runs four bytes ahead of the
+// next instruction: the prefetch model is enabled and the queue is full.
+func (c *CPU) queuePC() bool {
+	return c.prefetch && !c.stopped
+}
+
+// SetPrefetch enables or disables the two-word prefetch queue model.
+//
+// When enabled, instruction words are read through a queue kept two words
+// ahead of execution, as on the 68000: the bus sees each word fetched
+// before the instruction that uses it starts, a write into the next two
+// words does not affect instructions already prefetched, and a jump
+// discards the queue and refills it from the target. PC as reported by
+// Registers and accepted by SetState is then the hardware program counter
+// (the next instruction address + 4), matching SingleStepTests data.
+// Instruction timing is unchanged; the published cycle counts already
+// include prefetch. Disabled by default.
+func (c *CPU) SetPrefetch(enabled bool) {
+	c.prefetch = enabled
+	c.pqValid = false
+}
+
+// TraceFunc receives each instruction as Step is about to execute it: pc
+// is the address of the opcode word, ir the opcode, and regs the register
+// state at that point (regs.PC has already advanced past the opcode).
+type TraceFunc func(pc uint32, ir uint16, regs Registers)
+
+// SetTraceFunc installs a callback invoked by Step before each instruction
+// is dispatched. It is not called for exceptions taken between
+// instructions, while the CPU is stopped, or when StepCycles only pays down
+// a cycle deficit. Pass nil to remove it.
+func (c *CPU) SetTraceFunc(fn TraceFunc) {
+	c.traceFunc = fn
+	c.updateHooks()
+}
+
+// StackMisalignedFunc receives the odd value an instruction left in A7.
+type StackMisalignedFunc func(sp uint32)
+
+// SetStackMisalignedFunc installs a callback invoked after an instruction
+// that changes A7 to an odd address, such as MOVEA, ADDQ or UNLK, as an
+// early warning of the address error the next stack access will take. It
+// is not called again while A7 stays at the same odd value. Pass nil to
+// remove it, the default.
+func (c *CPU) SetStackMisalignedFunc(fn StackMisalignedFunc) {
+	c.stackFunc = fn
+	c.updateHooks()
+}
+
+// InstructionFunc receives the cycles a Step consumed, the same value Step
+// returns, once the instruction and any exception it raised are complete.
+type InstructionFunc func(cycles int)
+
+// SetInstructionFunc installs a callback invoked at the end of every Step
+// that is not refused by a halted CPU, including the idle steps of a
+// stopped CPU, so a system can advance other devices in lockstep; the
+// reported cycles add up to the growth of Cycles apart from AddCycles.
+// StepCycles, RunCycles and the other run loops call it once per
+// underlying Step. Pass nil to remove it.
+func (c *CPU) SetInstructionFunc(fn InstructionFunc) {
+	c.instrFunc = fn
+}
+
+// Kinds of unimplemented opcode reported to an IllegalFunc.
+const (
+	IllegalOpcode = iota // illegal instruction, vector 4
+	IllegalLineA         // $Axxx opcode, vector 10
+	IllegalLineF         // $Fxxx opcode, vector 11
+)
+
+// IllegalFunc receives an opcode that raised an illegal instruction or
+// Line A/F exception: pc is the address of the opcode word, ir the opcode,
+// and kind one of IllegalOpcode, IllegalLineA or IllegalLineF.
+type IllegalFunc func(pc uint32, ir uint16, kind int)
+
+// SetIllegalFunc installs a callback invoked before an illegal instruction
+// or Line A/F exception is processed, including instructions the selected
+// variant does not implement. Pass nil to remove it.
+func (c *CPU) SetIllegalFunc(fn IllegalFunc) {
+	c.illegalFunc = fn
+}
+
+// ResetPinFunc receives the RESET output asserted by the RESET
+// instruction; pulse is the number of clocks it stays asserted for.
+type ResetPinFunc func(pulse int)
+
+// SetResetPinFunc installs a callback invoked when the RESET instruction
+// asserts the RESET output, after Bus.Reset. It lets a system reset its
+// peripherals separately from the bus. The CPU's registers are left
+// untouched by the instruction. Pass nil to remove it.
+func (c *CPU) SetResetPinFunc(fn ResetPinFunc) {
+	c.resetPinFunc = fn
+}
+
+// SetResetTiming overrides the cycles the RESET instruction takes and the
+// pulse width, in clocks, passed to the ResetPinFunc, for systems whose
+// clocking differs from the stock part. Zero selects the variant's
+// default: 132 and 124, or 518 and 512 on the MC68020.
+func (c *CPU) SetResetTiming(cycles, pulse int) {
+	c.resetCycles = cycles
+	c.resetPulse = pulse
+}
+
+// SetLogf installs a printf-style function that receives the CPU's
+// diagnostic messages: address errors, bus errors, error exceptions and
+// double bus faults. log.Printf is a suitable argument. The default, and
+// passing nil, discards them.
+func (c *CPU) SetLogf(fn func(format string, args ...any)) {
+	c.logFunc = fn
+}
+
+// logf passes a diagnostic message to the installed log function, if any.
+func (c *CPU) logf(format string, args ...any) {
+	if c.logFunc != nil {
+		c.logFunc(format, args...)
+	}
 }
 
 // RequestInterrupt queues an interrupt at the given priority level (1-7).
@@ -207,6 +856,22 @@ func (c *CPU) RequestInterrupt(level uint8, vector *uint8) {
 	}
 }
 
+// PulseInterrupt raises a one-shot, edge-triggered interrupt at the given
+// priority level (1-7), for a device that signals an event rather than
+// holding its line. The pulse is latched apart from the level-sensitive
+// request managed by RequestInterrupt, SetIPL and ClearInterrupt, so
+// lowering the IPL lines does not withdraw it; it waits while masked and
+// is cleared when acknowledged, so it is serviced exactly once. A higher
+// level replaces a lower pending pulse. When the pulse and the IPL lines
+// request the same level, the pulse is taken first. Pass nil for vector
+// to use auto-vectoring.
+func (c *CPU) PulseInterrupt(level uint8, vector *uint8) {
+	if level > c.pulseIPL {
+		c.pulseIPL = level
+		c.pulseVec = vector
+	}
+}
+
 // SetIPL drives the encoded interrupt level present on the IPL2-IPL0
 // inputs (M68000 User's Manual Sec 3.5). The input is level-sensitive: it
 // reflects the level the interrupting device currently asserts, so it may
@@ -220,61 +885,209 @@ func (c *CPU) SetIPL(level uint8, vector *uint8) {
 	c.pendingVec = vector
 }
 
-// readBus reads from the bus with 24-bit address masking.
-// Word and long accesses to odd addresses halt the CPU (address error).
+// ClearInterrupt withdraws any pending interrupt request, as if the
+// interrupting device deasserted IPL2-IPL0 before the processor serviced
+// it. It is equivalent to SetIPL(0, nil).
+//
+// Acknowledging an interrupt also clears the pending request, so a device
+// whose line stays asserted must request again. For levels 1-6 this
+// differs from hardware, where a held level is taken again as soon as the
+// mask allows; for level 7, which is edge triggered on the 68000, it
+// matches taking the interrupt once per assertion.
+func (c *CPU) ClearInterrupt() {
+	c.SetIPL(0, nil)
+}
+
+// PendingInterrupt returns the interrupt request currently latched and not
+// yet acknowledged, with the vector passed to RequestInterrupt, SetIPL or
+// PulseInterrupt (nil for auto-vectoring). When both a pulse and a level
+// request are pending it reports the one that would be taken first.
+// Level 0 means nothing is pending. A request masked by the status
+// register is still reported.
+func (c *CPU) PendingInterrupt() (level uint8, vector *uint8) {
+	if c.pulseIPL >= c.pendingIPL {
+		return c.pulseIPL, c.pulseVec
+	}
+	return c.pendingIPL, c.pendingVec
+}
+
+// BusError signals that the bus access currently in progress is
+// terminated by BERR. It must be called by the Bus from within one of its
+// Read or Write methods, or by an IntAckFunc to end the interrupt
+// acknowledge as a spurious interrupt; addr is the faulting address
+// reported in the exception frame. Once the access returns, the CPU
+// abandons the current instruction and takes a bus error exception
+// (vector 2) with a group 0 stack frame. A bus error while that frame is
+// being stacked is a double bus fault and halts the CPU.
+func (c *CPU) BusError(addr uint32) {
+	c.berr = true
+	c.berrAddr = addr
+}
+
+// readBus reads data from the bus with 24-bit address masking.
+// Word and long accesses to odd addresses take an address error.
 func (c *CPU) readBus(sz size, addr uint32) uint32 {
+	return c.readMem(sz, addr, false)
+}
+
+// readMem reads from the bus with 24-bit address masking. program selects
+// the program space function code (instruction fetch) over data space.
+func (c *CPU) readMem(sz size, addr uint32, program bool) uint32 {
 	if c.halted {
 		return 0
 	}
 	if sz != sizeByte && addr&1 != 0 {
-		log.Printf("[m68k] address error: read %s from odd addr=%06x PC=%06x prevPC=%06x IR=%04x",
+		c.logf("[m68k] address error: read %s from odd addr=%06x PC=%06x prevPC=%06x IR=%04x",
 			sz, addr&0xFFFFFF, c.reg.PC, c.prevPC, c.ir)
-		c.halted = true
-		return 0
+		c.addressError(addr, sz, true, program)
 	}
 	addr &= 0xFFFFFF
-	switch sz {
-	case sizeByte:
-		return uint32(c.bus.Read8(addr))
-	case sizeWord:
-		return uint32(c.bus.Read16(addr))
-	case sizeLong:
-		return uint32(c.bus.Read32(addr))
+	if c.hooks&hookBus != 0 {
+		c.busHooks(sz, program)
+	}
+	var val uint32
+	switch {
+	case sz == sizeByte:
+		val = uint32(c.bus.Read8(addr))
+	case c.variant == MC68008:
+		for i := uint32(0); i < uint32(sz); i++ {
+			val = val<<8 | uint32(c.bus.Read8((addr+i)&0xFFFFFF))
+		}
+		c.cycles += byteBusExtra(sz)
+	case sz == sizeWord:
+		val = uint32(c.bus.Read16(addr))
+	case addr == 0xFFFFFE:
+		// A long is two word bus cycles; at the top of the address
+		// space the second one wraps around to 0.
+		val = uint32(c.bus.Read16(addr))<<16 | uint32(c.bus.Read16(0))
+	default:
+		val = c.bus.Read32(addr)
+	}
+	if c.hooks&hookBusTrace != 0 {
+		c.traceAccess(sz, addr, false, program, val)
+	}
+	if c.berr {
+		c.busError(sz, true, program)
 	}
-	return 0
+	if c.hooks&hookWatch != 0 && !program {
+		c.checkWatch(sz, addr, watchRead, val)
+	}
+	return val
 }
 
 // writeBus writes to the bus with 24-bit address masking.
-// Word and long accesses to odd addresses halt the CPU (address error).
+// Word and long accesses to odd addresses take an address error.
 func (c *CPU) writeBus(sz size, addr uint32, val uint32) {
 	if c.halted {
 		return
 	}
 	if sz != sizeByte && addr&1 != 0 {
-		log.Printf("[m68k] address error: write %s to odd addr=%06x val=%08x PC=%06x prevPC=%06x IR=%04x",
+		c.logf("[m68k] address error: write %s to odd addr=%06x val=%08x PC=%06x prevPC=%06x IR=%04x",
 			sz, addr&0xFFFFFF, val&sz.Mask(), c.reg.PC, c.prevPC, c.ir)
-		c.halted = true
-		return
+		c.addressError(addr, sz, false, false)
 	}
 	addr &= 0xFFFFFF
+	if c.hooks&hookBus != 0 {
+		c.busHooks(sz, false)
+	}
 	val &= sz.Mask()
-	switch sz {
-	case sizeByte:
+	switch {
+	case sz == sizeByte:
 		c.bus.Write8(addr, uint8(val))
-	case sizeWord:
+	case c.variant == MC68008:
+		for i := uint32(0); i < uint32(sz); i++ {
+			c.bus.Write8((addr+i)&0xFFFFFF, uint8(val>>((uint32(sz)-1-i)*8)))
+		}
+		c.cycles += byteBusExtra(sz)
+	case sz == sizeWord:
 		c.bus.Write16(addr, uint16(val))
-	case sizeLong:
+	case addr == 0xFFFFFE:
+		c.bus.Write16(addr, uint16(val>>16))
+		c.bus.Write16(0, uint16(val))
+	default:
 		c.bus.Write32(addr, val)
 	}
+	if c.hooks&hookBusTrace != 0 {
+		c.traceAccess(sz, addr, true, false, val)
+	}
+	if c.berr {
+		c.busError(sz, false, false)
+	}
+	if c.hooks&hookWatch != 0 {
+		c.checkWatch(sz, addr, watchWrite, val)
+	}
+}
+
+// busHooks reports an access of sz about to start to the optional bus
+// interfaces: the function code, the privilege level and the cycle.
+func (c *CPU) busHooks(sz size, program bool) {
+	if c.fcBus != nil {
+		c.fcBus.SetFunctionCode(c.functionCode(program))
+	}
+	if c.svBus != nil {
+		c.svBus.SetSupervisor(c.functionCode(program)&4 != 0)
+	}
+	if c.cycleBus != nil || c.busTracer != nil {
+		c.stampCycle(sz)
+	}
+}
+
+// stampCycle records the start of an sz access in accessStamp and reports
+// it to the CycleBus. An instruction's time is mostly charged once its
+// handler finishes, so each access is placed 4 clocks per bus cycle after
+// the previous one, and no earlier than the cycles charged so far.
+func (c *CPU) stampCycle(sz size) {
+	stamp := max(c.busStamp, c.cycles)
+	c.accessStamp = stamp
+	if c.cycleBus != nil {
+		c.cycleBus.SetCycle(stamp)
+	}
+	n := uint64(1)
+	switch {
+	case c.variant == MC68008:
+		n = uint64(sz)
+	case sz == sizeLong:
+		n = 2
+	}
+	c.busStamp = stamp + 4*n
+}
+
+// byteBusExtra returns the cycles an MC68008 adds to a word or long access:
+// each byte beyond the first per 68000 bus cycle costs another 4-clock
+// byte cycle.
+func byteBusExtra(sz size) uint64 {
+	return uint64(sz) / 2 * 4
 }
 
 // fetchPC reads a 16-bit word at the current PC and advances PC by 2.
 func (c *CPU) fetchPC() uint16 {
-	val := c.readBus(sizeWord, c.reg.PC)
+	if c.prefetch {
+		return c.fetchQueue()
+	}
+	val := c.readMem(sizeWord, c.reg.PC, true)
 	c.reg.PC += 2
 	return uint16(val)
 }
 
+// fetchQueue takes the word at PC from the prefetch queue and refills the
+// queue with the word after it. A queue that no longer starts at PC (after
+// a jump, exception, or reset) is refilled from PC first.
+func (c *CPU) fetchQueue() uint16 {
+	if !c.pqValid || c.pqAddr != c.reg.PC {
+		c.pqValid = false
+		c.pq[0] = uint16(c.readMem(sizeWord, c.reg.PC, true))
+		c.pq[1] = uint16(c.readMem(sizeWord, c.reg.PC+2, true))
+		c.pqAddr = c.reg.PC
+		c.pqValid = true
+	}
+	val := c.pq[0]
+	c.pq[0] = c.pq[1]
+	c.reg.PC += 2
+	c.pqAddr = c.reg.PC
+	c.pq[1] = uint16(c.readMem(sizeWord, c.reg.PC+2, true))
+	return val
+}
+
 // fetchPCLong reads a 32-bit long at the current PC and advances PC by 4.
 func (c *CPU) fetchPCLong() uint32 {
 	hi := c.fetchPC()
@@ -343,17 +1156,38 @@ func (c *CPU) setCCR(ccr uint8) {
 // performing a hardware reset. This is intended for testing, where
 // exact CPU state must be established before executing an instruction.
 func (c *CPU) SetState(regs Registers) {
+	c.clearLoop()
+	c.fcBus, _ = c.bus.(FCBus)
+	c.svBus, _ = c.bus.(SupervisorBus)
+	c.rmwBus, _ = c.bus.(RMWBus)
+	c.cycleBus, _ = c.bus.(CycleBus)
+	c.updateHooks()
 	c.reg.D = regs.D
 	c.reg.SR = regs.SR
 	c.reg.USP = regs.USP
 	c.reg.SSP = regs.SSP
 	c.reg.PC = regs.PC
+	c.reg.VBR = regs.VBR
+	c.reg.SFC = regs.SFC & 7
+	c.reg.DFC = regs.DFC & 7
+	if c.prefetch {
+		c.reg.PC -= 4
+	}
+	c.pqValid = false
 	c.stopped = false
 	c.halted = false
+	c.haltReason = HaltNone
 	c.cycles = 0
+	c.busStamp = 0
 	c.deficit = 0
+	c.lastExc = lastException{}
 	c.pendingIPL = 0
 	c.pendingVec = nil
+	c.pulseIPL = 0
+	c.pulseVec = nil
+	c.trace = false
+	c.tracePending = false
+	c.clearFault()
 
 	// A7 is the active stack pointer: SSP in supervisor mode, USP in user mode
 	for i := 0; i < 7; i++ {
@@ -365,3 +1199,69 @@ func (c *CPU) SetState(regs Registers) {
 		c.reg.A[7] = regs.USP
 	}
 }
+
+// D returns data register Dn (n = 0-7).
+func (c *CPU) D(n int) uint32 {
+	return c.reg.D[n&7]
+}
+
+// SetD sets data register Dn (n = 0-7).
+func (c *CPU) SetD(n int, v uint32) {
+	c.reg.D[n&7] = v
+}
+
+// A returns address register An (n = 0-7). A7 is the active stack pointer.
+func (c *CPU) A(n int) uint32 {
+	return c.reg.A[n&7]
+}
+
+// SetA sets address register An (n = 0-7). Writing A7 sets the active
+// stack pointer and its shadow: SSP in supervisor mode, USP in user mode.
+func (c *CPU) SetA(n int, v uint32) {
+	n &= 7
+	c.reg.A[n] = v
+	if n == 7 {
+		if c.supervisor() {
+			c.reg.SSP = v
+		} else {
+			c.reg.USP = v
+		}
+	}
+}
+
+// PC returns the program counter as reported by Registers.
+func (c *CPU) PC() uint32 {
+	if c.queuePC() {
+		return c.reg.PC + 4
+	}
+	return c.reg.PC
+}
+
+// SetPC sets the program counter, using the same convention as SetState
+// when the prefetch model is enabled. Execution continues at the new PC
+// on the next Step; a STOPped CPU stays stopped.
+func (c *CPU) SetPC(v uint32) {
+	if c.queuePC() {
+		v -= 4
+	}
+	c.reg.PC = v
+	c.pqValid = false
+}
+
+// SR returns the status register.
+func (c *CPU) SR() uint16 {
+	return c.reg.SR
+}
+
+// InterruptMask returns the interrupt priority mask, SR bits 10-8. A
+// device can skip raising a level at or below it, other than level 7,
+// since the CPU would not take it until the mask is lowered.
+func (c *CPU) InterruptMask() uint8 {
+	return interruptMask(c.reg.SR)
+}
+
+// SetSR sets the status register. Changing the S bit swaps A7 between the
+// user and supervisor stack pointers as an instruction writing SR would.
+func (c *CPU) SetSR(v uint16) {
+	c.setSR(v)
+}
@@ -1,6 +1,11 @@
 package m68k
 
-import "testing"
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
 
 func TestInstructionCycles(t *testing.T) {
 	tests := []struct {
@@ -738,6 +743,156 @@ func TestInstructionCycles(t *testing.T) {
 			a:      [8]uint32{0x2000},
 			cycles: 12, // 8 + 4((An))
 		},
+		// --- MULS (38 + 2n, n = 01/10 pairs in <ea>:0) ---
+		{
+			name: "MULS D0,D1 src=0x0000 = 38",
+			setup: func(bus *testBus, pc uint32) {
+				// 0xC3C0: MULS D0,D1
+				writeWord(bus, pc, 0xC3C0)
+			},
+			d:      [8]uint32{0x0000, 0x1234},
+			cycles: 38, // no bit transitions
+		},
+		{
+			name: "MULS D0,D1 src=0xFFFF = 40",
+			setup: func(bus *testBus, pc uint32) {
+				writeWord(bus, pc, 0xC3C0)
+			},
+			d:      [8]uint32{0xFFFF, 0x1234},
+			cycles: 40, // 1_1111_1111_1111_1111_0: one transition at bit 0
+		},
+		{
+			name: "MULS D0,D1 src=0x5555 = 70",
+			setup: func(bus *testBus, pc uint32) {
+				writeWord(bus, pc, 0xC3C0)
+			},
+			d:      [8]uint32{0x5555, 0x1234},
+			cycles: 70, // 0101...0101_0: every adjacent pair differs (16)
+		},
+		{
+			name: "MULS (A0),D1 src=0x8000 = 44",
+			setup: func(bus *testBus, pc uint32) {
+				// 0xC3D0: MULS (A0),D1
+				writeWord(bus, pc, 0xC3D0)
+				writeWord(bus, 0x2000, 0x8000)
+			},
+			a:      [8]uint32{0x2000},
+			cycles: 44, // 38 + 2*1 + 4((An))
+		},
+		// --- DIVU ---
+		{
+			name: "DIVU D0,D1 0/1 = 136",
+			setup: func(bus *testBus, pc uint32) {
+				// 0x82C0: DIVU D0,D1
+				writeWord(bus, pc, 0x82C0)
+			},
+			d:      [8]uint32{0x0001, 0x00000000},
+			cycles: 136, // every trial subtraction fails
+		},
+		{
+			name: "DIVU D0,D1 overflow = 10",
+			setup: func(bus *testBus, pc uint32) {
+				writeWord(bus, pc, 0x82C0)
+			},
+			d:      [8]uint32{0x0001, 0x00010000},
+			cycles: 10, // quotient 0x10000 does not fit in 16 bits
+		},
+		// --- CHK ---
+		{
+			name: "CHK D0,D1 in bounds = 10",
+			setup: func(bus *testBus, pc uint32) {
+				// 0x4380: CHK D0,D1
+				writeWord(bus, pc, 0x4380)
+			},
+			d:      [8]uint32{0x0005, 0x0003},
+			cycles: 10,
+		},
+		{
+			name: "CHK D0,D1 above bound trap = 38",
+			setup: func(bus *testBus, pc uint32) {
+				writeWord(bus, pc, 0x4380)
+				bus.Write32(vecCHK*4, 0x3000)
+			},
+			d:      [8]uint32{0x0003, 0x0005},
+			cycles: 38,
+		},
+		{
+			name: "CHK (A0),D1 negative trap = 44",
+			setup: func(bus *testBus, pc uint32) {
+				// 0x4390: CHK (A0),D1
+				writeWord(bus, pc, 0x4390)
+				writeWord(bus, 0x2000, 0x0005)
+				bus.Write32(vecCHK*4, 0x3000)
+			},
+			d:      [8]uint32{0, 0xFFFF},
+			a:      [8]uint32{0x2000},
+			cycles: 44, // 40 + 4((An))
+		},
+		// --- Bit manipulation (hardware-verified, not PRM) ---
+		{
+			name: "BTST D1,#imm = 10",
+			setup: func(bus *testBus, pc uint32) {
+				writeWord(bus, pc, 0x033C)
+				writeWord(bus, pc+2, 0x0001)
+			},
+			cycles: 10,
+		},
+		{
+			name: "BTST #3,D0 = 10",
+			setup: func(bus *testBus, pc uint32) {
+				writeWord(bus, pc, 0x0800)
+				writeWord(bus, pc+2, 0x0003)
+			},
+			cycles: 10,
+		},
+		{
+			name: "BCHG #3,D0 = 10",
+			setup: func(bus *testBus, pc uint32) {
+				writeWord(bus, pc, 0x0840)
+				writeWord(bus, pc+2, 0x0003)
+			},
+			cycles: 10,
+		},
+		{
+			name: "BCHG #20,D0 = 12",
+			setup: func(bus *testBus, pc uint32) {
+				writeWord(bus, pc, 0x0840)
+				writeWord(bus, pc+2, 0x0014)
+			},
+			cycles: 12, // bit in upper word
+		},
+		{
+			name: "BCLR #3,D0 = 12",
+			setup: func(bus *testBus, pc uint32) {
+				writeWord(bus, pc, 0x0880)
+				writeWord(bus, pc+2, 0x0003)
+			},
+			cycles: 12,
+		},
+		{
+			name: "BCLR #20,D0 = 14",
+			setup: func(bus *testBus, pc uint32) {
+				writeWord(bus, pc, 0x0880)
+				writeWord(bus, pc+2, 0x0014)
+			},
+			cycles: 14, // bit in upper word
+		},
+		{
+			name: "BSET #3,D0 = 10",
+			setup: func(bus *testBus, pc uint32) {
+				writeWord(bus, pc, 0x08C0)
+				writeWord(bus, pc+2, 0x0003)
+			},
+			cycles: 10,
+		},
+		{
+			name: "BSET #20,D0 = 12",
+			setup: func(bus *testBus, pc uint32) {
+				writeWord(bus, pc, 0x08C0)
+				writeWord(bus, pc+2, 0x0014)
+			},
+			cycles: 12, // bit in upper word
+		},
 		// --- MOVE to CCR ---
 		{
 			name: "MOVE D0,CCR = 12",
@@ -789,69 +944,95 @@ func TestInstructionCycles(t *testing.T) {
 	}
 }
 
+// checkAddressErrorFrame verifies that cpu took an address error into the
+// handler at 0x3000 with the given status word, access address, and
+// stacked PC, from a supervisor stack starting at 0x10000.
+func checkAddressErrorFrame(t *testing.T, cpu *CPU, bus *testBus, status uint16, addr, pc uint32) {
+	t.Helper()
+
+	if cpu.Halted() {
+		t.Fatalf("CPU halted, want address error exception")
+	}
+	reg := cpu.Registers()
+	if reg.PC != 0x3000 {
+		t.Errorf("PC = 0x%06X, want handler 0x3000", reg.PC)
+	}
+	sp := reg.A[7]
+	if sp != 0x10000-14 {
+		t.Fatalf("SSP = 0x%06X, want 0x%06X", sp, 0x10000-14)
+	}
+	if got := bus.Read16(sp); got != status {
+		t.Errorf("status word = 0x%04X, want 0x%04X", got, status)
+	}
+	if got := bus.Read32(sp + 2); got != addr {
+		t.Errorf("access address = 0x%08X, want 0x%08X", got, addr)
+	}
+	if got := bus.Read16(sp + 6); got != cpu.ir {
+		t.Errorf("stacked IR = 0x%04X, want 0x%04X", got, cpu.ir)
+	}
+	if got := bus.Read16(sp + 8); got != 0x2700 {
+		t.Errorf("stacked SR = 0x%04X, want 0x2700", got)
+	}
+	if got := bus.Read32(sp + 10); got != pc {
+		t.Errorf("stacked PC = 0x%06X, want 0x%06X", got, pc)
+	}
+}
+
 func TestAddressError(t *testing.T) {
-	t.Run("word read from odd address halts", func(t *testing.T) {
+	newAECPU := func(op uint16, regs Registers) (*CPU, *testBus) {
 		bus := &testBus{}
 		cpu := &CPU{bus: bus}
+		writeWord(bus, 0x1000, op)
+		bus.Write32(vecAddressError*4, 0x3000)
+		regs.PC = 0x1000
+		regs.SR = 0x2700
+		regs.SSP = 0x10000
+		cpu.SetState(regs)
+		return cpu, bus
+	}
 
+	t.Run("word read from odd address", func(t *testing.T) {
 		// MOVE.W (A0), D0 — opcode 0x3010
-		pc := uint32(0x1000)
-		writeWord(bus, pc, 0x3010)
-
-		cpu.SetState(Registers{A: [8]uint32{0x2001}, PC: pc, SR: 0x2700, SSP: 0x10000})
-		cpu.Step()
-
-		if !cpu.Halted() {
-			t.Errorf("expected CPU to be halted after word read from odd address")
+		cpu, bus := newAECPU(0x3010, Registers{A: [8]uint32{0x2001}})
+		if got := cpu.Step(); got != 50 {
+			t.Errorf("cycles = %d, want 50", got)
 		}
+		checkAddressErrorFrame(t, cpu, bus, 0x3000|ssRead|FCSuperData, 0x2001, 0x1002)
 	})
 
-	t.Run("long read from odd address halts", func(t *testing.T) {
-		bus := &testBus{}
-		cpu := &CPU{bus: bus}
-
-		// MOVE.L (A0), D0 — opcode 0x2010
-		pc := uint32(0x1000)
-		writeWord(bus, pc, 0x2010)
-
-		cpu.SetState(Registers{A: [8]uint32{0x2001}, PC: pc, SR: 0x2700, SSP: 0x10000})
+	t.Run("long read from odd address", func(t *testing.T) {
+		// MOVE.L (A0)+, D0 — opcode 0x2018; A0 is not incremented
+		cpu, bus := newAECPU(0x2018, Registers{A: [8]uint32{0x2001}})
 		cpu.Step()
-
-		if !cpu.Halted() {
-			t.Errorf("expected CPU to be halted after long read from odd address")
+		checkAddressErrorFrame(t, cpu, bus, 0x2000|ssRead|FCSuperData, 0x2001, 0x1002)
+		if a0 := cpu.Registers().A[0]; a0 != 0x2001 {
+			t.Errorf("A0 = 0x%08X, want 0x2001", a0)
 		}
 	})
 
-	t.Run("word write to odd address halts", func(t *testing.T) {
-		bus := &testBus{}
-		cpu := &CPU{bus: bus}
-
+	t.Run("word write to odd address", func(t *testing.T) {
 		// MOVE.W D0, (A0) — opcode 0x3080
-		pc := uint32(0x1000)
-		writeWord(bus, pc, 0x3080)
-
-		cpu.SetState(Registers{D: [8]uint32{0x1234}, A: [8]uint32{0x2001}, PC: pc, SR: 0x2700, SSP: 0x10000})
+		cpu, bus := newAECPU(0x3080, Registers{D: [8]uint32{0x1234}, A: [8]uint32{0x2001}})
 		cpu.Step()
-
-		if !cpu.Halted() {
-			t.Errorf("expected CPU to be halted after word write to odd address")
+		checkAddressErrorFrame(t, cpu, bus, 0x3080|FCSuperData, 0x2001, 0x1002)
+		if bus.mem[0x2000] != 0 || bus.mem[0x2001] != 0 {
+			t.Errorf("odd write reached memory")
 		}
 	})
 
-	t.Run("long write to odd address halts", func(t *testing.T) {
-		bus := &testBus{}
-		cpu := &CPU{bus: bus}
-
-		// MOVE.L D0, (A0) — opcode 0x2080
-		pc := uint32(0x1000)
-		writeWord(bus, pc, 0x2080)
-
-		cpu.SetState(Registers{D: [8]uint32{0x12345678}, A: [8]uint32{0x2001}, PC: pc, SR: 0x2700, SSP: 0x10000})
+	t.Run("long write to odd address", func(t *testing.T) {
+		// MOVE.L D0, (d16,A0) — opcode 0x2140; stacked PC excludes the displacement
+		cpu, bus := newAECPU(0x2140, Registers{D: [8]uint32{0x12345678}, A: [8]uint32{0x2001}})
+		writeWord(bus, 0x1002, 0x0010)
 		cpu.Step()
+		checkAddressErrorFrame(t, cpu, bus, 0x2140|FCSuperData, 0x2011, 0x1002)
+	})
 
-		if !cpu.Halted() {
-			t.Errorf("expected CPU to be halted after long write to odd address")
-		}
+	t.Run("jump to odd address", func(t *testing.T) {
+		// JMP (A0) — opcode 0x4ED0
+		cpu, bus := newAECPU(0x4ED0, Registers{A: [8]uint32{0x2001}})
+		cpu.Step()
+		checkAddressErrorFrame(t, cpu, bus, 0x4EC0|ssRead|FCSuperProgram, 0x2001, 0x1002)
 	})
 
 	t.Run("byte read from odd address works", func(t *testing.T) {
@@ -894,22 +1075,28 @@ func TestAddressError(t *testing.T) {
 		}
 	})
 
-	t.Run("odd PC halts", func(t *testing.T) {
+	t.Run("fetch from odd PC", func(t *testing.T) {
 		bus := &testBus{}
 		cpu := &CPU{bus: bus}
+		bus.Write32(vecAddressError*4, 0x3000)
 
-		// Put a NOP at address 0x1000 in case fetch reaches there
-		writeWord(bus, 0x1000, 0x4E71)
-
-		// Set PC to an odd address
 		cpu.SetState(Registers{PC: 0x1001, SR: 0x2700, SSP: 0x10000})
-		cycles := cpu.Step()
+		cpu.Step()
+
+		checkAddressErrorFrame(t, cpu, bus, ssRead|FCSuperProgram, 0x1001, 0x1001)
+	})
+
+	t.Run("address error with zero vectors halts", func(t *testing.T) {
+		bus := &testBus{}
+		cpu := &CPU{bus: bus}
+
+		// MOVE.W (A0), D0 with vectors 3 and 15 both zero
+		writeWord(bus, 0x1000, 0x3010)
+		cpu.SetState(Registers{A: [8]uint32{0x2001}, PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+		cpu.Step()
 
 		if !cpu.Halted() {
-			t.Errorf("expected CPU to be halted with odd PC")
-		}
-		if cycles != 0 {
-			t.Errorf("Step() returned %d cycles, want 0 for halted CPU", cycles)
+			t.Errorf("expected CPU to be halted with uninitialized vectors")
 		}
 	})
 
@@ -1132,3 +1319,2105 @@ func TestAddCycles(t *testing.T) {
 		t.Errorf("AddCycles(100): got delta %d, want 100", after-before)
 	}
 }
+
+// rmwBus is a testBus that records the order of byte accesses, word and
+// long writes ("stack") and the RMWBus lock brackets. With cpu set, the
+// byte access logged as fault ("read" or "write") ends with a bus error.
+type rmwBus struct {
+	testBus
+	log   []string
+	cpu   *CPU
+	fault string
+}
+
+func (b *rmwBus) record(op string, addr uint32) {
+	b.log = append(b.log, op)
+	if b.cpu != nil && op == b.fault {
+		b.cpu.BusError(addr)
+	}
+}
+
+func (b *rmwBus) Read8(addr uint32) uint8 {
+	b.record("read", addr)
+	return b.testBus.Read8(addr)
+}
+
+func (b *rmwBus) Write8(addr uint32, val uint8) {
+	b.record("write", addr)
+	b.testBus.Write8(addr, val)
+}
+
+func (b *rmwBus) Write16(addr uint32, val uint16) {
+	b.record("stack", addr)
+	b.testBus.Write16(addr, val)
+}
+
+func (b *rmwBus) Write32(addr uint32, val uint32) {
+	b.record("stack", addr)
+	b.testBus.Write32(addr, val)
+}
+
+func (b *rmwBus) BeginRMW(addr uint32) { b.log = append(b.log, "begin") }
+func (b *rmwBus) EndRMW(addr uint32)   { b.log = append(b.log, "end") }
+
+func TestTASLockedBus(t *testing.T) {
+	bus := &rmwBus{}
+	cpu := &CPU{bus: bus}
+
+	// TAS (A0): 0x4AD0
+	writeWord(&bus.testBus, 0x1000, 0x4AD0)
+	bus.mem[0x2000] = 0x00
+	cpu.SetState(Registers{A: [8]uint32{0x2000}, PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+
+	if got := cpu.Step(); got != 14 {
+		t.Errorf("TAS (A0) cycles = %d, want 14", got)
+	}
+	want := []string{"begin", "read", "write", "end"}
+	if len(bus.log) != len(want) {
+		t.Fatalf("bus activity = %v, want %v", bus.log, want)
+	}
+	for i := range want {
+		if bus.log[i] != want[i] {
+			t.Fatalf("bus activity = %v, want %v", bus.log, want)
+		}
+	}
+	if bus.mem[0x2000] != 0x80 {
+		t.Errorf("RAM[0x2000] = 0x%02X, want 0x80", bus.mem[0x2000])
+	}
+	if cpu.Registers().SR&flagZ == 0 {
+		t.Errorf("Z flag clear, want set for zero operand")
+	}
+}
+
+// TestTASBusError checks that a bus error on either access of TAS still
+// releases the RMWBus lock, before the bus error frame is stacked.
+func TestTASBusError(t *testing.T) {
+	for _, tt := range []struct {
+		fault string
+		want  []string
+	}{
+		{"read", []string{"begin", "read", "end", "stack"}},
+		{"write", []string{"begin", "read", "write", "end", "stack"}},
+	} {
+		// TAS (A0)
+		bus := &rmwBus{fault: tt.fault}
+		bus.testBus.Write32(vecBusError*4, 0x3000)
+		cpu := busCPU(bus, &bus.testBus, MC68000, Registers{A: [8]uint32{0x2000}, SR: 0x2700}, 0x4AD0)
+		bus.cpu = cpu
+
+		cpu.Step()
+		bus.log = slices.Compact(bus.log)
+		if pc := cpu.PC(); pc != 0x3000 {
+			t.Errorf("%s fault: PC = 0x%X, want bus error handler 0x3000", tt.fault, pc)
+		}
+		if !slices.Equal(bus.log, tt.want) {
+			t.Errorf("%s fault: bus activity = %v, want %v", tt.fault, bus.log, tt.want)
+		}
+		if cpu.rmwLocked {
+			t.Errorf("%s fault: RMW lock still held", tt.fault)
+		}
+	}
+}
+
+// berrBus is a testBus that terminates every access in [lo, hi) with a
+// bus error.
+type berrBus struct {
+	testBus
+	cpu    *CPU
+	lo, hi uint32
+}
+
+func (b *berrBus) check(addr uint32) {
+	if addr >= b.lo && addr < b.hi {
+		b.cpu.BusError(addr)
+	}
+}
+
+func (b *berrBus) Read16(addr uint32) uint16 {
+	b.check(addr)
+	return b.testBus.Read16(addr)
+}
+
+func (b *berrBus) Read32(addr uint32) uint32 {
+	b.check(addr)
+	return b.testBus.Read32(addr)
+}
+
+func (b *berrBus) Write16(addr uint32, val uint16) {
+	b.check(addr)
+	b.testBus.Write16(addr, val)
+}
+
+func (b *berrBus) Write32(addr uint32, val uint32) {
+	b.check(addr)
+	b.testBus.Write32(addr, val)
+}
+
+func (b *berrBus) word(addr uint32) uint16 { return b.testBus.Read16(addr) }
+func (b *berrBus) long(addr uint32) uint32 { return b.testBus.Read32(addr) }
+
+func TestBusError(t *testing.T) {
+	newBerrCPU := func(op uint16, sr uint16) (*CPU, *berrBus) {
+		bus := &berrBus{lo: 0x800000, hi: 0x900000}
+		cpu := &CPU{bus: bus}
+		bus.cpu = cpu
+		writeWord(&bus.testBus, 0x1000, op)
+		bus.testBus.Write32(vecBusError*4, 0x3000)
+		cpu.SetState(Registers{
+			D:   [8]uint32{0x11112222},
+			A:   [8]uint32{0x800010},
+			PC:  0x1000,
+			SR:  sr,
+			USP: 0x8000,
+			SSP: 0x10000,
+		})
+		return cpu, bus
+	}
+
+	t.Run("data read stacks group 0 frame", func(t *testing.T) {
+		// MOVE.W (A0), D0
+		cpu, bus := newBerrCPU(0x3010, 0x2704)
+
+		if got := cpu.Step(); got != 50 {
+			t.Errorf("cycles = %d, want 50", got)
+		}
+		reg := cpu.Registers()
+		if reg.PC != 0x3000 {
+			t.Errorf("PC = 0x%06X, want 0x3000", reg.PC)
+		}
+		if reg.A[7] != 0x10000-14 {
+			t.Errorf("SSP = 0x%06X, want 0x%06X", reg.A[7], 0x10000-14)
+		}
+		if reg.D[0] != 0x11112222 {
+			t.Errorf("D0 = 0x%08X, want unchanged 0x11112222", reg.D[0])
+		}
+		if reg.SR != 0x2704 {
+			t.Errorf("SR = 0x%04X, want 0x2704", reg.SR)
+		}
+
+		sp := reg.A[7]
+		if got, want := bus.word(sp), uint16(0x3000|ssRead|FCSuperData); got != want {
+			t.Errorf("status word = 0x%04X, want 0x%04X", got, want)
+		}
+		if got := bus.long(sp + 2); got != 0x800010 {
+			t.Errorf("access address = 0x%08X, want 0x800010", got)
+		}
+		if got := bus.word(sp + 6); got != 0x3010 {
+			t.Errorf("IR = 0x%04X, want 0x3010", got)
+		}
+		if got := bus.word(sp + 8); got != 0x2704 {
+			t.Errorf("stacked SR = 0x%04X, want 0x2704", got)
+		}
+		if got := bus.long(sp + 10); got != 0x1002 {
+			t.Errorf("stacked PC = 0x%06X, want 0x1002", got)
+		}
+	})
+
+	t.Run("user write reports user data space", func(t *testing.T) {
+		// MOVE.W D0, (A0)
+		cpu, bus := newBerrCPU(0x3080, 0x0000)
+
+		cpu.Step()
+		reg := cpu.Registers()
+		if reg.SR&flagS == 0 {
+			t.Fatalf("SR = 0x%04X, want supervisor mode", reg.SR)
+		}
+		if reg.USP != 0x8000 {
+			t.Errorf("USP = 0x%06X, want 0x8000", reg.USP)
+		}
+		sp := reg.A[7]
+		if got, want := bus.word(sp), uint16(0x3080|FCUserData); got != want {
+			t.Errorf("status word = 0x%04X, want 0x%04X", got, want)
+		}
+		if got := bus.word(sp + 8); got != 0x0000 {
+			t.Errorf("stacked SR = 0x%04X, want 0x0000", got)
+		}
+	})
+
+	t.Run("bus error while stacking frame halts", func(t *testing.T) {
+		// MOVE.W (A0), D0 with the supervisor stack also in the faulting region
+		cpu, _ := newBerrCPU(0x3010, 0x2700)
+		cpu.reg.A[7] = 0x800100
+
+		cpu.Step()
+		if !cpu.Halted() {
+			t.Errorf("expected CPU to be halted after double bus fault")
+		}
+	})
+}
+
+func TestRTEFromAddressErrorFrame(t *testing.T) {
+	bus := &testBus{}
+	cpu := &CPU{bus: bus}
+
+	// User code: MOVE.W (A0), D0 with odd A0, then NOP
+	writeWord(bus, 0x1000, 0x3010)
+	writeWord(bus, 0x1002, 0x4E71)
+
+	// Handler: ADDQ.L #8, A7 drops the fault information, RTE returns
+	// through the remaining short frame.
+	bus.Write32(vecAddressError*4, 0x3000)
+	writeWord(bus, 0x3000, 0x508F)
+	writeWord(bus, 0x3002, 0x4E73)
+
+	cpu.SetState(Registers{A: [8]uint32{0x2001}, PC: 0x1000, SR: 0x0004, USP: 0x8000, SSP: 0x10000})
+
+	cpu.Step() // address error
+	if sp := cpu.Registers().A[7]; sp != 0x10000-14 {
+		t.Fatalf("SSP after fault = 0x%06X, want 0x%06X", sp, 0x10000-14)
+	}
+	cpu.Step() // ADDQ.L #8, A7
+	cpu.Step() // RTE
+
+	reg := cpu.Registers()
+	if reg.PC != 0x1002 {
+		t.Errorf("PC = 0x%06X, want 0x1002", reg.PC)
+	}
+	if reg.SR != 0x0004 {
+		t.Errorf("SR = 0x%04X, want 0x0004", reg.SR)
+	}
+	if reg.A[7] != 0x8000 {
+		t.Errorf("A7/USP = 0x%06X, want 0x8000", reg.A[7])
+	}
+	if reg.SSP != 0x10000 {
+		t.Errorf("SSP = 0x%06X, want 0x10000", reg.SSP)
+	}
+}
+
+// TestExceptionFrameFormats checks the frame each variant stacks for an
+// exception or interrupt: SR and PC alone on the 68000, a format 0 word
+// holding the vector offset above them on the 68010, and on the 68020 the
+// six-word format 2 frame with the trapping instruction's address for a
+// divide by zero. Each extra word costs 4 cycles. An interrupt's Step also
+// runs the NOP at the start of its handler.
+func TestExceptionFrameFormats(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		v      Variant
+		prog   []uint16
+		irq    bool
+		pc     uint32   // stacked PC
+		frame  []uint16 // words above the stacked PC
+		cycles int
+	}{
+		{"68000 TRAP", MC68000, []uint16{0x4E40}, false, 0x1002, nil, 34},
+		{"68010 TRAP", MC68010, []uint16{0x4E40}, false, 0x1002, []uint16{0x0080}, 38},
+		{"68020 TRAP", MC68020, []uint16{0x4E40}, false, 0x1002, []uint16{0x0080}, 38},
+		{"68010 illegal", MC68010, []uint16{0x4AFC}, false, 0x1000, []uint16{0x0010}, 38},
+		{"68010 interrupt", MC68010, []uint16{0x4E71}, true, 0x1000, []uint16{0x0064}, 48 + 4},
+		{"68020 interrupt", MC68020, []uint16{0x4E71}, true, 0x1000, []uint16{0x0064}, 48 + 4},
+		{"68010 DIVU by zero", MC68010, []uint16{0x80FC, 0x0000}, false, 0x1004, []uint16{0x0014}, 46},
+		{"68020 DIVU by zero", MC68020, []uint16{0x80FC, 0x0000}, false, 0x1004, []uint16{0x2014, 0x0000, 0x1000}, 54},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cpu, bus := progCPU(tt.v, Registers{SR: 0x2000}, tt.prog...)
+			for _, vec := range []int{vecTrap0, vecIllegalInstruction, vecAutoVector1, vecDivideByZero} {
+				bus.Write32(uint32(vec)*4, 0x3000)
+			}
+			fillNOPs(bus, 0x3000, 1)
+			if tt.irq {
+				cpu.RequestInterrupt(1, nil)
+			}
+			if n := cpu.Step(); n != tt.cycles {
+				t.Errorf("cycles = %d, want %d", n, tt.cycles)
+			}
+			sp := cpu.Registers().A[7]
+			if want := 0x10000 - 6 - 2*uint32(len(tt.frame)); sp != want {
+				t.Fatalf("SSP = 0x%X, want 0x%X", sp, want)
+			}
+			if sr := bus.Read16(sp); sr != 0x2000 {
+				t.Errorf("stacked SR = 0x%04X, want 0x2000", sr)
+			}
+			if pc := bus.Read32(sp + 2); pc != tt.pc {
+				t.Errorf("stacked PC = 0x%X, want 0x%X", pc, tt.pc)
+			}
+			for i, want := range tt.frame {
+				if w := bus.Read16(sp + 6 + uint32(i*2)); w != want {
+					t.Errorf("frame word %d = 0x%04X, want 0x%04X", i, w, want)
+				}
+			}
+		})
+	}
+}
+
+// TestBusFaultFrameFormats checks the bus error frames of the 68010
+// (format 8, 29 words) and 68020 (format A, 16 words): the format word,
+// the variant's special status word and the access address at their
+// offsets, and 4 cycles for each word beyond the 68000's seven.
+func TestBusFaultFrameFormats(t *testing.T) {
+	for _, tt := range []struct {
+		v       Variant
+		size    uint32
+		format  uint16
+		sswOff  uint32
+		ssw     uint16
+		addrOff uint32
+		cycles  int
+	}{
+		{MC68010, 58, 0x8008, 8, 0x1000 | 0x0100 | FCSuperData, 10, 138},
+		{MC68020, 32, 0xA008, 10, 0x0100 | 0x0040 | 0x0020 | FCSuperData, 16, 86},
+	} {
+		t.Run(tt.v.String(), func(t *testing.T) {
+			// MOVE.W (A0),D0 from the faulting region
+			bus := &berrBus{lo: 0x800000, hi: 0x900000}
+			bus.testBus.Write32(vecBusError*4, 0x3000)
+			cpu := busCPU(bus, &bus.testBus, tt.v, Registers{A: [8]uint32{0x800010}, SR: 0x2704}, 0x3010)
+			bus.cpu = cpu
+
+			if n := cpu.Step(); n != tt.cycles {
+				t.Errorf("cycles = %d, want %d", n, tt.cycles)
+			}
+			sp := cpu.Registers().A[7]
+			if sp != 0x10000-tt.size {
+				t.Fatalf("SSP = 0x%X, want 0x%X", sp, 0x10000-tt.size)
+			}
+			if got := bus.word(sp); got != 0x2704 {
+				t.Errorf("stacked SR = 0x%04X, want 0x2704", got)
+			}
+			if got := bus.long(sp + 2); got != 0x1002 {
+				t.Errorf("stacked PC = 0x%X, want 0x1002", got)
+			}
+			if got := bus.word(sp + 6); got != tt.format {
+				t.Errorf("format word = 0x%04X, want 0x%04X", got, tt.format)
+			}
+			if got := bus.word(sp + tt.sswOff); got != tt.ssw {
+				t.Errorf("status word = 0x%04X, want 0x%04X", got, tt.ssw)
+			}
+			if got := bus.long(sp + tt.addrOff); got != 0x800010 {
+				t.Errorf("access address = 0x%X, want 0x800010", got)
+			}
+		})
+	}
+}
+
+// TestRTEFrameFormats runs RTE on the frames the 68010 and 68020 stack:
+// it returns through each and releases the whole frame, charging 4 cycles
+// for each word read beyond SR and PC, and a format the variant never
+// stacks takes a format error with the frame left in place.
+func TestRTEFrameFormats(t *testing.T) {
+	t.Run("format 0", func(t *testing.T) {
+		for _, v := range []Variant{MC68010, MC68020} {
+			// TRAP #0 from user mode, handler RTE
+			cpu, bus := progCPU(v, Registers{USP: 0x8000}, 0x4E40, 0x4E71)
+			bus.Write32(vecTrap0*4, 0x3000)
+			writeWord(bus, 0x3000, 0x4E73)
+			cpu.Step()
+			if n := cpu.Step(); n != 24 {
+				t.Errorf("%v: RTE cycles = %d, want 24", v, n)
+			}
+			reg := cpu.Registers()
+			if reg.PC != 0x1002 || reg.SR != 0 || reg.A[7] != 0x8000 || reg.SSP != 0x10000 {
+				t.Errorf("%v: PC = 0x%X SR = 0x%04X A7 = 0x%X SSP = 0x%X, want 0x1002, 0, 0x8000, 0x10000",
+					v, reg.PC, reg.SR, reg.A[7], reg.SSP)
+			}
+		}
+	})
+
+	t.Run("format 2", func(t *testing.T) {
+		// DIVU #0,D0, handler RTE
+		cpu, bus := progCPU(MC68020, Registers{SR: 0x2700}, 0x80FC, 0x0000, 0x4E71)
+		bus.Write32(vecDivideByZero*4, 0x3000)
+		writeWord(bus, 0x3000, 0x4E73)
+		cpu.Step()
+		if n := cpu.Step(); n != 32 {
+			t.Errorf("RTE cycles = %d, want 32", n)
+		}
+		if reg := cpu.Registers(); reg.PC != 0x1004 || reg.A[7] != 0x10000 {
+			t.Errorf("PC = 0x%X A7 = 0x%X, want 0x1004, 0x10000", reg.PC, reg.A[7])
+		}
+	})
+
+	t.Run("bus fault", func(t *testing.T) {
+		for _, tt := range []struct {
+			v      Variant
+			cycles int
+		}{
+			{MC68010, 124},
+			{MC68020, 72},
+		} {
+			// MOVE.W (A0),D0 from the faulting region, handler RTE
+			bus := &berrBus{lo: 0x800000, hi: 0x900000}
+			fillNOPs(&bus.testBus, 0x1002, 2)
+			bus.testBus.Write32(vecBusError*4, 0x3000)
+			writeWord(&bus.testBus, 0x3000, 0x4E73)
+			cpu := busCPU(bus, &bus.testBus, tt.v, Registers{A: [8]uint32{0x800010}, SR: 0x2704}, 0x3010)
+			bus.cpu = cpu
+			cpu.Step()
+			if n := cpu.Step(); n != tt.cycles {
+				t.Errorf("%v: RTE cycles = %d, want %d", tt.v, n, tt.cycles)
+			}
+			if reg := cpu.Registers(); reg.PC != 0x1002 || reg.SR != 0x2704 || reg.A[7] != 0x10000 {
+				t.Errorf("%v: PC = 0x%X SR = 0x%04X A7 = 0x%X, want the stacked 0x1002, 0x2704, 0x10000",
+					tt.v, reg.PC, reg.SR, reg.A[7])
+			}
+		}
+	})
+
+	t.Run("format error", func(t *testing.T) {
+		for _, tt := range []struct {
+			v      Variant
+			format uint16
+		}{
+			{MC68010, 0x2000}, // the 68020's six-word frame
+			{MC68010, 0xA000}, // the 68020's bus fault frame
+			{MC68020, 0x8000}, // the 68010's bus fault frame
+			{MC68020, 0x1000}, // throwaway frame, never stacked
+		} {
+			// TRAP #0, handler MOVE.W #format,6(A7); RTE
+			cpu, bus := progCPU(tt.v, Registers{SR: 0x2700}, 0x4E40)
+			bus.Write32(vecTrap0*4, 0x3000)
+			bus.Write32(vecFormatError*4, 0x4000)
+			writeWord(bus, 0x3000, 0x3F7C)
+			writeWord(bus, 0x3002, tt.format)
+			writeWord(bus, 0x3004, 0x0006)
+			writeWord(bus, 0x3006, 0x4E73)
+			cpu.Step()
+			cpu.Step()
+			cpu.Step()
+			sp := cpu.Registers().A[7]
+			if pc := cpu.PC(); pc != 0x4000 {
+				t.Errorf("%v format %X: PC = 0x%X, want format error handler 0x4000", tt.v, tt.format>>12, pc)
+			}
+			if sp != 0x10000-16 {
+				t.Fatalf("%v format %X: SSP = 0x%X, want 0x%X", tt.v, tt.format>>12, sp, 0x10000-16)
+			}
+			if pc := bus.Read32(sp + 2); pc != 0x3006 {
+				t.Errorf("%v format %X: stacked PC = 0x%X, want the RTE at 0x3006", tt.v, tt.format>>12, pc)
+			}
+			if w := bus.Read16(sp + 6); w != 0x0038 {
+				t.Errorf("%v format %X: format word = 0x%04X, want 0x0038", tt.v, tt.format>>12, w)
+			}
+		}
+	})
+}
+
+// TestExceptionStorm covers handlers that fault again straight away: each
+// must end in a halt rather than stacking frames without limit.
+func TestExceptionStorm(t *testing.T) {
+	newStormCPU := func(op uint16, a0 uint32, ssp uint32) (*CPU, *berrBus) {
+		bus := &berrBus{lo: 0x800000, hi: 0x900000}
+		cpu := &CPU{bus: bus}
+		bus.cpu = cpu
+		writeWord(&bus.testBus, 0x1000, op)
+		cpu.SetState(Registers{A: [8]uint32{a0}, PC: 0x1000, SR: 0x2700, SSP: ssp})
+		return cpu, bus
+	}
+
+	t.Run("bus error stacking a group 1 frame halts", func(t *testing.T) {
+		cpu, bus := newStormCPU(0x4AFC, 0, 0x800100) // ILLEGAL
+		bus.testBus.Write32(vecIllegalInstruction*4, 0x2000)
+		bus.testBus.Write32(vecBusError*4, 0x3000)
+		cpu.Step()
+		if !cpu.Halted() {
+			t.Error("expected a double bus fault")
+		}
+	})
+
+	t.Run("odd handler for a group 1 exception", func(t *testing.T) {
+		// The illegal handler is odd, so its prefetch raises an address
+		// error; the address error handler is odd too.
+		cpu, bus := newStormCPU(0x4AFC, 0, 0x10000)
+		bus.testBus.Write32(vecIllegalInstruction*4, 0x2001)
+		bus.testBus.Write32(vecAddressError*4, 0x3001)
+		cpu.Step()
+		if !cpu.Halted() {
+			t.Error("expected a double bus fault")
+		}
+		// Group 1 frame then group 0 frame, and nothing more.
+		if sp := cpu.Registers().A[7]; sp != 0x10000-6-14 {
+			t.Errorf("SSP = 0x%X, want 0x%X", sp, 0x10000-6-14)
+		}
+	})
+
+	t.Run("odd address error handler halts", func(t *testing.T) {
+		cpu, bus := newStormCPU(0x3010, 0x2001, 0x10000) // MOVE.W (A0),D0
+		bus.testBus.Write32(vecAddressError*4, 0x3001)
+		cpu.Step()
+		if !cpu.Halted() {
+			t.Error("expected a double bus fault")
+		}
+		if sp := cpu.Registers().A[7]; sp != 0x10000-14 {
+			t.Errorf("SSP = 0x%X, want 0x%X", sp, 0x10000-14)
+		}
+	})
+
+	t.Run("odd bus error handler halts", func(t *testing.T) {
+		cpu, bus := newStormCPU(0x3010, 0x800010, 0x10000)
+		bus.testBus.Write32(vecBusError*4, 0x3001)
+		cpu.Step()
+		if !cpu.Halted() {
+			t.Error("expected a double bus fault")
+		}
+	})
+
+	t.Run("even handler is entered", func(t *testing.T) {
+		cpu, bus := newStormCPU(0x3010, 0x2001, 0x10000)
+		bus.testBus.Write32(vecAddressError*4, 0x3000)
+		cpu.Step()
+		if cpu.Halted() {
+			t.Fatal("unexpected halt")
+		}
+		if pc := cpu.Registers().PC; pc != 0x3000 {
+			t.Errorf("PC = 0x%X, want 0x3000", pc)
+		}
+	})
+
+	// haltsOnce checks that a Step ends in a halt charging the cut short
+	// group 0 processing, and that nothing more runs afterwards.
+	haltsOnce := func(t *testing.T, cpu *CPU) {
+		t.Helper()
+		if n := cpu.Step(); n != groupZeroCycles {
+			t.Errorf("halting Step = %d cycles, want %d", n, groupZeroCycles)
+		}
+		if !cpu.Halted() {
+			t.Fatal("expected a double bus fault")
+		}
+		cycles, sp := cpu.Cycles(), cpu.reg.A[7]
+		if n := cpu.Step(); n != 0 || cpu.Cycles() != cycles || cpu.reg.A[7] != sp {
+			t.Errorf("Step after halt = %d cycles, A7 0x%X, want 0 and A7 0x%X", n, cpu.reg.A[7], sp)
+		}
+	}
+
+	t.Run("odd SSP during TRAP halts", func(t *testing.T) {
+		cpu, bus := newStormCPU(0x4E41, 0, 0x10001) // TRAP #1
+		bus.testBus.Write32((vecTrap0+1)*4, 0x2000)
+		bus.testBus.Write32(vecAddressError*4, 0x3000)
+		haltsOnce(t, cpu)
+		if _, _, _, taken := cpu.LastException(); !taken {
+			t.Error("no exception recorded")
+		}
+	})
+
+	t.Run("bus error stacking an address error frame halts", func(t *testing.T) {
+		// MOVE.W (A0),D0 with odd A0 and the supervisor stack in the
+		// bus error region
+		cpu, bus := newStormCPU(0x3010, 0x2001, 0x800100)
+		bus.testBus.Write32(vecAddressError*4, 0x3000)
+		bus.testBus.Write32(vecBusError*4, 0x4000)
+		haltsOnce(t, cpu)
+		if vec, _, _, _ := cpu.LastException(); vec != vecAddressError {
+			t.Errorf("last exception = %d, want the address error", vec)
+		}
+	})
+
+	t.Run("exception raised while stacking halts", func(t *testing.T) {
+		cpu, bus := newStormCPU(0x4AFC, 0, 0x10000) // ILLEGAL
+		bus.testBus.Write32(vecIllegalInstruction*4, 0x2000)
+		cpu.inException = true // as if part way through stacking a frame
+		haltsOnce(t, cpu)
+		if sp := cpu.reg.A[7]; sp != 0x10000 {
+			t.Errorf("A7 = 0x%X, want no frame stacked", sp)
+		}
+	})
+}
+
+// TestHaltReason checks that each way of halting reports its own reason,
+// and that a reset clears it.
+func TestHaltReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		op     uint16
+		a0     uint32
+		ssp    uint32
+		setup  func(cpu *CPU, bus *berrBus)
+		reason HaltReason
+	}{
+		{"bus error stacking a group 0 frame", 0x4AFC, 0, 0x800100, nil, HaltDoubleBusFault},
+		{"odd SSP during TRAP", 0x4E41, 0, 0x10001, nil, HaltOddStack},
+		{"odd address error handler", 0x3010, 0x2001, 0x10000, func(cpu *CPU, bus *berrBus) {
+			bus.testBus.Write32(vecAddressError*4, 0x3001)
+		}, HaltOddHandler},
+		{"exception while stacking", 0x4AFC, 0, 0x10000, func(cpu *CPU, bus *berrBus) {
+			cpu.inException = true
+		}, HaltExceptionReentry},
+		{"uninitialized vector", 0x4E41, 0, 0x10000, func(cpu *CPU, bus *berrBus) {
+			bus.testBus.Write32((vecTrap0+1)*4, 0)
+			bus.testBus.Write32(vecUninitialized*4, 0)
+		}, HaltUninitializedVector},
+		{"strict mode", 0x4E71, 0, 0x10000, func(cpu *CPU, bus *berrBus) {
+			cpu.SetStrictUnimplemented(true)
+			nop := opcodeTable[0x4E71]
+			opcodeTable[0x4E71] = nil
+			t.Cleanup(func() { opcodeTable[0x4E71] = nop })
+		}, HaltUnimplemented},
+	}
+	seen := map[string]bool{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &berrBus{lo: 0x800000, hi: 0x900000}
+			cpu := &CPU{bus: bus}
+			bus.cpu = cpu
+			writeWord(&bus.testBus, 0x1000, tt.op)
+			for v := 2; v < 48; v++ {
+				bus.testBus.Write32(uint32(v)*4, 0x2000)
+			}
+			cpu.SetState(Registers{A: [8]uint32{tt.a0}, PC: 0x1000, SR: 0x2700, SSP: tt.ssp})
+			if tt.setup != nil {
+				tt.setup(cpu, bus)
+			}
+			if cpu.HaltReason() != HaltNone {
+				t.Fatalf("HaltReason() = %v before Step", cpu.HaltReason())
+			}
+			cpu.Step()
+			if !cpu.Halted() || cpu.HaltReason() != tt.reason {
+				t.Errorf("Halted() = %v, HaltReason() = %v; want true, %v", cpu.Halted(), cpu.HaltReason(), tt.reason)
+			}
+			if got := cpu.Unimplemented(); got != (tt.reason == HaltUnimplemented) {
+				t.Errorf("Unimplemented() = %v", got)
+			}
+			if seen[tt.reason.String()] {
+				t.Errorf("reason %q is not distinct", tt.reason)
+			}
+			seen[tt.reason.String()] = true
+
+			cpu.ResetTo(0x10000, 0x1000)
+			if cpu.HaltReason() != HaltNone {
+				t.Errorf("HaltReason() = %v after reset, want none", cpu.HaltReason())
+			}
+		})
+	}
+}
+
+func TestTrace(t *testing.T) {
+	newTraceCPU := func(sr uint16, code ...uint16) (*CPU, *testBus) {
+		cpu, bus := progCPU(MC68000, Registers{SR: sr}, code...)
+		bus.Write32(vecTrace*4, 0x3000)
+		bus.Write32(vecIllegalInstruction*4, 0x4000)
+		bus.Write32(vecTrap0*4, 0x5000)
+		fillNOPs(bus, 0x3000, 4)
+		fillNOPs(bus, 0x4000, 4)
+		fillNOPs(bus, 0x5000, 4)
+		return cpu, bus
+	}
+
+	t.Run("trace follows the traced instruction", func(t *testing.T) {
+		cpu, bus := newTraceCPU(0xA700, 0x4E71, 0x4E71)
+
+		if got := cpu.Step(); got != 4 {
+			t.Errorf("NOP cycles = %d, want 4", got)
+		}
+		if pc := cpu.Registers().PC; pc != 0x1002 {
+			t.Fatalf("PC after NOP = 0x%06X, want 0x1002", pc)
+		}
+
+		// Trace exception, then the handler's first NOP
+		if got := cpu.Step(); got != 34+4 {
+			t.Errorf("trace cycles = %d, want %d", got, 34+4)
+		}
+		reg := cpu.Registers()
+		if reg.PC != 0x3002 {
+			t.Errorf("PC = 0x%06X, want 0x3002", reg.PC)
+		}
+		if reg.SR&flagT != 0 {
+			t.Errorf("SR = 0x%04X, want T clear in handler", reg.SR)
+		}
+		if got := bus.Read32(reg.A[7] + 2); got != 0x1002 {
+			t.Errorf("stacked PC = 0x%06X, want 0x1002", got)
+		}
+		if got := bus.Read16(reg.A[7]); got != 0xA700 {
+			t.Errorf("stacked SR = 0x%04X, want 0xA700", got)
+		}
+	})
+
+	t.Run("clearing T still traces that instruction", func(t *testing.T) {
+		// MOVE #$2700, SR
+		cpu, _ := newTraceCPU(0xA700, 0x46FC, 0x2700)
+
+		cpu.Step()
+		cpu.Step()
+		if pc := cpu.Registers().PC; pc != 0x3002 {
+			t.Errorf("PC = 0x%06X, want trace handler 0x3002", pc)
+		}
+	})
+
+	t.Run("illegal instruction is not traced", func(t *testing.T) {
+		cpu, _ := newTraceCPU(0xA700, 0x4AFC)
+
+		cpu.Step()
+		cpu.Step()
+		if pc := cpu.Registers().PC; pc != 0x4002 {
+			t.Errorf("PC = 0x%06X, want illegal handler 0x4002", pc)
+		}
+	})
+
+	t.Run("trap is traced into its handler", func(t *testing.T) {
+		// TRAP #0
+		cpu, bus := newTraceCPU(0xA700, 0x4E40)
+
+		cpu.Step()
+		cpu.Step()
+		reg := cpu.Registers()
+		if reg.PC != 0x3002 {
+			t.Errorf("PC = 0x%06X, want trace handler 0x3002", reg.PC)
+		}
+		if got := bus.Read32(reg.A[7] + 2); got != 0x5000 {
+			t.Errorf("stacked PC = 0x%06X, want trap handler 0x5000", got)
+		}
+	})
+
+	t.Run("traced STOP resumes through trace", func(t *testing.T) {
+		// STOP #$2700
+		cpu, _ := newTraceCPU(0xA700, 0x4E72, 0x2700)
+
+		cpu.Step()
+		if !cpu.stopped {
+			t.Fatalf("CPU not stopped after STOP")
+		}
+		cpu.Step()
+		if cpu.stopped {
+			t.Errorf("CPU still stopped after trace")
+		}
+		if pc := cpu.Registers().PC; pc != 0x3002 {
+			t.Errorf("PC = 0x%06X, want trace handler 0x3002", pc)
+		}
+	})
+
+	t.Run("no trace when T clear", func(t *testing.T) {
+		cpu, _ := newTraceCPU(0x2700, 0x4E71, 0x4E71)
+
+		cpu.Step()
+		cpu.Step()
+		if pc := cpu.Registers().PC; pc != 0x1004 {
+			t.Errorf("PC = 0x%06X, want 0x1004", pc)
+		}
+	})
+}
+
+func TestPrefetch(t *testing.T) {
+	// MOVE.W D0, ($1004).W overwrites the following NOP with MOVEQ #5, D1.
+	setup := func(prefetch bool) *CPU {
+		bus := &testBus{}
+		cpu := &CPU{bus: bus}
+		writeWord(bus, 0x1000, 0x31C0)
+		writeWord(bus, 0x1002, 0x1004)
+		fillNOPs(bus, 0x1004, 4)
+		cpu.SetPrefetch(prefetch)
+		pc := uint32(0x1000)
+		if prefetch {
+			pc += 4
+		}
+		cpu.SetState(Registers{D: [8]uint32{0x7205}, PC: pc, SR: 0x2700, SSP: 0x10000})
+		return cpu
+	}
+
+	t.Run("write into prefetched words is not seen", func(t *testing.T) {
+		cpu := setup(true)
+		cpu.Step()
+		if pc := cpu.Registers().PC; pc != 0x1008 {
+			t.Errorf("PC = 0x%06X, want hardware PC 0x1008", pc)
+		}
+		cpu.Step()
+		if d1 := cpu.Registers().D[1]; d1 != 0 {
+			t.Errorf("D1 = %d, want 0 (stale NOP executed)", d1)
+		}
+	})
+
+	t.Run("without prefetch the new opcode executes", func(t *testing.T) {
+		cpu := setup(false)
+		cpu.Step()
+		if pc := cpu.Registers().PC; pc != 0x1004 {
+			t.Errorf("PC = 0x%06X, want 0x1004", pc)
+		}
+		cpu.Step()
+		if d1 := cpu.Registers().D[1]; d1 != 5 {
+			t.Errorf("D1 = %d, want 5", d1)
+		}
+	})
+
+	t.Run("jump refills the queue", func(t *testing.T) {
+		bus := &testBus{}
+		cpu := &CPU{bus: bus}
+		// JMP ($2000).W then MOVEQ #7, D1 at the target
+		writeWord(bus, 0x1000, 0x4EF8)
+		writeWord(bus, 0x1002, 0x2000)
+		writeWord(bus, 0x2000, 0x7207)
+		cpu.SetPrefetch(true)
+		cpu.SetState(Registers{PC: 0x1004, SR: 0x2700, SSP: 0x10000})
+
+		cpu.Step()
+		cpu.Step()
+		reg := cpu.Registers()
+		if reg.D[1] != 7 {
+			t.Errorf("D1 = %d, want 7", reg.D[1])
+		}
+		if reg.PC != 0x2006 {
+			t.Errorf("PC = 0x%06X, want 0x2006", reg.PC)
+		}
+	})
+}
+
+func TestRegisterAccessors(t *testing.T) {
+	t.Run("data and address registers", func(t *testing.T) {
+		cpu, _ := newNOPCPU(4)
+		cpu.SetD(3, 0x12345678)
+		cpu.SetA(2, 0x00ABCDEF)
+		if got := cpu.D(3); got != 0x12345678 {
+			t.Errorf("D3 = 0x%08X, want 0x12345678", got)
+		}
+		if got := cpu.A(2); got != 0x00ABCDEF {
+			t.Errorf("A2 = 0x%08X, want 0x00ABCDEF", got)
+		}
+		reg := cpu.Registers()
+		if reg.D[3] != 0x12345678 || reg.A[2] != 0x00ABCDEF {
+			t.Errorf("Registers() = D3 0x%08X A2 0x%08X", reg.D[3], reg.A[2])
+		}
+	})
+
+	t.Run("A7 updates the active shadow", func(t *testing.T) {
+		cpu, _ := newNOPCPU(4)
+		cpu.SetState(Registers{PC: 0x1000, SR: 0x2700, SSP: 0x10000, USP: 0x8000})
+		cpu.SetA(7, 0x20000)
+		reg := cpu.Registers()
+		if reg.SSP != 0x20000 || reg.USP != 0x8000 {
+			t.Errorf("supervisor: SSP = 0x%X USP = 0x%X, want 0x20000 0x8000", reg.SSP, reg.USP)
+		}
+
+		cpu.SetSR(0x0000)
+		if got := cpu.A(7); got != 0x8000 {
+			t.Errorf("after leaving supervisor A7 = 0x%X, want USP 0x8000", got)
+		}
+		cpu.SetA(7, 0x9000)
+		cpu.SetSR(0x2000)
+		if got := cpu.A(7); got != 0x20000 {
+			t.Errorf("after entering supervisor A7 = 0x%X, want SSP 0x20000", got)
+		}
+		if got := cpu.Registers().USP; got != 0x9000 {
+			t.Errorf("USP = 0x%X, want 0x9000", got)
+		}
+	})
+
+	t.Run("SR masks unimplemented bits", func(t *testing.T) {
+		cpu, _ := newNOPCPU(4)
+		cpu.SetSR(0xFFFF)
+		if got := cpu.SR(); got != 0xA71F {
+			t.Errorf("SR = 0x%04X, want 0xA71F", got)
+		}
+	})
+
+	t.Run("PC redirects execution", func(t *testing.T) {
+		for _, prefetch := range []bool{false, true} {
+			cpu, bus := newNOPCPU(4)
+			cpu.SetPrefetch(prefetch)
+			writeWord(bus, 0x2000, 0x7207) // MOVEQ #7,D1
+			pc := uint32(0x2000)
+			if prefetch {
+				pc += 4
+			}
+			cpu.SetPC(pc)
+			if got := cpu.PC(); got != pc {
+				t.Errorf("prefetch=%v: PC() = 0x%X, want 0x%X", prefetch, got, pc)
+			}
+			cpu.Step()
+			if got := cpu.D(1); got != 7 {
+				t.Errorf("prefetch=%v: D1 = %d, want 7", prefetch, got)
+			}
+			if got := cpu.PC(); got != pc+2 {
+				t.Errorf("prefetch=%v: PC() = 0x%X, want 0x%X", prefetch, got, pc+2)
+			}
+		}
+	})
+}
+
+func TestTraceFunc(t *testing.T) {
+	type entry struct {
+		pc uint32
+		ir uint16
+	}
+
+	t.Run("three NOPs", func(t *testing.T) {
+		cpu, _ := newNOPCPU(4)
+		var log []entry
+		cpu.SetTraceFunc(func(pc uint32, ir uint16, regs Registers) {
+			log = append(log, entry{pc, ir})
+			if regs.PC != pc+2 {
+				t.Errorf("regs.PC = 0x%X, want 0x%X", regs.PC, pc+2)
+			}
+		})
+		for i := 0; i < 3; i++ {
+			cpu.Step()
+		}
+		want := []entry{{0x1000, 0x4E71}, {0x1002, 0x4E71}, {0x1004, 0x4E71}}
+		if len(log) != len(want) {
+			t.Fatalf("got %d entries, want %d", len(log), len(want))
+		}
+		for i := range want {
+			if log[i] != want[i] {
+				t.Errorf("entry %d = %+v, want %+v", i, log[i], want[i])
+			}
+		}
+	})
+
+	t.Run("not called while paying a deficit", func(t *testing.T) {
+		cpu, _ := newNOPCPU(4)
+		calls := 0
+		cpu.SetTraceFunc(func(uint32, uint16, Registers) { calls++ })
+		cpu.StepCycles(2) // NOP costs 4: 2 left as deficit
+		cpu.StepCycles(2)
+		if calls != 1 {
+			t.Errorf("calls = %d, want 1", calls)
+		}
+	})
+
+	t.Run("removed with nil", func(t *testing.T) {
+		cpu, _ := newNOPCPU(4)
+		calls := 0
+		cpu.SetTraceFunc(func(uint32, uint16, Registers) { calls++ })
+		cpu.Step()
+		cpu.SetTraceFunc(nil)
+		cpu.Step()
+		if calls != 1 {
+			t.Errorf("calls = %d, want 1", calls)
+		}
+	})
+}
+
+func TestIllegalFunc(t *testing.T) {
+	type entry struct {
+		pc   uint32
+		ir   uint16
+		kind int
+	}
+	cpu, bus := newNOPCPU(8)
+	writeWord(bus, 0x1000, 0x4AFC) // ILLEGAL
+	writeWord(bus, 0x1002, 0xA000) // Line A
+	writeWord(bus, 0x1004, 0xF000) // Line F
+	writeWord(bus, 0x1006, 0x42C0) // MOVE CCR,D0: 68010 only
+	for _, v := range []int{vecIllegalInstruction, vecLineA, vecLineF} {
+		bus.Write32(uint32(v)*4, 0x2000)
+	}
+	var log []entry
+	cpu.SetIllegalFunc(func(pc uint32, ir uint16, kind int) {
+		log = append(log, entry{pc, ir, kind})
+	})
+	for pc := uint32(0x1000); pc < 0x1008; pc += 2 {
+		cpu.SetState(Registers{PC: pc, SR: 0x2700, A: [8]uint32{7: 0x10000}, SSP: 0x10000})
+		cpu.Step()
+		if got := cpu.Registers().PC; got != 0x2000 {
+			t.Errorf("PC after %04X = 0x%X, want 0x2000", bus.mem[pc:pc+2], got)
+		}
+	}
+	want := []entry{
+		{0x1000, 0x4AFC, IllegalOpcode},
+		{0x1002, 0xA000, IllegalLineA},
+		{0x1004, 0xF000, IllegalLineF},
+		{0x1006, 0x42C0, IllegalOpcode},
+	}
+	if len(log) != len(want) {
+		t.Fatalf("got %d calls, want %d", len(log), len(want))
+	}
+	for i := range want {
+		if log[i] != want[i] {
+			t.Errorf("call %d = %+v, want %+v", i, log[i], want[i])
+		}
+	}
+
+	// Other exceptions do not report.
+	log = nil
+	writeWord(bus, 0x1000, 0x4E40) // TRAP #0
+	bus.Write32(vecTrap0*4, 0x2000)
+	cpu.SetState(Registers{PC: 0x1000, SR: 0x2700, A: [8]uint32{7: 0x10000}, SSP: 0x10000})
+	cpu.Step()
+	if len(log) != 0 {
+		t.Errorf("TRAP reported %+v", log)
+	}
+}
+
+func TestSetLogf(t *testing.T) {
+	bus := &testBus{}
+	cpu := &CPU{bus: bus}
+	writeWord(bus, 0x1000, 0x3010) // MOVE.W (A0),D0
+	bus.Write32(vecAddressError*4, 0x3000)
+	var msgs []string
+	cpu.SetLogf(func(format string, args ...any) {
+		msgs = append(msgs, fmt.Sprintf(format, args...))
+	})
+	cpu.SetState(Registers{PC: 0x1000, SR: 0x2700, A: [8]uint32{0x2001}, SSP: 0x10000})
+	cpu.Step()
+	if len(msgs) == 0 {
+		t.Fatal("no message logged for address error")
+	}
+	if !strings.Contains(msgs[0], "address error") || !strings.Contains(msgs[0], "002001") {
+		t.Errorf("message = %q, want an address error at 002001", msgs[0])
+	}
+
+	// Removing the function silences the CPU again.
+	msgs = nil
+	cpu.SetLogf(nil)
+	cpu.SetState(Registers{PC: 0x1000, SR: 0x2700, A: [8]uint32{0x2001}, SSP: 0x10000})
+	cpu.Step()
+	if len(msgs) != 0 {
+		t.Errorf("logged %q after SetLogf(nil)", msgs)
+	}
+}
+
+// fcBus records the function code in effect for each bus access.
+type fcBus struct {
+	testBus
+	fc    uint8
+	reads map[uint32]uint8
+	write map[uint32]uint8
+	iack  int
+}
+
+func (b *fcBus) SetFunctionCode(fc uint8) {
+	b.fc = fc
+	if fc == FCCPUSpace {
+		b.iack++
+	}
+}
+
+func (b *fcBus) Read16(addr uint32) uint16 {
+	b.reads[addr] = b.fc
+	return b.testBus.Read16(addr)
+}
+
+func (b *fcBus) Write16(addr uint32, val uint16) {
+	b.write[addr] = b.fc
+	b.testBus.Write16(addr, val)
+}
+
+func TestFunctionCodes(t *testing.T) {
+	run := func(sr uint16) *fcBus {
+		bus := &fcBus{reads: map[uint32]uint8{}, write: map[uint32]uint8{}}
+		writeWord(&bus.testBus, 0x1000, 0x3080) // MOVE.W D0,(A0)
+		writeWord(&bus.testBus, 0x1002, 0x3211) // MOVE.W (A1),D1
+		cpu := &CPU{bus: bus}
+		cpu.SetState(Registers{A: [8]uint32{0x4000, 0x4002}, PC: 0x1000, SR: sr, SSP: 0x10000, USP: 0x8000})
+		cpu.Step()
+		cpu.Step()
+		return bus
+	}
+
+	for _, tt := range []struct {
+		name          string
+		sr            uint16
+		program, data uint8
+	}{
+		{"supervisor", 0x2700, FCSuperProgram, FCSuperData},
+		{"user", 0x0000, FCUserProgram, FCUserData},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := run(tt.sr)
+			if got := bus.reads[0x1000]; got != tt.program {
+				t.Errorf("opcode fetch FC = %d, want %d", got, tt.program)
+			}
+			if got := bus.write[0x4000]; got != tt.data {
+				t.Errorf("data write FC = %d, want %d", got, tt.data)
+			}
+			if got := bus.reads[0x4002]; got != tt.data {
+				t.Errorf("data read FC = %d, want %d", got, tt.data)
+			}
+		})
+	}
+
+	t.Run("interrupt acknowledge", func(t *testing.T) {
+		bus := &fcBus{reads: map[uint32]uint8{}, write: map[uint32]uint8{}}
+		fillNOPs(&bus.testBus, 0x1000, 4)
+		fillNOPs(&bus.testBus, 0x2000, 4)
+		bus.testBus.Write32(0x68, 0x2000)
+		cpu := &CPU{bus: bus}
+		cpu.SetState(Registers{PC: 0x1000, SR: 0x2000, SSP: 0x10000})
+		cpu.RequestInterrupt(2, nil)
+		cpu.Step()
+		if bus.iack != 1 {
+			t.Errorf("CPU space cycles = %d, want 1", bus.iack)
+		}
+		if got := bus.write[0x10000-6]; got != FCSuperData {
+			t.Errorf("stack write FC = %d, want %d", got, FCSuperData)
+		}
+	})
+}
+
+// protectBus is a testBus that rejects user mode writes below limit.
+type protectBus struct {
+	testBus
+	cpu        *CPU
+	supervisor bool
+	limit      uint32
+}
+
+func (b *protectBus) SetSupervisor(supervisor bool) {
+	b.supervisor = supervisor
+}
+
+func (b *protectBus) Write16(addr uint32, val uint16) {
+	if !b.supervisor && addr < b.limit {
+		b.cpu.BusError(addr)
+		return
+	}
+	b.testBus.Write16(addr, val)
+}
+
+func TestSupervisorBus(t *testing.T) {
+	run := func(sr uint16) (*CPU, *protectBus) {
+		bus := &protectBus{limit: 0x8000}
+		writeWord(&bus.testBus, 0x1000, 0x3080) // MOVE.W D0,(A0)
+		bus.testBus.Write32(vecBusError*4, 0x3000)
+		cpu := &CPU{bus: bus}
+		bus.cpu = cpu
+		cpu.SetState(Registers{
+			D: [8]uint32{0x1234}, A: [8]uint32{0x4000},
+			PC: 0x1000, SR: sr, SSP: 0x10000, USP: 0x9000,
+		})
+		cpu.Step()
+		return cpu, bus
+	}
+
+	cpu, bus := run(0x2700)
+	if got := bus.testBus.Read16(0x4000); got != 0x1234 {
+		t.Errorf("supervisor write: memory = 0x%04X, want 0x1234", got)
+	}
+	if pc := cpu.PC(); pc != 0x1002 {
+		t.Errorf("supervisor write: PC = 0x%X, want 0x1002", pc)
+	}
+
+	cpu, bus = run(0x0000)
+	if got := bus.testBus.Read16(0x4000); got != 0 {
+		t.Errorf("user write: memory = 0x%04X, want it rejected", got)
+	}
+	if pc := cpu.PC(); pc != 0x3000 {
+		t.Errorf("user write: PC = 0x%X, want the bus error handler", pc)
+	}
+	if !bus.supervisor {
+		t.Error("bus error frame was not stacked in supervisor mode")
+	}
+}
+
+// cycleBus records the cycle stamp of each word and long access.
+type cycleBus struct {
+	testBus
+	cycle  uint64
+	stamps []string
+}
+
+func (b *cycleBus) SetCycle(cycle uint64) {
+	b.cycle = cycle
+}
+
+func (b *cycleBus) Read16(addr uint32) uint16 {
+	b.stamps = append(b.stamps, fmt.Sprintf("%d:R%X", b.cycle, addr))
+	return b.testBus.Read16(addr)
+}
+
+func (b *cycleBus) Read32(addr uint32) uint32 {
+	b.stamps = append(b.stamps, fmt.Sprintf("%d:R%X", b.cycle, addr))
+	return b.testBus.Read32(addr)
+}
+
+func (b *cycleBus) Write16(addr uint32, val uint16) {
+	b.stamps = append(b.stamps, fmt.Sprintf("%d:W%X", b.cycle, addr))
+	b.testBus.Write16(addr, val)
+}
+
+func (b *cycleBus) Write32(addr uint32, val uint32) {
+	b.stamps = append(b.stamps, fmt.Sprintf("%d:W%X", b.cycle, addr))
+	b.testBus.Write32(addr, val)
+}
+
+func TestBusCycleStamp(t *testing.T) {
+	bus := &cycleBus{}
+	writeWord(&bus.testBus, 0x1000, 0x2290) // MOVE.L (A0),(A1)
+	writeWord(&bus.testBus, 0x1002, 0x2290)
+	cpu := &CPU{bus: bus}
+	cpu.SetState(Registers{A: [8]uint32{0x4000, 0x5000}, PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+	cpu.Step()
+	if n := cpu.Cycles(); n != 20 {
+		t.Fatalf("cycles = %d, want 20", n)
+	}
+	cpu.Step()
+
+	// Each access follows the previous one by 4 clocks per bus cycle, and
+	// the next instruction starts once the first has been charged.
+	want := []string{"0:R1000", "4:R4000", "12:W5000", "20:R1002", "24:R4000", "32:W5000"}
+	if fmt.Sprint(bus.stamps) != fmt.Sprint(want) {
+		t.Errorf("stamps = %v, want %v", bus.stamps, want)
+	}
+}
+
+// TestReadModifyWriteOrder checks that an instruction updating memory
+// through (An)+ reads the operand once and writes it back once to the same
+// address, incrementing An a single time.
+func TestReadModifyWriteOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		prog []uint16
+		want []string
+	}{
+		{"NOT.W (A0)+", []uint16{0x4658}, []string{"0:R1000", "4:R4000", "8:W4000"}},
+		{"ADDI.W #1,(A0)+", []uint16{0x0658, 0x0001}, []string{"0:R1000", "4:R1002", "8:R4000", "12:W4000"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &cycleBus{}
+			for i, w := range tt.prog {
+				writeWord(&bus.testBus, 0x1000+uint32(i*2), w)
+			}
+			writeWord(&bus.testBus, 0x4000, 0x1234)
+			cpu := &CPU{bus: bus}
+			cpu.SetState(Registers{A: [8]uint32{0x4000}, PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+			cpu.Step()
+			if fmt.Sprint(bus.stamps) != fmt.Sprint(tt.want) {
+				t.Errorf("accesses = %v, want %v", bus.stamps, tt.want)
+			}
+			if a0 := cpu.A(0); a0 != 0x4002 {
+				t.Errorf("A0 = 0x%X, want 0x4002", a0)
+			}
+		})
+	}
+}
+
+func TestResetTo(t *testing.T) {
+	bus := &testBus{}
+	fillNOPs(bus, 0x2000, 2)
+	bus.Write32(0, 0xDEAD0000) // vector table must not be read
+	bus.Write32(4, 0xDEAD0004)
+	cpu := &CPU{bus: bus}
+	cpu.SetState(Registers{D: [8]uint32{1, 2}, PC: 0x1001, SR: 0x0000, USP: 0x7000})
+	cpu.Step() // odd PC: address error
+	cpu.deficit = 7
+	cpu.stopped = true
+	cpu.RequestInterrupt(3, nil)
+
+	cpu.ResetTo(0x8000, 0x2000)
+
+	reg := cpu.Registers()
+	want := Registers{PC: 0x2000, SR: 0x2700, SSP: 0x8000}
+	want.A[7] = 0x8000
+	if reg != want {
+		t.Errorf("registers = %+v, want %+v", reg, want)
+	}
+	if cpu.Halted() || cpu.Stopped() || cpu.Deficit() != 0 || cpu.Cycles() != 0 {
+		t.Errorf("halted=%v stopped=%v deficit=%d cycles=%d, want all clear",
+			cpu.Halted(), cpu.Stopped(), cpu.Deficit(), cpu.Cycles())
+	}
+	if level, _ := cpu.PendingInterrupt(); level != 0 {
+		t.Errorf("pending level = %d, want 0", level)
+	}
+	if n := cpu.Step(); n != 4 || cpu.PC() != 0x2002 {
+		t.Errorf("Step = %d cycles, PC 0x%X, want a NOP at 0x2000", n, cpu.PC())
+	}
+}
+
+// resetCountBus counts Bus.Reset calls.
+type resetCountBus struct {
+	testBus
+	resets int
+}
+
+func (b *resetCountBus) Reset() { b.resets++ }
+
+func TestResetCPU(t *testing.T) {
+	bus := &resetCountBus{}
+	bus.Write32(0, 0x8000)
+	bus.Write32(4, 0x2000)
+	cpu := New(bus)
+	if bus.resets != 0 {
+		t.Errorf("New: bus resets = %d, want 0", bus.resets)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		reset  func()
+		resets int
+	}{
+		{"ResetCPU", cpu.ResetCPU, 0},
+		{"Reset", cpu.Reset, 1},
+	} {
+		bus.resets = 0
+		cpu.SetState(Registers{D: [8]uint32{1}, PC: 0x1000, SR: 0x0000, USP: 0x7000})
+		cpu.halted = true
+		tt.reset()
+		if bus.resets != tt.resets {
+			t.Errorf("%s: bus resets = %d, want %d", tt.name, bus.resets, tt.resets)
+		}
+		reg := cpu.Registers()
+		if reg.PC != 0x2000 || reg.SSP != 0x8000 || reg.SR != 0x2700 || reg.D[0] != 0 {
+			t.Errorf("%s: PC=0x%X SSP=0x%X SR=0x%04X D0=%d, want the reset state",
+				tt.name, reg.PC, reg.SSP, reg.SR, reg.D[0])
+		}
+		if cpu.Halted() {
+			t.Errorf("%s: still halted", tt.name)
+		}
+	}
+}
+
+func TestResetPinFunc(t *testing.T) {
+	bus := &testBus{}
+	writeWord(bus, 0x1000, 0x4E70) // RESET
+	cpu := &CPU{bus: bus}
+	before := Registers{
+		D:   [8]uint32{1, 2, 3, 4, 5, 6, 7, 8},
+		A:   [8]uint32{0x10, 0x20, 0x30, 0x40, 0x50, 0x60, 0x70},
+		PC:  0x1000,
+		SR:  0x2715,
+		USP: 0x8000,
+		SSP: 0x10000,
+	}
+	cpu.SetState(before)
+	var pulses []int
+	cpu.SetResetPinFunc(func(pulse int) { pulses = append(pulses, pulse) })
+
+	if n := cpu.Step(); n != resetInstrCycles {
+		t.Errorf("cycles = %d, want %d", n, resetInstrCycles)
+	}
+	if len(pulses) != 1 || pulses[0] != resetPulseClocks {
+		t.Errorf("reset pin pulses = %v, want [%d]", pulses, resetPulseClocks)
+	}
+	got := cpu.Registers()
+	want := before
+	want.A[7] = before.SSP
+	want.PC = 0x1002
+	want.IR = 0x4E70
+	if got != want {
+		t.Errorf("registers changed:\n got  %+v\n want %+v", got, want)
+	}
+}
+
+func TestResetTiming(t *testing.T) {
+	tests := []struct {
+		name                  string
+		v                     Variant
+		cycles, pulse         int
+		wantCycles, wantPulse int
+	}{
+		{"68000 default", MC68000, 0, 0, 132, 124},
+		{"68020 default", MC68020, 0, 0, 518, 512},
+		{"custom", MC68000, 264, 248, 264, 248},
+		{"custom cycles only", MC68010, 200, 0, 200, 124},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			writeWord(bus, 0x1000, 0x4E70) // RESET
+			cpu := &CPU{bus: bus, variant: tt.v}
+			cpu.SetState(Registers{PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+			cpu.SetResetTiming(tt.cycles, tt.pulse)
+			pulse := 0
+			cpu.SetResetPinFunc(func(p int) { pulse = p })
+			if n := cpu.Step(); n != tt.wantCycles {
+				t.Errorf("cycles = %d, want %d", n, tt.wantCycles)
+			}
+			if pulse != tt.wantPulse {
+				t.Errorf("pulse = %d, want %d", pulse, tt.wantPulse)
+			}
+		})
+	}
+}
+
+func TestStoppedAndPrevPC(t *testing.T) {
+	bus := &testBus{}
+	writeWord(bus, 0x1000, 0x4E71) // NOP
+	writeWord(bus, 0x1002, 0x4E72) // STOP #$2000
+	writeWord(bus, 0x1004, 0x2000)
+	cpu := &CPU{bus: bus}
+	cpu.SetState(Registers{PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+
+	cpu.Step()
+	if cpu.Stopped() {
+		t.Error("Stopped() = true after NOP")
+	}
+	if got := cpu.PrevPC(); got != 0x1000 {
+		t.Errorf("PrevPC() = 0x%X, want 0x1000", got)
+	}
+
+	cpu.Step()
+	if !cpu.Stopped() {
+		t.Error("Stopped() = false after STOP")
+	}
+	if got := cpu.PrevPC(); got != 0x1002 {
+		t.Errorf("PrevPC() = 0x%X, want 0x1002", got)
+	}
+	cpu.Step()
+	if !cpu.Stopped() || cpu.PrevPC() != 0x1002 {
+		t.Errorf("stopped step changed state: Stopped() = %v PrevPC() = 0x%X", cpu.Stopped(), cpu.PrevPC())
+	}
+}
+
+func TestRunDrivers(t *testing.T) {
+	t.Run("RunInstructions", func(t *testing.T) {
+		cpu, _ := newNOPCPU(20)
+		if got := cpu.RunInstructions(5); got != 20 {
+			t.Errorf("RunInstructions(5) = %d, want 20", got)
+		}
+		if pc := cpu.Registers().PC; pc != 0x100A {
+			t.Errorf("PC = 0x%X, want 0x100A", pc)
+		}
+	})
+
+	t.Run("RunCycles scanline boundaries", func(t *testing.T) {
+		cpu, _ := newNOPCPU(20)
+
+		// Scanline 1: 10 cycles runs three NOPs, the third overrunning by 2.
+		if got := cpu.RunCycles(10); got != 10 {
+			t.Errorf("scanline 1 = %d, want 10", got)
+		}
+		if d := cpu.Deficit(); d != 2 {
+			t.Errorf("deficit after scanline 1 = %d, want 2", d)
+		}
+		if pc := cpu.Registers().PC; pc != 0x1006 {
+			t.Errorf("PC after scanline 1 = 0x%X, want 0x1006", pc)
+		}
+
+		// Scanline 2: the deficit is paid first, then two NOPs fit exactly.
+		if got := cpu.RunCycles(10); got != 10 {
+			t.Errorf("scanline 2 = %d, want 10", got)
+		}
+		if d := cpu.Deficit(); d != 0 {
+			t.Errorf("deficit after scanline 2 = %d, want 0", d)
+		}
+		if pc := cpu.Registers().PC; pc != 0x100A {
+			t.Errorf("PC after scanline 2 = 0x%X, want 0x100A", pc)
+		}
+	})
+
+	t.Run("halted CPU stops both drivers", func(t *testing.T) {
+		cpu, _ := newNOPCPU(1)
+		cpu.SetState(Registers{PC: 0x1001, SR: 0x2700, SSP: 0x10000})
+		cpu.Step()
+		if !cpu.Halted() {
+			t.Fatal("expected halt")
+		}
+		if got := cpu.RunCycles(100); got != 0 {
+			t.Errorf("RunCycles = %d, want 0", got)
+		}
+		if got := cpu.RunInstructions(10); got != 0 {
+			t.Errorf("RunInstructions = %d, want 0", got)
+		}
+	})
+}
+
+func TestRunUntil(t *testing.T) {
+	atPC := func(pc uint32) func(*CPU) bool {
+		return func(c *CPU) bool { return c.PC() == pc }
+	}
+
+	t.Run("stops at PC", func(t *testing.T) {
+		cpu, _ := newNOPCPU(20)
+		cycles, stopped := cpu.RunUntil(atPC(0x1010), 1000)
+		if !stopped {
+			t.Error("stopped = false, want true")
+		}
+		if cycles != 32 {
+			t.Errorf("cycles = %d, want 32", cycles)
+		}
+		if pc := cpu.PC(); pc != 0x1010 {
+			t.Errorf("PC = 0x%X, want 0x1010", pc)
+		}
+	})
+
+	t.Run("already satisfied", func(t *testing.T) {
+		cpu, _ := newNOPCPU(4)
+		if cycles, stopped := cpu.RunUntil(atPC(0x1000), 1000); cycles != 0 || !stopped {
+			t.Errorf("RunUntil = %d, %v, want 0, true", cycles, stopped)
+		}
+	})
+
+	t.Run("cycle ceiling", func(t *testing.T) {
+		cpu, _ := newNOPCPU(20)
+		cycles, stopped := cpu.RunUntil(atPC(0x2000), 10)
+		if stopped {
+			t.Error("stopped = true, want false")
+		}
+		if cycles != 12 {
+			t.Errorf("cycles = %d, want 12 (three NOPs, the last crossing the ceiling)", cycles)
+		}
+	})
+
+	t.Run("halt", func(t *testing.T) {
+		cpu, _ := newNOPCPU(1)
+		cpu.SetState(Registers{PC: 0x1001, SR: 0x2700, SSP: 0x10000})
+		if _, stopped := cpu.RunUntil(atPC(0x2000), 1000); stopped || !cpu.Halted() {
+			t.Errorf("stopped = %v, halted = %v, want false, true", stopped, cpu.Halted())
+		}
+	})
+}
+
+func TestStepOver(t *testing.T) {
+	newCPU := func(prog ...uint16) (*CPU, *testBus) {
+		cpu, bus := newNOPCPU(16)
+		for i, w := range prog {
+			writeWord(bus, 0x1000+uint32(i)*2, w)
+		}
+		// Subroutine at 0x1100: MOVEQ #5,D0; NOP; RTS
+		writeWord(bus, 0x1100, 0x7005)
+		writeWord(bus, 0x1102, 0x4E71)
+		writeWord(bus, 0x1104, 0x4E75)
+		return cpu, bus
+	}
+
+	t.Run("BSR", func(t *testing.T) {
+		cpu, _ := newCPU(0x6100, 0x00FE) // BSR.W $1100
+		cycles := cpu.StepOver()
+		if pc := cpu.PC(); pc != 0x1004 {
+			t.Errorf("PC = 0x%X, want 0x1004", pc)
+		}
+		if d0 := cpu.D(0); d0 != 5 {
+			t.Errorf("D0 = %d, want 5", d0)
+		}
+		if sp := cpu.A(7); sp != 0x10000 {
+			t.Errorf("A7 = 0x%X, want 0x10000", sp)
+		}
+		// BSR.W 18 + MOVEQ 4 + NOP 4 + RTS 16
+		if cycles != 42 {
+			t.Errorf("cycles = %d, want 42", cycles)
+		}
+	})
+
+	t.Run("JSR abs.L", func(t *testing.T) {
+		cpu, _ := newCPU(0x4EB9, 0x0000, 0x1100)
+		cpu.StepOver()
+		if pc := cpu.PC(); pc != 0x1006 {
+			t.Errorf("PC = 0x%X, want 0x1006", pc)
+		}
+	})
+
+	t.Run("not a call", func(t *testing.T) {
+		cpu, _ := newCPU(0x7003) // MOVEQ #3,D0
+		if cycles := cpu.StepOver(); cycles != 4 {
+			t.Errorf("cycles = %d, want 4", cycles)
+		}
+		if pc := cpu.PC(); pc != 0x1002 {
+			t.Errorf("PC = 0x%X, want 0x1002", pc)
+		}
+	})
+
+	t.Run("never returns", func(t *testing.T) {
+		cpu, bus := newCPU(0x6100, 0x00FE)
+		writeWord(bus, 0x1104, 0x60FE) // BRA.S *
+		cpu.StepOver()
+		if pc := cpu.PC(); pc != 0x1104 {
+			t.Errorf("PC = 0x%X, want 0x1104", pc)
+		}
+		if cpu.Cycles() < stepOverCycles {
+			t.Errorf("cycles = %d, want at least %d", cpu.Cycles(), stepOverCycles)
+		}
+	})
+}
+
+// loopCPU loads a program at 0x1000 that ends in BRA back to its start.
+func loopCPU(prog ...uint16) *CPU {
+	disp := -(len(prog)*2 + 2)
+	prog = append(prog[:len(prog):len(prog)], 0x6000|uint16(uint8(int8(disp))))
+	cpu, _ := progCPU(MC68000, Registers{A: [8]uint32{0x4000}, SR: 0x2700}, prog...)
+	return cpu
+}
+
+// benchLoop steps the loopCPU program with no optional features in use,
+// and again with a history and a watchpoint the program never touches,
+// to compare the fast path against the hooked one.
+func benchLoop(b *testing.B, prog ...uint16) {
+	for _, hooks := range []bool{false, true} {
+		name := "no hooks"
+		if hooks {
+			name = "hooks"
+		}
+		b.Run(name, func(b *testing.B) {
+			cpu := loopCPU(prog...)
+			if hooks {
+				cpu.SetHistorySize(16)
+				cpu.SetWatchpoint(0x8000, true, true)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cpu.Step()
+			}
+		})
+	}
+}
+
+func BenchmarkStepNOP(b *testing.B) {
+	// NOP; NOP; NOP
+	benchLoop(b, 0x4E71, 0x4E71, 0x4E71)
+}
+
+func BenchmarkMOVELoop(b *testing.B) {
+	// MOVE.W D0,(A0); MOVE.L (A0),D1; MOVE.B D1,D2
+	benchLoop(b, 0x3080, 0x2210, 0x1401)
+}
+
+func BenchmarkADDLoop(b *testing.B) {
+	// ADD.W D1,D0; ADD.L D0,(A0); ADD.B (A0),D2
+	benchLoop(b, 0xD041, 0xD190, 0xD410)
+}
+
+// byteBus is a testBus that counts accesses by width.
+type byteBus struct {
+	testBus
+	bytes, words int
+}
+
+func (b *byteBus) Read8(addr uint32) uint8 {
+	b.bytes++
+	return b.testBus.Read8(addr)
+}
+
+func (b *byteBus) Write8(addr uint32, val uint8) {
+	b.bytes++
+	b.testBus.Write8(addr, val)
+}
+
+func (b *byteBus) Read16(addr uint32) uint16 {
+	b.words++
+	return b.testBus.Read16(addr)
+}
+
+func (b *byteBus) Write16(addr uint32, val uint16) {
+	b.words++
+	b.testBus.Write16(addr, val)
+}
+
+func TestVariant(t *testing.T) {
+	t.Run("constructors", func(t *testing.T) {
+		bus := &testBus{}
+		if v := New(bus).Variant(); v != MC68000 {
+			t.Errorf("New variant = %v, want MC68000", v)
+		}
+		for _, v := range []Variant{MC68000, MC68008, MC68010, MC68020} {
+			if got := NewVariant(bus, v).Variant(); got != v {
+				t.Errorf("NewVariant(%v).Variant() = %v", v, got)
+			}
+		}
+		if s := MC68010.String(); s != "MC68010" {
+			t.Errorf("String() = %q, want MC68010", s)
+		}
+	})
+
+	t.Run("68008 splits word accesses into byte cycles", func(t *testing.T) {
+		for _, tt := range []struct {
+			v             Variant
+			cycles        int
+			bytes, words  int
+			wantMemResult uint16
+		}{
+			{MC68000, 8, 0, 2, 0xBEEF},
+			{MC68008, 16, 4, 0, 0xBEEF},
+		} {
+			bus := &byteBus{}
+			writeWord(&bus.testBus, 0x1000, 0x3080) // MOVE.W D0,(A0)
+			cpu := &CPU{bus: bus, variant: tt.v}
+			cpu.SetState(Registers{D: [8]uint32{0xBEEF}, A: [8]uint32{0x4000}, PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+			if n := cpu.Step(); n != tt.cycles {
+				t.Errorf("%v: cycles = %d, want %d", tt.v, n, tt.cycles)
+			}
+			if bus.bytes != tt.bytes || bus.words != tt.words {
+				t.Errorf("%v: %d byte and %d word accesses, want %d and %d", tt.v, bus.bytes, bus.words, tt.bytes, tt.words)
+			}
+			if got := bus.testBus.Read16(0x4000); got != tt.wantMemResult {
+				t.Errorf("%v: memory = 0x%04X, want 0x%04X", tt.v, got, tt.wantMemResult)
+			}
+		}
+	})
+
+	t.Run("68008 long access", func(t *testing.T) {
+		bus := &testBus{}
+		writeWord(bus, 0x1000, 0x2010) // MOVE.L (A0),D0
+		bus.Write32(0x4000, 0x12345678)
+		cpu := &CPU{bus: bus, variant: MC68008}
+		cpu.SetState(Registers{A: [8]uint32{0x4000}, PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+		if n := cpu.Step(); n != 24 {
+			t.Errorf("cycles = %d, want 24 (12 + 4 opcode + 8 operand)", n)
+		}
+		if d0 := cpu.Registers().D[0]; d0 != 0x12345678 {
+			t.Errorf("D0 = 0x%08X, want 0x12345678", d0)
+		}
+	})
+}
+
+func TestIndex68020(t *testing.T) {
+	// MOVE.L <ea>,D0 with the (d8,A0,Xn) mode; prog holds the extension
+	// words. The long at each candidate address identifies the one read.
+	run := func(v Variant, prog []uint16) uint32 {
+		bus := &testBus{}
+		writeWord(bus, 0x1000, 0x2030)
+		for i, w := range prog {
+			writeWord(bus, 0x1002+uint32(i*2), w)
+		}
+		for _, addr := range []uint32{0x4014, 0x4020, 0x16340, 0x6010} {
+			bus.Write32(addr, addr)
+		}
+		bus.Write32(0x5000, 0x6000) // pointer for memory indirection
+		cpu := &CPU{bus: bus, variant: v}
+		cpu.SetState(Registers{
+			PC: 0x1000, SR: 0x2700, SSP: 0x10000,
+			A: [8]uint32{0x4000}, D: [8]uint32{1: 4},
+		})
+		cpu.Step()
+		if pc, want := cpu.Registers().PC, 0x1002+uint32(len(prog)*2); pc != want {
+			t.Errorf("%v: PC = 0x%X, want 0x%X", v, pc, want)
+		}
+		return cpu.D(0)
+	}
+
+	t.Run("scaled index", func(t *testing.T) {
+		// (16,A0,D1.L*4): the 68000 ignores the scale field
+		prog := []uint16{0x1C10}
+		if got := run(MC68000, prog); got != 0x4014 {
+			t.Errorf("MC68000 read 0x%X, want 0x4014", got)
+		}
+		if got := run(MC68020, prog); got != 0x4020 {
+			t.Errorf("MC68020 read 0x%X, want 0x4020", got)
+		}
+	})
+
+	t.Run("suppressed index with long base displacement", func(t *testing.T) {
+		// ($12340.L,A0)
+		if got := run(MC68020, []uint16{0x0170, 0x0001, 0x2340}); got != 0x16340 {
+			t.Errorf("read 0x%X, want 0x16340", got)
+		}
+	})
+
+	t.Run("postindexed memory indirect with suppressed base", func(t *testing.T) {
+		// ([$5000.W],D1.L*2,8.W): pointer 0x6000 + 8 + 8
+		if got := run(MC68020, []uint16{0x1BA6, 0x5000, 0x0008}); got != 0x6010 {
+			t.Errorf("read 0x%X, want 0x6010", got)
+		}
+	})
+}
+
+func TestRegistersFlagHelpers(t *testing.T) {
+	tests := []struct {
+		sr     uint16
+		flags  string
+		super  bool
+		trace  bool
+		intMsk uint8
+	}{
+		{0xA71F, "T-S--7---XNZVC", true, true, 7},
+		{0x0000, "-----0--------", false, false, 0},
+		{0x2704, "--S--7-----Z--", true, false, 7},
+		{0x0319, "-----3---XN--C", false, false, 3},
+	}
+	for _, tt := range tests {
+		r := Registers{SR: tt.sr}
+		if got := r.FlagString(); got != tt.flags {
+			t.Errorf("SR %04X: FlagString() = %q, want %q", tt.sr, got, tt.flags)
+		}
+		if r.Supervisor() != tt.super || r.TraceEnabled() != tt.trace || r.InterruptMask() != tt.intMsk {
+			t.Errorf("SR %04X: Supervisor=%v TraceEnabled=%v InterruptMask=%d", tt.sr, r.Supervisor(), r.TraceEnabled(), r.InterruptMask())
+		}
+	}
+}
+
+func TestCPUInterruptMask(t *testing.T) {
+	cpu, _ := newNOPCPU(1)
+	for _, sr := range []uint16{0x2700, 0x2000, 0x0300, 0x251F, 0xA600, 0x0100, 0x04FF} {
+		cpu.SetSR(sr)
+		want := uint8(sr>>8) & 7
+		if got := cpu.InterruptMask(); got != want {
+			t.Errorf("SR %04X: InterruptMask() = %d, want %d", sr, got, want)
+		}
+		if got := cpu.Registers().InterruptMask(); got != want {
+			t.Errorf("SR %04X: Registers().InterruptMask() = %d, want %d", sr, got, want)
+		}
+	}
+}
+
+func TestIsImplemented(t *testing.T) {
+	for _, tt := range []struct {
+		ir      uint16
+		want    bool
+		illegal bool
+	}{
+		{0x4E71, true, false},  // NOP
+		{0xE9D0, true, false},  // BFEXTU (A0): 68020 only, but in the table
+		{0x4AFC, false, true},  // ILLEGAL
+		{0xA000, false, true},  // Line A
+		{0xAFFF, false, true},  // Line A
+		{0xF000, false, true},  // Line F
+		{0xF456, false, true},  // Line F
+		{0x4AFB, false, false}, // TAS (d8,PC,Xn): unassigned
+	} {
+		if got := IsImplemented(tt.ir); got != tt.want {
+			t.Errorf("IsImplemented(0x%04X) = %v, want %v", tt.ir, got, tt.want)
+		}
+		if got := IsIllegal(tt.ir); got != tt.illegal {
+			t.Errorf("IsIllegal(0x%04X) = %v, want %v", tt.ir, got, tt.illegal)
+		}
+	}
+
+	n := 0
+	for ir := 0; ir < 0x10000; ir++ {
+		if IsImplemented(uint16(ir)) {
+			n++
+		}
+	}
+	if got := ImplementedCount(); got != n || n == 0 {
+		t.Errorf("ImplementedCount() = %d, want %d", got, n)
+	}
+
+	// The illegal words are dispatched from the table, so strict mode
+	// leaves them to their exceptions
+	for _, tt := range []struct {
+		ir  uint16
+		vec int
+	}{
+		{0x4AFC, vecIllegalInstruction},
+		{0xA123, vecLineA},
+		{0xF456, vecLineF},
+	} {
+		if opcodeTable[tt.ir] == nil {
+			t.Errorf("0x%04X is not registered", tt.ir)
+		}
+		bus := &testBus{}
+		bus.Write32(uint32(tt.vec)*4, 0x3000)
+		writeWord(bus, 0x1000, tt.ir)
+		cpu := &CPU{bus: bus}
+		cpu.SetState(Registers{PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+		cpu.SetStrictUnimplemented(true)
+		cpu.Step()
+		if cpu.Halted() || cpu.PC() != 0x3000 {
+			t.Errorf("0x%04X: halted %v PC 0x%X, want vector %d taken", tt.ir, cpu.Halted(), cpu.PC(), tt.vec)
+		}
+	}
+}
+
+// FuzzStep runs arbitrary instruction words against arbitrary register and
+// memory contents on every variant. Whatever the input, stepping must only
+// execute, take exceptions or halt, never panic.
+func FuzzStep(f *testing.F) {
+	f.Add(uint16(0x4E71), []byte{}, uint16(0x2700), uint8(0))
+	f.Add(uint16(0x3030), []byte{0xFF, 0xFF}, uint16(0x0000), uint8(1))             // MOVE.W d8(A0,Xn),D0
+	f.Add(uint16(0x4CD8), []byte{0x7F, 0xFF}, uint16(0x2000), uint8(2))             // MOVEM.L (A0)+
+	f.Add(uint16(0xE9F0), []byte{0x10, 0x00, 0x01, 0x80}, uint16(0x2700), uint8(3)) // BFEXTU full-format index
+	f.Add(uint16(0x4C47), []byte{0x0C, 0x01}, uint16(0x2700), uint8(3))             // DIVS.L D7,D1:D0
+	f.Fuzz(func(t *testing.T, ir uint16, mem []byte, sr uint16, variant uint8) {
+		bus := &testBus{}
+		writeWord(bus, 0x1000, ir)
+		copy(bus.mem[0x1002:0x1100], mem)
+		cpu := &CPU{bus: bus, variant: Variant(variant % 4)}
+		var regs Registers
+		for i := range regs.D {
+			// Spread the memory bytes over the registers so they can reach
+			// odd, negative and out-of-range values.
+			regs.D[i] = uint32(i)<<28 | bus.Read32(0x1002+uint32(i)*4)
+			regs.A[i] = bus.Read32(0x1022 + uint32(i)*4)
+		}
+		regs.PC, regs.SR, regs.SSP, regs.USP = 0x1000, sr, 0x10000, 0x8000
+		cpu.SetState(regs)
+		for range 4 {
+			cpu.Step()
+		}
+	})
+}
+
+func TestLongAccessWraps(t *testing.T) {
+	bus := &testBus{}
+	writeWord(bus, 0x1000, 0x2039) // MOVE.L ($FFFFFE).L,D0
+	writeWord(bus, 0x1002, 0x00FF)
+	writeWord(bus, 0x1004, 0xFFFE)
+	writeWord(bus, 0x1006, 0x23C1) // MOVE.L D1,($FFFFFE).L
+	writeWord(bus, 0x1008, 0x00FF)
+	writeWord(bus, 0x100A, 0xFFFE)
+	writeWord(bus, 0xFFFFFE, 0x1234)
+	writeWord(bus, 0, 0x5678)
+	cpu := &CPU{bus: bus}
+	cpu.SetState(Registers{D: [8]uint32{1: 0x9ABCDEF0}, PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+	cpu.Step()
+	if d0 := cpu.D(0); d0 != 0x12345678 {
+		t.Errorf("D0 = 0x%08X, want 0x12345678", d0)
+	}
+	cpu.Step()
+	if hi, lo := bus.Read16(0xFFFFFE), bus.Read16(0); hi != 0x9ABC || lo != 0xDEF0 {
+		t.Errorf("memory = 0x%04X/0x%04X, want 0x9ABC/0xDEF0", hi, lo)
+	}
+}
+
+func TestInstructionFunc(t *testing.T) {
+	prog := []uint16{
+		0x7005, // MOVEQ #5,D0
+		0xC0C0, // MULU D0,D0
+		0x2080, // MOVE.L D0,(A0)
+		0x3011, // MOVE.W (A1),D0: odd A1, address error
+		0x4E71, // NOP
+		0x4AFC, // ILLEGAL
+	}
+	cpu, bus := progCPU(MC68000, Registers{A: [8]uint32{0x4000, 0x4001}, SR: 0x2700}, prog...)
+	writeWord(bus, 0x3000, 0x4EF8) // JMP ($1008).W
+	writeWord(bus, 0x3002, 0x1008)
+	writeWord(bus, 0x3100, 0x4E72) // STOP #$2000
+	writeWord(bus, 0x3102, 0x2000)
+	bus.Write32(vecAddressError*4, 0x3000)
+	bus.Write32(vecIllegalInstruction*4, 0x3100)
+
+	calls, sum := 0, 0
+	cpu.SetInstructionFunc(func(cycles int) {
+		calls++
+		sum += cycles
+	})
+	steps := 0
+	for cpu.Cycles() < 400 {
+		cpu.StepCycles(7)
+		steps++
+	}
+	if sum != int(cpu.Cycles()) {
+		t.Errorf("callback cycles sum to %d, want Cycles() = %d", sum, cpu.Cycles())
+	}
+	if calls == 0 || calls >= steps {
+		t.Errorf("callback ran %d times over %d StepCycles calls, want once per Step", calls, steps)
+	}
+	if !cpu.Stopped() {
+		t.Error("program did not reach STOP")
+	}
+
+	cpu.SetInstructionFunc(nil)
+	cpu.Step()
+	if sum != int(cpu.Cycles())-4 {
+		t.Error("callback ran after being removed")
+	}
+}
+
+func TestEffectiveAddress(t *testing.T) {
+	tests := []struct {
+		name      string
+		v         Variant
+		mode, reg uint8
+		sz        int
+		ext       []uint16
+	}{
+		{"(A1)", MC68000, 2, 1, 2, nil},
+		{"(A1)+", MC68000, 3, 1, 4, nil},
+		{"-(A1)", MC68000, 4, 1, 2, nil},
+		{"-(A7) byte", MC68000, 4, 7, 1, nil},
+		{"d16(A1)", MC68000, 5, 1, 2, []uint16{0xFFF0}},
+		{"d8(A1,D1.W)", MC68000, 6, 1, 2, []uint16{0x10FE}},
+		{"abs.W", MC68000, 7, 0, 2, []uint16{0x8000}},
+		{"abs.L", MC68000, 7, 1, 4, []uint16{0x0012, 0x3456}},
+		{"d16(PC)", MC68000, 7, 2, 2, []uint16{0x0100}},
+		{"d8(PC,A1.L)", MC68000, 7, 3, 2, []uint16{0x9804}},
+		{"(d8,A1,D1.L*4)", MC68020, 6, 1, 4, []uint16{0x1C10}},
+		{"([$5000.W],D1.L*2,8.W)", MC68020, 6, 1, 4, []uint16{0x1BA6, 0x5000, 0x0008}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			for i, w := range tt.ext {
+				writeWord(bus, 0x1002+uint32(i*2), w)
+			}
+			bus.Write32(0x5000, 0x6000) // pointer for memory indirection
+			cpu := &CPU{bus: bus, variant: tt.v}
+			regs := Registers{
+				PC: 0x1002, SR: 0x2700, SSP: 0x10000,
+				A: [8]uint32{1: 0x4000, 7: 0x10000}, D: [8]uint32{1: 0x10008},
+			}
+			cpu.SetState(regs)
+
+			got, ok := cpu.EffectiveAddress(tt.mode, tt.reg, tt.sz)
+			if !ok {
+				t.Fatal("ok = false, want true")
+			}
+			if r := cpu.Registers(); r.PC != regs.PC || r.A != regs.A {
+				t.Errorf("PC = 0x%X A = %X, want them unchanged", r.PC, r.A)
+			}
+			if n := cpu.Cycles(); n != 0 {
+				t.Errorf("cycles = %d, want 0", n)
+			}
+			if want := cpu.resolveEA(tt.mode, tt.reg, size(tt.sz)).address(); got != want {
+				t.Errorf("EffectiveAddress = 0x%X, resolveEA = 0x%X", got, want)
+			}
+		})
+	}
+
+	cpu := &CPU{bus: &testBus{}}
+	for _, f := range [][2]uint8{{0, 0}, {1, 0}, {7, 4}, {7, 5}, {8, 0}} {
+		if _, ok := cpu.EffectiveAddress(f[0], f[1], 2); ok {
+			t.Errorf("mode %d reg %d: ok = true, want false", f[0], f[1])
+		}
+	}
+}
+
+func TestBusHelpers(t *testing.T) {
+	bus := &testBus{}
+	WriteLong(bus, 0x1001, 0x12345678)
+	if got := bus.mem[0x1001:0x1005]; string(got) != "\x12\x34\x56\x78" {
+		t.Errorf("bytes = % X, want 12 34 56 78", got)
+	}
+	if got := ReadLong(bus, 0x1001); got != 0x12345678 {
+		t.Errorf("ReadLong = 0x%08X, want 0x12345678", got)
+	}
+	if got := ReadWord(bus, 0x1003); got != 0x5678 {
+		t.Errorf("ReadWord = 0x%04X, want 0x5678", got)
+	}
+
+	// High address bits are ignored and the top of memory wraps to 0
+	WriteWord(bus, 0xFF002000, 0xABCD)
+	if got := bus.mem[0x2000:0x2002]; string(got) != "\xAB\xCD" {
+		t.Errorf("bytes at 0x2000 = % X, want AB CD", got)
+	}
+	WriteLong(bus, 0xFFFFFE, 0x11223344)
+	if got := ReadWord(bus, 0); got != 0x3344 {
+		t.Errorf("word at 0 = 0x%04X, want 0x3344", got)
+	}
+	if got := ReadLong(bus, 0x01FFFFFE); got != 0x11223344 {
+		t.Errorf("ReadLong across the wrap = 0x%08X, want 0x11223344", got)
+	}
+}
+
+func TestStrictUnimplemented(t *testing.T) {
+	for op := 0; op < 0x10000; op++ {
+		if validMC68000(uint16(op)) && opcodeTable[op] == nil {
+			t.Errorf("%04X: valid MC68000 instruction is not implemented", op)
+		}
+	}
+
+	// Remove NOP from the table for the duration of the test
+	nop := opcodeTable[0x4E71]
+	opcodeTable[0x4E71] = nil
+	defer func() { opcodeTable[0x4E71] = nop }()
+
+	run := func(strict bool, op uint16) *CPU {
+		bus := &testBus{}
+		bus.Write32(vecIllegalInstruction*4, 0x3000)
+		writeWord(bus, 0x1000, op)
+		cpu := &CPU{bus: bus}
+		cpu.SetState(Registers{PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+		cpu.SetStrictUnimplemented(strict)
+		cpu.Step()
+		return cpu
+	}
+
+	cpu := run(false, 0x4E71)
+	if cpu.Halted() || cpu.Unimplemented() || cpu.PC() != 0x3000 {
+		t.Errorf("lenient: halted %v unimplemented %v PC 0x%X, want the illegal instruction handler",
+			cpu.Halted(), cpu.Unimplemented(), cpu.PC())
+	}
+
+	cpu = run(true, 0x4E71)
+	if !cpu.Halted() || !cpu.Unimplemented() || cpu.PrevPC() != 0x1000 {
+		t.Errorf("strict: halted %v unimplemented %v PrevPC 0x%X, want an unimplemented halt at 0x1000",
+			cpu.Halted(), cpu.Unimplemented(), cpu.PrevPC())
+	}
+	if n := cpu.Step(); n != 0 {
+		t.Errorf("strict: Step after the halt = %d, want 0", n)
+	}
+	cpu.ResetTo(0x10000, 0x1000)
+	if cpu.Halted() || cpu.Unimplemented() {
+		t.Error("strict: still halted after reset")
+	}
+
+	// ILLEGAL is not an instruction, so strict mode still takes the exception
+	cpu = run(true, 0x4AFC)
+	if cpu.Halted() || cpu.Unimplemented() || cpu.PC() != 0x3000 {
+		t.Errorf("ILLEGAL: halted %v unimplemented %v PC 0x%X, want the illegal instruction handler",
+			cpu.Halted(), cpu.Unimplemented(), cpu.PC())
+	}
+}
+
+func TestStackMisalignedFunc(t *testing.T) {
+	prog := []uint16{
+		0x2E7C, 0x0000, 0x7FFF, // MOVEA.L #$7FFF,A7
+		0x4E71, // NOP
+		0x528F, // ADDQ.L #1,A7
+		0x538F, // SUBQ.L #1,A7
+	}
+	cpu, _ := progCPU(MC68000, Registers{SR: 0x2700}, prog...)
+	var got []uint32
+	cpu.SetStackMisalignedFunc(func(sp uint32) { got = append(got, sp) })
+
+	want := [][]uint32{{0x7FFF}, {0x7FFF}, {0x7FFF}, {0x7FFF, 0x7FFF}}
+	for i, w := range want {
+		cpu.Step()
+		if !slices.Equal(got, w) {
+			t.Fatalf("after instruction %d: calls = %X, want %X", i+1, got, w)
+		}
+	}
+}
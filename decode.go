@@ -7,3 +7,205 @@ type opFunc func(*CPU)
 // opcodeTable is a 64K-entry lookup table indexed by the first instruction word.
 // nil entries are treated as illegal instructions.
 var opcodeTable [65536]opFunc
+
+// IsImplemented reports whether ir is the first word of an instruction in
+// the opcode table. Other words take an illegal instruction, Line A or
+// Line F exception. Instructions added by a later variant are included and
+// still take an illegal instruction exception on earlier ones. The words
+// for which IsIllegal reports true are registered too, but are not
+// instructions and are excluded.
+func IsImplemented(ir uint16) bool {
+	return opcodeTable[ir] != nil && !IsIllegal(ir)
+}
+
+// firstVariant returns the earliest variant on which ir is an instruction:
+// MC68010 for MOVEC, MOVES, RTD and MOVE from CCR, MC68020 for the bit
+// field instructions, CAS, CHK2/CMP2 and the long multiply and divide, and
+// MC68000 for the rest. Earlier variants take an illegal instruction
+// exception on these words. ir must be one IsImplemented reports true for.
+func firstVariant(ir uint16) Variant {
+	switch {
+	case ir == 0x4E7A || ir == 0x4E7B || ir == 0x4E74 || ir&0xFFC0 == 0x42C0 ||
+		ir&0xFF00 == 0x0E00 && ir&0x00C0 != 0x00C0:
+		return MC68010
+	case ir&0xF8C0 == 0xE8C0 || ir&0xF9C0 == 0x08C0 && ir&0x0600 != 0 ||
+		ir&0xF9C0 == 0x00C0 && ir&0x0600 != 0x0600 || ir&0xFF80 == 0x4C00:
+		return MC68020
+	}
+	return MC68000
+}
+
+// IsIllegal reports whether ir is architecturally illegal: ILLEGAL ($4AFC),
+// which every variant reserves to take an illegal instruction exception,
+// or a word of the Line A ($Axxx) or Line F ($Fxxx) ranges. A word for
+// which neither IsIllegal nor IsImplemented reports true is unassigned.
+func IsIllegal(ir uint16) bool {
+	return ir == 0x4AFC || ir>>12 == 0xA || ir>>12 == 0xF
+}
+
+// ImplementedCount returns the number of first instruction words for which
+// IsImplemented reports true.
+func ImplementedCount() int {
+	n := 0
+	for ir := range opcodeTable {
+		if IsImplemented(uint16(ir)) {
+			n++
+		}
+	}
+	return n
+}
+
+// validMC68000 reports whether ir is the first word of an instruction in
+// the MC68000 instruction set, decided from the encoding alone rather
+// than from opcodeTable: the operation and size fields name an
+// instruction and its addressing modes are ones that instruction accepts.
+// Line A, Line F and ILLEGAL ($4AFC) are not instructions.
+func validMC68000(ir uint16) bool {
+	mode, reg := ir>>3&7, ir&7
+	opmode := ir >> 6 & 7
+	sz := ir >> 6 & 3
+
+	switch ir >> 12 {
+	case 0x0:
+		if ir&0x0100 != 0 {
+			// Dynamic bit operations, or MOVEP for An
+			if mode == 1 {
+				return true
+			}
+			return eaData(mode, reg) && (sz == 0 || eaAlterable(mode, reg))
+		}
+		switch ir >> 9 & 7 {
+		case 4: // Static bit operations
+			if sz == 0 {
+				return eaData(mode, reg) && !(mode == 7 && reg == 4)
+			}
+			return eaDataAlterable(mode, reg)
+		case 7:
+			return false
+		}
+		if mode == 7 && reg == 4 && sz < 2 {
+			// ORI, ANDI and EORI to CCR or SR
+			op := ir >> 9 & 7
+			return op == 0 || op == 1 || op == 5
+		}
+		return sz != 3 && eaDataAlterable(mode, reg)
+
+	case 0x1, 0x2, 0x3: // MOVE, MOVEA
+		dstMode, dstReg := opmode, ir>>9&7
+		if !eaValid(mode, reg) || ir>>12 == 1 && (mode == 1 || dstMode == 1) {
+			return false
+		}
+		return dstMode == 1 || eaDataAlterable(dstMode, dstReg)
+
+	case 0x4:
+		return validMC68000Misc(ir, mode, reg)
+
+	case 0x5:
+		if sz == 3 { // DBcc, Scc
+			return mode == 1 || eaDataAlterable(mode, reg)
+		}
+		// ADDQ, SUBQ
+		return eaAlterable(mode, reg) && !(sz == 0 && mode == 1)
+
+	case 0x6: // Bcc, BSR, BRA
+		return true
+
+	case 0x7: // MOVEQ
+		return ir&0x0100 == 0
+
+	case 0x8, 0xC:
+		switch {
+		case opmode == 3 || opmode == 7: // DIVU, DIVS, MULU, MULS
+			return eaData(mode, reg)
+		case opmode < 3: // OR, AND <ea>,Dn
+			return eaData(mode, reg)
+		case mode > 1: // OR, AND Dn,<ea>
+			return eaMemAlterable(mode, reg)
+		case opmode == 4: // SBCD, ABCD
+			return true
+		}
+		// EXG Dx,Dy; Ax,Ay; Dx,Ay
+		return ir>>12 == 0xC && (opmode == 5 || opmode == 6 && mode == 1)
+
+	case 0x9, 0xB, 0xD:
+		switch {
+		case opmode == 3 || opmode == 7: // SUBA, CMPA, ADDA
+			return eaValid(mode, reg)
+		case opmode < 3: // SUB, CMP, ADD <ea>,Dn
+			return eaValid(mode, reg) && !(opmode == 0 && mode == 1)
+		case ir>>12 == 0xB: // CMPM, EOR
+			return mode == 1 || eaDataAlterable(mode, reg)
+		}
+		// SUBX, ADDX, or SUB, ADD Dn,<ea>
+		return mode < 2 || eaMemAlterable(mode, reg)
+
+	case 0xE:
+		if sz == 3 { // Memory shifts by one
+			return ir&0x0800 == 0 && eaMemAlterable(mode, reg)
+		}
+		return true
+	}
+	return false
+}
+
+// validMC68000Misc is validMC68000 for line 4.
+func validMC68000Misc(ir, mode, reg uint16) bool {
+	switch {
+	case ir&0x01C0 == 0x01C0: // LEA
+		return eaControl(mode, reg)
+	case ir&0x01C0 == 0x0180: // CHK.W
+		return eaData(mode, reg)
+	case ir&0x0100 != 0:
+		return false
+	}
+
+	switch ir & 0xFFC0 {
+	case 0x40C0: // MOVE from SR
+		return eaDataAlterable(mode, reg)
+	case 0x44C0, 0x46C0: // MOVE to CCR, SR
+		return eaData(mode, reg)
+	case 0x42C0, 0x4C00, 0x4C40:
+		return false
+	case 0x4800: // NBCD
+		return eaDataAlterable(mode, reg)
+	case 0x4840: // SWAP, PEA
+		return mode == 0 || eaControl(mode, reg)
+	case 0x4880, 0x48C0: // EXT, MOVEM to memory
+		return mode == 0 || mode == 4 || eaControlAlterable(mode, reg)
+	case 0x4AC0: // TAS
+		return eaDataAlterable(mode, reg)
+	case 0x4C80, 0x4CC0: // MOVEM to registers
+		return mode == 3 || eaControl(mode, reg)
+	case 0x4E40: // TRAP, LINK, UNLK, MOVE USP, and RESET to RTR except RTD
+		return ir <= 0x4E77 && ir != 0x4E74
+	case 0x4E80, 0x4EC0: // JSR, JMP
+		return eaControl(mode, reg)
+	}
+
+	// NEGX, CLR, NEG, NOT, TST
+	switch ir & 0xFF00 {
+	case 0x4000, 0x4200, 0x4400, 0x4600, 0x4A00:
+		return eaDataAlterable(mode, reg)
+	}
+	return false
+}
+
+// Addressing mode categories for validMC68000.
+
+func eaValid(mode, reg uint16) bool { return mode < 7 || reg < 5 }
+
+func eaData(mode, reg uint16) bool { return mode != 1 && eaValid(mode, reg) }
+
+func eaAlterable(mode, reg uint16) bool { return mode < 7 || reg < 2 }
+
+func eaDataAlterable(mode, reg uint16) bool { return mode != 1 && eaAlterable(mode, reg) }
+
+func eaMemAlterable(mode, reg uint16) bool { return mode > 1 && eaAlterable(mode, reg) }
+
+func eaControl(mode, reg uint16) bool {
+	return mode == 2 || mode == 5 || mode == 6 || mode == 7 && reg < 4
+}
+
+func eaControlAlterable(mode, reg uint16) bool {
+	return eaControl(mode, reg) && eaAlterable(mode, reg)
+}
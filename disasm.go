@@ -0,0 +1,698 @@
+package m68k
+
+import (
+	"fmt"
+	"strings"
+)
+
+// condNames holds the mnemonic suffix for each condition code (0-15).
+var condNames = [16]string{
+	"T", "F", "HI", "LS", "CC", "CS", "NE", "EQ",
+	"VC", "VS", "PL", "MI", "GE", "LT", "GT", "LE",
+}
+
+// shiftNames holds the shift/rotate mnemonic stems indexed by type bits.
+var shiftNames = [4]string{"AS", "LS", "ROX", "RO"}
+
+// bitfieldNames holds the 68020 bit field mnemonics indexed by bits 10-8.
+var bitfieldNames = [8]string{
+	"BFTST", "BFEXTU", "BFCHG", "BFEXTS", "BFCLR", "BFFFO", "BFSET", "BFINS",
+}
+
+// Disassemble decodes the instruction at addr and returns it in Motorola
+// syntax (e.g. "MOVE.W D0,(A1)") together with its length in bytes,
+// including extension words. Memory is read directly from the bus without
+// advancing the cycle counter or raising bus or address errors. Opcodes
+// that the CPU would reject as illegal, including the Line-A and Line-F
+// ranges and instructions added by a later variant than the CPU's, are
+// returned as "DC.W $xxxx" with a length of 2.
+//
+// Immediates, absolute addresses and branch targets are printed in hex;
+// displacements are signed hex relative to their base register. On the
+// MC68020 indexed operands show their scale factor, and full format
+// extension words are decoded as ([bd,An,Xn],od) and its variants.
+func (c *CPU) Disassemble(addr uint32) (string, int) {
+	d := disassembler{bus: c.bus, pc: addr, variant: c.variant}
+	text := d.decode()
+	return text, int(d.pc - addr)
+}
+
+// InstructionLength returns the length in bytes of the instruction at
+// addr, including every extension word, without executing it. It decodes
+// the instruction as Disassemble does, so an opcode the CPU would reject
+// has a length of 2.
+func (c *CPU) InstructionLength(addr uint32) int {
+	_, n := c.Disassemble(addr)
+	return n
+}
+
+// disassembler walks one instruction's words starting at pc, decoding the
+// instruction set of variant, which on the MC68020 includes the full
+// decoding of indexed extension words.
+type disassembler struct {
+	bus     Bus
+	pc      uint32
+	variant Variant
+}
+
+// word reads the next instruction word.
+func (d *disassembler) word() uint16 {
+	v := d.bus.Read16(d.pc & 0xFFFFFF)
+	d.pc += 2
+	return v
+}
+
+// at returns the address of the next instruction word.
+func (d *disassembler) at() uint32 {
+	return d.pc
+}
+
+// long reads the next two instruction words as a long.
+func (d *disassembler) long() uint32 {
+	hi := d.word()
+	lo := d.word()
+	return uint32(hi)<<16 | uint32(lo)
+}
+
+// pointer reads the long at addr, for EffectiveAddress's memory indirect
+// modes, without moving pc.
+func (d *disassembler) pointer(addr uint32) uint32 {
+	return uint32(d.bus.Read16(addr&0xFFFFFF))<<16 | uint32(d.bus.Read16((addr+2)&0xFFFFFF))
+}
+
+// imm reads an immediate operand of the given size.
+func (d *disassembler) imm(sz size) string {
+	if sz == sizeLong {
+		return fmt.Sprintf("#$%X", d.long())
+	}
+	return fmt.Sprintf("#$%X", uint32(d.word())&sz.Mask())
+}
+
+// ea formats the effective address for a mode/register pair, consuming
+// its extension words.
+func (d *disassembler) ea(mode, reg uint16, sz size) string {
+	switch mode {
+	case 0:
+		return fmt.Sprintf("D%d", reg)
+	case 1:
+		return fmt.Sprintf("A%d", reg)
+	case 2:
+		return fmt.Sprintf("(A%d)", reg)
+	case 3:
+		return fmt.Sprintf("(A%d)+", reg)
+	case 4:
+		return fmt.Sprintf("-(A%d)", reg)
+	case 5:
+		return fmt.Sprintf("%s(A%d)", signedHex(int32(int16(d.word()))), reg)
+	case 6:
+		return d.index(fmt.Sprintf("A%d", reg))
+	case 7:
+		switch reg {
+		case 0:
+			return fmt.Sprintf("($%X).W", d.word())
+		case 1:
+			return fmt.Sprintf("($%X).L", d.long())
+		case 2:
+			return fmt.Sprintf("%s(PC)", signedHex(int32(int16(d.word()))))
+		case 3:
+			return d.index("PC")
+		case 4:
+			return d.imm(sz)
+		}
+	}
+	return "?"
+}
+
+// index formats a brief extension word indexed operand d8(base,Xn.s),
+// or for the MC68020 a full format one.
+func (d *disassembler) index(base string) string {
+	ext := d.word()
+	if d.variant >= MC68020 && ext&0x0100 != 0 {
+		return d.fullIndex(base, ext)
+	}
+	return fmt.Sprintf("%s(%s,%s)", signedHex(int32(int8(ext))), base, d.indexReg(ext))
+}
+
+// indexReg formats the index register of an extension word as Xn.s, with
+// the scale factor when it is not 1 on the MC68020.
+func (d *disassembler) indexReg(ext uint16) string {
+	xn := "D"
+	if ext&0x8000 != 0 {
+		xn = "A"
+	}
+	xs := "W"
+	if ext&0x0800 != 0 {
+		xs = "L"
+	}
+	text := fmt.Sprintf("%s%d.%s", xn, (ext>>12)&7, xs)
+	if scale := (ext >> 9) & 3; d.variant >= MC68020 && scale != 0 {
+		text += fmt.Sprintf("*%d", 1<<scale)
+	}
+	return text
+}
+
+// fullIndex formats a full format extension word, consuming its base and
+// outer displacements the way calcFullIndex does: (bd,base,Xn) without
+// memory indirection, ([bd,base,Xn],od) preindexed and ([bd,base],Xn,od)
+// postindexed. Suppressed and null parts are left out.
+func (d *disassembler) fullIndex(base string, ext uint16) string {
+	if ext&0x0080 != 0 {
+		base = ""
+	}
+	var xn string
+	if ext&0x0040 == 0 {
+		xn = d.indexReg(ext)
+	}
+	bd := d.fullDisp(ext >> 4)
+
+	iis := ext & 7
+	if ext&0x0040 != 0 && iis > 3 {
+		iis = 0
+	}
+	switch {
+	case iis == 0 || iis == 4:
+		return "(" + joinOperands(bd, base, xn) + ")"
+	case iis < 4:
+		return "([" + joinOperands(bd, base, xn) + "]" + prefixComma(d.fullDisp(iis)) + ")"
+	default:
+		return "([" + joinOperands(bd, base) + "]" + prefixComma(xn) + prefixComma(d.fullDisp(iis)) + ")"
+	}
+}
+
+// fullDisp formats a full format displacement whose size is given by the
+// low two bits of field, or "" for a null one.
+func (d *disassembler) fullDisp(field uint16) string {
+	switch field & 3 {
+	case 2:
+		return signedHex(int32(int16(d.word())))
+	case 3:
+		return signedHex(int32(d.long()))
+	}
+	return ""
+}
+
+// joinOperands joins the non-empty parts with commas.
+func joinOperands(parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, ",")
+}
+
+// prefixComma returns s preceded by a comma, or "" if s is empty.
+func prefixComma(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "," + s
+}
+
+// target formats a branch destination relative to base.
+func target(base uint32, disp int32) string {
+	return fmt.Sprintf("$%X", (uint32(int32(base)+disp))&0xFFFFFF)
+}
+
+// signedHex formats a displacement as signed hex.
+func signedHex(v int32) string {
+	if v < 0 {
+		return fmt.Sprintf("-$%X", -int64(v))
+	}
+	return fmt.Sprintf("$%X", v)
+}
+
+// sizeSuffix returns the mnemonic size suffix.
+func sizeSuffix(sz size) string {
+	switch sz {
+	case sizeByte:
+		return ".B"
+	case sizeWord:
+		return ".W"
+	case sizeLong:
+		return ".L"
+	}
+	return ""
+}
+
+// regList formats a MOVEM register mask. In predecrement mode the mask is
+// reversed (bit 0 = A7).
+func regList(mask uint16, predec bool) string {
+	if predec {
+		var r uint16
+		for i := 0; i < 16; i++ {
+			if mask&(1<<i) != 0 {
+				r |= 1 << (15 - i)
+			}
+		}
+		mask = r
+	}
+	var parts []string
+	for _, bank := range []struct {
+		name  string
+		shift uint
+	}{{"D", 0}, {"A", 8}} {
+		bits := (mask >> bank.shift) & 0xFF
+		for i := 0; i < 8; i++ {
+			if bits&(1<<i) == 0 {
+				continue
+			}
+			j := i
+			for j < 7 && bits&(1<<(j+1)) != 0 {
+				j++
+			}
+			if j == i {
+				parts = append(parts, fmt.Sprintf("%s%d", bank.name, i))
+			} else {
+				parts = append(parts, fmt.Sprintf("%s%d-%s%d", bank.name, i, bank.name, j))
+			}
+			i = j
+		}
+	}
+	if len(parts) == 0 {
+		return "#0"
+	}
+	return strings.Join(parts, "/")
+}
+
+// decode disassembles the instruction starting at d.pc.
+func (d *disassembler) decode() string {
+	start := d.pc
+	op := d.word()
+
+	if op == 0x4AFC {
+		return "ILLEGAL"
+	}
+	if !IsImplemented(op) || firstVariant(op) > d.variant {
+		return fmt.Sprintf("DC.W $%04X", op)
+	}
+
+	mode := (op >> 3) & 7
+	reg := op & 7
+	rx := (op >> 9) & 7
+
+	switch op >> 12 {
+	case 0x0:
+		return d.decodeImmBit(op, mode, reg, rx)
+
+	case 0x1, 0x2, 0x3:
+		sz := moveSizeMap[(op>>12)&3]
+		src := d.ea(mode, reg, sz)
+		dstMode := (op >> 6) & 7
+		if dstMode == 1 {
+			return fmt.Sprintf("MOVEA%s %s,A%d", sizeSuffix(sz), src, rx)
+		}
+		return fmt.Sprintf("MOVE%s %s,%s", sizeSuffix(sz), src, d.ea(dstMode, rx, sz))
+
+	case 0x4:
+		return d.decodeMisc(op, mode, reg, rx)
+
+	case 0x5:
+		cc := (op >> 8) & 0xF
+		if op&0x00F8 == 0x00C8 {
+			disp := int32(int16(d.word()))
+			return fmt.Sprintf("DB%s D%d,%s", condNames[cc], reg, target(start+2, disp))
+		}
+		if op&0x00C0 == 0x00C0 {
+			return fmt.Sprintf("S%s %s", condNames[cc], d.ea(mode, reg, sizeByte))
+		}
+		data := rx
+		if data == 0 {
+			data = 8
+		}
+		sz := sizeEncoding((op >> 6) & 3)
+		name := "ADDQ"
+		if op&0x0100 != 0 {
+			name = "SUBQ"
+		}
+		return fmt.Sprintf("%s%s #%d,%s", name, sizeSuffix(sz), data, d.ea(mode, reg, sz))
+
+	case 0x6:
+		cc := (op >> 8) & 0xF
+		disp := int32(int8(op))
+		suffix := ".S"
+		if disp == 0 {
+			disp = int32(int16(d.word()))
+			suffix = ".W"
+		}
+		name := "B" + condNames[cc]
+		switch cc {
+		case 0:
+			name = "BRA"
+		case 1:
+			name = "BSR"
+		}
+		return fmt.Sprintf("%s%s %s", name, suffix, target(start+2, disp))
+
+	case 0x7:
+		return fmt.Sprintf("MOVEQ #%d,D%d", int8(op), rx)
+
+	case 0x8:
+		switch {
+		case op&0x01F0 == 0x0100:
+			return d.decodeX("SBCD", op, sizeByte)
+		case op&0x01C0 == 0x00C0:
+			return fmt.Sprintf("DIVU.W %s,D%d", d.ea(mode, reg, sizeWord), rx)
+		case op&0x01C0 == 0x01C0:
+			return fmt.Sprintf("DIVS.W %s,D%d", d.ea(mode, reg, sizeWord), rx)
+		}
+		return d.decodeALU("OR", op, mode, reg, rx)
+
+	case 0x9, 0xD:
+		name := "SUB"
+		if op>>12 == 0xD {
+			name = "ADD"
+		}
+		switch {
+		case op&0x00C0 == 0x00C0:
+			sz := sizeWord
+			if op&0x0100 != 0 {
+				sz = sizeLong
+			}
+			return fmt.Sprintf("%sA%s %s,A%d", name, sizeSuffix(sz), d.ea(mode, reg, sz), rx)
+		case op&0x0130 == 0x0100:
+			return d.decodeX(name+"X", op, sizeEncoding((op>>6)&3))
+		}
+		return d.decodeALU(name, op, mode, reg, rx)
+
+	case 0xB:
+		sz := sizeEncoding((op >> 6) & 3)
+		switch {
+		case op&0x00C0 == 0x00C0:
+			sz = sizeWord
+			if op&0x0100 != 0 {
+				sz = sizeLong
+			}
+			return fmt.Sprintf("CMPA%s %s,A%d", sizeSuffix(sz), d.ea(mode, reg, sz), rx)
+		case op&0x0138 == 0x0108:
+			return fmt.Sprintf("CMPM%s (A%d)+,(A%d)+", sizeSuffix(sz), reg, rx)
+		case op&0x0100 != 0:
+			return fmt.Sprintf("EOR%s D%d,%s", sizeSuffix(sz), rx, d.ea(mode, reg, sz))
+		}
+		return fmt.Sprintf("CMP%s %s,D%d", sizeSuffix(sz), d.ea(mode, reg, sz), rx)
+
+	case 0xC:
+		switch {
+		case op&0x01F0 == 0x0100:
+			return d.decodeX("ABCD", op, sizeByte)
+		case op&0x01C0 == 0x00C0:
+			return fmt.Sprintf("MULU.W %s,D%d", d.ea(mode, reg, sizeWord), rx)
+		case op&0x01C0 == 0x01C0:
+			return fmt.Sprintf("MULS.W %s,D%d", d.ea(mode, reg, sizeWord), rx)
+		case op&0x01F8 == 0x0140:
+			return fmt.Sprintf("EXG D%d,D%d", rx, reg)
+		case op&0x01F8 == 0x0148:
+			return fmt.Sprintf("EXG A%d,A%d", rx, reg)
+		case op&0x01F8 == 0x0188:
+			return fmt.Sprintf("EXG D%d,A%d", rx, reg)
+		}
+		return d.decodeALU("AND", op, mode, reg, rx)
+
+	case 0xE:
+		if op&0x08C0 == 0x08C0 {
+			return d.decodeBitfield(op, mode, reg)
+		}
+		dir := "R"
+		if op&0x0100 != 0 {
+			dir = "L"
+		}
+		if op&0x00C0 == 0x00C0 {
+			name := shiftNames[(op>>9)&3]
+			return fmt.Sprintf("%s%s.W %s", name, dir, d.ea(mode, reg, sizeWord))
+		}
+		name := shiftNames[(op>>3)&3]
+		sz := sizeEncoding((op >> 6) & 3)
+		if op&0x0020 != 0 {
+			return fmt.Sprintf("%s%s%s D%d,D%d", name, dir, sizeSuffix(sz), rx, reg)
+		}
+		count := rx
+		if count == 0 {
+			count = 8
+		}
+		return fmt.Sprintf("%s%s%s #%d,D%d", name, dir, sizeSuffix(sz), count, reg)
+	}
+
+	return fmt.Sprintf("DC.W $%04X", op)
+}
+
+// decodeBitfield decodes the 68020 bit field instructions, written
+// <ea>{offset:width} with the data register before or after as the
+// instruction requires.
+func (d *disassembler) decodeBitfield(op, mode, reg uint16) string {
+	kind := (op >> 8) & 7
+	ext := d.word()
+	offset := fmt.Sprint((ext >> 6) & 31)
+	if ext&0x0800 != 0 {
+		offset = fmt.Sprintf("D%d", (ext>>6)&7)
+	}
+	width := fmt.Sprint(ext & 31)
+	if ext&0x0020 != 0 {
+		width = fmt.Sprintf("D%d", ext&7)
+	} else if ext&31 == 0 {
+		width = "32"
+	}
+	field := fmt.Sprintf("%s{%s:%s}", d.ea(mode, reg, sizeByte), offset, width)
+	dn := (ext >> 12) & 7
+	switch kind {
+	case bfEXTU, bfEXTS, bfFFO:
+		return fmt.Sprintf("%s %s,D%d", bitfieldNames[kind], field, dn)
+	case bfINS:
+		return fmt.Sprintf("%s D%d,%s", bitfieldNames[kind], dn, field)
+	}
+	return fmt.Sprintf("%s %s", bitfieldNames[kind], field)
+}
+
+// decodeImmBit decodes line 0: immediate arithmetic/logic, the CCR/SR
+// immediate forms, bit operations, MOVEP and CAS.
+func (d *disassembler) decodeImmBit(op, mode, reg, rx uint16) string {
+	bitNames := [4]string{"BTST", "BCHG", "BCLR", "BSET"}
+
+	switch {
+	case op&0x0138 == 0x0108:
+		disp := signedHex(int32(int16(d.word())))
+		sz := sizeWord
+		if op&0x0040 != 0 {
+			sz = sizeLong
+		}
+		if op&0x0080 != 0 {
+			return fmt.Sprintf("MOVEP%s D%d,%s(A%d)", sizeSuffix(sz), rx, disp, reg)
+		}
+		return fmt.Sprintf("MOVEP%s %s(A%d),D%d", sizeSuffix(sz), disp, reg, rx)
+	case op&0x0100 != 0:
+		return fmt.Sprintf("%s D%d,%s", bitNames[(op>>6)&3], rx, d.ea(mode, reg, sizeByte))
+	case op&0x09C0 == 0x00C0 && op&0x0600 != 0x0600:
+		sz := sizeEncoding((op >> 9) & 3)
+		ext := d.word()
+		name := "CMP2"
+		if ext&0x0800 != 0 {
+			name = "CHK2"
+		}
+		rn := fmt.Sprintf("D%d", (ext>>12)&7)
+		if ext&0x8000 != 0 {
+			rn = fmt.Sprintf("A%d", (ext>>12)&7)
+		}
+		return fmt.Sprintf("%s%s %s,%s", name, sizeSuffix(sz), d.ea(mode, reg, sz), rn)
+	case op&0x09C0 == 0x08C0 && op&0x0600 != 0:
+		sz := [4]size{0, sizeByte, sizeWord, sizeLong}[(op>>9)&3]
+		ext := d.word()
+		return fmt.Sprintf("CAS%s D%d,D%d,%s", sizeSuffix(sz), ext&7, (ext>>6)&7, d.ea(mode, reg, sz))
+	case op&0x0F00 == 0x0E00:
+		sz := sizeEncoding((op >> 6) & 3)
+		ext := d.word()
+		rn := fmt.Sprintf("D%d", (ext>>12)&7)
+		if ext&0x8000 != 0 {
+			rn = fmt.Sprintf("A%d", (ext>>12)&7)
+		}
+		if ext&0x0800 != 0 {
+			return fmt.Sprintf("MOVES%s %s,%s", sizeSuffix(sz), rn, d.ea(mode, reg, sz))
+		}
+		return fmt.Sprintf("MOVES%s %s,%s", sizeSuffix(sz), d.ea(mode, reg, sz), rn)
+	case op&0x0F00 == 0x0800:
+		bit := d.word() & 0xFF
+		return fmt.Sprintf("%s #%d,%s", bitNames[(op>>6)&3], bit, d.ea(mode, reg, sizeByte))
+	}
+
+	names := [8]string{"ORI", "ANDI", "SUBI", "ADDI", "", "EORI", "CMPI", ""}
+	name := names[(op>>9)&7]
+	switch op & 0x00FF {
+	case 0x003C:
+		return fmt.Sprintf("%s %s,CCR", name, d.imm(sizeByte))
+	case 0x007C:
+		return fmt.Sprintf("%s %s,SR", name, d.imm(sizeWord))
+	}
+	sz := sizeEncoding((op >> 6) & 3)
+	src := d.imm(sz)
+	return fmt.Sprintf("%s%s %s,%s", name, sizeSuffix(sz), src, d.ea(mode, reg, sz))
+}
+
+// decodeMisc decodes line 4: the miscellaneous single-operand, control and
+// system instructions.
+func (d *disassembler) decodeMisc(op, mode, reg, rx uint16) string {
+	switch op {
+	case 0x4E70:
+		return "RESET"
+	case 0x4E71:
+		return "NOP"
+	case 0x4E72:
+		return fmt.Sprintf("STOP %s", d.imm(sizeWord))
+	case 0x4E73:
+		return "RTE"
+	case 0x4E74:
+		return fmt.Sprintf("RTD #%s", signedHex(int32(int16(d.word()))))
+	case 0x4E75:
+		return "RTS"
+	case 0x4E76:
+		return "TRAPV"
+	case 0x4E77:
+		return "RTR"
+	case 0x4E7A, 0x4E7B:
+		ext := d.word()
+		rn := fmt.Sprintf("D%d", (ext>>12)&7)
+		if ext&0x8000 != 0 {
+			rn = fmt.Sprintf("A%d", (ext>>12)&7)
+		}
+		var rc string
+		switch ext & 0x0FFF {
+		case ctrlSFC:
+			rc = "SFC"
+		case ctrlDFC:
+			rc = "DFC"
+		case ctrlUSP:
+			rc = "USP"
+		case ctrlVBR:
+			rc = "VBR"
+		default:
+			rc = fmt.Sprintf("$%03X", ext&0x0FFF)
+		}
+		if op&1 == 0 {
+			return fmt.Sprintf("MOVEC %s,%s", rc, rn)
+		}
+		return fmt.Sprintf("MOVEC %s,%s", rn, rc)
+	}
+
+	switch op & 0xFFF8 {
+	case 0x4840:
+		return fmt.Sprintf("SWAP D%d", reg)
+	case 0x4880:
+		return fmt.Sprintf("EXT.W D%d", reg)
+	case 0x48C0:
+		return fmt.Sprintf("EXT.L D%d", reg)
+	case 0x4E50:
+		return fmt.Sprintf("LINK A%d,#%s", reg, signedHex(int32(int16(d.word()))))
+	case 0x4E58:
+		return fmt.Sprintf("UNLK A%d", reg)
+	case 0x4E60:
+		return fmt.Sprintf("MOVE A%d,USP", reg)
+	case 0x4E68:
+		return fmt.Sprintf("MOVE USP,A%d", reg)
+	}
+
+	if op&0xFFF0 == 0x4E40 {
+		return fmt.Sprintf("TRAP #%d", op&0xF)
+	}
+
+	switch op & 0xFFC0 {
+	case 0x40C0:
+		return fmt.Sprintf("MOVE SR,%s", d.ea(mode, reg, sizeWord))
+	case 0x42C0:
+		return fmt.Sprintf("MOVE CCR,%s", d.ea(mode, reg, sizeWord))
+	case 0x44C0:
+		return fmt.Sprintf("MOVE %s,CCR", d.ea(mode, reg, sizeWord))
+	case 0x46C0:
+		return fmt.Sprintf("MOVE %s,SR", d.ea(mode, reg, sizeWord))
+	case 0x4800:
+		return fmt.Sprintf("NBCD %s", d.ea(mode, reg, sizeByte))
+	case 0x4840:
+		return fmt.Sprintf("PEA %s", d.ea(mode, reg, sizeLong))
+	case 0x4AC0:
+		return fmt.Sprintf("TAS %s", d.ea(mode, reg, sizeByte))
+	case 0x4E80:
+		return fmt.Sprintf("JSR %s", d.ea(mode, reg, sizeLong))
+	case 0x4EC0:
+		return fmt.Sprintf("JMP %s", d.ea(mode, reg, sizeLong))
+	}
+
+	if op&0xFF80 == 0x4C00 {
+		ext := d.word()
+		src := d.ea(mode, reg, sizeLong)
+		dl, dh := (ext>>12)&7, ext&7
+		sign := "U"
+		if ext&0x0800 != 0 {
+			sign = "S"
+		}
+		name := "MUL" + sign
+		if op&0x0040 != 0 {
+			name = "DIV" + sign
+			if ext&0x0400 == 0 && dh != dl {
+				name += "L"
+			}
+		}
+		if ext&0x0400 != 0 || name[len(name)-1] == 'L' {
+			return fmt.Sprintf("%s.L %s,D%d:D%d", name, src, dh, dl)
+		}
+		return fmt.Sprintf("%s.L %s,D%d", name, src, dl)
+	}
+
+	if op&0xFB80 == 0x4880 {
+		sz := sizeWord
+		if op&0x0040 != 0 {
+			sz = sizeLong
+		}
+		mask := d.word()
+		if op&0x0400 != 0 {
+			return fmt.Sprintf("MOVEM%s %s,%s", sizeSuffix(sz), d.ea(mode, reg, sz), regList(mask, false))
+		}
+		return fmt.Sprintf("MOVEM%s %s,%s", sizeSuffix(sz), regList(mask, mode == 4), d.ea(mode, reg, sz))
+	}
+
+	switch op & 0x01C0 {
+	case 0x01C0:
+		return fmt.Sprintf("LEA %s,A%d", d.ea(mode, reg, sizeLong), rx)
+	case 0x0180:
+		return fmt.Sprintf("CHK.W %s,D%d", d.ea(mode, reg, sizeWord), rx)
+	}
+
+	var name string
+	switch op & 0xFF00 {
+	case 0x4000:
+		name = "NEGX"
+	case 0x4200:
+		name = "CLR"
+	case 0x4400:
+		name = "NEG"
+	case 0x4600:
+		name = "NOT"
+	case 0x4A00:
+		name = "TST"
+	default:
+		return fmt.Sprintf("DC.W $%04X", op)
+	}
+	sz := sizeEncoding((op >> 6) & 3)
+	return fmt.Sprintf("%s%s %s", name, sizeSuffix(sz), d.ea(mode, reg, sz))
+}
+
+// decodeALU decodes the two-operand <ea>,Dn / Dn,<ea> forms of ADD, SUB,
+// AND and OR, selected by the direction bit 8.
+func (d *disassembler) decodeALU(name string, op, mode, reg, rx uint16) string {
+	sz := sizeEncoding((op >> 6) & 3)
+	if op&0x0100 != 0 {
+		return fmt.Sprintf("%s%s D%d,%s", name, sizeSuffix(sz), rx, d.ea(mode, reg, sz))
+	}
+	return fmt.Sprintf("%s%s %s,D%d", name, sizeSuffix(sz), d.ea(mode, reg, sz), rx)
+}
+
+// decodeX decodes the register/predecrement pairs of ADDX, SUBX, ABCD and
+// SBCD, selected by the R/M bit 3.
+func (d *disassembler) decodeX(name string, op uint16, sz size) string {
+	rx := (op >> 9) & 7
+	ry := op & 7
+	suffix := sizeSuffix(sz)
+	if name == "ABCD" || name == "SBCD" {
+		suffix = ""
+	}
+	if op&0x0008 != 0 {
+		return fmt.Sprintf("%s%s -(A%d),-(A%d)", name, suffix, ry, rx)
+	}
+	return fmt.Sprintf("%s%s D%d,D%d", name, suffix, ry, rx)
+}
@@ -0,0 +1,281 @@
+package m68k
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDisassemble(t *testing.T) {
+	tests := []struct {
+		words []uint16
+		want  string
+	}{
+		// Addressing modes
+		{[]uint16{0x3200}, "MOVE.W D0,D1"},
+		{[]uint16{0x3280}, "MOVE.W D0,(A1)"},
+		{[]uint16{0x2C49}, "MOVEA.L A1,A6"},
+		{[]uint16{0x12D8}, "MOVE.B (A0)+,(A1)+"},
+		{[]uint16{0x2320}, "MOVE.L -(A0),-(A1)"},
+		{[]uint16{0x3028, 0xFFFC}, "MOVE.W -$4(A0),D0"},
+		{[]uint16{0x3030, 0x1802}, "MOVE.W $2(A0,D1.L),D0"},
+		{[]uint16{0x3030, 0xA0FE}, "MOVE.W -$2(A0,A2.W),D0"},
+		{[]uint16{0x3038, 0x8000}, "MOVE.W ($8000).W,D0"},
+		{[]uint16{0x3039, 0x00FF, 0x0010}, "MOVE.W ($FF0010).L,D0"},
+		{[]uint16{0x303A, 0x0010}, "MOVE.W $10(PC),D0"},
+		{[]uint16{0x303B, 0x3006}, "MOVE.W $6(PC,D3.W),D0"},
+		{[]uint16{0x303C, 0x1234}, "MOVE.W #$1234,D0"},
+		{[]uint16{0x203C, 0xDEAD, 0xBEEF}, "MOVE.L #$DEADBEEF,D0"},
+		{[]uint16{0x103C, 0x00AB}, "MOVE.B #$AB,D0"},
+		{[]uint16{0x23FC, 0x0000, 0x0001, 0x00FF, 0x0000}, "MOVE.L #$1,($FF0000).L"},
+
+		// Immediate and bit operations
+		{[]uint16{0x0640, 0x0010}, "ADDI.W #$10,D0"},
+		{[]uint16{0x0C80, 0x0000, 0x0064}, "CMPI.L #$64,D0"},
+		{[]uint16{0x003C, 0x0001}, "ORI #$1,CCR"},
+		{[]uint16{0x027C, 0xF8FF}, "ANDI #$F8FF,SR"},
+		{[]uint16{0x0800, 0x0003}, "BTST #3,D0"},
+		{[]uint16{0x03D0}, "BSET D1,(A0)"},
+		{[]uint16{0x0188, 0x0004}, "MOVEP.W D0,$4(A0)"},
+		{[]uint16{0x0349, 0x0000}, "MOVEP.L $0(A1),D1"},
+
+		// Quick and single operand
+		{[]uint16{0x70FF}, "MOVEQ #-1,D0"},
+		{[]uint16{0x5088}, "ADDQ.L #8,A0"},
+		{[]uint16{0x5341}, "SUBQ.W #1,D1"},
+		{[]uint16{0x4280}, "CLR.L D0"},
+		{[]uint16{0x4A10}, "TST.B (A0)"},
+		{[]uint16{0x4AD0}, "TAS (A0)"},
+		{[]uint16{0x4840}, "SWAP D0"},
+		{[]uint16{0x48C1}, "EXT.L D1"},
+		{[]uint16{0x4850}, "PEA (A0)"},
+		{[]uint16{0x41F9, 0x0000, 0x1000}, "LEA ($1000).L,A0"},
+		{[]uint16{0x4181}, "CHK.W D1,D0"},
+		{[]uint16{0x57C0}, "SEQ D0"},
+
+		// MOVEM register lists
+		{[]uint16{0x48E7, 0xC0C0}, "MOVEM.L D0-D1/A0-A1,-(A7)"},
+		{[]uint16{0x4CDF, 0x0303}, "MOVEM.L (A7)+,D0-D1/A0-A1"},
+		{[]uint16{0x4890, 0x8081}, "MOVEM.W D0/D7/A7,(A0)"},
+
+		// Arithmetic and logic
+		{[]uint16{0xD041}, "ADD.W D1,D0"},
+		{[]uint16{0xD390}, "ADD.L D1,(A0)"},
+		{[]uint16{0xD1C1}, "ADDA.L D1,A0"},
+		{[]uint16{0x9101}, "SUBX.B D1,D0"},
+		{[]uint16{0x9149}, "SUBX.W -(A1),-(A0)"},
+		{[]uint16{0xB041}, "CMP.W D1,D0"},
+		{[]uint16{0xB3C8}, "CMPA.L A0,A1"},
+		{[]uint16{0xB308}, "CMPM.B (A0)+,(A1)+"},
+		{[]uint16{0xB340}, "EOR.W D1,D0"},
+		{[]uint16{0xC0C1}, "MULU.W D1,D0"},
+		{[]uint16{0x81C1}, "DIVS.W D1,D0"},
+		{[]uint16{0xC101}, "ABCD D1,D0"},
+		{[]uint16{0x8109}, "SBCD -(A1),-(A0)"},
+		{[]uint16{0xC141}, "EXG D0,D1"},
+		{[]uint16{0xC189}, "EXG D0,A1"},
+		{[]uint16{0x8210}, "OR.B (A0),D1"},
+		{[]uint16{0xC250}, "AND.W (A0),D1"},
+
+		// Shifts
+		{[]uint16{0xE348}, "LSL.W #1,D0"},
+		{[]uint16{0xE080}, "ASR.L #8,D0"},
+		{[]uint16{0xE27B}, "ROR.W D1,D3"},
+		{[]uint16{0xE5D0}, "ROXL.W (A0)"},
+
+		// Program control (assembled at $1000)
+		{[]uint16{0x6000, 0x00FE}, "BRA.W $1100"},
+		{[]uint16{0x66FE}, "BNE.S $1000"},
+		{[]uint16{0x6104}, "BSR.S $1006"},
+		{[]uint16{0x51C8, 0xFFFE}, "DBF D0,$1000"},
+		{[]uint16{0x4EB9, 0x0000, 0x2000}, "JSR ($2000).L"},
+		{[]uint16{0x4ED0}, "JMP (A0)"},
+		{[]uint16{0x4E56, 0xFFF8}, "LINK A6,#-$8"},
+		{[]uint16{0x4E5E}, "UNLK A6"},
+		{[]uint16{0x4E4F}, "TRAP #15"},
+		{[]uint16{0x4E72, 0x2700}, "STOP #$2700"},
+		{[]uint16{0x4E75}, "RTS"},
+		{[]uint16{0x4E73}, "RTE"},
+		{[]uint16{0x4E71}, "NOP"},
+		{[]uint16{0x40C0}, "MOVE SR,D0"},
+		{[]uint16{0x46FC, 0x2000}, "MOVE #$2000,SR"},
+		{[]uint16{0x4E60}, "MOVE A0,USP"},
+
+		// Illegal encodings
+		{[]uint16{0x4AFC}, "ILLEGAL"},
+		{[]uint16{0xA000}, "DC.W $A000"},
+		{[]uint16{0xF000}, "DC.W $F000"},
+		{[]uint16{0x35C0}, "DC.W $35C0"}, // MOVE.W D0,d16(PC)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			bus := &testBus{}
+			cpu := New(bus)
+			for i, w := range tt.words {
+				writeWord(bus, 0x1000+uint32(i)*2, w)
+			}
+			got, n := cpu.Disassemble(0x1000)
+			if got != tt.want {
+				t.Errorf("Disassemble = %q, want %q", got, tt.want)
+			}
+			if want := len(tt.words) * 2; n != want {
+				t.Errorf("length = %d, want %d", n, want)
+			}
+		})
+	}
+}
+
+// TestDisassembleVariants checks that the instructions a variant adds are
+// decoded on it and later variants, and are DC.W with a length of 2 on the
+// earlier ones, which take an illegal instruction exception on them.
+func TestDisassembleVariants(t *testing.T) {
+	tests := []struct {
+		words []uint16
+		v     Variant // first variant with the instruction
+		want  string
+	}{
+		{[]uint16{0x4E74, 0x000C}, MC68010, "RTD #$C"},
+		{[]uint16{0x42D0}, MC68010, "MOVE CCR,(A0)"},
+		{[]uint16{0x4E7A, 0x0801}, MC68010, "MOVEC VBR,D0"},
+		{[]uint16{0x4E7B, 0x9000}, MC68010, "MOVEC A1,SFC"},
+		{[]uint16{0x0E50, 0x1800}, MC68010, "MOVES.W D1,(A0)"},
+		{[]uint16{0xE8C0, 0x0008}, MC68020, "BFTST D0{0:8}"},
+		{[]uint16{0x0CD0, 0x0081}, MC68020, "CAS.W D1,D2,(A0)"},
+		{[]uint16{0x02D0, 0x1000}, MC68020, "CMP2.W (A0),D1"},
+		{[]uint16{0x4C39, 0x0800, 0x0001, 0x0000}, MC68020, "MULS.L ($10000).L,D0"},
+		{[]uint16{0x4C40, 0x1001}, MC68020, "DIVU.L D0,D1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			for _, v := range []Variant{MC68000, MC68008, MC68010, MC68020} {
+				bus := &testBus{}
+				cpu := NewVariant(bus, v)
+				for i, w := range tt.words {
+					writeWord(bus, 0x1000+uint32(i)*2, w)
+				}
+				want, wantLen := tt.want, len(tt.words)*2
+				if v < tt.v {
+					want, wantLen = fmt.Sprintf("DC.W $%04X", tt.words[0]), 2
+				}
+				got, n := cpu.Disassemble(0x1000)
+				if got != want || n != wantLen {
+					t.Errorf("%v: Disassemble = %q, %d; want %q, %d", v, got, n, want, wantLen)
+				}
+				if n := cpu.InstructionLength(0x1000); n != wantLen {
+					t.Errorf("%v: InstructionLength = %d, want %d", v, n, wantLen)
+				}
+			}
+		})
+	}
+}
+
+func TestDisassembleWalk(t *testing.T) {
+	bus := &testBus{}
+	cpu := New(bus)
+	prog := []uint16{
+		0x203C, 0x0000, 0x0010, // MOVE.L #$10,D0
+		0x5380, // SUBQ.L #1,D0
+		0x66FC, // BNE.S
+		0x4E75, // RTS
+	}
+	for i, w := range prog {
+		writeWord(bus, 0x2000+uint32(i)*2, w)
+	}
+
+	want := []string{"MOVE.L #$10,D0", "SUBQ.L #1,D0", "BNE.S $2006", "RTS"}
+	addr := uint32(0x2000)
+	for _, w := range want {
+		got, n := cpu.Disassemble(addr)
+		if got != w {
+			t.Fatalf("at %06X: got %q, want %q", addr, got, w)
+		}
+		addr += uint32(n)
+	}
+	if cpu.Cycles() != 0 {
+		t.Errorf("Disassemble consumed cycles: %d", cpu.Cycles())
+	}
+}
+
+func TestInstructionLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant Variant
+		words   []uint16
+	}{
+		{"NOP", MC68000, []uint16{0x4E71}},
+		{"MOVE.W d16(An)", MC68000, []uint16{0x3028, 0xFFFC}},
+		{"MOVE.W d8(An,Xn)", MC68000, []uint16{0x3030, 0x1802}},
+		{"MOVE.W abs.W", MC68000, []uint16{0x3038, 0x8000}},
+		{"MOVE.W abs.L", MC68000, []uint16{0x3039, 0x00FF, 0x0010}},
+		{"MOVE.W d16(PC)", MC68000, []uint16{0x303A, 0x0010}},
+		{"MOVE.W d8(PC,Xn)", MC68000, []uint16{0x303B, 0x3006}},
+		{"MOVE.B #imm", MC68000, []uint16{0x103C, 0x00AB}},
+		{"MOVE.L #imm", MC68000, []uint16{0x203C, 0xDEAD, 0xBEEF}},
+		{"MOVE.L #imm,abs.L", MC68000, []uint16{0x23FC, 0x0000, 0x0001, 0x00FF, 0x0000}},
+		{"MOVE.L abs.L,abs.L", MC68000, []uint16{0x23F9, 0x0000, 0x2000, 0x0000, 0x3000}},
+		{"ADDI.L #imm,abs.L", MC68000, []uint16{0x06B9, 0x0000, 0x0001, 0x00FF, 0x0000}},
+		{"BTST #n,d16(An)", MC68000, []uint16{0x0828, 0x0003, 0x0010}},
+		{"MOVEM.L abs.W", MC68000, []uint16{0x48F8, 0x0303, 0x2000}},
+		{"Bcc.W", MC68000, []uint16{0x6600, 0x0010}},
+		{"JSR abs.L", MC68000, []uint16{0x4EB9, 0x0000, 0x2000}},
+		{"illegal", MC68000, []uint16{0xA000}},
+		{"BFEXTU abs.L", MC68020, []uint16{0xE9F9, 0x110C, 0x0000, 0x2000}},
+		// MOVE.L ([bd.L,A0,D1.L*4],od.W),D0
+		{"full format preindexed", MC68020, []uint16{0x2030, 0x1D32, 0x0000, 0x1000, 0x0004}},
+		// MOVE.L ([bd.W,A0],D1.L,od.L),D0
+		{"full format postindexed", MC68020, []uint16{0x2030, 0x1927, 0x0100, 0x0000, 0x0008}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			cpu := New(bus)
+			cpu.variant = tt.variant
+			for i, w := range tt.words {
+				writeWord(bus, 0x1000+uint32(i)*2, w)
+			}
+			if got, want := cpu.InstructionLength(0x1000), len(tt.words)*2; got != want {
+				t.Errorf("InstructionLength = %d, want %d", got, want)
+			}
+			if cpu.PC() != 0 || cpu.Cycles() != 0 {
+				t.Errorf("InstructionLength changed state: PC = 0x%X, cycles = %d", cpu.PC(), cpu.Cycles())
+			}
+		})
+	}
+}
+
+func TestDisassembleCoversTable(t *testing.T) {
+	bus := &testBus{}
+	cpu := NewVariant(bus, MC68020)
+	for op := 0; op < 0x10000; op++ {
+		if !IsImplemented(uint16(op)) {
+			continue
+		}
+		writeWord(bus, 0x1000, uint16(op))
+		got, n := cpu.Disassemble(0x1000)
+		if strings.HasPrefix(got, "DC.W") || strings.Contains(got, "?") || n < 2 || n > 10 {
+			t.Errorf("opcode %04X: got %q (length %d)", op, got, n)
+		}
+	}
+}
+
+// TestDisassembleMatchesStep checks every implemented opcode on the 68000:
+// Disassemble returns DC.W exactly for the words Step takes an illegal
+// instruction exception on.
+func TestDisassembleMatchesStep(t *testing.T) {
+	cpu, bus := progCPU(MC68000, Registers{SR: 0x2700})
+	for op := 0; op < 0x10000; op++ {
+		if !IsImplemented(uint16(op)) {
+			continue
+		}
+		writeWord(bus, 0x1000, uint16(op))
+		text, _ := cpu.Disassemble(0x1000)
+		bus.Write32(vecIllegalInstruction*4, 0x3000)
+		cpu.SetState(Registers{PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+		cpu.Step()
+		vec, _, _, _ := cpu.LastException()
+		if illegal := vec == vecIllegalInstruction; illegal != strings.HasPrefix(text, "DC.W") {
+			t.Errorf("opcode %04X: Disassemble = %q, illegal instruction exception %v", op, text, illegal)
+		}
+	}
+}
@@ -63,6 +63,7 @@ func (c *CPU) resolveEA(mode, reg uint8, sz size) ea {
 		return ea{mode: eaAddrReg, reg: reg}
 
 	case 2: // (An) - Address register indirect
+		c.faultAdj = 0
 		return ea{mode: eaMemory, addr: c.reg.A[reg]}
 
 	case 3: // (An)+ - Address register indirect with postincrement
@@ -71,7 +72,8 @@ func (c *CPU) resolveEA(mode, reg uint8, sz size) ea {
 		if reg == 7 && sz == sizeByte {
 			inc = 2 // SP always stays word-aligned
 		}
-		c.reg.A[reg] += inc
+		c.reg.A[reg] += postincStep(addr, sz, inc)
+		c.faultAdj = 0
 		return ea{mode: eaMemory, addr: addr}
 
 	case 4: // -(An) - Address register indirect with predecrement
@@ -80,35 +82,25 @@ func (c *CPU) resolveEA(mode, reg uint8, sz size) ea {
 			dec = 2 // SP always stays word-aligned
 		}
 		c.reg.A[reg] -= dec
+		c.faultAdj = predecFaultAdj(sz)
 		return ea{mode: eaMemory, addr: c.reg.A[reg]}
 
-	case 5: // d16(An) - Address register indirect with displacement
-		disp := int16(c.fetchPC())
-		return ea{mode: eaMemory, addr: uint32(int32(c.reg.A[reg]) + int32(disp))}
-
-	case 6: // d8(An,Xn) - Address register indirect with index
-		ext := c.fetchPC()
-		return ea{mode: eaMemory, addr: c.calcIndex(c.reg.A[reg], ext)}
+	case 5, 6: // d16(An), d8(An,Xn)
+		addr := c.memAddress(mode, reg, (*stream)(c))
+		c.faultAdj = -2
+		return ea{mode: eaMemory, addr: addr}
 
 	case 7:
 		switch reg {
-		case 0: // abs.W - Absolute short (sign-extended to 32 bits)
-			addr := int16(c.fetchPC())
-			return ea{mode: eaMemory, addr: uint32(int32(addr))}
-
-		case 1: // abs.L - Absolute long
-			addr := c.fetchPCLong()
+		case 0, 1: // abs.W, abs.L
+			addr := c.memAddress(mode, reg, (*stream)(c))
+			c.faultAdj = 0
 			return ea{mode: eaMemory, addr: addr}
 
-		case 2: // d16(PC) - PC relative with displacement
-			pc := c.reg.PC // PC points to the extension word
-			disp := int16(c.fetchPC())
-			return ea{mode: eaMemory, addr: uint32(int32(pc) + int32(disp))}
-
-		case 3: // d8(PC,Xn) - PC relative with index
-			pc := c.reg.PC // PC points to the extension word
-			ext := c.fetchPC()
-			return ea{mode: eaMemory, addr: c.calcIndex(pc, ext)}
+		case 2, 3: // d16(PC), d8(PC,Xn)
+			addr := c.memAddress(mode, reg, (*stream)(c))
+			c.faultAdj = -2
+			return ea{mode: eaMemory, addr: addr}
 
 		case 4: // #imm - Immediate
 			switch sz {
@@ -130,10 +122,90 @@ func (c *CPU) resolveEA(mode, reg uint8, sz size) ea {
 	return ea{}
 }
 
-// calcIndex computes a base + d8(Xn) indexed address from an extension word.
-// Extension word format: D/A | Reg(3) | W/L | 0(3) | Disp(8)
-func (c *CPU) calcIndex(base uint32, ext uint16) uint32 {
-	disp := int8(ext & 0xFF)
+// EffectiveAddress returns the address a memory operand of sz bytes
+// (1, 2 or 4) with the given mode/register fields would use if its
+// extension words started at the current PC, without executing it: PC
+// and the address registers are left untouched and memory is read
+// directly from the bus, as by Disassemble. (An)+ yields An and -(An)
+// the decremented An. ok is false for the register and immediate modes
+// and for invalid encodings.
+func (c *CPU) EffectiveAddress(mode, reg uint8, sz int) (addr uint32, ok bool) {
+	if sz != 1 && sz != 2 && sz != 4 || reg > 7 {
+		return 0, false
+	}
+	switch {
+	case mode == 2 || mode == 3:
+		return c.reg.A[reg], true
+	case mode == 4:
+		dec := uint32(sz)
+		if reg == 7 && sz == 1 {
+			dec = 2
+		}
+		return c.reg.A[reg] - dec, true
+	case mode == 5 || mode == 6 || mode == 7 && reg < 4:
+		return c.memAddress(mode, reg, &disassembler{bus: c.bus, pc: c.reg.PC, variant: c.variant}), true
+	}
+	return 0, false
+}
+
+// extSource supplies the extension words and memory indirect pointers an
+// address calculation consumes; at is the address of the next word.
+type extSource interface {
+	at() uint32
+	word() uint16
+	long() uint32
+	pointer(addr uint32) uint32
+}
+
+// stream is the extSource of an executing instruction: it fetches from
+// the instruction stream and reads pointers as bus cycles.
+type stream CPU
+
+func (s *stream) at() uint32                 { return s.reg.PC }
+func (s *stream) word() uint16               { return (*CPU)(s).fetchPC() }
+func (s *stream) long() uint32               { return (*CPU)(s).fetchPCLong() }
+func (s *stream) pointer(addr uint32) uint32 { return (*CPU)(s).readBus(sizeLong, addr) }
+
+// memAddress computes the address of the displacement, indexed, absolute
+// and PC-relative modes (5, 6 and 7 with reg 0-3), taking extension words
+// from src. PC-relative modes are based on src's position at the first
+// extension word.
+func (c *CPU) memAddress(mode, reg uint8, src extSource) uint32 {
+	switch {
+	case mode == 5:
+		return uint32(int32(c.reg.A[reg]) + int32(int16(src.word())))
+	case mode == 6:
+		return c.calcIndex(c.reg.A[reg], src.word(), src)
+	case reg == 0:
+		return uint32(int32(int16(src.word())))
+	case reg == 1:
+		return src.long()
+	}
+	pc := src.at()
+	if reg == 2 {
+		return uint32(int32(pc) + int32(int16(src.word())))
+	}
+	return c.calcIndex(pc, src.word(), src)
+}
+
+// calcIndex computes an indexed address from an extension word.
+// Brief format: D/A | Reg(3) | W/L | Scale(2) | 0 | Disp(8)
+// The scale field and the full format (bit 8 set) are 68020 additions;
+// earlier variants ignore bits 10-8 and always take the brief format.
+func (c *CPU) calcIndex(base uint32, ext uint16, src extSource) uint32 {
+	if c.variant < MC68020 {
+		return base + c.indexValue(ext, false) + uint32(int32(int8(ext&0xFF)))
+	}
+	if ext&0x0100 != 0 {
+		return c.calcFullIndex(base, ext, src)
+	}
+	return base + c.indexValue(ext, true) + uint32(int32(int8(ext&0xFF)))
+}
+
+// indexValue returns the index register named by an extension word,
+// sign-extended from a word unless bit 11 selects the full long, and
+// shifted by the scale field when scaled is set.
+func (c *CPU) indexValue(ext uint16, scaled bool) uint32 {
 	xn := (ext >> 12) & 7
 
 	var idx int32
@@ -147,6 +219,62 @@ func (c *CPU) calcIndex(base uint32, ext uint16) uint32 {
 	if ext&0x0800 == 0 {
 		idx = int32(int16(idx))
 	}
+	if scaled {
+		idx <<= (ext >> 9) & 3
+	}
+	return uint32(idx)
+}
+
+// calcFullIndex computes the address for a 68020 full format extension
+// word, taking its base and outer displacements from src:
+//
+//	D/A | Reg(3) | W/L | Scale(2) | 1 | BS | IS | BD SIZE(2) | 0 | I/IS(3)
+//
+// BS suppresses the base register (or PC) and IS the index. BD SIZE
+// selects a null, word or long base displacement. I/IS selects memory
+// indirection: with the index in use, 1-3 index before the indirect
+// fetch and 5-7 after it; the low two bits give the outer displacement
+// size as for BD SIZE. The reserved encodings (BD SIZE 0, I/IS 4, and
+// I/IS 5-7 with the index suppressed) are treated as a null displacement
+// and no indirection.
+func (c *CPU) calcFullIndex(base uint32, ext uint16, src extSource) uint32 {
+	if ext&0x0080 != 0 {
+		base = 0
+	}
+	var idx uint32
+	if ext&0x0040 == 0 {
+		idx = c.indexValue(ext, true)
+	}
+	addr := base + c.fullDisp(ext>>4, src)
+
+	iis := ext & 7
+	if ext&0x0040 != 0 && iis > 3 {
+		iis = 0
+	}
+	switch {
+	case iis == 0 || iis == 4:
+		return addr + idx
+	case iis < 4:
+		// Preindexed: the index is added before the indirect fetch
+		addr = src.pointer(addr + idx)
+		return addr + c.fullDisp(iis, src)
+	default:
+		// Postindexed: the index is added to the fetched pointer
+		addr = src.pointer(addr)
+		return addr + idx + c.fullDisp(iis, src)
+	}
+}
 
-	return uint32(int32(base) + idx + int32(disp))
+// fullDisp takes a displacement from src whose size is given by the low
+// two bits of field: 2 for a sign-extended word, 3 for a long, otherwise
+// null.
+func (c *CPU) fullDisp(field uint16, src extSource) uint32 {
+	switch field & 3 {
+	case 2:
+		return uint32(int32(int16(src.word())))
+	case 3:
+		return src.long()
+	default:
+		return 0
+	}
 }
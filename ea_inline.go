@@ -16,22 +16,27 @@ func makeEARead(mode, reg uint16) eaReadFunc {
 	case 1:
 		return func(c *CPU, sz size) uint32 { return c.reg.A[reg] & sz.Mask() }
 	case 2:
-		return func(c *CPU, sz size) uint32 { return c.readBus(sz, c.reg.A[reg]) }
+		return func(c *CPU, sz size) uint32 {
+			c.faultAdj = 0
+			return c.readBus(sz, c.reg.A[reg])
+		}
 	case 3:
 		if reg == 7 {
 			return func(c *CPU, sz size) uint32 {
+				c.faultAdj = 0
 				addr := c.reg.A[7]
 				inc := uint32(sz)
 				if sz == sizeByte {
 					inc = 2
 				}
-				c.reg.A[7] += inc
+				c.reg.A[7] += postincStep(addr, sz, inc)
 				return c.readBus(sz, addr)
 			}
 		}
 		return func(c *CPU, sz size) uint32 {
+			c.faultAdj = 0
 			addr := c.reg.A[reg]
-			c.reg.A[reg] += uint32(sz)
+			c.reg.A[reg] += postincStep(addr, sz, uint32(sz))
 			return c.readBus(sz, addr)
 		}
 	case 4:
@@ -42,45 +47,54 @@ func makeEARead(mode, reg uint16) eaReadFunc {
 					dec = 2
 				}
 				c.reg.A[7] -= dec
+				c.faultAdj = predecFaultAdj(sz)
 				return c.readBus(sz, c.reg.A[7])
 			}
 		}
 		return func(c *CPU, sz size) uint32 {
 			c.reg.A[reg] -= uint32(sz)
+			c.faultAdj = predecFaultAdj(sz)
 			return c.readBus(sz, c.reg.A[reg])
 		}
 	case 5:
 		return func(c *CPU, sz size) uint32 {
 			disp := int16(c.fetchPC())
+			c.faultAdj = -2
 			return c.readBus(sz, uint32(int32(c.reg.A[reg])+int32(disp)))
 		}
 	case 6:
 		return func(c *CPU, sz size) uint32 {
 			ext := c.fetchPC()
-			return c.readBus(sz, c.calcIndex(c.reg.A[reg], ext))
+			c.faultAdj = -2
+			return c.readBus(sz, c.calcIndex(c.reg.A[reg], ext, (*stream)(c)))
 		}
 	case 7:
 		switch reg {
 		case 0:
 			return func(c *CPU, sz size) uint32 {
 				addr := int16(c.fetchPC())
+				c.faultAdj = 0
 				return c.readBus(sz, uint32(int32(addr)))
 			}
 		case 1:
 			return func(c *CPU, sz size) uint32 {
-				return c.readBus(sz, c.fetchPCLong())
+				addr := c.fetchPCLong()
+				c.faultAdj = 0
+				return c.readBus(sz, addr)
 			}
 		case 2:
 			return func(c *CPU, sz size) uint32 {
 				pc := c.reg.PC
 				disp := int16(c.fetchPC())
+				c.faultAdj = -2
 				return c.readBus(sz, uint32(int32(pc)+int32(disp)))
 			}
 		case 3:
 			return func(c *CPU, sz size) uint32 {
 				pc := c.reg.PC
 				ext := c.fetchPC()
-				return c.readBus(sz, c.calcIndex(pc, ext))
+				c.faultAdj = -2
+				return c.readBus(sz, c.calcIndex(pc, ext, (*stream)(c)))
 			}
 		case 4:
 			return func(c *CPU, sz size) uint32 {
@@ -100,22 +114,27 @@ func makeEARead(mode, reg uint16) eaReadFunc {
 func makeEAMemAddr(mode, reg uint16) eaAddrFunc {
 	switch mode {
 	case 2:
-		return func(c *CPU, _ size) uint32 { return c.reg.A[reg] }
+		return func(c *CPU, _ size) uint32 {
+			c.faultAdj = 0
+			return c.reg.A[reg]
+		}
 	case 3:
 		if reg == 7 {
 			return func(c *CPU, sz size) uint32 {
+				c.faultAdj = 0
 				addr := c.reg.A[7]
 				inc := uint32(sz)
 				if sz == sizeByte {
 					inc = 2
 				}
-				c.reg.A[7] += inc
+				c.reg.A[7] += postincStep(addr, sz, inc)
 				return addr
 			}
 		}
 		return func(c *CPU, sz size) uint32 {
+			c.faultAdj = 0
 			addr := c.reg.A[reg]
-			c.reg.A[reg] += uint32(sz)
+			c.reg.A[reg] += postincStep(addr, sz, uint32(sz))
 			return addr
 		}
 	case 4:
@@ -126,49 +145,82 @@ func makeEAMemAddr(mode, reg uint16) eaAddrFunc {
 					dec = 2
 				}
 				c.reg.A[7] -= dec
+				c.faultAdj = predecFaultAdj(sz)
 				return c.reg.A[7]
 			}
 		}
 		return func(c *CPU, sz size) uint32 {
 			c.reg.A[reg] -= uint32(sz)
+			c.faultAdj = predecFaultAdj(sz)
 			return c.reg.A[reg]
 		}
 	case 5:
 		return func(c *CPU, _ size) uint32 {
 			disp := int16(c.fetchPC())
+			c.faultAdj = -2
 			return uint32(int32(c.reg.A[reg]) + int32(disp))
 		}
 	case 6:
 		return func(c *CPU, _ size) uint32 {
 			ext := c.fetchPC()
-			return c.calcIndex(c.reg.A[reg], ext)
+			c.faultAdj = -2
+			return c.calcIndex(c.reg.A[reg], ext, (*stream)(c))
 		}
 	case 7:
 		switch reg {
 		case 0:
 			return func(c *CPU, _ size) uint32 {
 				addr := int16(c.fetchPC())
+				c.faultAdj = 0
 				return uint32(int32(addr))
 			}
 		case 1:
-			return func(c *CPU, _ size) uint32 { return c.fetchPCLong() }
+			return func(c *CPU, _ size) uint32 {
+				addr := c.fetchPCLong()
+				c.faultAdj = 0
+				return addr
+			}
 		case 2:
 			return func(c *CPU, _ size) uint32 {
 				pc := c.reg.PC
 				disp := int16(c.fetchPC())
+				c.faultAdj = -2
 				return uint32(int32(pc) + int32(disp))
 			}
 		case 3:
 			return func(c *CPU, _ size) uint32 {
 				pc := c.reg.PC
 				ext := c.fetchPC()
-				return c.calcIndex(pc, ext)
+				c.faultAdj = -2
+				return c.calcIndex(pc, ext, (*stream)(c))
 			}
 		}
 	}
 	return nil
 }
 
+// postincStep returns the amount an (An)+ access at addr advances An by.
+// A long access to an odd address takes an address error before the
+// 68000 updates An, whereas byte and word accesses have already
+// incremented it; the fault itself is raised by the access that follows.
+func postincStep(addr uint32, sz size, inc uint32) uint32 {
+	if sz == sizeLong && addr&1 != 0 {
+		return 0
+	}
+	return inc
+}
+
+// predecFaultAdj returns the stacked-PC adjustment for an address error on
+// a -(An) access: the 68000 refills the prefetch queue during the
+// decrement of a byte or word operand, but not before the first word of a
+// long one.
+func predecFaultAdj(sz size) int32 {
+	if sz == sizeLong {
+		return 0
+	}
+	return 2
+}
+
 // eaFetchConst returns precomputed EA source fetch cycle costs.
 // base is the cost for byte/word sizes. longExtra is added for long size.
 func eaFetchConst(mode, reg uint16) (base uint64, longExtra uint64) {
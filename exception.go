@@ -1,7 +1,5 @@
 package m68k
 
-import "log"
-
 // MC68000 exception vector numbers.
 const (
 	vecResetSSP           = 0
@@ -16,56 +14,459 @@ const (
 	vecTrace              = 9
 	vecLineA              = 10
 	vecLineF              = 11
+	vecFormatError        = 14
 	vecUninitialized      = 15
 	vecSpuriousInterrupt  = 24
 	vecAutoVector1        = 25
 	vecTrap0              = 32 // TRAP #0 through TRAP #15 = vectors 32-47
 )
 
-// exception processes an exception: enters supervisor mode, pushes the
-// return frame (PC + SR), reads the vector, and jumps to the handler.
+// Group 0 special status word bits (M68000 User's Manual Sec 6.3.9.1).
+// Bits 2-0 hold the function code of the faulting access; bits 15-5 are
+// undefined and carry the instruction register, as on real hardware.
+const (
+	ssRead     = 1 << 4 // R/W: set for a read, clear for a write
+	ssNotInstr = 1 << 3 // I/N: set when the fault occurred during exception processing
+)
+
+// 68010 special status word bits (MC68010 User's Manual Sec 5.5.2). Bits
+// 2-0 hold the function code of the faulting access.
+const (
+	ssw10IF = 1 << 13 // instruction fetch
+	ssw10DF = 1 << 12 // data read
+	ssw10BY = 1 << 9  // byte transfer
+	ssw10RW = 1 << 8  // read
+)
+
+// 68020 special status word bits (MC68020 User's Manual Sec 6.4). Bits 2-0
+// hold the function code of the faulting access and bits 5-4 its size.
+const (
+	ssw20FB = 1 << 14 // fault on an instruction fetch
+	ssw20RB = 1 << 12 // rerun that fetch
+	ssw20DF = 1 << 8  // fault on a data cycle
+	ssw20RW = 1 << 6  // read
+)
+
+// Stack frame formats, held in bits 15-12 of the format word the 68010 and
+// 68020 stack above the PC. The 68000 stacks no format word.
+const (
+	frameShort    = 0x0 // SR, PC and the format word
+	frameSixWord  = 0x2 // 68020: adds the address of the instruction that trapped
+	frameBusFault = 0x8 // 68010 bus and address errors, 29 words
+	frameShortBus = 0xA // 68020 bus and address errors, 16 words
+)
+
+// groupZeroCycles is the cost of bus and address error exception processing.
+const groupZeroCycles = 50
+
+// busAbort is the panic value used to unwind an instruction that was
+// terminated by a bus or address error. Step recovers it.
+type busAbort struct{}
+
+// Exception processing times from the MC68000 User's Manual Table 8-14,
+// covering the stacking, vector fetch and refill of the prefetch queue.
+// The table has no entry for a spurious interrupt; its acknowledge is
+// modelled as an eight clock cycle, the four of a normal acknowledge plus
+// the two wait states before BERR is recognized and the two idle clocks
+// that follow it, with the rest of the sequence unchanged.
+const (
+	excStdCycles     = 34 // illegal, privilege, Line A/F, trace, TRAP, TRAPV
+	excCHKCycles     = 40 // CHK, plus the bound's EA time
+	excDivZeroCycles = 38 // DIVU/DIVS, plus the divisor's EA time
+	intAckCycles     = 44 // interrupt, assuming a four clock IACK cycle
+	intBerrCycles    = 48 // spurious interrupt, its IACK cycle ended by BERR
+)
+
+// exceptionCycles holds the entry cost of the exceptions raised through
+// exception and trapEA, indexed by vector. Vectors not listed (TRAP #n)
+// take excStdCycles.
+var exceptionCycles = [...]uint64{
+	vecIllegalInstruction: excStdCycles,
+	vecDivideByZero:       excDivZeroCycles,
+	vecCHK:                excCHKCycles,
+	vecTRAPV:              excStdCycles,
+	vecPrivilegeViolation: excStdCycles,
+	vecTrace:              excStdCycles,
+	vecLineA:              excStdCycles,
+	vecLineF:              excStdCycles,
+}
+
+func exceptionTime(vector int) uint64 {
+	if vector < len(exceptionCycles) && exceptionCycles[vector] != 0 {
+		return exceptionCycles[vector]
+	}
+	return excStdCycles
+}
+
+// exception processes an exception with its entry cost from
+// exceptionCycles.
 func (c *CPU) exception(vector int) {
-	// Log error exceptions (vectors 2-11) for diagnostics
-	if vector >= vecBusError && vector <= vecLineF {
-		log.Printf("[m68k] exception %d at PC=%06x SR=%04x", vector, c.reg.PC, c.reg.SR)
+	c.processException(vector, exceptionTime(vector))
+}
+
+// trapEA processes an instruction trap whose handler has already fetched an
+// operand, charging that operand's EA time on top of the entry cost.
+func (c *CPU) trapEA(vector int, eaCycles uint64) {
+	c.processException(vector, exceptionTime(vector)+eaCycles)
+}
+
+// lastException is the exception recorded for LastException.
+type lastException struct {
+	vector int
+	pc     uint32
+	sr     uint16
+	taken  bool
+}
+
+// LastException returns the most recent exception or interrupt the CPU
+// began processing: its vector number (for an interrupt, the vector
+// acknowledged, such as 24+level for an auto-vector) and the PC and SR
+// it stacked. taken is false if there has been none since the last reset
+// or SetState. The record is not part of the serialized state.
+func (c *CPU) LastException() (vector int, pc uint32, sr uint16, taken bool) {
+	e := c.lastExc
+	return e.vector, e.pc, e.sr, e.taken
+}
+
+// processException processes an exception: enters supervisor mode, pushes
+// the return frame (see pushFrame), reads the vector, and jumps to the
+// handler. cycles is the total cost charged once the handler address is
+// loaded, to which the frame's extra words are added; instructions whose
+// trap timing differs from the standard exception entry pass their own
+// value.
+func (c *CPU) processException(vector int, cycles uint64) {
+	// Only a bus or address error can interrupt exception processing;
+	// anything else raised while a frame is being stacked would recurse.
+	if c.inException || c.groupZero {
+		c.logf("[m68k] double fault: exception %d during exception processing at PC=%06x", vector, c.reg.PC)
+		c.doubleFault(HaltExceptionReentry)
+	}
+
+	if c.illegalFunc != nil {
+		switch vector {
+		case vecIllegalInstruction:
+			c.illegalFunc(c.prevPC, c.ir, IllegalOpcode)
+		case vecLineA:
+			c.illegalFunc(c.prevPC, c.ir, IllegalLineA)
+		case vecLineF:
+			c.illegalFunc(c.prevPC, c.ir, IllegalLineF)
+		}
+	}
+
+	// Log error exceptions (vectors 2-11, except trace) for diagnostics
+	if vector >= vecBusError && vector <= vecLineF && vector != vecTrace {
+		c.logf("[m68k] exception %d at PC=%06x SR=%04x", vector, c.reg.PC, c.reg.SR)
 	}
 
 	// Determine the PC to push. For group 1 fault exceptions (illegal
-	// instruction, privilege violation, Line-A, Line-F), the 68000 pushes
+	// instruction, privilege violation, Line-A, Line-F, and the format
+	// error RTE takes from the 68010 on), the 68000 pushes
 	// the address of the faulting instruction. For all other exceptions
 	// (group 2: TRAP, TRAPV, CHK, divide-by-zero; and interrupts/trace),
 	// the 68000 pushes the next instruction address (current PC).
+	// These faults also suppress a pending trace, since the instruction
+	// never executed; group 2 traps are traced once their exception has
+	// been processed, so the trace handler runs before the trap handler.
 	pushPC := c.reg.PC
 	switch vector {
-	case vecIllegalInstruction, vecPrivilegeViolation, vecLineA, vecLineF:
+	case vecIllegalInstruction, vecPrivilegeViolation, vecLineA, vecLineF, vecFormatError:
 		pushPC = c.prevPC
+		c.trace = false
 	}
 
 	oldSR := c.reg.SR
+	c.inException = true
+	c.lastExc = lastException{vector, pushPC, oldSR, true}
 
 	// Enter supervisor mode, clear trace
+	c.enterSupervisor()
+
+	// Push the return frame onto the supervisor stack
+	cycles += c.pushFrame(vector, pushPC, oldSR)
+
+	// Read handler address from vector table
+	addr, ok := c.readVector(vector)
+	c.inException = false
+	if !ok {
+		return
+	}
+	c.reg.PC = addr
+
+	c.cycles += cycles
+}
+
+// pushFrame stacks the return frame of an exception or interrupt through
+// vector and returns the cost of its words beyond the 68000's three, 4
+// clocks a word. The 68000 stacks PC and SR alone. The 68010 adds a format
+// 0 word holding the vector offset above them, and the 68020 stacks the
+// six-word format 2 frame, with the address of the instruction that
+// trapped, for CHK, CHK2, TRAPV, divide by zero and trace.
+func (c *CPU) pushFrame(vector int, pc uint32, sr uint16) uint64 {
+	var extra uint64
+	switch {
+	case c.variant >= MC68020 && (vector == vecCHK || vector == vecTRAPV ||
+		vector == vecDivideByZero || vector == vecTrace):
+		c.pushLong(c.prevPC)
+		c.pushWord(formatWord(frameSixWord, vector))
+		extra = 12
+	case c.variant >= MC68010:
+		c.pushWord(formatWord(frameShort, vector))
+		extra = 4
+	}
+	c.pushLong(pc)
+	c.pushWord(sr)
+	return extra
+}
+
+// formatWord builds the format word of a frame: the format in bits 15-12
+// and the vector offset below.
+func formatWord(format uint16, vector int) uint16 {
+	return format<<12 | uint16(vector*4)&0xFFF
+}
+
+// frameSize returns the length in bytes of a stack frame of the given
+// format, or 0 if this variant never stacks one, for RTE.
+func (c *CPU) frameSize(format uint16) uint32 {
+	switch {
+	case format == frameShort:
+		return 8
+	case format == frameSixWord && c.variant >= MC68020:
+		return 12
+	case format == frameBusFault && c.variant == MC68010:
+		return 58
+	case format == frameShortBus && c.variant >= MC68020:
+		return 32
+	}
+	return 0
+}
+
+// enterSupervisor switches to the supervisor stack if needed, sets S,
+// and clears T.
+func (c *CPU) enterSupervisor() {
 	if c.reg.SR&flagS == 0 {
 		c.reg.USP = c.reg.A[7]
 		c.reg.A[7] = c.reg.SSP
 	}
 	c.reg.SR = (c.reg.SR | flagS) & ^flagT
+}
 
-	// Push PC and old SR onto supervisor stack
-	c.pushLong(pushPC)
-	c.pushWord(oldSR)
+// vectorAddr returns the address of the vector table entry for vector,
+// relative to the vector base register.
+func (c *CPU) vectorAddr(vector int) uint32 {
+	return c.reg.VBR + uint32(vector)*4
+}
 
-	// Read handler address from vector table
-	addr := c.readBus(sizeLong, uint32(vector)*4)
+// readVector reads the handler address for vector, for exceptions and
+// interrupts alike. A zero entry falls back to the uninitialized-interrupt
+// vector (15); if that is also zero the CPU halts and ok is false. The
+// hardware has no such check and would jump to 0, but a zero entry is
+// almost always a table the program has not filled in.
+func (c *CPU) readVector(vector int) (addr uint32, ok bool) {
+	addr = c.readBus(sizeLong, c.vectorAddr(vector))
 	if addr == 0 {
 		// Uninitialized vector: try the uninitialized-interrupt vector
-		addr = c.readBus(sizeLong, vecUninitialized*4)
+		addr = c.readBus(sizeLong, c.vectorAddr(vecUninitialized))
 		if addr == 0 {
 			// Double fault on uninitialized vectors: halt
-			c.halted = true
-			return
+			c.halt(HaltUninitializedVector)
+			return 0, false
 		}
 	}
-	c.reg.PC = addr
+	return addr, true
+}
+
+// busError takes the bus error raised by BusError during the access that
+// just completed. sz, read and program describe that access.
+func (c *CPU) busError(sz size, read, program bool) {
+	c.berr = false
+	c.groupZeroException(vecBusError, c.berrAddr, sz, read, program)
+}
+
+// addressError takes an address error (vector 3) for a word or long
+// access to the odd address addr. sz, read and program describe the
+// access.
+func (c *CPU) addressError(addr uint32, sz size, read, program bool) {
+	c.groupZeroException(vecAddressError, addr, sz, read, program)
+}
+
+// accessStatus builds the low bits of the group 0 status word for an
+// access in the current processor state.
+func (c *CPU) accessStatus(read, program bool) uint16 {
+	status := uint16(c.functionCode(program))
+	if read {
+		status |= ssRead
+	}
+	if c.inException {
+		status |= ssNotInstr
+	}
+	return status
+}
+
+// functionCode returns the function code driven for a program or data
+// space access in the current processor state, or the MOVES alternate
+// function code while one is in effect.
+func (c *CPU) functionCode(program bool) uint8 {
+	switch {
+	case c.useAltFC && !program:
+		return c.altFC
+	case c.supervisor() && program:
+		return FCSuperProgram
+	case c.supervisor():
+		return FCSuperData
+	case program:
+		return FCUserProgram
+	default:
+		return FCUserData
+	}
+}
+
+// groupZeroException processes a bus or address error on an sz access to
+// addr: enters supervisor mode, stacks the fault frame (see
+// pushFaultFrame), vectors through vector, and aborts the rest of the
+// current instruction. A fault while this frame is being stacked or the
+// vector read, or an odd handler address, is a double bus fault and halts
+// the CPU.
+func (c *CPU) groupZeroException(vector int, addr uint32, sz size, read, program bool) {
+	if c.groupZero {
+		c.logf("[m68k] double bus fault at PC=%06x addr=%06x", c.reg.PC, addr&0xFFFFFF)
+		if vector == vecAddressError && c.reg.A[7]&1 != 0 {
+			c.doubleFault(HaltOddStack)
+		}
+		c.doubleFault(HaltDoubleBusFault)
+	}
+	c.logf("[m68k] exception %d at PC=%06x SR=%04x addr=%06x", vector, c.reg.PC, c.reg.SR, addr&0xFFFFFF)
+
+	c.endRMW()
+	status := c.accessStatus(read, program)
+	c.groupZero = true
+	c.inException = false
+	c.useAltFC = false
+	oldSR := c.reg.SR
+	pushPC := uint32(int32(c.reg.PC) + c.faultAdj)
+	c.lastExc = lastException{vector, pushPC, oldSR, true}
+
+	c.enterSupervisor()
+
+	cycles := groupZeroCycles + c.pushFaultFrame(vector, pushPC, oldSR, addr, sz, status, program)
+
+	if handler, ok := c.readVector(vector); ok {
+		// The handler's first words are fetched before group 0 processing
+		// completes, so an odd handler faults again inside it rather than
+		// raising one address error after another and stacking a frame
+		// each time.
+		if handler&1 != 0 {
+			c.logf("[m68k] double bus fault: odd handler=%06x for exception %d", handler&0xFFFFFF, vector)
+			c.doubleFault(HaltOddHandler)
+		}
+		c.reg.PC = handler
+		c.cycles += cycles
+	}
+	c.groupZero = false
+	panic(busAbort{})
+}
+
+// pushFaultFrame stacks the frame of a bus or address error on an sz access
+// to addr and returns the cost of its words beyond the 68000's seven, 4
+// clocks a word. status is the access's 68000 status word from
+// accessStatus, and program is set for an instruction fetch.
+//
+// The 68000 stacks the 14-byte group 0 frame: from the top of the frame
+// down PC, SR, the instruction register, the access address, and the
+// status word with the instruction register in its undefined upper bits.
+// The 68010 stacks the 29-word format 8 frame and the 68020 the 16-word
+// format A frame, each with the access address and the variant's own
+// status word; the data buffers, pipe stages and internal state the
+// hardware saves for continuing the instruction are stacked as zero, apart
+// from the 68010's instruction input buffer, which holds the instruction
+// register.
+func (c *CPU) pushFaultFrame(vector int, pc uint32, sr uint16, addr uint32, sz size, status uint16, program bool) uint64 {
+	fc := status & 7
+	read := status&ssRead != 0
+	switch c.variant {
+	case MC68010:
+		ssw := fc
+		switch {
+		case program:
+			ssw |= ssw10IF
+		case read:
+			ssw |= ssw10DF
+		}
+		if sz == sizeByte {
+			ssw |= ssw10BY
+		}
+		if read {
+			ssw |= ssw10RW
+		}
+		for range 16 {
+			c.pushWord(0) // internal information
+		}
+		c.pushWord(c.ir) // instruction input buffer
+		c.pushWord(0)
+		c.pushWord(0) // data input buffer
+		c.pushWord(0)
+		c.pushWord(0) // data output buffer
+		c.pushWord(0)
+		c.pushLong(addr)
+		c.pushWord(ssw)
+		c.pushWord(formatWord(frameBusFault, vector))
+		c.pushLong(pc)
+		c.pushWord(sr)
+		return 22 * 4
+	case MC68020:
+		ssw := fc
+		if program {
+			ssw |= ssw20FB | ssw20RB
+		} else {
+			ssw |= ssw20DF
+		}
+		if read {
+			ssw |= ssw20RW
+		}
+		switch sz {
+		case sizeByte:
+			ssw |= 1 << 4
+		case sizeWord:
+			ssw |= 2 << 4
+		}
+		c.pushWord(0) // internal registers
+		c.pushWord(0)
+		c.pushLong(0) // data output buffer
+		c.pushWord(0) // internal registers
+		c.pushWord(0)
+		c.pushLong(addr)
+		c.pushWord(0) // instruction pipe stages B and C
+		c.pushWord(0)
+		c.pushWord(ssw)
+		c.pushWord(0) // internal register
+		c.pushWord(formatWord(frameShortBus, vector))
+		c.pushLong(pc)
+		c.pushWord(sr)
+		return 9 * 4
+	}
+	c.pushLong(pc)
+	c.pushWord(sr)
+	c.pushWord(c.ir)
+	c.pushLong(addr)
+	c.pushWord(status | c.ir&^0x1F)
+	return 0
+}
+
+// doubleFault halts the CPU on a fault during group 0 exception processing
+// and aborts the current instruction. The group 0 processing cut short by
+// the fault is charged, so the Step that halts reports the time the CPU
+// spent before the HALT output asserted. reason is reported by
+// HaltReason.
+func (c *CPU) doubleFault(reason HaltReason) {
+	c.halt(reason)
+	c.cycles += groupZeroCycles
+	c.clearFault()
+	panic(busAbort{})
+}
 
-	c.cycles += 34
+// clearFault resets the transient bus error state.
+func (c *CPU) clearFault() {
+	c.useAltFC = false
+	c.berr = false
+	c.groupZero = false
+	c.inException = false
 }
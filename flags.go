@@ -12,6 +12,84 @@ const (
 	flagT uint16 = 1 << 15 // Trace
 )
 
+// UndefinedFlagsMode selects what the CPU does with the condition codes the
+// PRM leaves undefined, for comparing traces against emulators that model
+// them differently.
+type UndefinedFlagsMode uint8
+
+const (
+	// UndefinedFlagsObserved sets undefined flags as the 68000 is observed
+	// to (the SingleStepTests data). This is the default.
+	UndefinedFlagsObserved UndefinedFlagsMode = iota
+
+	// UndefinedFlagsUnchanged leaves undefined flags at their prior value.
+	UndefinedFlagsUnchanged
+)
+
+// SetUndefinedFlagsMode selects how the condition codes the PRM leaves
+// undefined are set. Only these instructions consult it:
+//
+//   - DIVU.W and DIVS.W on overflow: N and Z
+//   - CHK: Z, V and C when it traps, and N too when it does not
+//   - ABCD, SBCD and NBCD: N and V
+//
+// The long divides and CHK2/CMP2 always leave their undefined flags
+// unchanged.
+func (c *CPU) SetUndefinedFlagsMode(m UndefinedFlagsMode) {
+	c.undefFlags = m
+}
+
+// keepUndefined restores the flags in mask to their values in old when
+// undefined flags are left unchanged. Instructions set their undefined
+// flags as observed and call it afterwards.
+func (c *CPU) keepUndefined(old, mask uint16) {
+	if c.undefFlags == UndefinedFlagsUnchanged {
+		c.reg.SR = c.reg.SR&^mask | old&mask
+	}
+}
+
+// FlagString formats the status register one character per bit, from
+// bit 15 down to bit 0, in the style "T-S--7---XNZVC": set flags appear
+// as their letter and clear ones, like the unused bits, as '-'. The
+// interrupt mask takes the place of bits 10-8 as a single digit.
+func (r Registers) FlagString() string {
+	b := []byte("--------------")
+	for _, f := range []struct {
+		pos  int
+		bit  uint16
+		name byte
+	}{
+		{0, flagT, 'T'}, {2, flagS, 'S'},
+		{9, flagX, 'X'}, {10, flagN, 'N'}, {11, flagZ, 'Z'}, {12, flagV, 'V'}, {13, flagC, 'C'},
+	} {
+		if r.SR&f.bit != 0 {
+			b[f.pos] = f.name
+		}
+	}
+	b[5] = '0' + r.InterruptMask()
+	return string(b)
+}
+
+// Supervisor reports whether the S bit is set.
+func (r Registers) Supervisor() bool {
+	return r.SR&flagS != 0
+}
+
+// TraceEnabled reports whether the T bit is set.
+func (r Registers) TraceEnabled() bool {
+	return r.SR&flagT != 0
+}
+
+// InterruptMask returns the interrupt priority mask, SR bits 10-8.
+func (r Registers) InterruptMask() uint8 {
+	return interruptMask(r.SR)
+}
+
+// interruptMask extracts the interrupt priority mask from sr.
+func interruptMask(sr uint16) uint8 {
+	return uint8(sr>>8) & 7
+}
+
 // setFlagsAdd sets XNZVC after an addition: result = dst + src.
 func (c *CPU) setFlagsAdd(src, dst, result uint32, sz size) {
 	msb := sz.MSB()
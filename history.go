@@ -0,0 +1,52 @@
+package m68k
+
+// HistoryEntry records one executed instruction: its address and opcode.
+type HistoryEntry struct {
+	PC uint32
+	IR uint16
+}
+
+// history is a ring of the most recent instructions.
+type history struct {
+	buf  []HistoryEntry
+	next int  // slot the next entry is written to
+	full bool // buf has wrapped at least once
+}
+
+// SetHistorySize keeps a record of the last n instructions started, for
+// reading back with History, and discards any existing record. n <= 0
+// turns the history off, the default.
+func (c *CPU) SetHistorySize(n int) {
+	if n <= 0 {
+		c.history = nil
+	} else {
+		c.history = &history{buf: make([]HistoryEntry, n)}
+	}
+	c.updateHooks()
+}
+
+// History returns the recorded instructions, oldest first. The last entry
+// is the most recently started instruction, which after a double bus
+// fault is the one that halted the CPU. It returns nil when the history
+// is off.
+func (c *CPU) History() []HistoryEntry {
+	h := c.history
+	if h == nil {
+		return nil
+	}
+	if !h.full {
+		return append([]HistoryEntry(nil), h.buf[:h.next]...)
+	}
+	out := make([]HistoryEntry, 0, len(h.buf))
+	out = append(out, h.buf[h.next:]...)
+	return append(out, h.buf[:h.next]...)
+}
+
+func (h *history) record(pc uint32, ir uint16) {
+	h.buf[h.next] = HistoryEntry{PC: pc, IR: ir}
+	h.next++
+	if h.next == len(h.buf) {
+		h.next = 0
+		h.full = true
+	}
+}
@@ -0,0 +1,51 @@
+package m68k
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	cpu, _ := newNOPCPU(10)
+	if cpu.History() != nil {
+		t.Error("History() non-nil while disabled")
+	}
+
+	cpu.SetHistorySize(4)
+	cpu.RunInstructions(2)
+	want := []HistoryEntry{{0x1000, 0x4E71}, {0x1002, 0x4E71}}
+	if got := cpu.History(); !slices.Equal(got, want) {
+		t.Errorf("History() = %v, want %v", got, want)
+	}
+
+	// After wrapping, the oldest entries are dropped.
+	cpu.RunInstructions(4)
+	want = []HistoryEntry{{0x1004, 0x4E71}, {0x1006, 0x4E71}, {0x1008, 0x4E71}, {0x100A, 0x4E71}}
+	if got := cpu.History(); !slices.Equal(got, want) {
+		t.Errorf("History() = %v, want %v", got, want)
+	}
+
+	cpu.SetHistorySize(0)
+	if cpu.History() != nil {
+		t.Error("History() non-nil after SetHistorySize(0)")
+	}
+}
+
+func TestHistoryBeforeHalt(t *testing.T) {
+	bus := &testBus{}
+	writeWord(bus, 0x1000, 0x4E71) // NOP
+	writeWord(bus, 0x1002, 0x4EF9) // JMP ($1001).L
+	bus.Write32(0x1004, 0x1001)
+	cpu := &CPU{bus: bus}
+	cpu.SetState(Registers{PC: 0x1000, SR: 0x2700, SSP: 0x10001}) // odd SSP: the fault frame faults
+	cpu.SetHistorySize(8)
+
+	cpu.RunInstructions(10)
+	if !cpu.Halted() {
+		t.Fatal("expected a double bus fault")
+	}
+	want := []HistoryEntry{{0x1000, 0x4E71}, {0x1002, 0x4EF9}}
+	if got := cpu.History(); !slices.Equal(got, want) {
+		t.Errorf("History() = %v, want %v", got, want)
+	}
+}
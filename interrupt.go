@@ -1,58 +1,108 @@
 package m68k
 
+// IntAckFunc answers the interrupt acknowledge cycle for the given level.
+// It returns the vector number the device places on the bus, or
+// autoVector true when the device asserts VPA to request the auto-vector
+// (24 + level) instead. It is called for every acknowledge, so the choice
+// can differ from one level or one interrupt to the next.
+//
+// A callback that calls CPU.BusError ends the acknowledge with BERR, as
+// when no device responds, and the CPU takes the spurious interrupt
+// vector (24) whatever it returns. A device that supplies vector 15, as
+// an unprogrammed Motorola peripheral does, gets the uninitialized
+// interrupt vector. Both are stacked as any other interrupt. The vectored
+// acknowledge takes the interrupt's 44 cycles, and the one ended by BERR
+// 48, modelled as an eight clock cycle rather than a four clock one.
+type IntAckFunc func(level uint8) (vector uint8, autoVector bool)
+
+// SetIntAckFunc installs a callback that supplies the vector whenever an
+// interrupt is acknowledged, taking precedence over the vector passed to
+// RequestInterrupt, SetIPL or PulseInterrupt. Pass nil to restore that
+// behaviour.
+func (c *CPU) SetIntAckFunc(fn IntAckFunc) {
+	c.intAckFunc = fn
+}
+
 // checkInterrupt tests whether a pending interrupt should be serviced
 // and processes it if so. Called at the start of each Step.
 func (c *CPU) checkInterrupt() {
-	if c.pendingIPL == 0 {
+	level := max(c.pendingIPL, c.pulseIPL)
+	if level == 0 {
 		return
 	}
 
-	mask := uint8((c.reg.SR >> 8) & 7)
+	mask := interruptMask(c.reg.SR)
 
 	// Level 7 is non-maskable; all others must exceed the current mask
-	if c.pendingIPL > mask || c.pendingIPL == 7 {
+	if level > mask || level == 7 {
 		c.processInterrupt()
 	}
 }
 
-// processInterrupt services the pending interrupt: saves context, reads
-// the vector, and jumps to the handler.
+// processInterrupt services the highest pending interrupt, preferring a
+// pulse to a level request of the same priority: it clears that request,
+// acknowledges it, saves context, reads the vector, and jumps to the
+// handler.
 func (c *CPU) processInterrupt() {
-	level := c.pendingIPL
-	vec := c.pendingVec
-	c.pendingIPL = 0
-	c.pendingVec = nil
+	var level uint8
+	var vec *uint8
+	if c.pulseIPL >= c.pendingIPL {
+		level, vec = c.pulseIPL, c.pulseVec
+		c.pulseIPL = 0
+		c.pulseVec = nil
+	} else {
+		level, vec = c.pendingIPL, c.pendingVec
+		c.pendingIPL = 0
+		c.pendingVec = nil
+	}
 
 	oldSR := c.reg.SR
+	c.inException = true
 
 	// Enter supervisor mode, clear trace, set interrupt mask to this level
-	if c.reg.SR&flagS == 0 {
-		c.reg.USP = c.reg.A[7]
-		c.reg.A[7] = c.reg.SSP
-	}
-	c.reg.SR = (c.reg.SR | flagS) & ^flagT
+	c.enterSupervisor()
 	c.reg.SR = (c.reg.SR & 0xF8FF) | uint16(level)<<8
 
-	// Push return frame
-	c.pushLong(c.reg.PC)
-	c.pushWord(oldSR)
-
-	// Determine vector number
+	// Determine vector number. The acknowledge callback, when set, plays
+	// the part of the device answering the IACK cycle.
+	if c.fcBus != nil {
+		c.fcBus.SetFunctionCode(FCCPUSpace)
+	}
 	var vectorNum uint8
-	if vec != nil {
+	cycles := uint64(intAckCycles)
+	if c.intAckFunc != nil {
+		v, auto := c.intAckFunc(level)
+		switch {
+		case c.berr:
+			// No device answered and BERR ended the cycle: a spurious
+			// interrupt, stacked as any other interrupt
+			c.berr = false
+			vectorNum = vecSpuriousInterrupt
+			cycles = intBerrCycles
+		case auto:
+			vectorNum = 24 + level
+		default:
+			vectorNum = v
+		}
+	} else if vec != nil {
 		vectorNum = *vec
 	} else {
 		vectorNum = 24 + level // auto-vector
 	}
+	c.lastExc = lastException{int(vectorNum), c.reg.PC, oldSR, true}
 
-	// Read handler address
-	addr := c.readBus(sizeLong, uint32(vectorNum)*4)
-	if addr == 0 {
-		addr = c.readBus(sizeLong, vecSpuriousInterrupt*4)
-	}
+	// Push the return frame, which from the 68010 on holds the vector
+	cycles += c.pushFrame(int(vectorNum), c.reg.PC, oldSR)
 
+	// Read handler address, falling back on a zero entry as any other
+	// exception does
+	addr, ok := c.readVector(int(vectorNum))
+	c.inException = false
+	if !ok {
+		return
+	}
 	c.reg.PC = addr
 
 	c.stopped = false
-	c.cycles += 44
+	c.cycles += cycles
 }
@@ -62,3 +62,478 @@ func TestSetIPLLevelSensitive(t *testing.T) {
 		}
 	})
 }
+
+// TestIntAckFunc verifies that the acknowledge callback supplies the vector
+// for the level being serviced, and that it can request auto-vectoring.
+func TestIntAckFunc(t *testing.T) {
+	t.Run("device supplies vector", func(t *testing.T) {
+		cpu, bus := setIPLCPU(0x2000)
+		fillNOPs(bus, 0x3000, 8)
+		bus.Write32(0x40*4, 0x3000) // vector 0x40 -> handler at 0x3000
+		var acked []uint8
+		cpu.SetIntAckFunc(func(level uint8) (uint8, bool) {
+			acked = append(acked, level)
+			return 0x40, false
+		})
+		vec := uint8(0x50) // ignored while the callback is set
+		cpu.RequestInterrupt(2, &vec)
+		cpu.Step()
+		if len(acked) != 1 || acked[0] != 2 {
+			t.Errorf("acknowledged levels = %v, want [2]", acked)
+		}
+		if pc := cpu.Registers().PC; pc != 0x3002 {
+			t.Errorf("PC = 0x%06X, want 0x3002 (handler at vector 0x40)", pc)
+		}
+	})
+
+	t.Run("device requests auto-vector", func(t *testing.T) {
+		cpu, _ := setIPLCPU(0x2000)
+		cpu.SetIntAckFunc(func(uint8) (uint8, bool) { return 0x40, true })
+		cpu.RequestInterrupt(2, nil)
+		cpu.Step()
+		if pc := cpu.Registers().PC; pc != 0x2002 {
+			t.Errorf("PC = 0x%06X, want 0x2002 (level 2 autovector handler)", pc)
+		}
+	})
+
+	t.Run("mixed auto-vectored and vectored devices", func(t *testing.T) {
+		// A level 2 device asserts VPA; the level 4 device puts vector
+		// 0x40 on the bus. The callback decides per acknowledge.
+		cpu, bus := setIPLCPU(0x2000)
+		fillNOPs(bus, 0x3000, 8)
+		bus.Write32(0x40*4, 0x3000)
+		bus.Write32((24+4)*4, 0x5000) // level 4 auto-vector, must not be used
+		cpu.SetIntAckFunc(func(level uint8) (uint8, bool) {
+			if level == 4 {
+				return 0x40, false
+			}
+			return 0, true
+		})
+		cpu.RequestInterrupt(2, nil)
+		cpu.Step()
+		if pc := cpu.PC(); pc != 0x2002 {
+			t.Errorf("level 2: PC = 0x%06X, want 0x2002 (auto-vector 26 handler)", pc)
+		}
+		cpu.RequestInterrupt(4, nil)
+		cpu.Step()
+		if pc := cpu.PC(); pc != 0x3002 {
+			t.Errorf("level 4: PC = 0x%06X, want 0x3002 (vector 0x40 handler)", pc)
+		}
+		if vec, pc, _, _ := cpu.LastException(); vec != 0x40 || pc != 0x2002 {
+			t.Errorf("LastException = %d, 0x%X; want %d, 0x2002", vec, pc, 0x40)
+		}
+	})
+}
+
+// TestClearInterrupt verifies that a request withdrawn with ClearInterrupt
+// before the next Step is not taken, even when it is above the mask.
+func TestClearInterrupt(t *testing.T) {
+	bus := &testBus{}
+	fillNOPs(bus, 0x1000, 8)
+	fillNOPs(bus, 0x2000, 8)
+	bus.Write32(0x6C, 0x2000) // vector 27 = level 3 autovector
+	cpu := &CPU{bus: bus}
+	cpu.SetState(Registers{PC: 0x1000, SR: 0x2000, SSP: 0x10000})
+
+	cpu.RequestInterrupt(3, nil)
+	cpu.ClearInterrupt()
+	cpu.Step()
+	if pc := cpu.Registers().PC; pc != 0x1002 {
+		t.Errorf("PC = 0x%06X, want 0x1002 (NOP executed, no interrupt)", pc)
+	}
+	if sp := cpu.Registers().A[7]; sp != 0x10000 {
+		t.Errorf("SSP = 0x%X, want 0x10000 (nothing stacked)", sp)
+	}
+
+	// A fresh request afterwards is still taken.
+	cpu.RequestInterrupt(3, nil)
+	cpu.Step()
+	if pc := cpu.Registers().PC; pc != 0x2002 {
+		t.Errorf("PC = 0x%06X, want 0x2002 (level 3 handler)", pc)
+	}
+}
+
+func TestPendingInterrupt(t *testing.T) {
+	bus := &testBus{}
+	fillNOPs(bus, 0x1000, 8)
+	fillNOPs(bus, 0x2000, 8)
+	bus.Write32(0x70, 0x2000) // vector 28 = level 4 autovector
+	cpu := &CPU{bus: bus}
+	cpu.SetState(Registers{PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+
+	if level, vec := cpu.PendingInterrupt(); level != 0 || vec != nil {
+		t.Errorf("PendingInterrupt() = %d, %v before any request", level, vec)
+	}
+
+	cpu.RequestInterrupt(4, nil)
+	if level, vec := cpu.PendingInterrupt(); level != 4 || vec != nil {
+		t.Errorf("PendingInterrupt() = %d, %v, want 4, nil", level, vec)
+	}
+
+	// Masked: still pending after an instruction.
+	cpu.Step()
+	if level, _ := cpu.PendingInterrupt(); level != 4 {
+		t.Errorf("masked level = %d, want 4", level)
+	}
+
+	cpu.ClearInterrupt()
+	if level, vec := cpu.PendingInterrupt(); level != 0 || vec != nil {
+		t.Errorf("after ClearInterrupt = %d, %v, want 0, nil", level, vec)
+	}
+
+	// Acknowledging clears it.
+	v := uint8(28)
+	cpu.SetSR(0x2000)
+	cpu.RequestInterrupt(4, &v)
+	if _, vec := cpu.PendingInterrupt(); vec == nil || *vec != 28 {
+		t.Errorf("vector = %v, want 28", vec)
+	}
+	cpu.Step()
+	if level, _ := cpu.PendingInterrupt(); level != 0 {
+		t.Errorf("level after acknowledge = %d, want 0", level)
+	}
+}
+
+// TestInterruptAfterMaskLowered checks that an instruction lowering the
+// interrupt mask lets a pending interrupt in before the next instruction,
+// within the same Step.
+func TestInterruptAfterMaskLowered(t *testing.T) {
+	tests := []struct {
+		name   string
+		prog   []uint16
+		pushPC uint32
+		cycles int
+	}{
+		{"MOVE to SR", []uint16{0x46FC, 0x2000}, 0x1004, 16 + intAckCycles},
+		{"ANDI to SR", []uint16{0x027C, 0xF8FF}, 0x1004, 20 + intAckCycles},
+		{"STOP", []uint16{0x4E72, 0x2000}, 0x1004, 4 + intAckCycles},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			fillNOPs(bus, 0x1000, 8)
+			fillNOPs(bus, 0x2000, 8)
+			for i, w := range tt.prog {
+				writeWord(bus, 0x1000+uint32(i*2), w)
+			}
+			bus.Write32(0x6C, 0x2000) // vector 27 = level 3 autovector
+			cpu := &CPU{bus: bus}
+			cpu.SetState(Registers{PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+			cpu.RequestInterrupt(3, nil)
+
+			if n := cpu.Step(); n != tt.cycles {
+				t.Errorf("cycles = %d, want %d", n, tt.cycles)
+			}
+			regs := cpu.Registers()
+			if regs.PC != 0x2000 {
+				t.Errorf("PC = 0x%X, want 0x2000", regs.PC)
+			}
+			if regs.SR != 0x2300 {
+				t.Errorf("SR = 0x%04X, want 0x2300", regs.SR)
+			}
+			if got := bus.Read32(regs.A[7] + 2); got != tt.pushPC {
+				t.Errorf("stacked PC = 0x%X, want 0x%X", got, tt.pushPC)
+			}
+			if cpu.Stopped() {
+				t.Error("still stopped")
+			}
+		})
+	}
+
+	// A mask lowered to a level that still blocks the request leaves it
+	// pending.
+	bus := &testBus{}
+	fillNOPs(bus, 0x1000, 8)
+	writeWord(bus, 0x1000, 0x46FC) // MOVE #$2500,SR
+	writeWord(bus, 0x1002, 0x2500)
+	bus.Write32(0x6C, 0x2000)
+	cpu := &CPU{bus: bus}
+	cpu.SetState(Registers{PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+	cpu.RequestInterrupt(3, nil)
+	cpu.Step()
+	if pc := cpu.Registers().PC; pc != 0x1004 {
+		t.Errorf("PC = 0x%X, want 0x1004", pc)
+	}
+	if level, _ := cpu.PendingInterrupt(); level != 3 {
+		t.Errorf("pending level = %d, want 3", level)
+	}
+}
+
+// TestInterruptWhileStopped verifies that an interrupt arriving while the CPU
+// is stopped is charged only its exception processing, stacks the SR loaded
+// by STOP and the address of the instruction after STOP, and that RTE resumes
+// there.
+func TestInterruptWhileStopped(t *testing.T) {
+	for _, prefetch := range []bool{false, true} {
+		name := "no prefetch"
+		if prefetch {
+			name = "prefetch"
+		}
+		t.Run(name, func(t *testing.T) {
+			bus := &testBus{}
+			fillNOPs(bus, 0x1000, 8)
+			writeWord(bus, 0x1000, 0x4E72) // STOP #$2300
+			writeWord(bus, 0x1002, 0x2300)
+			writeWord(bus, 0x2000, 0x4E73) // RTE
+			bus.Write32(0x74, 0x2000)      // vector 29 = level 5 autovector
+			cpu := &CPU{bus: bus}
+			cpu.SetPrefetch(prefetch)
+			pc := func(addr uint32) uint32 {
+				if prefetch {
+					return addr + 4
+				}
+				return addr
+			}
+			cpu.SetState(Registers{PC: pc(0x1000), SR: 0x2700, SSP: 0x10000})
+
+			cpu.Step()
+			if !cpu.Stopped() {
+				t.Fatal("not stopped after STOP")
+			}
+			if got := cpu.Registers().PC; got != 0x1004 {
+				t.Errorf("PC while stopped = 0x%X, want 0x1004", got)
+			}
+			if n := cpu.Step(); n != 4 {
+				t.Errorf("idle step = %d cycles, want 4", n)
+			}
+
+			cpu.RequestInterrupt(5, nil)
+			if n := cpu.Step(); n != intAckCycles {
+				t.Errorf("interrupt step = %d cycles, want %d", n, intAckCycles)
+			}
+			if cpu.Stopped() {
+				t.Error("still stopped after interrupt")
+			}
+			regs := cpu.Registers()
+			if regs.PC != pc(0x2000) {
+				t.Errorf("PC = 0x%X, want 0x%X", regs.PC, pc(0x2000))
+			}
+			if regs.SR != 0x2500 {
+				t.Errorf("SR = 0x%04X, want 0x2500", regs.SR)
+			}
+			if got := bus.Read16(regs.A[7]); got != 0x2300 {
+				t.Errorf("stacked SR = 0x%04X, want 0x2300", got)
+			}
+			if got := bus.Read32(regs.A[7] + 2); got != 0x1004 {
+				t.Errorf("stacked PC = 0x%X, want 0x1004", got)
+			}
+
+			cpu.Step() // RTE
+			if got := cpu.Registers().PC; got != pc(0x1004) {
+				t.Errorf("PC after RTE = 0x%X, want 0x%X", got, pc(0x1004))
+			}
+			if got := cpu.Registers().SR; got != 0x2300 {
+				t.Errorf("SR after RTE = 0x%04X, want 0x2300", got)
+			}
+		})
+	}
+}
+
+// TestPulseInterrupt checks that a pulsed interrupt is taken exactly once,
+// waits while masked, and is independent of the level-sensitive request.
+func TestPulseInterrupt(t *testing.T) {
+	// pulseCPU runs NOPs at 0x1000 with the level-4 autovector pointing at
+	// a handler at 0x2000 that lowers the mask to 0 and then runs NOPs,
+	// counting interrupt acknowledges per level.
+	pulseCPU := func(sr uint16) (*CPU, map[uint8]int) {
+		cpu, bus := progCPU(MC68000, Registers{SR: sr})
+		fillNOPs(bus, 0x1000, 8)
+		writeWord(bus, 0x2000, 0x46FC) // MOVE #$2000,SR
+		writeWord(bus, 0x2002, 0x2000)
+		fillNOPs(bus, 0x2004, 8)
+		bus.Write32(0x70, 0x2000) // vector 28 = level 4 autovector
+		bus.Write32(0x64, 0x2000) // vector 25 = level 1 autovector
+		acks := map[uint8]int{}
+		cpu.SetIntAckFunc(func(level uint8) (uint8, bool) {
+			acks[level]++
+			return 0, true
+		})
+		return cpu, acks
+	}
+
+	t.Run("taken once", func(t *testing.T) {
+		cpu, acks := pulseCPU(0x2000)
+		cpu.PulseInterrupt(4, nil)
+		cpu.Step()
+		if pc := cpu.PC(); pc != 0x2004 {
+			t.Errorf("PC = 0x%06X, want 0x2004 (handler entered, mask lowered)", pc)
+		}
+		cpu.Step()
+		if pc := cpu.PC(); pc != 0x2006 {
+			t.Errorf("PC = 0x%06X, want 0x2006 (no second interrupt)", pc)
+		}
+		if acks[4] != 1 {
+			t.Errorf("level 4 acknowledged %d times, want 1", acks[4])
+		}
+		if level, _ := cpu.PendingInterrupt(); level != 0 {
+			t.Errorf("pending level = %d, want 0", level)
+		}
+	})
+
+	t.Run("waits while masked", func(t *testing.T) {
+		cpu, acks := pulseCPU(0x2700)
+		cpu.PulseInterrupt(4, nil)
+		cpu.Step()
+		if pc := cpu.PC(); pc != 0x1002 || acks[4] != 0 {
+			t.Fatalf("PC = 0x%06X, acks = %d, want the masked pulse held", pc, acks[4])
+		}
+		cpu.SetSR(0x2000)
+		cpu.Step()
+		if pc := cpu.PC(); pc != 0x2004 || acks[4] != 1 {
+			t.Errorf("PC = 0x%06X, acks = %d, want the pulse taken once unmasked", pc, acks[4])
+		}
+	})
+
+	t.Run("not withdrawn by ClearInterrupt", func(t *testing.T) {
+		cpu, acks := pulseCPU(0x2000)
+		cpu.PulseInterrupt(4, nil)
+		cpu.ClearInterrupt()
+		cpu.Step()
+		if acks[4] != 1 {
+			t.Errorf("level 4 acknowledged %d times, want 1", acks[4])
+		}
+	})
+
+	t.Run("level request kept", func(t *testing.T) {
+		cpu, acks := pulseCPU(0x2000)
+		cpu.SetIPL(1, nil)
+		cpu.PulseInterrupt(4, nil)
+		if level, _ := cpu.PendingInterrupt(); level != 4 {
+			t.Errorf("pending level = %d, want 4", level)
+		}
+		cpu.Step() // pulse taken, handler lowers the mask
+		cpu.Step() // level 1 request still asserted
+		if acks[4] != 1 || acks[1] != 1 {
+			t.Errorf("acks = %v, want one each for levels 4 and 1", acks)
+		}
+	})
+}
+
+// TestUninitializedVectorFallback checks that a zero vector table entry
+// sends an exception and an interrupt the same way: to the uninitialized
+// interrupt vector, or to a halt when that is zero too.
+func TestUninitializedVectorFallback(t *testing.T) {
+	newCPU := func(uninit uint32) (*CPU, *testBus) {
+		// TRAP #1, with a zero vector
+		cpu, bus := progCPU(MC68000, Registers{SR: 0x2000}, 0x4E41)
+		fillNOPs(bus, 0x1002, 4)
+		fillNOPs(bus, 0x4000, 4)
+		bus.Write32(vecUninitialized*4, uninit)
+		return cpu, bus
+	}
+
+	t.Run("exception", func(t *testing.T) {
+		cpu, _ := newCPU(0x4000)
+		cpu.Step()
+		if pc := cpu.PC(); pc != 0x4000 {
+			t.Errorf("PC = 0x%X, want the uninitialized vector handler 0x4000", pc)
+		}
+		if vec, _, _, _ := cpu.LastException(); vec != vecTrap0+1 {
+			t.Errorf("last exception = %d, want %d", vec, vecTrap0+1)
+		}
+	})
+
+	t.Run("interrupt", func(t *testing.T) {
+		cpu, _ := newCPU(0x4000)
+		cpu.SetPC(0x1002)
+		cpu.RequestInterrupt(3, nil) // auto-vector 27, zero
+		cpu.Step()
+		if pc := cpu.PC(); pc != 0x4002 {
+			t.Errorf("PC = 0x%X, want the uninitialized vector handler entered", pc)
+		}
+		if vec, _, _, _ := cpu.LastException(); vec != vecAutoVector1+2 {
+			t.Errorf("last exception = %d, want %d", vec, vecAutoVector1+2)
+		}
+		if sp := cpu.Registers().A[7]; sp != 0x10000-6 {
+			t.Errorf("SSP = 0x%X, want 0x%X", sp, 0x10000-6)
+		}
+	})
+
+	t.Run("both zero halt", func(t *testing.T) {
+		for _, irq := range []bool{false, true} {
+			cpu, _ := newCPU(0)
+			if irq {
+				cpu.SetPC(0x1002)
+				cpu.RequestInterrupt(3, nil)
+			}
+			cpu.Step()
+			if !cpu.Halted() || cpu.HaltReason() != HaltUninitializedVector {
+				t.Errorf("interrupt %v: Halted() = %v, HaltReason() = %v; want an uninitialized vector halt",
+					irq, cpu.Halted(), cpu.HaltReason())
+			}
+		}
+	})
+}
+
+// TestSpuriousInterrupt checks an acknowledge ended by BERR: the spurious
+// interrupt vector is taken with an interrupt's frame, not a bus error's,
+// and its own 48 cycles. A device answering vector 15 gets the
+// uninitialized interrupt vector in an ordinary 44 cycle acknowledge.
+func TestSpuriousInterrupt(t *testing.T) {
+	newCPU := func() (*CPU, *testBus) {
+		cpu, bus := progCPU(MC68000, Registers{SR: 0x2000})
+		fillNOPs(bus, 0x1000, 4)
+		fillNOPs(bus, 0x3000, 4)
+		fillNOPs(bus, 0x4000, 4)
+		bus.Write32(vecSpuriousInterrupt*4, 0x3000)
+		bus.Write32(vecUninitialized*4, 0x4000)
+		bus.Write32(vecBusError*4, 0x5000)
+		bus.Write32((24+5)*4, 0x6000)
+		cpu.RequestInterrupt(5, nil)
+		return cpu, bus
+	}
+
+	tests := []struct {
+		name   string
+		ack    func(cpu *CPU) IntAckFunc
+		vector int
+		pc     uint32
+		cycles int
+	}{
+		{"bus error during IACK", func(cpu *CPU) IntAckFunc {
+			return func(uint8) (uint8, bool) {
+				cpu.BusError(0xFFFFFF)
+				return 0, true
+			}
+		}, vecSpuriousInterrupt, 0x3002, 48 + 4},
+		{"BERR watchdog delay", func(cpu *CPU) IntAckFunc {
+			return func(uint8) (uint8, bool) {
+				cpu.AddCycles(16)
+				cpu.BusError(0xFFFFFF)
+				return 0x40, false
+			}
+		}, vecSpuriousInterrupt, 0x3002, 16 + 48 + 4},
+		{"uninitialized device", func(cpu *CPU) IntAckFunc {
+			return func(uint8) (uint8, bool) { return vecUninitialized, false }
+		}, vecUninitialized, 0x4002, 44 + 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpu, bus := newCPU()
+			cpu.SetIntAckFunc(tt.ack(cpu))
+			if n := cpu.Step(); n != tt.cycles {
+				t.Errorf("cycles = %d, want %d", n, tt.cycles)
+			}
+			reg := cpu.Registers()
+			if reg.PC != tt.pc {
+				t.Errorf("PC = 0x%X, want 0x%X", reg.PC, tt.pc)
+			}
+			if reg.SR != 0x2500 {
+				t.Errorf("SR = 0x%04X, want 0x2500", reg.SR)
+			}
+			// Six byte frame: SR, then the PC of the interrupted instruction
+			if reg.A[7] != 0x10000-6 {
+				t.Errorf("SSP = 0x%X, want 0x%X", reg.A[7], 0x10000-6)
+			}
+			if sr := bus.Read16(0x10000 - 6); sr != 0x2000 {
+				t.Errorf("stacked SR = 0x%04X, want 0x2000", sr)
+			}
+			if pc := bus.Read32(0x10000 - 4); pc != 0x1000 {
+				t.Errorf("stacked PC = 0x%X, want 0x1000", pc)
+			}
+			if vec, _, _, _ := cpu.LastException(); vec != tt.vector {
+				t.Errorf("last exception = %d, want %d", vec, tt.vector)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package m68k
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LoadBinary writes data to the bus starting at addr, one byte at a time
+// as WriteWord does, so addr may be odd. Addresses are masked to 24 bits
+// and wrap from 0xFFFFFF to 0.
+func LoadBinary(bus Bus, addr uint32, data []byte) {
+	for i, b := range data {
+		bus.Write8((addr+uint32(i))&0xFFFFFF, b)
+	}
+}
+
+// LoadSRecord reads Motorola S-records from r and writes the data of the
+// S1, S2 and S3 records to the bus as LoadBinary does. It returns the
+// entry address of the S7, S8 or S9 record ending the file, or 0 if there
+// is none. Header (S0) and count (S5, S6) records are checked and
+// skipped, as are blank lines. Data written before a malformed record
+// stays on the bus.
+func LoadSRecord(bus Bus, r io.Reader) (entry uint32, err error) {
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		addr, data, typ, err := parseSRecord(text)
+		if err != nil {
+			return 0, fmt.Errorf("m68k: S-record line %d: %w", line, err)
+		}
+		switch typ {
+		case '1', '2', '3':
+			LoadBinary(bus, addr, data)
+		case '7', '8', '9':
+			return addr, nil
+		}
+	}
+	return 0, sc.Err()
+}
+
+// srecAddrLen is the length in bytes of the address field of each
+// S-record type.
+var srecAddrLen = map[byte]int{
+	'0': 2, '1': 2, '2': 3, '3': 4, '5': 2, '6': 3, '7': 4, '8': 3, '9': 2,
+}
+
+// parseSRecord decodes one S-record, verifying its byte count and
+// checksum, and returns its address, data and type digit.
+func parseSRecord(text string) (addr uint32, data []byte, typ byte, err error) {
+	if len(text) < 4 || text[0] != 'S' {
+		return 0, nil, 0, errors.New("missing S-record header")
+	}
+	typ = text[1]
+	n, ok := srecAddrLen[typ]
+	if !ok {
+		return 0, nil, 0, fmt.Errorf("unknown record type S%c", typ)
+	}
+	b, err := hex.DecodeString(text[2:])
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	if len(b) < n+2 || int(b[0]) != len(b)-1 {
+		return 0, nil, 0, errors.New("byte count does not match record length")
+	}
+	var sum byte
+	for _, v := range b[:len(b)-1] {
+		sum += v
+	}
+	if ^sum != b[len(b)-1] {
+		return 0, nil, 0, fmt.Errorf("checksum 0x%02X, want 0x%02X", b[len(b)-1], ^sum)
+	}
+	for _, v := range b[1 : 1+n] {
+		addr = addr<<8 | uint32(v)
+	}
+	return addr, b[1+n : len(b)-1], typ, nil
+}
@@ -0,0 +1,79 @@
+package m68k
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadBinary(t *testing.T) {
+	bus := &testBus{}
+	LoadBinary(bus, 0x1001, []byte{0x12, 0x34, 0x56})
+	if got := bus.mem[0x1000:0x1005]; string(got) != "\x00\x12\x34\x56\x00" {
+		t.Errorf("memory = % X, want 00 12 34 56 00", got)
+	}
+	LoadBinary(bus, 0xFFFFFFFF, []byte{0xAB, 0xCD})
+	if bus.mem[0xFFFFFF] != 0xAB || bus.mem[0] != 0xCD {
+		t.Errorf("bytes at 0xFFFFFF and 0 = %02X %02X, want AB CD", bus.mem[0xFFFFFF], bus.mem[0])
+	}
+}
+
+func TestLoadSRecord(t *testing.T) {
+	const file = `S00600004844521B
+S10710004E714E7566
+S207012345DEADBE46
+
+S30900002000CAFEBABE96
+S5030003F9
+S9031000EC
+`
+	bus := &testBus{}
+	entry, err := LoadSRecord(bus, strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry != 0x1000 {
+		t.Errorf("entry = 0x%X, want 0x1000", entry)
+	}
+	for _, tt := range []struct {
+		addr uint32
+		want string
+	}{
+		{0x1000, "\x4E\x71\x4E\x75"},
+		{0x12345, "\xDE\xAD\xBE"},
+		{0x2000, "\xCA\xFE\xBA\xBE"},
+	} {
+		if got := bus.mem[tt.addr : tt.addr+uint32(len(tt.want))]; string(got) != tt.want {
+			t.Errorf("memory at 0x%X = % X, want % X", tt.addr, got, tt.want)
+		}
+	}
+
+	t.Run("entry records", func(t *testing.T) {
+		for _, tt := range []struct {
+			rec  string
+			want uint32
+		}{
+			{"S80401234592", 0x12345},
+			{"S70500FC0000FE", 0xFC0000},
+			{"S10710004E714E7566", 0}, // no entry record
+		} {
+			entry, err := LoadSRecord(&testBus{}, strings.NewReader(tt.rec))
+			if err != nil || entry != tt.want {
+				t.Errorf("%s: entry 0x%X, err %v, want 0x%X", tt.rec, entry, err, tt.want)
+			}
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, rec := range []string{
+			"S10710004E714E7567", // checksum
+			"S10810004E714E7566", // byte count
+			"S40300FC",           // record type
+			"S107100G4E714E7566", // hex digit
+			"10710004E714E7566",  // header
+		} {
+			if _, err := LoadSRecord(&testBus{}, strings.NewReader(rec)); err == nil {
+				t.Errorf("%s: no error", rec)
+			}
+		}
+	})
+}
@@ -1,5 +1,7 @@
 package m68k
 
+import "math/bits"
+
 func init() {
 	registerADD()
 	registerADDA()
@@ -19,11 +21,14 @@ func init() {
 	registerMULS()
 	registerDIVU()
 	registerDIVS()
+	registerMULL()
+	registerDIVL()
 	registerNEG()
 	registerNEGX()
 	registerCLR()
 	registerEXT()
 	registerCHK()
+	registerCHK2()
 }
 
 // sizeEncoding maps the standard 2-bit size field (bits 7-6) to Size.
@@ -59,7 +64,7 @@ func registerADD() {
 						continue
 					}
 					opcode := 0xD000 | dn<<9 | szBits<<6 | mode<<3 | reg
-					opcodeTable[opcode] = makeADDtoReg(dn, mode, reg)
+					opcodeTable[opcode] = makeADDtoReg(sizeEncoding(szBits), dn, mode, reg)
 				}
 			}
 			// Direction 1: Dn,<ea> (memory alterable only)
@@ -69,51 +74,49 @@ func registerADD() {
 						continue
 					}
 					opcode := 0xD000 | dn<<9 | (szBits+4)<<6 | mode<<3 | reg
-					opcodeTable[opcode] = makeADDtoEA(dn, mode, reg)
+					opcodeTable[opcode] = makeADDtoEA(sizeEncoding(szBits), dn, mode, reg)
 				}
 			}
 		}
 	}
 }
 
-func makeADDtoReg(dn, mode, reg uint16) opFunc {
+func makeADDtoReg(sz size, dn, mode, reg uint16) opFunc {
 	read := makeEARead(mode, reg)
 	eaBase, eaLong := eaFetchConst(mode, reg)
 	isMem := mode >= 2 && !(mode == 7 && reg == 4)
+	mask := sz.Mask()
+	cycles := 4 + eaBase
+	if sz == sizeLong && isMem {
+		cycles = 6 + eaBase + eaLong
+	} else if sz == sizeLong {
+		cycles = 8 + eaBase + eaLong
+	}
 	return func(c *CPU) {
-		sz := sizeEncoding((c.ir >> 6) & 3)
 		s := read(c, sz)
-		d := c.reg.D[dn] & sz.Mask()
+		d := c.reg.D[dn] & mask
 		result := s + d
 		c.setFlagsAdd(s, d, result, sz)
-		mask := sz.Mask()
 		c.reg.D[dn] = (c.reg.D[dn] & ^mask) | (result & mask)
-		if sz != sizeLong {
-			c.cycles += 4 + eaBase
-		} else if isMem {
-			c.cycles += 6 + eaBase + eaLong
-		} else {
-			c.cycles += 8 + eaBase + eaLong
-		}
+		c.cycles += cycles
 	}
 }
 
-func makeADDtoEA(dn, mode, reg uint16) opFunc {
+func makeADDtoEA(sz size, dn, mode, reg uint16) opFunc {
 	addr := makeEAMemAddr(mode, reg)
 	eaBase, eaLong := eaFetchConst(mode, reg)
+	cycles := 8 + eaBase
+	if sz == sizeLong {
+		cycles = 12 + eaBase + eaLong
+	}
 	return func(c *CPU) {
-		sz := sizeEncoding(((c.ir >> 6) & 7) - 4)
 		a := addr(c, sz)
 		d := c.readBus(sz, a)
 		s := c.reg.D[dn] & sz.Mask()
 		result := s + d
 		c.setFlagsAdd(s, d, result, sz)
 		c.writeBus(sz, a, result)
-		if sz == sizeLong {
-			c.cycles += 12 + eaBase + eaLong
-		} else {
-			c.cycles += 8 + eaBase
-		}
+		c.cycles += cycles
 	}
 }
 
@@ -340,9 +343,9 @@ func opADDXmem(c *CPU) {
 	sz := sizeEncoding((c.ir >> 6) & 3)
 	ry := c.ir & 7
 
-	src := c.resolveEA(4, uint8(ry), sz) // -(Ay)
+	src := c.predecX(uint8(ry), sz) // -(Ay)
 	s := src.read(c, sz)
-	dst := c.resolveEA(4, uint8(rx), sz) // -(Ax)
+	dst := c.predecX(uint8(rx), sz) // -(Ax)
 	d := dst.read(c, sz)
 	x := uint32(0)
 	if c.reg.SR&flagX != 0 {
@@ -364,6 +367,23 @@ func opADDXmem(c *CPU) {
 	}
 }
 
+// predecX resolves a -(An) operand of ADDX or SUBX. For long operands the
+// 68000 reads the low word first and commits the decrement only once it
+// has been read, so an address error reports An-2, leaves An unchanged,
+// and stacks a PC one prefetch on.
+func (c *CPU) predecX(an uint8, sz size) ea {
+	if sz != sizeLong {
+		return c.resolveEA(4, an, sz)
+	}
+	addr := c.reg.A[an] - 4
+	if addr&1 != 0 {
+		c.faultAdj = 2
+		c.addressError(addr+2, sizeWord, true, false)
+	}
+	c.reg.A[an] = addr
+	return ea{mode: eaMemory, addr: addr}
+}
+
 // --- SUB ---
 
 func registerSUB() {
@@ -379,7 +399,7 @@ func registerSUB() {
 						continue
 					}
 					opcode := 0x9000 | dn<<9 | szBits<<6 | mode<<3 | reg
-					opcodeTable[opcode] = makeSUBtoReg(dn, mode, reg)
+					opcodeTable[opcode] = makeSUBtoReg(sizeEncoding(szBits), dn, mode, reg)
 				}
 			}
 			// Dn,<ea>
@@ -389,51 +409,49 @@ func registerSUB() {
 						continue
 					}
 					opcode := 0x9000 | dn<<9 | (szBits+4)<<6 | mode<<3 | reg
-					opcodeTable[opcode] = makeSUBtoEA(dn, mode, reg)
+					opcodeTable[opcode] = makeSUBtoEA(sizeEncoding(szBits), dn, mode, reg)
 				}
 			}
 		}
 	}
 }
 
-func makeSUBtoReg(dn, mode, reg uint16) opFunc {
+func makeSUBtoReg(sz size, dn, mode, reg uint16) opFunc {
 	read := makeEARead(mode, reg)
 	eaBase, eaLong := eaFetchConst(mode, reg)
 	isMem := mode >= 2 && !(mode == 7 && reg == 4)
+	mask := sz.Mask()
+	cycles := 4 + eaBase
+	if sz == sizeLong && isMem {
+		cycles = 6 + eaBase + eaLong
+	} else if sz == sizeLong {
+		cycles = 8 + eaBase + eaLong
+	}
 	return func(c *CPU) {
-		sz := sizeEncoding((c.ir >> 6) & 3)
 		s := read(c, sz)
-		d := c.reg.D[dn] & sz.Mask()
+		d := c.reg.D[dn] & mask
 		result := d - s
 		c.setFlagsSub(s, d, result, sz)
-		mask := sz.Mask()
 		c.reg.D[dn] = (c.reg.D[dn] & ^mask) | (result & mask)
-		if sz != sizeLong {
-			c.cycles += 4 + eaBase
-		} else if isMem {
-			c.cycles += 6 + eaBase + eaLong
-		} else {
-			c.cycles += 8 + eaBase + eaLong
-		}
+		c.cycles += cycles
 	}
 }
 
-func makeSUBtoEA(dn, mode, reg uint16) opFunc {
+func makeSUBtoEA(sz size, dn, mode, reg uint16) opFunc {
 	addr := makeEAMemAddr(mode, reg)
 	eaBase, eaLong := eaFetchConst(mode, reg)
+	cycles := 8 + eaBase
+	if sz == sizeLong {
+		cycles = 12 + eaBase + eaLong
+	}
 	return func(c *CPU) {
-		sz := sizeEncoding(((c.ir >> 6) & 7) - 4)
 		a := addr(c, sz)
 		d := c.readBus(sz, a)
 		s := c.reg.D[dn] & sz.Mask()
 		result := d - s
 		c.setFlagsSub(s, d, result, sz)
 		c.writeBus(sz, a, result)
-		if sz == sizeLong {
-			c.cycles += 12 + eaBase + eaLong
-		} else {
-			c.cycles += 8 + eaBase
-		}
+		c.cycles += cycles
 	}
 }
 
@@ -655,9 +673,9 @@ func opSUBXmem(c *CPU) {
 	sz := sizeEncoding((c.ir >> 6) & 3)
 	ry := c.ir & 7
 
-	src := c.resolveEA(4, uint8(ry), sz)
+	src := c.predecX(uint8(ry), sz)
 	s := src.read(c, sz)
-	dst := c.resolveEA(4, uint8(rx), sz)
+	dst := c.predecX(uint8(rx), sz)
 	d := dst.read(c, sz)
 	x := uint32(0)
 	if c.reg.SR&flagX != 0 {
@@ -735,6 +753,8 @@ func registerCMPA() {
 	}
 }
 
+// makeCMPA compares all 32 bits of An with the source, a word source
+// being sign-extended first, so the flags always come from a long compare.
 func makeCMPA(an, mode, reg uint16) opFunc {
 	read := makeEARead(mode, reg)
 	eaBase, eaLong := eaFetchConst(mode, reg)
@@ -836,9 +856,13 @@ func opCMPM(c *CPU) {
 	ay := c.ir & 7
 	ax := (c.ir >> 9) & 7
 
+	// CMPM has already prefetched the next word when it reads its
+	// operands, so an address error stacks a PC one word further on.
 	src := c.resolveEA(3, uint8(ay), sz) // (Ay)+
+	c.faultAdj = 2
 	s := src.read(c, sz)
 	dst := c.resolveEA(3, uint8(ax), sz) // (Ax)+
+	c.faultAdj = 2
 	d := dst.read(c, sz)
 	result := d - s
 	c.setFlagsCmp(s, d, result, sz)
@@ -908,18 +932,26 @@ func makeMULS(dn, mode, reg uint16) opFunc {
 	read := makeEARead(mode, reg)
 	eaBase, eaLong := eaFetchConst(mode, reg)
 	return func(c *CPU) {
-		s := int32(int16(read(c, sizeWord)))
+		src := read(c, sizeWord)
+		s := int32(int16(src))
 		d := int32(int16(c.reg.D[dn] & 0xFFFF))
 		result := uint32(s * d)
 		c.reg.D[dn] = result
 		c.setFlagsLogical(result, sizeLong)
-		c.cycles += 70 + eaBase
+		c.cycles += mulsCycles(uint16(src)) + eaBase
 		if sizeWord == sizeLong {
 			c.cycles += eaLong
 		}
 	}
 }
 
+// mulsCycles returns the MULS execution time for a source operand:
+// 38 + 2n, where n is the number of 01 or 10 bit pairs in the 17-bit
+// value formed by appending a 0 below the source (PRM Table 8-9).
+func mulsCycles(src uint16) uint64 {
+	return 38 + 2*uint64(bits.OnesCount16(src^src<<1))
+}
+
 // --- DIVU ---
 
 func registerDIVU() {
@@ -945,26 +977,61 @@ func makeDIVU(dn, mode, reg uint16) opFunc {
 	return func(c *CPU) {
 		divisor := read(c, sizeWord)
 		if divisor == 0 {
-			c.exception(vecDivideByZero)
+			// Group 2 trap: the stacked PC is the next instruction and
+			// the divisor's EA time is charged on top of the 38 cycles.
+			c.trapEA(vecDivideByZero, eaBase)
 			return
 		}
 		dividend := c.reg.D[dn]
 		quotient := dividend / divisor
 		remainder := dividend % divisor
+		// On overflow the register is left unchanged. The 68000 sets N and
+		// V and clears Z and C (confirmed by the SST data), X untouched.
 		if quotient > 0xFFFF {
+			old := c.reg.SR
 			c.reg.SR |= flagV | flagN
 			c.reg.SR &^= flagC | flagZ
+			c.keepUndefined(old, flagN|flagZ)
 		} else {
 			c.reg.D[dn] = (remainder&0xFFFF)<<16 | (quotient & 0xFFFF)
 			c.setFlagsLogical(quotient, sizeWord)
 		}
-		c.cycles += 140 + eaBase
+		c.cycles += divuCycles(dividend, uint16(divisor)) + eaBase
 		if sizeWord == sizeLong {
 			c.cycles += eaLong
 		}
 	}
 }
 
+// divuCycles returns the DIVU execution time for a nonzero divisor by
+// replaying the microcode's shift/subtract loop over the remaining 15
+// quotient bits. On top of a 76-cycle base, a step costs nothing extra
+// when the bit shifted out of the dividend forces the subtraction, 2
+// cycles when the trial subtraction succeeds, and 4 when it fails.
+// Overflow (quotient above 0xFFFF) is detected before the loop and costs 10.
+func divuCycles(dividend uint32, divisor uint16) uint64 {
+	hdivisor := uint32(divisor) << 16
+	if dividend >= hdivisor {
+		return 10
+	}
+
+	cycles := uint64(76)
+	for i := 0; i < 15; i++ {
+		msb := int32(dividend) < 0
+		dividend <<= 1
+		if msb {
+			dividend -= hdivisor
+			continue
+		}
+		cycles += 4
+		if dividend >= hdivisor {
+			dividend -= hdivisor
+			cycles -= 2
+		}
+	}
+	return cycles
+}
+
 // --- DIVS ---
 
 func registerDIVS() {
@@ -990,15 +1057,19 @@ func makeDIVS(dn, mode, reg uint16) opFunc {
 	return func(c *CPU) {
 		divisor := int32(int16(read(c, sizeWord)))
 		if divisor == 0 {
-			c.exception(vecDivideByZero)
+			c.trapEA(vecDivideByZero, eaBase)
 			return
 		}
 		dividend := int32(c.reg.D[dn])
 		quotient := dividend / divisor
 		remainder := dividend % divisor
+		// Overflow leaves the register and X alone and, as for DIVU,
+		// sets N and V and clears Z and C.
 		if quotient > 32767 || quotient < -32768 {
+			old := c.reg.SR
 			c.reg.SR |= flagV | flagN
 			c.reg.SR &^= flagC | flagZ
+			c.keepUndefined(old, flagN|flagZ)
 		} else {
 			c.reg.D[dn] = uint32(remainder&0xFFFF)<<16 | uint32(quotient)&0xFFFF
 			c.setFlagsLogical(uint32(quotient), sizeWord)
@@ -1010,6 +1081,154 @@ func makeDIVS(dn, mode, reg uint16) opFunc {
 	}
 }
 
+// --- MULU.L / MULS.L (68020) ---
+
+// Cycle counts approximating the MC68020 cache case times of the long
+// multiply and divide forms, without EA calculation time.
+const (
+	mulLCycles  = 43
+	divuLCycles = 78
+	divsLCycles = 90
+)
+
+// registerMULL registers MULU.L and MULS.L for the data addressing modes.
+// Encoding: 0100 1100 00 MMM RRR
+// Extension word: 0 | Dl(3) | S | Sz | 000 0000 | Dh(3)
+// S selects MULS.L; Sz selects the 64-bit product in Dh:Dl, otherwise the
+// low 32 bits go to Dl and V reports whether the product overflowed them.
+// On the 68000 and 68010 the opcodes are illegal instructions.
+func registerMULL() {
+	for mode := uint16(0); mode < 8; mode++ {
+		if mode == 1 {
+			continue
+		}
+		for reg := uint16(0); reg < 8; reg++ {
+			if mode == 7 && reg > 4 {
+				continue
+			}
+			opcodeTable[0x4C00|mode<<3|reg] = makeMULL(mode, reg)
+		}
+	}
+}
+
+func makeMULL(mode, reg uint16) opFunc {
+	read := makeEARead(mode, reg)
+	return func(c *CPU) {
+		if c.variant < MC68020 {
+			c.exception(vecIllegalInstruction)
+			return
+		}
+		ext := c.fetchPC()
+		dl, dh := (ext>>12)&7, ext&7
+		s := read(c, sizeLong)
+		d := c.reg.D[dl]
+
+		var result uint64
+		var overflow bool
+		if ext&0x0800 != 0 {
+			p := int64(int32(s)) * int64(int32(d))
+			result, overflow = uint64(p), p != int64(int32(p))
+		} else {
+			result = uint64(s) * uint64(d)
+			overflow = result>>32 != 0
+		}
+
+		c.reg.SR &^= flagN | flagZ | flagV | flagC
+		if ext&0x0400 != 0 {
+			c.reg.D[dl] = uint32(result)
+			c.reg.D[dh] = uint32(result >> 32)
+			if result == 0 {
+				c.reg.SR |= flagZ
+			}
+			if int64(result) < 0 {
+				c.reg.SR |= flagN
+			}
+		} else {
+			c.reg.D[dl] = uint32(result)
+			c.setFlagsLogical(uint32(result), sizeLong)
+			if overflow {
+				c.reg.SR |= flagV
+			}
+		}
+		c.cycles += mulLCycles
+	}
+}
+
+// --- DIVU.L / DIVS.L (68020) ---
+
+// registerDIVL registers DIVU.L and DIVS.L for the data addressing modes.
+// Encoding: 0100 1100 01 MMM RRR
+// Extension word: 0 | Dq(3) | S | Sz | 000 0000 | Dr(3)
+// S selects DIVS.L. Sz divides the 64-bit Dr:Dq, otherwise the 32-bit Dq;
+// the quotient goes to Dq and the remainder to Dr, or is discarded when Dr
+// and Dq are the same register.
+// On the 68000 and 68010 the opcodes are illegal instructions.
+func registerDIVL() {
+	for mode := uint16(0); mode < 8; mode++ {
+		if mode == 1 {
+			continue
+		}
+		for reg := uint16(0); reg < 8; reg++ {
+			if mode == 7 && reg > 4 {
+				continue
+			}
+			opcodeTable[0x4C40|mode<<3|reg] = makeDIVL(mode, reg)
+		}
+	}
+}
+
+func makeDIVL(mode, reg uint16) opFunc {
+	read := makeEARead(mode, reg)
+	return func(c *CPU) {
+		if c.variant < MC68020 {
+			c.exception(vecIllegalInstruction)
+			return
+		}
+		ext := c.fetchPC()
+		dq, dr := (ext>>12)&7, ext&7
+		divisor := read(c, sizeLong)
+		if divisor == 0 {
+			c.trapEA(vecDivideByZero, 0)
+			return
+		}
+
+		dividend := uint64(c.reg.D[dq])
+		if ext&0x0400 != 0 {
+			dividend |= uint64(c.reg.D[dr]) << 32
+		}
+
+		var quotient, remainder uint32
+		var overflow bool
+		cycles := uint64(divuLCycles)
+		if ext&0x0800 != 0 {
+			n := int64(dividend)
+			if ext&0x0400 == 0 {
+				n = int64(int32(dividend))
+			}
+			q := n / int64(int32(divisor))
+			quotient, remainder = uint32(q), uint32(n%int64(int32(divisor)))
+			overflow = q != int64(int32(q))
+			cycles = divsLCycles
+		} else {
+			q := dividend / uint64(divisor)
+			quotient, remainder = uint32(q), uint32(dividend%uint64(divisor))
+			overflow = q>>32 != 0
+		}
+
+		// On overflow the registers are left unchanged; N and Z are
+		// undefined and left alone.
+		if overflow {
+			c.reg.SR |= flagV
+			c.reg.SR &^= flagC
+		} else {
+			c.reg.D[dr] = remainder
+			c.reg.D[dq] = quotient
+			c.setFlagsLogical(quotient, sizeLong)
+		}
+		c.cycles += cycles
+	}
+}
+
 // --- NEG ---
 
 func registerNEG() {
@@ -1169,6 +1388,8 @@ func makeCLR(mode, reg uint16) opFunc {
 	return func(c *CPU) {
 		sz := sizeEncoding((c.ir >> 6) & 3)
 		a := addr(c, sz)
+		// The 68000 reads the operand before clearing it.
+		c.readBus(sz, a)
 		c.writeBus(sz, a, 0)
 		c.reg.SR &^= flagN | flagV | flagC
 		c.reg.SR |= flagZ
@@ -1234,21 +1455,102 @@ func makeCHK(dn, mode, reg uint16) opFunc {
 	return func(c *CPU) {
 		bound := int16(read(c, sizeWord))
 		val := int16(c.reg.D[dn] & 0xFFFF)
+		// Only N is defined, and only when CHK traps.
+		old := c.reg.SR
+		// Trap-taken timing includes exception processing. The pushed PC
+		// is the address of the instruction following CHK.
 		if val < 0 {
 			c.reg.SR &^= flagN | flagZ | flagV | flagC
 			c.reg.SR |= flagN
-			c.exception(vecCHK)
+			c.keepUndefined(old, flagZ|flagV|flagC)
+			c.trapEA(vecCHK, eaBase)
 			return
 		}
 		if val > bound {
+			// The upper bound trap is decided two cycles sooner.
 			c.reg.SR &^= flagN | flagZ | flagV | flagC
-			c.exception(vecCHK)
+			c.keepUndefined(old, flagZ|flagV|flagC)
+			c.processException(vecCHK, excCHKCycles-2+eaBase)
 			return
 		}
 		c.setFlagsCmp(uint32(val), uint32(bound), uint32(bound-val), sizeWord)
+		c.keepUndefined(old, flagN|flagZ|flagV|flagC)
 		c.cycles += 10 + eaBase
 		if sizeWord == sizeLong {
 			c.cycles += eaLong
 		}
 	}
 }
+
+// --- CHK2 / CMP2 (68020) ---
+
+// chk2Cycles approximates the MC68020 cache case time of CHK2 and CMP2,
+// without EA calculation time.
+const chk2Cycles = 16
+
+// registerCHK2 registers CHK2 <ea>,Rn and CMP2 <ea>,Rn for the control
+// addressing modes.
+// Encoding: 0000 0ss0 11 MMM RRR, ss = 00 byte, 01 word, 10 long
+// Extension word: D/A | Reg(3) | CHK2 | 000 0000 0000
+// On the 68000 and 68010 the opcodes are illegal instructions.
+func registerCHK2() {
+	for szBits := uint16(0); szBits < 3; szBits++ {
+		sz := sizeEncoding(szBits)
+		for mode := uint16(2); mode < 8; mode++ {
+			if mode == 3 || mode == 4 {
+				continue
+			}
+			for reg := uint16(0); reg < 8; reg++ {
+				if mode == 7 && reg > 3 {
+					continue
+				}
+				opcodeTable[0x00C0|szBits<<9|mode<<3|reg] = makeCHK2(sz, mode, reg)
+			}
+		}
+	}
+}
+
+// makeCHK2 compares Rn against the lower bound at <ea> and the upper bound
+// that follows it. Z is set when Rn equals either bound and C when it lies
+// outside them; CHK2 then takes a CHK exception. An address register is
+// compared in full against bounds sign-extended to a long. Rn is in bounds
+// when Rn - lower <= upper - lower as unsigned values, which holds for
+// signed and unsigned bound pairs alike.
+func makeCHK2(sz size, mode, reg uint16) opFunc {
+	addr := makeEAMemAddr(mode, reg)
+	return func(c *CPU) {
+		if c.variant < MC68020 {
+			c.exception(vecIllegalInstruction)
+			return
+		}
+		ext := c.fetchPC()
+		rn := (ext >> 12) & 7
+		a := addr(c, sz)
+		lower := c.readBus(sz, a)
+		upper := c.readBus(sz, a+uint32(sz))
+
+		mask := sz.Mask()
+		var val uint32
+		if ext&0x8000 != 0 {
+			val = c.reg.A[rn]
+			lower = sz.SignExtend(lower)
+			upper = sz.SignExtend(upper)
+			mask = 0xFFFFFFFF
+		} else {
+			val = c.reg.D[rn] & mask
+		}
+
+		c.reg.SR &^= flagZ | flagC
+		if val == lower || val == upper {
+			c.reg.SR |= flagZ
+		}
+		if (val-lower)&mask > (upper-lower)&mask {
+			c.reg.SR |= flagC
+			if ext&0x0800 != 0 {
+				c.trapEA(vecCHK, chk2Cycles)
+				return
+			}
+		}
+		c.cycles += chk2Cycles
+	}
+}
@@ -430,14 +430,13 @@ func TestADD_W(t *testing.T) {
 				RAM: [][2]uint32{{11687734, 209}, {11687735, 100}, {11687736, 241}, {11687737, 51}, {12, 104}, {13, 76}, {14, 131}, {15, 34}, {5014306, 188}, {5014307, 99}, {5014308, 64}, {5014309, 111}},
 			},
 			want: cpuState{
-				D:      [8]uint32{478702470, 1340516552, 2892925863, 760846340, 103963887, 2570774856, 2956880464, 3815113657},
-				A:      [7]uint32{2894363122, 619251031, 1290920016, 943671690, 768153819, 790541136, 2818650093},
-				PC:     1749844774,
-				SR:     9232,
-				USP:    38220,
-				SSP:    8455892,
-				RAM:    [][2]uint32{{11687734, 209}, {11687735, 100}, {11687736, 241}, {11687737, 51}, {8455904, 87}, {8455905, 58}, {8455900, 4}, {8455901, 16}, {8455902, 0}, {8455903, 178}, {8455898, 209}, {8455899, 100}, {8455896, 24}, {8455897, 219}, {8455892, 209}, {8455893, 113}, {8455894, 45}, {8455895, 201}, {12, 104}, {13, 76}, {14, 131}, {15, 34}, {5014306, 188}, {5014307, 99}, {5014308, 64}, {5014309, 111}},
-				Halted: true,
+				D:   [8]uint32{478702470, 1340516552, 2892925863, 760846340, 103963887, 2570774856, 2956880464, 3815113657},
+				A:   [7]uint32{2894363122, 619251031, 1290920016, 943671690, 768153819, 790541136, 2818650093},
+				PC:  1749844774,
+				SR:  9232,
+				USP: 38220,
+				SSP: 8455892,
+				RAM: [][2]uint32{{11687734, 209}, {11687735, 100}, {11687736, 241}, {11687737, 51}, {8455904, 87}, {8455905, 58}, {8455900, 4}, {8455901, 16}, {8455902, 0}, {8455903, 178}, {8455898, 209}, {8455899, 100}, {8455896, 24}, {8455897, 219}, {8455892, 209}, {8455893, 113}, {8455894, 45}, {8455895, 201}, {12, 104}, {13, 76}, {14, 131}, {15, 34}, {5014306, 188}, {5014307, 99}, {5014308, 64}, {5014309, 111}},
 			},
 		},
 		{
@@ -664,14 +663,13 @@ func TestADD_L(t *testing.T) {
 				RAM: [][2]uint32{{3065348, 219}, {3065349, 179}, {3065350, 12}, {3065351, 79}, {3065352, 6}, {3065353, 183}, {12, 224}, {13, 16}, {14, 233}, {15, 22}, {1108246, 10}, {1108247, 58}, {1108248, 220}, {1108249, 238}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2716418231, 4207376922, 2543932834, 4262317947, 3724476184, 22002888, 386349136, 3381172759},
-				A:      [7]uint32{2874603247, 2869405373, 1615831200, 1045252699, 2138434487, 709118711, 3722715766},
-				PC:     3759204634,
-				SR:     8979,
-				USP:    14939940,
-				SSP:    4539038,
-				RAM:    [][2]uint32{{3065348, 219}, {3065349, 179}, {3065350, 12}, {3065351, 79}, {3065352, 6}, {3065353, 183}, {4539050, 198}, {4539051, 6}, {4539046, 35}, {4539047, 19}, {4539048, 0}, {4539049, 46}, {4539044, 219}, {4539045, 179}, {4539042, 139}, {4539043, 97}, {4539038, 219}, {4539039, 181}, {4539040, 224}, {4539041, 54}, {12, 224}, {13, 16}, {14, 233}, {15, 22}, {1108246, 10}, {1108247, 58}, {1108248, 220}, {1108249, 238}},
-				Halted: true,
+				D:   [8]uint32{2716418231, 4207376922, 2543932834, 4262317947, 3724476184, 22002888, 386349136, 3381172759},
+				A:   [7]uint32{2874603247, 2869405373, 1615831200, 1045252699, 2138434487, 709118711, 3722715766},
+				PC:  3759204634,
+				SR:  8979,
+				USP: 14939940,
+				SSP: 4539038,
+				RAM: [][2]uint32{{3065348, 219}, {3065349, 179}, {3065350, 12}, {3065351, 79}, {3065352, 6}, {3065353, 183}, {4539050, 198}, {4539051, 6}, {4539046, 35}, {4539047, 19}, {4539048, 0}, {4539049, 46}, {4539044, 219}, {4539045, 179}, {4539042, 139}, {4539043, 97}, {4539038, 219}, {4539039, 181}, {4539040, 224}, {4539041, 54}, {12, 224}, {13, 16}, {14, 233}, {15, 22}, {1108246, 10}, {1108247, 58}, {1108248, 220}, {1108249, 238}},
 			},
 		},
 		{
@@ -730,14 +728,13 @@ func TestADD_L(t *testing.T) {
 				RAM: [][2]uint32{{8040036, 218}, {8040037, 152}, {8040038, 34}, {8040039, 236}, {12, 32}, {13, 58}, {14, 223}, {15, 18}, {3858194, 0}, {3858195, 181}, {3858196, 199}, {3858197, 69}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1546755666, 3978331449, 292994626, 2574451037, 2483638804, 525818923, 1449235995, 2779746162},
-				A:      [7]uint32{1387516899, 1905346324, 1200303954, 1497033632, 1983392107, 403827175, 3371803439},
-				PC:     540729110,
-				SR:     9477,
-				USP:    9588392,
-				SSP:    6158436,
-				RAM:    [][2]uint32{{8040036, 218}, {8040037, 152}, {8040038, 34}, {8040039, 236}, {6158448, 174}, {6158449, 102}, {6158444, 37}, {6158445, 5}, {6158446, 0}, {6158447, 122}, {6158442, 218}, {6158443, 152}, {6158440, 211}, {6158441, 227}, {6158436, 218}, {6158437, 149}, {6158438, 82}, {6158439, 179}, {12, 32}, {13, 58}, {14, 223}, {15, 18}, {3858194, 0}, {3858195, 181}, {3858196, 199}, {3858197, 69}},
-				Halted: true,
+				D:   [8]uint32{1546755666, 3978331449, 292994626, 2574451037, 2483638804, 525818923, 1449235995, 2779746162},
+				A:   [7]uint32{1387516899, 1905346324, 1200303954, 1497033632, 1983392107, 403827175, 3371803439},
+				PC:  540729110,
+				SR:  9477,
+				USP: 9588392,
+				SSP: 6158436,
+				RAM: [][2]uint32{{8040036, 218}, {8040037, 152}, {8040038, 34}, {8040039, 236}, {6158448, 174}, {6158449, 102}, {6158444, 37}, {6158445, 5}, {6158446, 0}, {6158447, 122}, {6158442, 218}, {6158443, 152}, {6158440, 211}, {6158441, 227}, {6158436, 218}, {6158437, 149}, {6158438, 82}, {6158439, 179}, {12, 32}, {13, 58}, {14, 223}, {15, 18}, {3858194, 0}, {3858195, 181}, {3858196, 199}, {3858197, 69}},
 			},
 		},
 		{
@@ -752,14 +749,13 @@ func TestADD_L(t *testing.T) {
 				RAM: [][2]uint32{{370026, 6}, {370027, 144}, {370028, 228}, {370029, 172}, {370030, 54}, {370031, 91}, {370032, 81}, {370033, 172}, {12, 41}, {13, 132}, {14, 198}, {15, 152}, {8701592, 170}, {8701593, 142}, {8701594, 173}, {8701595, 11}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1559146838, 3539592242, 3548872570, 963141589, 4011367675, 1828380738, 4264968104, 764381064},
-				A:      [7]uint32{349926689, 3406555350, 1647720366, 3609071408, 3111363858, 1523308046, 3279236371},
-				PC:     696567452,
-				SR:     8206,
-				USP:    13506444,
-				SSP:    190458,
-				RAM:    [][2]uint32{{370026, 6}, {370027, 144}, {370028, 228}, {370029, 172}, {370030, 54}, {370031, 91}, {370032, 81}, {370033, 172}, {190470, 165}, {190471, 112}, {190466, 32}, {190467, 14}, {190468, 0}, {190469, 5}, {190464, 6}, {190465, 144}, {190462, 117}, {190463, 33}, {190458, 6}, {190459, 149}, {190460, 20}, {190461, 219}, {12, 41}, {13, 132}, {14, 198}, {15, 152}, {8701592, 170}, {8701593, 142}, {8701594, 173}, {8701595, 11}},
-				Halted: true,
+				D:   [8]uint32{1559146838, 3539592242, 3548872570, 963141589, 4011367675, 1828380738, 4264968104, 764381064},
+				A:   [7]uint32{349926689, 3406555350, 1647720366, 3609071408, 3111363858, 1523308046, 3279236371},
+				PC:  696567452,
+				SR:  8206,
+				USP: 13506444,
+				SSP: 190458,
+				RAM: [][2]uint32{{370026, 6}, {370027, 144}, {370028, 228}, {370029, 172}, {370030, 54}, {370031, 91}, {370032, 81}, {370033, 172}, {190470, 165}, {190471, 112}, {190466, 32}, {190467, 14}, {190468, 0}, {190469, 5}, {190464, 6}, {190465, 144}, {190462, 117}, {190463, 33}, {190458, 6}, {190459, 149}, {190460, 20}, {190461, 219}, {12, 41}, {13, 132}, {14, 198}, {15, 152}, {8701592, 170}, {8701593, 142}, {8701594, 173}, {8701595, 11}},
 			},
 		},
 		{
@@ -986,14 +982,13 @@ func TestADDA_W(t *testing.T) {
 				RAM: [][2]uint32{{9681618, 212}, {9681619, 232}, {9681620, 136}, {9681621, 78}, {9681622, 154}, {9681623, 99}, {12, 30}, {13, 124}, {14, 113}, {15, 224}, {8155616, 59}, {8155617, 190}, {8155618, 55}, {8155619, 219}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3597950942, 3391415435, 3445088799, 1597919559, 294870065, 1337499212, 1126913713, 2248054717},
-				A:      [7]uint32{1497914091, 2386817744, 579384692, 2821960988, 3155809731, 2473268795, 2208773622},
-				PC:     511472100,
-				SR:     10007,
-				USP:    481542,
-				SSP:    10933710,
-				RAM:    [][2]uint32{{9681618, 212}, {9681619, 232}, {9681620, 136}, {9681621, 78}, {9681622, 154}, {9681623, 99}, {10933722, 186}, {10933723, 212}, {10933718, 135}, {10933719, 23}, {10933720, 0}, {10933721, 147}, {10933716, 212}, {10933717, 232}, {10933714, 227}, {10933715, 57}, {10933710, 212}, {10933711, 241}, {10933712, 89}, {10933713, 71}, {12, 30}, {13, 124}, {14, 113}, {15, 224}, {8155616, 59}, {8155617, 190}, {8155618, 55}, {8155619, 219}},
-				Halted: true,
+				D:   [8]uint32{3597950942, 3391415435, 3445088799, 1597919559, 294870065, 1337499212, 1126913713, 2248054717},
+				A:   [7]uint32{1497914091, 2386817744, 579384692, 2821960988, 3155809731, 2473268795, 2208773622},
+				PC:  511472100,
+				SR:  10007,
+				USP: 481542,
+				SSP: 10933710,
+				RAM: [][2]uint32{{9681618, 212}, {9681619, 232}, {9681620, 136}, {9681621, 78}, {9681622, 154}, {9681623, 99}, {10933722, 186}, {10933723, 212}, {10933718, 135}, {10933719, 23}, {10933720, 0}, {10933721, 147}, {10933716, 212}, {10933717, 232}, {10933714, 227}, {10933715, 57}, {10933710, 212}, {10933711, 241}, {10933712, 89}, {10933713, 71}, {12, 30}, {13, 124}, {14, 113}, {15, 224}, {8155616, 59}, {8155617, 190}, {8155618, 55}, {8155619, 219}},
 			},
 		},
 		{
@@ -1030,14 +1025,13 @@ func TestADDA_W(t *testing.T) {
 				RAM: [][2]uint32{{12860022, 216}, {12860023, 247}, {12860024, 251}, {12860025, 185}, {12860026, 85}, {12860027, 105}, {12, 231}, {13, 4}, {14, 111}, {15, 164}, {290724, 240}, {290725, 53}, {290726, 85}, {290727, 175}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3848523572, 3152832300, 732298797, 1686405678, 2759647777, 3002253350, 3241958134, 4280890333},
-				A:      [7]uint32{3011731805, 2936270313, 4131770887, 67293818, 2610481305, 3196519856, 2491756737},
-				PC:     3875827624,
-				SR:     8724,
-				USP:    8027608,
-				SSP:    3304248,
-				RAM:    [][2]uint32{{12860022, 216}, {12860023, 247}, {12860024, 251}, {12860025, 185}, {12860026, 85}, {12860027, 105}, {3304260, 58}, {3304261, 120}, {3304256, 2}, {3304257, 20}, {3304258, 0}, {3304259, 196}, {3304254, 216}, {3304255, 247}, {3304252, 251}, {3304253, 105}, {3304248, 216}, {3304249, 241}, {3304250, 0}, {3304251, 244}, {12, 231}, {13, 4}, {14, 111}, {15, 164}, {290724, 240}, {290725, 53}, {290726, 85}, {290727, 175}},
-				Halted: true,
+				D:   [8]uint32{3848523572, 3152832300, 732298797, 1686405678, 2759647777, 3002253350, 3241958134, 4280890333},
+				A:   [7]uint32{3011731805, 2936270313, 4131770887, 67293818, 2610481305, 3196519856, 2491756737},
+				PC:  3875827624,
+				SR:  8724,
+				USP: 8027608,
+				SSP: 3304248,
+				RAM: [][2]uint32{{12860022, 216}, {12860023, 247}, {12860024, 251}, {12860025, 185}, {12860026, 85}, {12860027, 105}, {3304260, 58}, {3304261, 120}, {3304256, 2}, {3304257, 20}, {3304258, 0}, {3304259, 196}, {3304254, 216}, {3304255, 247}, {3304252, 251}, {3304253, 105}, {3304248, 216}, {3304249, 241}, {3304250, 0}, {3304251, 244}, {12, 231}, {13, 4}, {14, 111}, {15, 164}, {290724, 240}, {290725, 53}, {290726, 85}, {290727, 175}},
 			},
 		},
 		{
@@ -1352,14 +1346,13 @@ func TestADDA_L(t *testing.T) {
 				RAM: [][2]uint32{{1819314, 215}, {1819315, 228}, {1819316, 230}, {1819317, 1}, {12, 21}, {13, 98}, {14, 204}, {15, 80}, {6474832, 25}, {6474833, 70}, {6474834, 68}, {6474835, 63}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1014014963, 1927771678, 383380099, 1616418368, 101374157, 1392850973, 996040081, 3782587050},
-				A:      [7]uint32{1957885287, 2124181366, 2911731850, 341525497, 2637370927, 3171271031, 3677820347},
-				PC:     358796372,
-				SR:     9992,
-				USP:    879188,
-				SSP:    10576802,
-				RAM:    [][2]uint32{{1819314, 215}, {1819315, 228}, {1819316, 230}, {1819317, 1}, {10576814, 194}, {10576815, 180}, {10576810, 135}, {10576811, 8}, {10576812, 0}, {10576813, 27}, {10576808, 215}, {10576809, 228}, {10576806, 22}, {10576807, 47}, {10576802, 215}, {10576803, 241}, {10576804, 157}, {10576805, 51}, {12, 21}, {13, 98}, {14, 204}, {15, 80}, {6474832, 25}, {6474833, 70}, {6474834, 68}, {6474835, 63}},
-				Halted: true,
+				D:   [8]uint32{1014014963, 1927771678, 383380099, 1616418368, 101374157, 1392850973, 996040081, 3782587050},
+				A:   [7]uint32{1957885287, 2124181366, 2911731850, 341525497, 2637370927, 3171271031, 3677820347},
+				PC:  358796372,
+				SR:  9992,
+				USP: 879188,
+				SSP: 10576802,
+				RAM: [][2]uint32{{1819314, 215}, {1819315, 228}, {1819316, 230}, {1819317, 1}, {10576814, 194}, {10576815, 180}, {10576810, 135}, {10576811, 8}, {10576812, 0}, {10576813, 27}, {10576808, 215}, {10576809, 228}, {10576806, 22}, {10576807, 47}, {10576802, 215}, {10576803, 241}, {10576804, 157}, {10576805, 51}, {12, 21}, {13, 98}, {14, 204}, {15, 80}, {6474832, 25}, {6474833, 70}, {6474834, 68}, {6474835, 63}},
 			},
 		},
 		{
@@ -1952,14 +1945,13 @@ func TestADDX_W(t *testing.T) {
 				RAM: [][2]uint32{{8735900, 209}, {8735901, 73}, {8735902, 35}, {8735903, 66}, {12, 79}, {13, 122}, {14, 194}, {15, 70}, {8045126, 176}, {8045127, 215}, {8045128, 230}, {8045129, 159}},
 			},
 			want: cpuState{
-				D:      [8]uint32{4224040949, 2022320032, 1925160569, 1739841148, 3920638392, 3871974204, 1202770696, 1059347476},
-				A:      [7]uint32{1919114651, 2948124419, 3822682899, 2814065292, 1532911305, 1463532462, 69421316},
-				PC:     1333445194,
-				SR:     8223,
-				USP:    7862948,
-				SSP:    6686574,
-				RAM:    [][2]uint32{{8735900, 209}, {8735901, 73}, {8735902, 35}, {8735903, 66}, {6686586, 76}, {6686587, 160}, {6686582, 32}, {6686583, 31}, {6686584, 0}, {6686585, 133}, {6686580, 209}, {6686581, 73}, {6686578, 207}, {6686579, 3}, {6686574, 209}, {6686575, 85}, {6686576, 175}, {6686577, 184}, {12, 79}, {13, 122}, {14, 194}, {15, 70}, {8045126, 176}, {8045127, 215}, {8045128, 230}, {8045129, 159}},
-				Halted: true,
+				D:   [8]uint32{4224040949, 2022320032, 1925160569, 1739841148, 3920638392, 3871974204, 1202770696, 1059347476},
+				A:   [7]uint32{1919114651, 2948124419, 3822682899, 2814065292, 1532911305, 1463532462, 69421316},
+				PC:  1333445194,
+				SR:  8223,
+				USP: 7862948,
+				SSP: 6686574,
+				RAM: [][2]uint32{{8735900, 209}, {8735901, 73}, {8735902, 35}, {8735903, 66}, {6686586, 76}, {6686587, 160}, {6686582, 32}, {6686583, 31}, {6686584, 0}, {6686585, 133}, {6686580, 209}, {6686581, 73}, {6686578, 207}, {6686579, 3}, {6686574, 209}, {6686575, 85}, {6686576, 175}, {6686577, 184}, {12, 79}, {13, 122}, {14, 194}, {15, 70}, {8045126, 176}, {8045127, 215}, {8045128, 230}, {8045129, 159}},
 			},
 		},
 		{
@@ -1996,14 +1988,13 @@ func TestADDX_W(t *testing.T) {
 				RAM: [][2]uint32{{12125188, 217}, {12125189, 72}, {12125190, 44}, {12125191, 204}, {7311742, 123}, {7311743, 67}, {12, 194}, {13, 122}, {14, 49}, {15, 54}, {8007990, 84}, {8007991, 0}, {8007992, 196}, {8007993, 224}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3991951231, 1579443155, 3900364942, 2563991609, 2015557299, 3201390280, 3989341645, 29201898},
-				A:      [7]uint32{913281406, 961767058, 47615166, 983406455, 2596001433, 1193193539, 2390382697},
-				PC:     3262787898,
-				SR:     10000,
-				USP:    5779338,
-				SSP:    11316558,
-				RAM:    [][2]uint32{{12125188, 217}, {12125189, 72}, {12125190, 44}, {12125191, 204}, {7311742, 123}, {7311743, 67}, {11316570, 4}, {11316571, 8}, {11316566, 39}, {11316567, 16}, {11316568, 0}, {11316569, 185}, {11316564, 217}, {11316565, 72}, {11316562, 214}, {11316563, 153}, {11316558, 217}, {11316559, 85}, {11316560, 154}, {11316561, 187}, {12, 194}, {13, 122}, {14, 49}, {15, 54}, {8007990, 84}, {8007991, 0}, {8007992, 196}, {8007993, 224}},
-				Halted: true,
+				D:   [8]uint32{3991951231, 1579443155, 3900364942, 2563991609, 2015557299, 3201390280, 3989341645, 29201898},
+				A:   [7]uint32{913281406, 961767058, 47615166, 983406455, 2596001433, 1193193539, 2390382697},
+				PC:  3262787898,
+				SR:  10000,
+				USP: 5779338,
+				SSP: 11316558,
+				RAM: [][2]uint32{{12125188, 217}, {12125189, 72}, {12125190, 44}, {12125191, 204}, {7311742, 123}, {7311743, 67}, {11316570, 4}, {11316571, 8}, {11316566, 39}, {11316567, 16}, {11316568, 0}, {11316569, 185}, {11316564, 217}, {11316565, 72}, {11316562, 214}, {11316563, 153}, {11316558, 217}, {11316559, 85}, {11316560, 154}, {11316561, 187}, {12, 194}, {13, 122}, {14, 49}, {15, 54}, {8007990, 84}, {8007991, 0}, {8007992, 196}, {8007993, 224}},
 			},
 		},
 		{
@@ -2318,14 +2309,13 @@ func TestADDX_L(t *testing.T) {
 				RAM: [][2]uint32{{4497946, 213}, {4497947, 142}, {4497948, 177}, {4497949, 254}, {1342028, 191}, {1342029, 4}, {1342026, 56}, {1342027, 2}, {12, 187}, {13, 136}, {14, 37}, {15, 224}, {8922592, 38}, {8922593, 64}, {8922594, 60}, {8922595, 203}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2746726150, 1945254889, 417643221, 2266878627, 2985751432, 2292996443, 186955863, 3616861156},
-				A:      [7]uint32{278975727, 461149493, 1675980301, 2331855053, 3036905593, 866750393, 152336970},
-				PC:     3146261988,
-				SR:     9218,
-				USP:    16613890,
-				SSP:    14511656,
-				RAM:    [][2]uint32{{4497946, 213}, {4497947, 142}, {4497948, 177}, {4497949, 254}, {1342028, 191}, {1342029, 4}, {1342026, 56}, {1342027, 2}, {14511668, 162}, {14511669, 30}, {14511664, 4}, {14511665, 2}, {14511666, 0}, {14511667, 68}, {14511662, 213}, {14511663, 142}, {14511660, 110}, {14511661, 11}, {14511656, 213}, {14511657, 145}, {14511658, 99}, {14511659, 229}, {12, 187}, {13, 136}, {14, 37}, {15, 224}, {8922592, 38}, {8922593, 64}, {8922594, 60}, {8922595, 203}},
-				Halted: true,
+				D:   [8]uint32{2746726150, 1945254889, 417643221, 2266878627, 2985751432, 2292996443, 186955863, 3616861156},
+				A:   [7]uint32{278975727, 461149493, 1675980301, 2331855053, 3036905593, 866750393, 152336970},
+				PC:  3146261988,
+				SR:  9218,
+				USP: 16613890,
+				SSP: 14511656,
+				RAM: [][2]uint32{{4497946, 213}, {4497947, 142}, {4497948, 177}, {4497949, 254}, {1342028, 191}, {1342029, 4}, {1342026, 56}, {1342027, 2}, {14511668, 162}, {14511669, 30}, {14511664, 4}, {14511665, 2}, {14511666, 0}, {14511667, 68}, {14511662, 213}, {14511663, 142}, {14511660, 110}, {14511661, 11}, {14511656, 213}, {14511657, 145}, {14511658, 99}, {14511659, 229}, {12, 187}, {13, 136}, {14, 37}, {15, 224}, {8922592, 38}, {8922593, 64}, {8922594, 60}, {8922595, 203}},
 			},
 		},
 		{
@@ -2362,14 +2352,13 @@ func TestADDX_L(t *testing.T) {
 				RAM: [][2]uint32{{4985322, 209}, {4985323, 143}, {4985324, 174}, {4985325, 217}, {15934416, 52}, {15934417, 66}, {15934414, 92}, {15934415, 201}, {12, 104}, {13, 170}, {14, 126}, {15, 194}, {11173570, 107}, {11173571, 39}, {11173572, 162}, {11173573, 131}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1488402042, 3546197818, 825194713, 2556981956, 3521454437, 2293392277, 3169881152, 4015812514},
-				A:      [7]uint32{3979553049, 1837613905, 1294803692, 449279801, 429588003, 2986754987, 1778440752},
-				PC:     1756004038,
-				SR:     9240,
-				USP:    8592258,
-				SSP:    15934400,
-				RAM:    [][2]uint32{{4985322, 209}, {4985323, 143}, {4985324, 174}, {4985325, 217}, {15934416, 52}, {15934417, 66}, {15934414, 92}, {15934415, 201}, {15934412, 17}, {15934413, 238}, {15934408, 36}, {15934409, 24}, {15934410, 0}, {15934411, 76}, {15934406, 209}, {15934407, 143}, {15934404, 41}, {15934405, 23}, {15934400, 209}, {15934401, 149}, {15934402, 237}, {15934403, 51}, {12, 104}, {13, 170}, {14, 126}, {15, 194}, {11173570, 107}, {11173571, 39}, {11173572, 162}, {11173573, 131}},
-				Halted: true,
+				D:   [8]uint32{1488402042, 3546197818, 825194713, 2556981956, 3521454437, 2293392277, 3169881152, 4015812514},
+				A:   [7]uint32{3979553049, 1837613905, 1294803692, 449279801, 429588003, 2986754987, 1778440752},
+				PC:  1756004038,
+				SR:  9240,
+				USP: 8592258,
+				SSP: 15934400,
+				RAM: [][2]uint32{{4985322, 209}, {4985323, 143}, {4985324, 174}, {4985325, 217}, {15934416, 52}, {15934417, 66}, {15934414, 92}, {15934415, 201}, {15934412, 17}, {15934413, 238}, {15934408, 36}, {15934409, 24}, {15934410, 0}, {15934411, 76}, {15934406, 209}, {15934407, 143}, {15934404, 41}, {15934405, 23}, {15934400, 209}, {15934401, 149}, {15934402, 237}, {15934403, 51}, {12, 104}, {13, 170}, {14, 126}, {15, 194}, {11173570, 107}, {11173571, 39}, {11173572, 162}, {11173573, 131}},
 			},
 		},
 		{
@@ -2918,14 +2907,13 @@ func TestSUB_W(t *testing.T) {
 				RAM: [][2]uint32{{11738164, 159}, {11738165, 120}, {11738166, 183}, {11738167, 203}, {11738168, 209}, {11738169, 234}, {12, 244}, {13, 36}, {14, 149}, {15, 136}, {2397576, 36}, {2397577, 33}, {2397578, 237}, {2397579, 237}},
 			},
 			want: cpuState{
-				D:      [8]uint32{778189525, 3625107800, 2162281962, 3042322923, 602243132, 131004613, 1295821721, 2828723018},
-				A:      [7]uint32{2402517068, 1417749771, 3823487136, 1208433966, 355320110, 4050864189, 844697989},
-				PC:     4096038284,
-				SR:     8457,
-				USP:    14659424,
-				SSP:    16745586,
-				RAM:    [][2]uint32{{11738164, 159}, {11738165, 120}, {11738166, 183}, {11738167, 203}, {11738168, 209}, {11738169, 234}, {16745598, 28}, {16745599, 56}, {16745594, 129}, {16745595, 9}, {16745596, 0}, {16745597, 179}, {16745592, 159}, {16745593, 120}, {16745590, 183}, {16745591, 203}, {16745586, 159}, {16745587, 113}, {16745588, 255}, {16745589, 255}, {12, 244}, {13, 36}, {14, 149}, {15, 136}, {2397576, 36}, {2397577, 33}, {2397578, 237}, {2397579, 237}},
-				Halted: true,
+				D:   [8]uint32{778189525, 3625107800, 2162281962, 3042322923, 602243132, 131004613, 1295821721, 2828723018},
+				A:   [7]uint32{2402517068, 1417749771, 3823487136, 1208433966, 355320110, 4050864189, 844697989},
+				PC:  4096038284,
+				SR:  8457,
+				USP: 14659424,
+				SSP: 16745586,
+				RAM: [][2]uint32{{11738164, 159}, {11738165, 120}, {11738166, 183}, {11738167, 203}, {11738168, 209}, {11738169, 234}, {16745598, 28}, {16745599, 56}, {16745594, 129}, {16745595, 9}, {16745596, 0}, {16745597, 179}, {16745592, 159}, {16745593, 120}, {16745590, 183}, {16745591, 203}, {16745586, 159}, {16745587, 113}, {16745588, 255}, {16745589, 255}, {12, 244}, {13, 36}, {14, 149}, {15, 136}, {2397576, 36}, {2397577, 33}, {2397578, 237}, {2397579, 237}},
 			},
 		},
 		{
@@ -2962,14 +2950,13 @@ func TestSUB_W(t *testing.T) {
 				RAM: [][2]uint32{{7658834, 149}, {7658835, 118}, {7658836, 164}, {7658837, 151}, {7658838, 248}, {7658839, 241}, {12, 237}, {13, 154}, {14, 143}, {15, 192}, {10129344, 250}, {10129345, 54}, {10129346, 187}, {10129347, 113}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3625249756, 3245171826, 930234665, 2446400959, 628581484, 1262082402, 2267988574, 4106991817},
-				A:      [7]uint32{3443608986, 1997088992, 383529149, 2454865281, 1372498769, 2042495561, 650617147},
-				PC:     3986329540,
-				SR:     9735,
-				USP:    1794264,
-				SSP:    1890686,
-				RAM:    [][2]uint32{{7658834, 149}, {7658835, 118}, {7658836, 164}, {7658837, 151}, {7658838, 248}, {7658839, 241}, {1890698, 221}, {1890699, 84}, {1890694, 38}, {1890695, 7}, {1890696, 0}, {1890697, 116}, {1890692, 149}, {1890693, 118}, {1890690, 209}, {1890691, 143}, {1890686, 149}, {1890687, 117}, {1890688, 38}, {1890689, 199}, {12, 237}, {13, 154}, {14, 143}, {15, 192}, {10129344, 250}, {10129345, 54}, {10129346, 187}, {10129347, 113}},
-				Halted: true,
+				D:   [8]uint32{3625249756, 3245171826, 930234665, 2446400959, 628581484, 1262082402, 2267988574, 4106991817},
+				A:   [7]uint32{3443608986, 1997088992, 383529149, 2454865281, 1372498769, 2042495561, 650617147},
+				PC:  3986329540,
+				SR:  9735,
+				USP: 1794264,
+				SSP: 1890686,
+				RAM: [][2]uint32{{7658834, 149}, {7658835, 118}, {7658836, 164}, {7658837, 151}, {7658838, 248}, {7658839, 241}, {1890698, 221}, {1890699, 84}, {1890694, 38}, {1890695, 7}, {1890696, 0}, {1890697, 116}, {1890692, 149}, {1890693, 118}, {1890690, 209}, {1890691, 143}, {1890686, 149}, {1890687, 117}, {1890688, 38}, {1890689, 199}, {12, 237}, {13, 154}, {14, 143}, {15, 192}, {10129344, 250}, {10129345, 54}, {10129346, 187}, {10129347, 113}},
 			},
 		},
 		{
@@ -3262,14 +3249,13 @@ func TestSUB_L(t *testing.T) {
 				RAM: [][2]uint32{{2744056, 144}, {2744057, 169}, {2744058, 250}, {2744059, 235}, {2744060, 170}, {2744061, 236}, {12, 123}, {13, 114}, {14, 91}, {15, 162}, {7494562, 19}, {7494563, 233}, {7494564, 189}, {7494565, 67}},
 			},
 			want: cpuState{
-				D:      [8]uint32{334203677, 312705136, 3898025512, 4292677127, 1400971187, 181492914, 817515606, 3825712596},
-				A:      [7]uint32{176575104, 3820522794, 960113371, 2718489097, 3678728455, 2696676619, 685288024},
-				PC:     2071092134,
-				SR:     9474,
-				USP:    1524878,
-				SSP:    11240876,
-				RAM:    [][2]uint32{{2744056, 144}, {2744057, 169}, {2744058, 250}, {2744059, 235}, {2744060, 170}, {2744061, 236}, {11240888, 222}, {11240889, 250}, {11240884, 5}, {11240885, 2}, {11240886, 0}, {11240887, 41}, {11240882, 144}, {11240883, 169}, {11240880, 136}, {11240881, 21}, {11240876, 144}, {11240877, 177}, {11240878, 227}, {11240879, 184}, {12, 123}, {13, 114}, {14, 91}, {15, 162}, {7494562, 19}, {7494563, 233}, {7494564, 189}, {7494565, 67}},
-				Halted: true,
+				D:   [8]uint32{334203677, 312705136, 3898025512, 4292677127, 1400971187, 181492914, 817515606, 3825712596},
+				A:   [7]uint32{176575104, 3820522794, 960113371, 2718489097, 3678728455, 2696676619, 685288024},
+				PC:  2071092134,
+				SR:  9474,
+				USP: 1524878,
+				SSP: 11240876,
+				RAM: [][2]uint32{{2744056, 144}, {2744057, 169}, {2744058, 250}, {2744059, 235}, {2744060, 170}, {2744061, 236}, {11240888, 222}, {11240889, 250}, {11240884, 5}, {11240885, 2}, {11240886, 0}, {11240887, 41}, {11240882, 144}, {11240883, 169}, {11240880, 136}, {11240881, 21}, {11240876, 144}, {11240877, 177}, {11240878, 227}, {11240879, 184}, {12, 123}, {13, 114}, {14, 91}, {15, 162}, {7494562, 19}, {7494563, 233}, {7494564, 189}, {7494565, 67}},
 			},
 		},
 		{
@@ -3306,14 +3292,13 @@ func TestSUB_L(t *testing.T) {
 				RAM: [][2]uint32{{16031104, 157}, {16031105, 150}, {16031106, 2}, {16031107, 139}, {12, 188}, {13, 32}, {14, 255}, {15, 146}, {2162578, 251}, {2162579, 82}, {2162580, 19}, {2162581, 71}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3733564301, 3722637472, 114559997, 3933481534, 2935905042, 2313870483, 1452650104, 3190890119},
-				A:      [7]uint32{139049803, 783282066, 656214, 921942992, 1484801290, 3818258180, 483380725},
-				PC:     3156279190,
-				SR:     10005,
-				USP:    16716632,
-				SSP:    11626016,
-				RAM:    [][2]uint32{{16031104, 157}, {16031105, 150}, {16031106, 2}, {16031107, 139}, {11626028, 157}, {11626029, 130}, {11626024, 167}, {11626025, 21}, {11626026, 0}, {11626027, 244}, {11626022, 157}, {11626023, 150}, {11626020, 205}, {11626021, 245}, {11626016, 157}, {11626017, 149}, {11626018, 28}, {11626019, 207}, {12, 188}, {13, 32}, {14, 255}, {15, 146}, {2162578, 251}, {2162579, 82}, {2162580, 19}, {2162581, 71}},
-				Halted: true,
+				D:   [8]uint32{3733564301, 3722637472, 114559997, 3933481534, 2935905042, 2313870483, 1452650104, 3190890119},
+				A:   [7]uint32{139049803, 783282066, 656214, 921942992, 1484801290, 3818258180, 483380725},
+				PC:  3156279190,
+				SR:  10005,
+				USP: 16716632,
+				SSP: 11626016,
+				RAM: [][2]uint32{{16031104, 157}, {16031105, 150}, {16031106, 2}, {16031107, 139}, {11626028, 157}, {11626029, 130}, {11626024, 167}, {11626025, 21}, {11626026, 0}, {11626027, 244}, {11626022, 157}, {11626023, 150}, {11626020, 205}, {11626021, 245}, {11626016, 157}, {11626017, 149}, {11626018, 28}, {11626019, 207}, {12, 188}, {13, 32}, {14, 255}, {15, 146}, {2162578, 251}, {2162579, 82}, {2162580, 19}, {2162581, 71}},
 			},
 		},
 		{
@@ -3650,14 +3635,13 @@ func TestSUBA_W(t *testing.T) {
 				RAM: [][2]uint32{{2189052, 146}, {2189053, 221}, {2189054, 242}, {2189055, 186}, {12, 58}, {13, 228}, {14, 187}, {15, 94}, {14990174, 251}, {14990175, 64}, {14990176, 202}, {14990177, 106}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1200094438, 2710464425, 1491532271, 3415499353, 1505559846, 3662795322, 1852107100, 1802724523},
-				A:      [7]uint32{2304171441, 4137259190, 1024336870, 3269010179, 3422266355, 742018201, 2420570018},
-				PC:     988068706,
-				SR:     8448,
-				USP:    9280120,
-				SSP:    4328618,
-				RAM:    [][2]uint32{{2189052, 146}, {2189053, 221}, {2189054, 242}, {2189055, 186}, {4328630, 102}, {4328631, 254}, {4328626, 129}, {4328627, 0}, {4328628, 0}, {4328629, 33}, {4328624, 146}, {4328625, 221}, {4328622, 76}, {4328623, 151}, {4328618, 146}, {4328619, 209}, {4328620, 44}, {4328621, 58}, {12, 58}, {13, 228}, {14, 187}, {15, 94}, {14990174, 251}, {14990175, 64}, {14990176, 202}, {14990177, 106}},
-				Halted: true,
+				D:   [8]uint32{1200094438, 2710464425, 1491532271, 3415499353, 1505559846, 3662795322, 1852107100, 1802724523},
+				A:   [7]uint32{2304171441, 4137259190, 1024336870, 3269010179, 3422266355, 742018201, 2420570018},
+				PC:  988068706,
+				SR:  8448,
+				USP: 9280120,
+				SSP: 4328618,
+				RAM: [][2]uint32{{2189052, 146}, {2189053, 221}, {2189054, 242}, {2189055, 186}, {4328630, 102}, {4328631, 254}, {4328626, 129}, {4328627, 0}, {4328628, 0}, {4328629, 33}, {4328624, 146}, {4328625, 221}, {4328622, 76}, {4328623, 151}, {4328618, 146}, {4328619, 209}, {4328620, 44}, {4328621, 58}, {12, 58}, {13, 228}, {14, 187}, {15, 94}, {14990174, 251}, {14990175, 64}, {14990176, 202}, {14990177, 106}},
 			},
 		},
 		{
@@ -3950,14 +3934,13 @@ func TestSUBA_L(t *testing.T) {
 				RAM: [][2]uint32{{6827906, 159}, {6827907, 219}, {6827908, 183}, {6827909, 74}, {12, 235}, {13, 196}, {14, 251}, {15, 24}, {12909336, 99}, {12909337, 153}, {12909338, 50}, {12909339, 37}},
 			},
 			want: cpuState{
-				D:      [8]uint32{4278707095, 3234980542, 852299093, 364256079, 3806006670, 160748904, 1313270789, 784579430},
-				A:      [7]uint32{3699481401, 2977221355, 823793567, 3976332257, 492966052, 4180531494, 216652224},
-				PC:     3955555100,
-				SR:     9476,
-				USP:    9251184,
-				SSP:    9116248,
-				RAM:    [][2]uint32{{6827906, 159}, {6827907, 219}, {6827908, 183}, {6827909, 74}, {9116260, 47}, {9116261, 132}, {9116256, 5}, {9116257, 4}, {9116258, 0}, {9116259, 104}, {9116254, 159}, {9116255, 219}, {9116252, 3}, {9116253, 225}, {9116248, 159}, {9116249, 209}, {9116250, 237}, {9116251, 2}, {12, 235}, {13, 196}, {14, 251}, {15, 24}, {12909336, 99}, {12909337, 153}, {12909338, 50}, {12909339, 37}},
-				Halted: true,
+				D:   [8]uint32{4278707095, 3234980542, 852299093, 364256079, 3806006670, 160748904, 1313270789, 784579430},
+				A:   [7]uint32{3699481401, 2977221355, 823793567, 3976332257, 492966052, 4180531494, 216652224},
+				PC:  3955555100,
+				SR:  9476,
+				USP: 9251184,
+				SSP: 9116248,
+				RAM: [][2]uint32{{6827906, 159}, {6827907, 219}, {6827908, 183}, {6827909, 74}, {9116260, 47}, {9116261, 132}, {9116256, 5}, {9116257, 4}, {9116258, 0}, {9116259, 104}, {9116254, 159}, {9116255, 219}, {9116252, 3}, {9116253, 225}, {9116248, 159}, {9116249, 209}, {9116250, 237}, {9116251, 2}, {12, 235}, {13, 196}, {14, 251}, {15, 24}, {12909336, 99}, {12909337, 153}, {12909338, 50}, {12909339, 37}},
 			},
 		},
 		{
@@ -3972,14 +3955,13 @@ func TestSUBA_L(t *testing.T) {
 				RAM: [][2]uint32{{6317084, 153}, {6317085, 233}, {6317086, 168}, {6317087, 64}, {6317088, 191}, {6317089, 138}, {12, 66}, {13, 224}, {14, 234}, {15, 32}, {14740000, 94}, {14740001, 156}, {14740002, 1}, {14740003, 164}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1991873915, 3961568039, 1651929968, 2575840779, 896837556, 2315102667, 842750628, 2507533045},
-				A:      [7]uint32{1833300072, 2976508747, 660631124, 495463360, 665171967, 1762331000, 3466448591},
-				PC:     1122036260,
-				SR:     9220,
-				USP:    11337178,
-				SSP:    6436042,
-				RAM:    [][2]uint32{{6317084, 153}, {6317085, 233}, {6317086, 168}, {6317087, 64}, {6317088, 191}, {6317089, 138}, {6436054, 100}, {6436055, 30}, {6436050, 164}, {6436051, 4}, {6436052, 0}, {6436053, 96}, {6436048, 153}, {6436049, 233}, {6436046, 147}, {6436047, 139}, {6436042, 153}, {6436043, 245}, {6436044, 177}, {6436045, 105}, {12, 66}, {13, 224}, {14, 234}, {15, 32}, {14740000, 94}, {14740001, 156}, {14740002, 1}, {14740003, 164}},
-				Halted: true,
+				D:   [8]uint32{1991873915, 3961568039, 1651929968, 2575840779, 896837556, 2315102667, 842750628, 2507533045},
+				A:   [7]uint32{1833300072, 2976508747, 660631124, 495463360, 665171967, 1762331000, 3466448591},
+				PC:  1122036260,
+				SR:  9220,
+				USP: 11337178,
+				SSP: 6436042,
+				RAM: [][2]uint32{{6317084, 153}, {6317085, 233}, {6317086, 168}, {6317087, 64}, {6317088, 191}, {6317089, 138}, {6436054, 100}, {6436055, 30}, {6436050, 164}, {6436051, 4}, {6436052, 0}, {6436053, 96}, {6436048, 153}, {6436049, 233}, {6436046, 147}, {6436047, 139}, {6436042, 153}, {6436043, 245}, {6436044, 177}, {6436045, 105}, {12, 66}, {13, 224}, {14, 234}, {15, 32}, {14740000, 94}, {14740001, 156}, {14740002, 1}, {14740003, 164}},
 			},
 		},
 		{
@@ -4550,14 +4532,13 @@ func TestSUBX_W(t *testing.T) {
 				RAM: [][2]uint32{{10310306, 145}, {10310307, 74}, {10310308, 101}, {10310309, 149}, {12, 113}, {13, 34}, {14, 72}, {15, 210}, {2246866, 48}, {2246867, 106}, {2246868, 137}, {2246869, 154}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2936823178, 7347522, 3773887089, 4003544569, 3057959006, 2070266061, 1523658904, 3894157454},
-				A:      [7]uint32{2682350763, 1452815909, 2769952261, 1459158443, 4178282268, 3078142158, 706093560},
-				PC:     1898072278,
-				SR:     9229,
-				USP:    4171798,
-				SSP:    4256156,
-				RAM:    [][2]uint32{{10310306, 145}, {10310307, 74}, {10310308, 101}, {10310309, 149}, {4256168, 82}, {4256169, 166}, {4256164, 4}, {4256165, 13}, {4256166, 0}, {4256167, 157}, {4256162, 145}, {4256163, 74}, {4256160, 30}, {4256161, 5}, {4256156, 145}, {4256157, 81}, {4256158, 165}, {4256159, 26}, {12, 113}, {13, 34}, {14, 72}, {15, 210}, {2246866, 48}, {2246867, 106}, {2246868, 137}, {2246869, 154}},
-				Halted: true,
+				D:   [8]uint32{2936823178, 7347522, 3773887089, 4003544569, 3057959006, 2070266061, 1523658904, 3894157454},
+				A:   [7]uint32{2682350763, 1452815909, 2769952261, 1459158443, 4178282268, 3078142158, 706093560},
+				PC:  1898072278,
+				SR:  9229,
+				USP: 4171798,
+				SSP: 4256156,
+				RAM: [][2]uint32{{10310306, 145}, {10310307, 74}, {10310308, 101}, {10310309, 149}, {4256168, 82}, {4256169, 166}, {4256164, 4}, {4256165, 13}, {4256166, 0}, {4256167, 157}, {4256162, 145}, {4256163, 74}, {4256160, 30}, {4256161, 5}, {4256156, 145}, {4256157, 81}, {4256158, 165}, {4256159, 26}, {12, 113}, {13, 34}, {14, 72}, {15, 210}, {2246866, 48}, {2246867, 106}, {2246868, 137}, {2246869, 154}},
 			},
 		},
 		{
@@ -4594,14 +4575,13 @@ func TestSUBX_W(t *testing.T) {
 				RAM: [][2]uint32{{15138638, 147}, {15138639, 75}, {15138640, 180}, {15138641, 87}, {12, 151}, {13, 160}, {14, 236}, {15, 228}, {10546404, 128}, {10546405, 31}, {10546406, 216}, {10546407, 24}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3992332493, 2953250120, 3581761513, 1360944680, 1036679635, 1848665135, 368980980, 1772969713},
-				A:      [7]uint32{1953424370, 199472263, 2900465183, 3331704017, 1205584150, 1112065161, 1755161018},
-				PC:     2543906024,
-				SR:     8704,
-				USP:    15746114,
-				SSP:    16484680,
-				RAM:    [][2]uint32{{15138638, 147}, {15138639, 75}, {15138640, 180}, {15138641, 87}, {16484692, 255}, {16484693, 82}, {16484688, 34}, {16484689, 0}, {16484690, 0}, {16484691, 230}, {16484686, 147}, {16484687, 75}, {16484684, 196}, {16484685, 209}, {16484680, 147}, {16484681, 85}, {16484682, 198}, {16484683, 149}, {12, 151}, {13, 160}, {14, 236}, {15, 228}, {10546404, 128}, {10546405, 31}, {10546406, 216}, {10546407, 24}},
-				Halted: true,
+				D:   [8]uint32{3992332493, 2953250120, 3581761513, 1360944680, 1036679635, 1848665135, 368980980, 1772969713},
+				A:   [7]uint32{1953424370, 199472263, 2900465183, 3331704017, 1205584150, 1112065161, 1755161018},
+				PC:  2543906024,
+				SR:  8704,
+				USP: 15746114,
+				SSP: 16484680,
+				RAM: [][2]uint32{{15138638, 147}, {15138639, 75}, {15138640, 180}, {15138641, 87}, {16484692, 255}, {16484693, 82}, {16484688, 34}, {16484689, 0}, {16484690, 0}, {16484691, 230}, {16484686, 147}, {16484687, 75}, {16484684, 196}, {16484685, 209}, {16484680, 147}, {16484681, 85}, {16484682, 198}, {16484683, 149}, {12, 151}, {13, 160}, {14, 236}, {15, 228}, {10546404, 128}, {10546405, 31}, {10546406, 216}, {10546407, 24}},
 			},
 		},
 		{
@@ -4850,14 +4830,13 @@ func TestSUBX_L(t *testing.T) {
 				RAM: [][2]uint32{{10788336, 149}, {10788337, 142}, {10788338, 143}, {10788339, 243}, {12, 50}, {13, 222}, {14, 157}, {15, 148}, {14589332, 87}, {14589333, 251}, {14589334, 193}, {14589335, 15}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3998716147, 1884058010, 347900656, 1574976611, 3082201317, 2227218840, 2511244533, 2551522518},
-				A:      [7]uint32{1723973144, 3968916633, 1403253225, 1895955939, 868571842, 764095775, 2031814423},
-				PC:     853450136,
-				SR:     8724,
-				USP:    10546882,
-				SSP:    4313360,
-				RAM:    [][2]uint32{{10788336, 149}, {10788337, 142}, {10788338, 143}, {10788339, 243}, {4313372, 157}, {4313373, 244}, {4313368, 34}, {4313369, 20}, {4313370, 0}, {4313371, 164}, {4313366, 149}, {4313367, 142}, {4313364, 7}, {4313365, 21}, {4313360, 149}, {4313361, 149}, {4313362, 121}, {4313363, 27}, {12, 50}, {13, 222}, {14, 157}, {15, 148}, {14589332, 87}, {14589333, 251}, {14589334, 193}, {14589335, 15}},
-				Halted: true,
+				D:   [8]uint32{3998716147, 1884058010, 347900656, 1574976611, 3082201317, 2227218840, 2511244533, 2551522518},
+				A:   [7]uint32{1723973144, 3968916633, 1403253225, 1895955939, 868571842, 764095775, 2031814423},
+				PC:  853450136,
+				SR:  8724,
+				USP: 10546882,
+				SSP: 4313360,
+				RAM: [][2]uint32{{10788336, 149}, {10788337, 142}, {10788338, 143}, {10788339, 243}, {4313372, 157}, {4313373, 244}, {4313368, 34}, {4313369, 20}, {4313370, 0}, {4313371, 164}, {4313366, 149}, {4313367, 142}, {4313364, 7}, {4313365, 21}, {4313360, 149}, {4313361, 149}, {4313362, 121}, {4313363, 27}, {12, 50}, {13, 222}, {14, 157}, {15, 148}, {14589332, 87}, {14589333, 251}, {14589334, 193}, {14589335, 15}},
 			},
 		},
 		{
@@ -4894,14 +4873,13 @@ func TestSUBX_L(t *testing.T) {
 				RAM: [][2]uint32{{16263256, 149}, {16263257, 136}, {16263258, 249}, {16263259, 41}, {12, 129}, {13, 34}, {14, 201}, {15, 52}, {2279732, 134}, {2279733, 128}, {2279734, 109}, {2279735, 192}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1073792535, 1713940482, 4150666253, 3680109022, 3585595004, 133752179, 568794129, 2437906882},
-				A:      [7]uint32{3406972139, 3339041660, 4172556270, 4200484020, 1820746192, 4211564701, 177623546},
-				PC:     2166540600,
-				SR:     9483,
-				USP:    8404574,
-				SSP:    12640648,
-				RAM:    [][2]uint32{{16263256, 149}, {16263257, 136}, {16263258, 249}, {16263259, 41}, {12640660, 40}, {12640661, 92}, {12640656, 5}, {12640657, 11}, {12640658, 0}, {12640659, 248}, {12640654, 149}, {12640655, 136}, {12640652, 68}, {12640653, 233}, {12640648, 149}, {12640649, 145}, {12640650, 203}, {12640651, 18}, {12, 129}, {13, 34}, {14, 201}, {15, 52}, {2279732, 134}, {2279733, 128}, {2279734, 109}, {2279735, 192}},
-				Halted: true,
+				D:   [8]uint32{1073792535, 1713940482, 4150666253, 3680109022, 3585595004, 133752179, 568794129, 2437906882},
+				A:   [7]uint32{3406972139, 3339041660, 4172556270, 4200484020, 1820746192, 4211564701, 177623546},
+				PC:  2166540600,
+				SR:  9483,
+				USP: 8404574,
+				SSP: 12640648,
+				RAM: [][2]uint32{{16263256, 149}, {16263257, 136}, {16263258, 249}, {16263259, 41}, {12640660, 40}, {12640661, 92}, {12640656, 5}, {12640657, 11}, {12640658, 0}, {12640659, 248}, {12640654, 149}, {12640655, 136}, {12640652, 68}, {12640653, 233}, {12640648, 149}, {12640649, 145}, {12640650, 203}, {12640651, 18}, {12, 129}, {13, 34}, {14, 201}, {15, 52}, {2279732, 134}, {2279733, 128}, {2279734, 109}, {2279735, 192}},
 			},
 		},
 		{
@@ -5516,14 +5494,13 @@ func TestCMP_W(t *testing.T) {
 				RAM: [][2]uint32{{9636856, 176}, {9636857, 97}, {9636858, 116}, {9636859, 108}, {12, 136}, {13, 72}, {14, 119}, {15, 208}, {4749264, 31}, {4749265, 143}, {4749266, 70}, {4749267, 210}},
 			},
 			want: cpuState{
-				D:      [8]uint32{142592529, 2185911067, 2934495150, 141010255, 2931120456, 3253358107, 845451191, 2640229853},
-				A:      [7]uint32{3094419315, 2760999197, 1086957140, 2775550500, 1745243799, 1831606442, 1400276022},
-				PC:     2286450644,
-				SR:     9990,
-				USP:    7476816,
-				SSP:    7402510,
-				RAM:    [][2]uint32{{9636856, 176}, {9636857, 97}, {9636858, 116}, {9636859, 108}, {7402522, 11}, {7402523, 252}, {7402518, 7}, {7402519, 6}, {7402520, 0}, {7402521, 147}, {7402516, 176}, {7402517, 97}, {7402514, 129}, {7402515, 29}, {7402510, 176}, {7402511, 113}, {7402512, 164}, {7402513, 145}, {12, 136}, {13, 72}, {14, 119}, {15, 208}, {4749264, 31}, {4749265, 143}, {4749266, 70}, {4749267, 210}},
-				Halted: true,
+				D:   [8]uint32{142592529, 2185911067, 2934495150, 141010255, 2931120456, 3253358107, 845451191, 2640229853},
+				A:   [7]uint32{3094419315, 2760999197, 1086957140, 2775550500, 1745243799, 1831606442, 1400276022},
+				PC:  2286450644,
+				SR:  9990,
+				USP: 7476816,
+				SSP: 7402510,
+				RAM: [][2]uint32{{9636856, 176}, {9636857, 97}, {9636858, 116}, {9636859, 108}, {7402522, 11}, {7402523, 252}, {7402518, 7}, {7402519, 6}, {7402520, 0}, {7402521, 147}, {7402516, 176}, {7402517, 97}, {7402514, 129}, {7402515, 29}, {7402510, 176}, {7402511, 113}, {7402512, 164}, {7402513, 145}, {12, 136}, {13, 72}, {14, 119}, {15, 208}, {4749264, 31}, {4749265, 143}, {4749266, 70}, {4749267, 210}},
 			},
 		},
 		{
@@ -5538,14 +5515,13 @@ func TestCMP_W(t *testing.T) {
 				RAM: [][2]uint32{{11441076, 181}, {11441077, 73}, {11441078, 125}, {11441079, 0}, {12, 208}, {13, 32}, {14, 233}, {15, 160}, {2156960, 236}, {2156961, 166}, {2156962, 25}, {2156963, 70}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2174776045, 2906739810, 2593367242, 1246203378, 3712275982, 270526096, 2282705329, 2654483443},
-				A:      [7]uint32{2647120325, 2459271213, 2668870752, 383522417, 2493378426, 4249576296, 3061110826},
-				PC:     3491817892,
-				SR:     9995,
-				USP:    875472,
-				SSP:    8920096,
-				RAM:    [][2]uint32{{11441076, 181}, {11441077, 73}, {11441078, 125}, {11441079, 0}, {8920108, 147}, {8920109, 184}, {8920104, 167}, {8920105, 11}, {8920106, 0}, {8920107, 174}, {8920102, 181}, {8920103, 73}, {8920100, 128}, {8920101, 43}, {8920096, 181}, {8920097, 85}, {8920098, 146}, {8920099, 149}, {12, 208}, {13, 32}, {14, 233}, {15, 160}, {2156960, 236}, {2156961, 166}, {2156962, 25}, {2156963, 70}},
-				Halted: true,
+				D:   [8]uint32{2174776045, 2906739810, 2593367242, 1246203378, 3712275982, 270526096, 2282705329, 2654483443},
+				A:   [7]uint32{2647120325, 2459271213, 2668870752, 383522417, 2493378426, 4249576296, 3061110826},
+				PC:  3491817892,
+				SR:  9995,
+				USP: 875472,
+				SSP: 8920096,
+				RAM: [][2]uint32{{11441076, 181}, {11441077, 73}, {11441078, 125}, {11441079, 0}, {8920108, 147}, {8920109, 184}, {8920104, 167}, {8920105, 11}, {8920106, 0}, {8920107, 174}, {8920102, 181}, {8920103, 73}, {8920100, 128}, {8920101, 43}, {8920096, 181}, {8920097, 85}, {8920098, 146}, {8920099, 149}, {12, 208}, {13, 32}, {14, 233}, {15, 160}, {2156960, 236}, {2156961, 166}, {2156962, 25}, {2156963, 70}},
 			},
 		},
 		{
@@ -5838,14 +5814,13 @@ func TestCMP_L(t *testing.T) {
 				RAM: [][2]uint32{{3335266, 188}, {3335267, 180}, {3335268, 76}, {3335269, 198}, {3335270, 106}, {3335271, 78}, {12, 102}, {13, 186}, {14, 182}, {15, 40}, {12236328, 90}, {12236329, 202}, {12236330, 190}, {12236331, 153}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1376672531, 1885140260, 623935522, 2233742434, 3028840733, 891270686, 2271780302, 3180168354},
-				A:      [7]uint32{2594637097, 2333959586, 1113885730, 2321971044, 3588261746, 4179365780, 3796795233},
-				PC:     1723512364,
-				SR:     9741,
-				USP:    996568,
-				SSP:    16454306,
-				RAM:    [][2]uint32{{3335266, 188}, {3335267, 180}, {3335268, 76}, {3335269, 198}, {3335270, 106}, {3335271, 78}, {16454318, 228}, {16454319, 100}, {16454314, 6}, {16454315, 13}, {16454316, 0}, {16454317, 50}, {16454312, 188}, {16454313, 180}, {16454310, 248}, {16454311, 85}, {16454306, 188}, {16454307, 177}, {16454308, 138}, {16454309, 104}, {12, 102}, {13, 186}, {14, 182}, {15, 40}, {12236328, 90}, {12236329, 202}, {12236330, 190}, {12236331, 153}},
-				Halted: true,
+				D:   [8]uint32{1376672531, 1885140260, 623935522, 2233742434, 3028840733, 891270686, 2271780302, 3180168354},
+				A:   [7]uint32{2594637097, 2333959586, 1113885730, 2321971044, 3588261746, 4179365780, 3796795233},
+				PC:  1723512364,
+				SR:  9741,
+				USP: 996568,
+				SSP: 16454306,
+				RAM: [][2]uint32{{3335266, 188}, {3335267, 180}, {3335268, 76}, {3335269, 198}, {3335270, 106}, {3335271, 78}, {16454318, 228}, {16454319, 100}, {16454314, 6}, {16454315, 13}, {16454316, 0}, {16454317, 50}, {16454312, 188}, {16454313, 180}, {16454310, 248}, {16454311, 85}, {16454306, 188}, {16454307, 177}, {16454308, 138}, {16454309, 104}, {12, 102}, {13, 186}, {14, 182}, {15, 40}, {12236328, 90}, {12236329, 202}, {12236330, 190}, {12236331, 153}},
 			},
 		},
 		{
@@ -5882,14 +5857,13 @@ func TestCMP_L(t *testing.T) {
 				RAM: [][2]uint32{{11401680, 190}, {11401681, 182}, {11401682, 156}, {11401683, 136}, {11401684, 196}, {11401685, 159}, {12, 22}, {13, 172}, {14, 78}, {15, 14}, {11292174, 37}, {11292175, 242}, {11292176, 208}, {11292177, 126}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1769718620, 2710303105, 1506316887, 1509684142, 3864885641, 4270562231, 325454053, 894372554},
-				A:      [7]uint32{3666905663, 3568298632, 2842972664, 4178202536, 14971123, 1315932753, 216521209},
-				PC:     380390930,
-				SR:     10014,
-				USP:    5074658,
-				SSP:    5124344,
-				RAM:    [][2]uint32{{11401680, 190}, {11401681, 182}, {11401682, 156}, {11401683, 136}, {11401684, 196}, {11401685, 159}, {5124356, 249}, {5124357, 210}, {5124352, 135}, {5124353, 30}, {5124354, 0}, {5124355, 173}, {5124350, 190}, {5124351, 182}, {5124348, 196}, {5124349, 9}, {5124344, 190}, {5124345, 177}, {5124346, 225}, {5124347, 151}, {12, 22}, {13, 172}, {14, 78}, {15, 14}, {11292174, 37}, {11292175, 242}, {11292176, 208}, {11292177, 126}},
-				Halted: true,
+				D:   [8]uint32{1769718620, 2710303105, 1506316887, 1509684142, 3864885641, 4270562231, 325454053, 894372554},
+				A:   [7]uint32{3666905663, 3568298632, 2842972664, 4178202536, 14971123, 1315932753, 216521209},
+				PC:  380390930,
+				SR:  10014,
+				USP: 5074658,
+				SSP: 5124344,
+				RAM: [][2]uint32{{11401680, 190}, {11401681, 182}, {11401682, 156}, {11401683, 136}, {11401684, 196}, {11401685, 159}, {5124356, 249}, {5124357, 210}, {5124352, 135}, {5124353, 30}, {5124354, 0}, {5124355, 173}, {5124350, 190}, {5124351, 182}, {5124348, 196}, {5124349, 9}, {5124344, 190}, {5124345, 177}, {5124346, 225}, {5124347, 151}, {12, 22}, {13, 172}, {14, 78}, {15, 14}, {11292174, 37}, {11292175, 242}, {11292176, 208}, {11292177, 126}},
 			},
 		},
 		{
@@ -5904,14 +5878,13 @@ func TestCMP_L(t *testing.T) {
 				RAM: [][2]uint32{{5056438, 186}, {5056439, 176}, {5056440, 26}, {5056441, 166}, {5056442, 8}, {5056443, 219}, {12, 236}, {13, 128}, {14, 220}, {15, 16}, {8444944, 43}, {8444945, 48}, {8444946, 216}, {8444947, 128}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2077505327, 463790756, 3129488334, 4263845755, 4193451820, 3717590645, 846018652, 2030228542},
-				A:      [7]uint32{2228968255, 4294540364, 4262826014, 2381997817, 3023409651, 3204030991, 914704705},
-				PC:     3967867924,
-				SR:     8704,
-				USP:    6027476,
-				SSP:    10809506,
-				RAM:    [][2]uint32{{5056438, 186}, {5056439, 176}, {5056440, 26}, {5056441, 166}, {5056442, 8}, {5056443, 219}, {10809518, 39}, {10809519, 184}, {10809514, 162}, {10809515, 0}, {10809516, 0}, {10809517, 77}, {10809512, 186}, {10809513, 176}, {10809510, 61}, {10809511, 137}, {10809506, 186}, {10809507, 181}, {10809508, 160}, {10809509, 128}, {12, 236}, {13, 128}, {14, 220}, {15, 16}, {8444944, 43}, {8444945, 48}, {8444946, 216}, {8444947, 128}},
-				Halted: true,
+				D:   [8]uint32{2077505327, 463790756, 3129488334, 4263845755, 4193451820, 3717590645, 846018652, 2030228542},
+				A:   [7]uint32{2228968255, 4294540364, 4262826014, 2381997817, 3023409651, 3204030991, 914704705},
+				PC:  3967867924,
+				SR:  8704,
+				USP: 6027476,
+				SSP: 10809506,
+				RAM: [][2]uint32{{5056438, 186}, {5056439, 176}, {5056440, 26}, {5056441, 166}, {5056442, 8}, {5056443, 219}, {10809518, 39}, {10809519, 184}, {10809514, 162}, {10809515, 0}, {10809516, 0}, {10809517, 77}, {10809512, 186}, {10809513, 176}, {10809510, 61}, {10809511, 137}, {10809506, 186}, {10809507, 181}, {10809508, 160}, {10809509, 128}, {12, 236}, {13, 128}, {14, 220}, {15, 16}, {8444944, 43}, {8444945, 48}, {8444946, 216}, {8444947, 128}},
 			},
 		},
 		{
@@ -6460,14 +6433,13 @@ func TestCMPA_L(t *testing.T) {
 				RAM: [][2]uint32{{4796256, 183}, {4796257, 236}, {4796258, 21}, {4796259, 34}, {4796260, 191}, {4796261, 37}, {12, 116}, {13, 242}, {14, 2}, {15, 254}, {15860478, 254}, {15860479, 45}, {15860480, 4}, {15860481, 213}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2890798821, 1862138709, 2854314823, 398855901, 3873552237, 4105501077, 2205126645, 3703781335},
-				A:      [7]uint32{854050806, 399742751, 2206797844, 3644069934, 2479716593, 1347522574, 2529794186},
-				PC:     1962017538,
-				SR:     8711,
-				USP:    3160850,
-				SSP:    11019122,
-				RAM:    [][2]uint32{{4796256, 183}, {4796257, 236}, {4796258, 21}, {4796259, 34}, {4796260, 191}, {4796261, 37}, {11019134, 47}, {11019135, 98}, {11019130, 34}, {11019131, 7}, {11019132, 0}, {11019133, 73}, {11019128, 183}, {11019129, 236}, {11019126, 142}, {11019127, 19}, {11019122, 183}, {11019123, 245}, {11019124, 147}, {11019125, 205}, {12, 116}, {13, 242}, {14, 2}, {15, 254}, {15860478, 254}, {15860479, 45}, {15860480, 4}, {15860481, 213}},
-				Halted: true,
+				D:   [8]uint32{2890798821, 1862138709, 2854314823, 398855901, 3873552237, 4105501077, 2205126645, 3703781335},
+				A:   [7]uint32{854050806, 399742751, 2206797844, 3644069934, 2479716593, 1347522574, 2529794186},
+				PC:  1962017538,
+				SR:  8711,
+				USP: 3160850,
+				SSP: 11019122,
+				RAM: [][2]uint32{{4796256, 183}, {4796257, 236}, {4796258, 21}, {4796259, 34}, {4796260, 191}, {4796261, 37}, {11019134, 47}, {11019135, 98}, {11019130, 34}, {11019131, 7}, {11019132, 0}, {11019133, 73}, {11019128, 183}, {11019129, 236}, {11019126, 142}, {11019127, 19}, {11019122, 183}, {11019123, 245}, {11019124, 147}, {11019125, 205}, {12, 116}, {13, 242}, {14, 2}, {15, 254}, {15860478, 254}, {15860479, 45}, {15860480, 4}, {15860481, 213}},
 			},
 		},
 		{
@@ -6482,14 +6454,13 @@ func TestCMPA_L(t *testing.T) {
 				RAM: [][2]uint32{{7496640, 181}, {7496641, 211}, {7496642, 190}, {7496643, 234}, {12, 47}, {13, 158}, {14, 165}, {15, 18}, {10396946, 193}, {10396947, 64}, {10396948, 197}, {10396949, 5}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2252290140, 3090839686, 3370884756, 2616564005, 4104746129, 3510351794, 3807957154, 3476238244},
-				A:      [7]uint32{3003121672, 108294146, 3334919252, 663744853, 390905783, 871256995, 2525146124},
-				PC:     798926102,
-				SR:     8971,
-				USP:    9358608,
-				SSP:    9889430,
-				RAM:    [][2]uint32{{7496640, 181}, {7496641, 211}, {7496642, 190}, {7496643, 234}, {9889442, 99}, {9889443, 194}, {9889438, 35}, {9889439, 11}, {9889440, 0}, {9889441, 114}, {9889436, 181}, {9889437, 211}, {9889434, 241}, {9889435, 85}, {9889430, 181}, {9889431, 213}, {9889432, 39}, {9889433, 143}, {12, 47}, {13, 158}, {14, 165}, {15, 18}, {10396946, 193}, {10396947, 64}, {10396948, 197}, {10396949, 5}},
-				Halted: true,
+				D:   [8]uint32{2252290140, 3090839686, 3370884756, 2616564005, 4104746129, 3510351794, 3807957154, 3476238244},
+				A:   [7]uint32{3003121672, 108294146, 3334919252, 663744853, 390905783, 871256995, 2525146124},
+				PC:  798926102,
+				SR:  8971,
+				USP: 9358608,
+				SSP: 9889430,
+				RAM: [][2]uint32{{7496640, 181}, {7496641, 211}, {7496642, 190}, {7496643, 234}, {9889442, 99}, {9889443, 194}, {9889438, 35}, {9889439, 11}, {9889440, 0}, {9889441, 114}, {9889436, 181}, {9889437, 211}, {9889434, 241}, {9889435, 85}, {9889430, 181}, {9889431, 213}, {9889432, 39}, {9889433, 143}, {12, 47}, {13, 158}, {14, 165}, {15, 18}, {10396946, 193}, {10396947, 64}, {10396948, 197}, {10396949, 5}},
 			},
 		},
 		{
@@ -6828,14 +6799,13 @@ func TestMULU(t *testing.T) {
 				RAM: [][2]uint32{{13310322, 194}, {13310323, 238}, {13310324, 222}, {13310325, 206}, {13310326, 140}, {13310327, 73}, {12, 192}, {13, 122}, {14, 107}, {15, 58}, {8022842, 21}, {8022843, 113}, {8022844, 93}, {8022845, 209}},
 			},
 			want: cpuState{
-				D:      [8]uint32{499070063, 2416728071, 2856028692, 2663182908, 302958052, 3718686238, 3918859815, 4238725179},
-				A:      [7]uint32{635427773, 736488623, 2903352753, 3303195388, 1422995643, 3916655940, 2230818305},
-				PC:     3229248318,
-				SR:     8984,
-				USP:    1814124,
-				SSP:    6229974,
-				RAM:    [][2]uint32{{13310322, 194}, {13310323, 238}, {13310324, 222}, {13310325, 206}, {13310326, 140}, {13310327, 73}, {6229986, 25}, {6229987, 116}, {6229982, 35}, {6229983, 24}, {6229984, 0}, {6229985, 203}, {6229980, 194}, {6229981, 238}, {6229978, 116}, {6229979, 207}, {6229974, 194}, {6229975, 245}, {6229976, 132}, {6229977, 247}, {12, 192}, {13, 122}, {14, 107}, {15, 58}, {8022842, 21}, {8022843, 113}, {8022844, 93}, {8022845, 209}},
-				Halted: true,
+				D:   [8]uint32{499070063, 2416728071, 2856028692, 2663182908, 302958052, 3718686238, 3918859815, 4238725179},
+				A:   [7]uint32{635427773, 736488623, 2903352753, 3303195388, 1422995643, 3916655940, 2230818305},
+				PC:  3229248318,
+				SR:  8984,
+				USP: 1814124,
+				SSP: 6229974,
+				RAM: [][2]uint32{{13310322, 194}, {13310323, 238}, {13310324, 222}, {13310325, 206}, {13310326, 140}, {13310327, 73}, {6229986, 25}, {6229987, 116}, {6229982, 35}, {6229983, 24}, {6229984, 0}, {6229985, 203}, {6229980, 194}, {6229981, 238}, {6229978, 116}, {6229979, 207}, {6229974, 194}, {6229975, 245}, {6229976, 132}, {6229977, 247}, {12, 192}, {13, 122}, {14, 107}, {15, 58}, {8022842, 21}, {8022843, 113}, {8022844, 93}, {8022845, 209}},
 			},
 		},
 		{
@@ -7108,14 +7078,13 @@ func TestMULS(t *testing.T) {
 				RAM: [][2]uint32{{7257764, 207}, {7257765, 208}, {7257766, 65}, {7257767, 182}, {12, 212}, {13, 136}, {14, 12}, {15, 28}, {8915996, 32}, {8915997, 192}, {8915998, 67}, {8915999, 162}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1704645368, 1901729937, 3128084608, 2902137850, 1704553321, 1645148921, 1541705176, 3019654251},
-				A:      [7]uint32{3191554209, 2285547708, 1406541405, 3273714787, 3633134209, 1861415841, 1909716418},
-				PC:     3565685792,
-				SR:     8463,
-				USP:    11325510,
-				SSP:    4905286,
-				RAM:    [][2]uint32{{7257764, 207}, {7257765, 208}, {7257766, 65}, {7257767, 182}, {4905298, 190}, {4905299, 166}, {4905294, 161}, {4905295, 15}, {4905296, 0}, {4905297, 110}, {4905292, 207}, {4905293, 208}, {4905290, 64}, {4905291, 161}, {4905286, 207}, {4905287, 213}, {4905288, 190}, {4905289, 59}, {12, 212}, {13, 136}, {14, 12}, {15, 28}, {8915996, 32}, {8915997, 192}, {8915998, 67}, {8915999, 162}},
-				Halted: true,
+				D:   [8]uint32{1704645368, 1901729937, 3128084608, 2902137850, 1704553321, 1645148921, 1541705176, 3019654251},
+				A:   [7]uint32{3191554209, 2285547708, 1406541405, 3273714787, 3633134209, 1861415841, 1909716418},
+				PC:  3565685792,
+				SR:  8463,
+				USP: 11325510,
+				SSP: 4905286,
+				RAM: [][2]uint32{{7257764, 207}, {7257765, 208}, {7257766, 65}, {7257767, 182}, {4905298, 190}, {4905299, 166}, {4905294, 161}, {4905295, 15}, {4905296, 0}, {4905297, 110}, {4905292, 207}, {4905293, 208}, {4905290, 64}, {4905291, 161}, {4905286, 207}, {4905287, 213}, {4905288, 190}, {4905289, 59}, {12, 212}, {13, 136}, {14, 12}, {15, 28}, {8915996, 32}, {8915997, 192}, {8915998, 67}, {8915999, 162}},
 			},
 		},
 		{
@@ -7130,14 +7099,13 @@ func TestMULS(t *testing.T) {
 				RAM: [][2]uint32{{11326768, 199}, {11326769, 212}, {11326770, 55}, {11326771, 14}, {12, 83}, {13, 26}, {14, 15}, {15, 150}, {1707926, 123}, {1707927, 31}, {1707928, 36}, {1707929, 21}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3201550852, 1081349702, 4268869544, 1144659714, 3038672353, 3112386840, 3444486923, 221977737},
-				A:      [7]uint32{3336816699, 1872675517, 1376689166, 3395013168, 586106007, 2358942989, 767937415},
-				PC:     1394216858,
-				SR:     9993,
-				USP:    10667612,
-				SSP:    11962328,
-				RAM:    [][2]uint32{{11326768, 199}, {11326769, 212}, {11326770, 55}, {11326771, 14}, {11962340, 213}, {11962341, 50}, {11962336, 167}, {11962337, 9}, {11962338, 0}, {11962339, 172}, {11962334, 199}, {11962335, 212}, {11962332, 68}, {11962333, 151}, {11962328, 199}, {11962329, 213}, {11962330, 34}, {11962331, 239}, {12, 83}, {13, 26}, {14, 15}, {15, 150}, {1707926, 123}, {1707927, 31}, {1707928, 36}, {1707929, 21}},
-				Halted: true,
+				D:   [8]uint32{3201550852, 1081349702, 4268869544, 1144659714, 3038672353, 3112386840, 3444486923, 221977737},
+				A:   [7]uint32{3336816699, 1872675517, 1376689166, 3395013168, 586106007, 2358942989, 767937415},
+				PC:  1394216858,
+				SR:  9993,
+				USP: 10667612,
+				SSP: 11962328,
+				RAM: [][2]uint32{{11326768, 199}, {11326769, 212}, {11326770, 55}, {11326771, 14}, {11962340, 213}, {11962341, 50}, {11962336, 167}, {11962337, 9}, {11962338, 0}, {11962339, 172}, {11962334, 199}, {11962335, 212}, {11962332, 68}, {11962333, 151}, {11962328, 199}, {11962329, 213}, {11962330, 34}, {11962331, 239}, {12, 83}, {13, 26}, {14, 15}, {15, 150}, {1707926, 123}, {1707927, 31}, {1707928, 36}, {1707929, 21}},
 			},
 		},
 		{
@@ -7152,14 +7120,13 @@ func TestMULS(t *testing.T) {
 				RAM: [][2]uint32{{3275972, 201}, {3275973, 249}, {3275974, 209}, {3275975, 224}, {3275976, 177}, {3275977, 43}, {3275978, 39}, {3275979, 198}, {12, 141}, {13, 154}, {14, 215}, {15, 86}, {10147670, 111}, {10147671, 17}, {10147672, 63}, {10147673, 41}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3647687287, 1485830246, 721887628, 2156691615, 450378171, 624011470, 49863416, 1355137234},
-				A:      [7]uint32{124806300, 46428065, 3305759920, 241462433, 945399426, 1783884140, 871044685},
-				PC:     2375735130,
-				SR:     8711,
-				USP:    6323212,
-				SSP:    10972170,
-				RAM:    [][2]uint32{{3275972, 201}, {3275973, 249}, {3275974, 209}, {3275975, 224}, {3275976, 177}, {3275977, 43}, {3275978, 39}, {3275979, 198}, {10972182, 252}, {10972183, 202}, {10972178, 130}, {10972179, 7}, {10972180, 0}, {10972181, 49}, {10972176, 201}, {10972177, 249}, {10972174, 177}, {10972175, 43}, {10972170, 201}, {10972171, 241}, {10972172, 209}, {10972173, 224}, {12, 141}, {13, 154}, {14, 215}, {15, 86}, {10147670, 111}, {10147671, 17}, {10147672, 63}, {10147673, 41}},
-				Halted: true,
+				D:   [8]uint32{3647687287, 1485830246, 721887628, 2156691615, 450378171, 624011470, 49863416, 1355137234},
+				A:   [7]uint32{124806300, 46428065, 3305759920, 241462433, 945399426, 1783884140, 871044685},
+				PC:  2375735130,
+				SR:  8711,
+				USP: 6323212,
+				SSP: 10972170,
+				RAM: [][2]uint32{{3275972, 201}, {3275973, 249}, {3275974, 209}, {3275975, 224}, {3275976, 177}, {3275977, 43}, {3275978, 39}, {3275979, 198}, {10972182, 252}, {10972183, 202}, {10972178, 130}, {10972179, 7}, {10972180, 0}, {10972181, 49}, {10972176, 201}, {10972177, 249}, {10972174, 177}, {10972175, 43}, {10972170, 201}, {10972171, 241}, {10972172, 209}, {10972173, 224}, {12, 141}, {13, 154}, {14, 215}, {15, 86}, {10147670, 111}, {10147671, 17}, {10147672, 63}, {10147673, 41}},
 			},
 		},
 		{
@@ -7174,15 +7141,14 @@ func TestMULS(t *testing.T) {
 				RAM: [][2]uint32{{10262038, 201}, {10262039, 224}, {10262040, 216}, {10262041, 150}, {16557168, 41}, {16557169, 24}, {10262042, 160}, {10262043, 207}},
 			},
 			want: cpuState{
-				D:   [8]uint32{1292028538, 1563758125, 1394486475, 3212262621, 155117400, 3984915181, 1212938363, 529248960},
-				A:   [7]uint32{838640752, 1277056709, 2192284561, 361350074, 2531121974, 2923346865, 50233311},
-				PC:  10262044,
-				SR:  8720,
-				USP: 5413410,
-				SSP: 15468822,
-				RAM: [][2]uint32{{10262038, 201}, {10262039, 224}, {10262040, 216}, {10262041, 150}, {16557168, 41}, {16557169, 24}, {10262042, 160}, {10262043, 207}},
-				// MULS: uses flat worst-case 70 cycles (real range 38-70).
-				// Cycles: 60,
+				D:      [8]uint32{1292028538, 1563758125, 1394486475, 3212262621, 155117400, 3984915181, 1212938363, 529248960},
+				A:      [7]uint32{838640752, 1277056709, 2192284561, 361350074, 2531121974, 2923346865, 50233311},
+				PC:     10262044,
+				SR:     8720,
+				USP:    5413410,
+				SSP:    15468822,
+				RAM:    [][2]uint32{{10262038, 201}, {10262039, 224}, {10262040, 216}, {10262041, 150}, {16557168, 41}, {16557169, 24}, {10262042, 160}, {10262043, 207}},
+				Cycles: 60,
 			},
 		},
 		{
@@ -7197,15 +7163,14 @@ func TestMULS(t *testing.T) {
 				RAM: [][2]uint32{{16187582, 205}, {16187583, 237}, {16187584, 165}, {16187585, 13}, {16187586, 198}, {16187587, 92}, {4688894, 2}, {4688895, 69}, {16187588, 252}, {16187589, 0}},
 			},
 			want: cpuState{
-				D:   [8]uint32{4020554460, 1046034208, 3440707144, 2082346192, 985801223, 2711674057, 4293354440, 2263125806},
-				A:   [7]uint32{2793487633, 846310692, 2932435961, 1621580739, 641085303, 3175606001, 2751795172},
-				PC:  16187590,
-				SR:  1304,
-				USP: 15891238,
-				SSP: 12708586,
-				RAM: [][2]uint32{{16187582, 205}, {16187583, 237}, {16187584, 165}, {16187585, 13}, {16187586, 198}, {16187587, 92}, {4688894, 2}, {4688895, 69}, {16187588, 252}, {16187589, 0}},
-				// MULS: uses flat worst-case 70 cycles (real range 38-70).
-				// Cycles: 62,
+				D:      [8]uint32{4020554460, 1046034208, 3440707144, 2082346192, 985801223, 2711674057, 4293354440, 2263125806},
+				A:      [7]uint32{2793487633, 846310692, 2932435961, 1621580739, 641085303, 3175606001, 2751795172},
+				PC:     16187590,
+				SR:     1304,
+				USP:    15891238,
+				SSP:    12708586,
+				RAM:    [][2]uint32{{16187582, 205}, {16187583, 237}, {16187584, 165}, {16187585, 13}, {16187586, 198}, {16187587, 92}, {4688894, 2}, {4688895, 69}, {16187588, 252}, {16187589, 0}},
+				Cycles: 62,
 			},
 		},
 		{
@@ -7432,15 +7397,14 @@ func TestDIVU(t *testing.T) {
 				RAM: [][2]uint32{{7822932, 136}, {7822933, 249}, {7822934, 123}, {7822935, 151}, {7822936, 142}, {7822937, 194}, {7822938, 61}, {7822939, 93}, {9932482, 115}, {9932483, 216}, {7822940, 11}, {7822941, 53}},
 			},
 			want: cpuState{
-				D:   [8]uint32{851875928, 3326892796, 3873463131, 1757372564, 3594990973, 3869244608, 1245250439, 1267479684},
-				A:   [7]uint32{1311392589, 1906478983, 557940564, 4217376967, 2321827578, 133874346, 2055113439},
-				PC:  7822942,
-				SR:  8714,
-				USP: 2144018,
-				SSP: 4103512,
-				RAM: [][2]uint32{{7822932, 136}, {7822933, 249}, {7822934, 123}, {7822935, 151}, {7822936, 142}, {7822937, 194}, {7822938, 61}, {7822939, 93}, {9932482, 115}, {9932483, 216}, {7822940, 11}, {7822941, 53}},
-				// DIVU: uses flat worst-case 140 cycles (real range 76-140).
-				// Cycles: 22,
+				D:      [8]uint32{851875928, 3326892796, 3873463131, 1757372564, 3594990973, 3869244608, 1245250439, 1267479684},
+				A:      [7]uint32{1311392589, 1906478983, 557940564, 4217376967, 2321827578, 133874346, 2055113439},
+				PC:     7822942,
+				SR:     8714,
+				USP:    2144018,
+				SSP:    4103512,
+				RAM:    [][2]uint32{{7822932, 136}, {7822933, 249}, {7822934, 123}, {7822935, 151}, {7822936, 142}, {7822937, 194}, {7822938, 61}, {7822939, 93}, {9932482, 115}, {9932483, 216}, {7822940, 11}, {7822941, 53}},
+				Cycles: 22,
 			},
 		},
 		{
@@ -7455,15 +7419,14 @@ func TestDIVU(t *testing.T) {
 				RAM: [][2]uint32{{10731876, 138}, {10731877, 217}, {10731878, 101}, {10731879, 14}, {3677122, 154}, {3677123, 240}, {10731880, 229}, {10731881, 151}},
 			},
 			want: cpuState{
-				D:   [8]uint32{2875726544, 3909222798, 1463529590, 1958708671, 2129862750, 1600417512, 2838606430, 1472630705},
-				A:   [7]uint32{3437469760, 238558148, 4166014496, 40557750, 268931461, 1288885616, 2910876823},
-				PC:  10731882,
-				SR:  33536,
-				USP: 14436168,
-				SSP: 12846300,
-				RAM: [][2]uint32{{10731876, 138}, {10731877, 217}, {10731878, 101}, {10731879, 14}, {3677122, 154}, {3677123, 240}, {10731880, 229}, {10731881, 151}},
-				// DIVU: uses flat worst-case 140 cycles (real range 76-140).
-				// Cycles: 114,
+				D:      [8]uint32{2875726544, 3909222798, 1463529590, 1958708671, 2129862750, 1600417512, 2838606430, 1472630705},
+				A:      [7]uint32{3437469760, 238558148, 4166014496, 40557750, 268931461, 1288885616, 2910876823},
+				PC:     10731882,
+				SR:     33536,
+				USP:    14436168,
+				SSP:    12846300,
+				RAM:    [][2]uint32{{10731876, 138}, {10731877, 217}, {10731878, 101}, {10731879, 14}, {3677122, 154}, {3677123, 240}, {10731880, 229}, {10731881, 151}},
+				Cycles: 114,
 			},
 		},
 		{
@@ -7478,14 +7441,13 @@ func TestDIVU(t *testing.T) {
 				RAM: [][2]uint32{{9890408, 140}, {9890409, 222}, {9890410, 91}, {9890411, 199}, {12, 215}, {13, 72}, {14, 104}, {15, 154}, {4745370, 237}, {4745371, 60}, {4745372, 96}, {4745373, 170}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3834880392, 129793522, 1395288021, 74557267, 3782152033, 4222315986, 326548149, 352547979},
-				A:      [7]uint32{3766499185, 1593713367, 3608282384, 4288771288, 2571080414, 2229585658, 263275135},
-				PC:     3611846814,
-				SR:     8733,
-				USP:    5114318,
-				SSP:    7162276,
-				RAM:    [][2]uint32{{9890408, 140}, {9890409, 222}, {9890410, 91}, {9890411, 199}, {7162288, 234}, {7162289, 106}, {7162284, 34}, {7162285, 29}, {7162286, 0}, {7162287, 150}, {7162282, 140}, {7162283, 222}, {7162280, 66}, {7162281, 125}, {7162276, 140}, {7162277, 213}, {7162278, 15}, {7162279, 177}, {12, 215}, {13, 72}, {14, 104}, {15, 154}, {4745370, 237}, {4745371, 60}, {4745372, 96}, {4745373, 170}},
-				Halted: true,
+				D:   [8]uint32{3834880392, 129793522, 1395288021, 74557267, 3782152033, 4222315986, 326548149, 352547979},
+				A:   [7]uint32{3766499185, 1593713367, 3608282384, 4288771288, 2571080414, 2229585658, 263275135},
+				PC:  3611846814,
+				SR:  8733,
+				USP: 5114318,
+				SSP: 7162276,
+				RAM: [][2]uint32{{9890408, 140}, {9890409, 222}, {9890410, 91}, {9890411, 199}, {7162288, 234}, {7162289, 106}, {7162284, 34}, {7162285, 29}, {7162286, 0}, {7162287, 150}, {7162282, 140}, {7162283, 222}, {7162280, 66}, {7162281, 125}, {7162276, 140}, {7162277, 213}, {7162278, 15}, {7162279, 177}, {12, 215}, {13, 72}, {14, 104}, {15, 154}, {4745370, 237}, {4745371, 60}, {4745372, 96}, {4745373, 170}},
 			},
 		},
 		{
@@ -7500,14 +7462,13 @@ func TestDIVU(t *testing.T) {
 				RAM: [][2]uint32{{819070, 130}, {819071, 209}, {819072, 45}, {819073, 231}, {12, 22}, {13, 210}, {14, 49}, {15, 210}, {13775314, 6}, {13775315, 81}, {13775316, 135}, {13775317, 237}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1131935187, 2967760831, 3107902653, 2388280997, 4053163231, 3090168194, 3223175447, 2391423524},
-				A:      [7]uint32{2775753798, 2091270239, 879934419, 2666592742, 4506931, 1945308521, 422319434},
-				PC:     382874070,
-				SR:     8725,
-				USP:    2463218,
-				SSP:    16327332,
-				RAM:    [][2]uint32{{819070, 130}, {819071, 209}, {819072, 45}, {819073, 231}, {16327344, 127}, {16327345, 128}, {16327340, 2}, {16327341, 21}, {16327342, 0}, {16327343, 12}, {16327338, 130}, {16327339, 209}, {16327336, 64}, {16327337, 95}, {16327332, 130}, {16327333, 209}, {16327334, 124}, {16327335, 166}, {12, 22}, {13, 210}, {14, 49}, {15, 210}, {13775314, 6}, {13775315, 81}, {13775316, 135}, {13775317, 237}},
-				Halted: true,
+				D:   [8]uint32{1131935187, 2967760831, 3107902653, 2388280997, 4053163231, 3090168194, 3223175447, 2391423524},
+				A:   [7]uint32{2775753798, 2091270239, 879934419, 2666592742, 4506931, 1945308521, 422319434},
+				PC:  382874070,
+				SR:  8725,
+				USP: 2463218,
+				SSP: 16327332,
+				RAM: [][2]uint32{{819070, 130}, {819071, 209}, {819072, 45}, {819073, 231}, {16327344, 127}, {16327345, 128}, {16327340, 2}, {16327341, 21}, {16327342, 0}, {16327343, 12}, {16327338, 130}, {16327339, 209}, {16327336, 64}, {16327337, 95}, {16327332, 130}, {16327333, 209}, {16327334, 124}, {16327335, 166}, {12, 22}, {13, 210}, {14, 49}, {15, 210}, {13775314, 6}, {13775315, 81}, {13775316, 135}, {13775317, 237}},
 			},
 		},
 		{
@@ -7522,14 +7483,13 @@ func TestDIVU(t *testing.T) {
 				RAM: [][2]uint32{{14913572, 128}, {14913573, 233}, {14913574, 198}, {14913575, 76}, {14913576, 136}, {14913577, 34}, {12, 233}, {13, 46}, {14, 225}, {15, 212}, {3072468, 147}, {3072469, 9}, {3072470, 123}, {3072471, 217}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2573467237, 3026339520, 4094927323, 3437714138, 3760626698, 2874405135, 3138198561, 2290507765},
-				A:      [7]uint32{1340680993, 1499019, 1526447768, 1580288638, 3229236120, 529531783, 3686956147},
-				PC:     3912163800,
-				SR:     8983,
-				USP:    2511940,
-				SSP:    4898962,
-				RAM:    [][2]uint32{{14913572, 128}, {14913573, 233}, {14913574, 198}, {14913575, 76}, {14913576, 136}, {14913577, 34}, {4898974, 144}, {4898975, 38}, {4898970, 35}, {4898971, 23}, {4898972, 0}, {4898973, 227}, {4898968, 128}, {4898969, 233}, {4898966, 165}, {4898967, 215}, {4898962, 128}, {4898963, 245}, {4898964, 0}, {4898965, 22}, {12, 233}, {13, 46}, {14, 225}, {15, 212}, {3072468, 147}, {3072469, 9}, {3072470, 123}, {3072471, 217}},
-				Halted: true,
+				D:   [8]uint32{2573467237, 3026339520, 4094927323, 3437714138, 3760626698, 2874405135, 3138198561, 2290507765},
+				A:   [7]uint32{1340680993, 1499019, 1526447768, 1580288638, 3229236120, 529531783, 3686956147},
+				PC:  3912163800,
+				SR:  8983,
+				USP: 2511940,
+				SSP: 4898962,
+				RAM: [][2]uint32{{14913572, 128}, {14913573, 233}, {14913574, 198}, {14913575, 76}, {14913576, 136}, {14913577, 34}, {4898974, 144}, {4898975, 38}, {4898970, 35}, {4898971, 23}, {4898972, 0}, {4898973, 227}, {4898968, 128}, {4898969, 233}, {4898966, 165}, {4898967, 215}, {4898962, 128}, {4898963, 245}, {4898964, 0}, {4898965, 22}, {12, 233}, {13, 46}, {14, 225}, {15, 212}, {3072468, 147}, {3072469, 9}, {3072470, 123}, {3072471, 217}},
 			},
 		},
 		{
@@ -7756,14 +7716,13 @@ func TestDIVS(t *testing.T) {
 				RAM: [][2]uint32{{1031500, 135}, {1031501, 245}, {1031502, 7}, {1031503, 251}, {1031504, 108}, {1031505, 133}, {12, 220}, {13, 50}, {14, 58}, {15, 120}, {3291768, 113}, {3291769, 38}, {3291770, 26}, {3291771, 106}},
 			},
 			want: cpuState{
-				D:      [8]uint32{29756972, 616991612, 1976999711, 1678834343, 612027647, 4184950574, 3466407835, 1066071521},
-				A:      [7]uint32{2537772812, 2955085712, 610031400, 2987339094, 923989849, 2054121622, 2621212741},
-				PC:     3694279292,
-				SR:     9993,
-				USP:    7062182,
-				SSP:    3341934,
-				RAM:    [][2]uint32{{1031500, 135}, {1031501, 245}, {1031502, 7}, {1031503, 251}, {1031504, 108}, {1031505, 133}, {3341946, 189}, {3341947, 78}, {3341942, 167}, {3341943, 9}, {3341944, 0}, {3341945, 15}, {3341940, 135}, {3341941, 245}, {3341938, 118}, {3341939, 189}, {3341934, 135}, {3341935, 245}, {3341936, 122}, {3341937, 111}, {12, 220}, {13, 50}, {14, 58}, {15, 120}, {3291768, 113}, {3291769, 38}, {3291770, 26}, {3291771, 106}},
-				Halted: true,
+				D:   [8]uint32{29756972, 616991612, 1976999711, 1678834343, 612027647, 4184950574, 3466407835, 1066071521},
+				A:   [7]uint32{2537772812, 2955085712, 610031400, 2987339094, 923989849, 2054121622, 2621212741},
+				PC:  3694279292,
+				SR:  9993,
+				USP: 7062182,
+				SSP: 3341934,
+				RAM: [][2]uint32{{1031500, 135}, {1031501, 245}, {1031502, 7}, {1031503, 251}, {1031504, 108}, {1031505, 133}, {3341946, 189}, {3341947, 78}, {3341942, 167}, {3341943, 9}, {3341944, 0}, {3341945, 15}, {3341940, 135}, {3341941, 245}, {3341938, 118}, {3341939, 189}, {3341934, 135}, {3341935, 245}, {3341936, 122}, {3341937, 111}, {12, 220}, {13, 50}, {14, 58}, {15, 120}, {3291768, 113}, {3291769, 38}, {3291770, 26}, {3291771, 106}},
 			},
 		},
 		{
@@ -8064,6 +8023,102 @@ func TestDIVS(t *testing.T) {
 	}
 }
 
+// TestDIVOverflow checks that an overflowing divide leaves the destination
+// untouched, keeps X and sets N and V with Z and C clear.
+func TestDIVOverflow(t *testing.T) {
+	tests := []struct {
+		name string
+		init cpuState
+		want cpuState
+	}{
+		{
+			name: "DIVU D1,D0 quotient above $FFFF",
+			init: cpuState{
+				D:   [8]uint32{0x00100000, 0x00000001},
+				PC:  0x1004,
+				SR:  0x271F,
+				SSP: 0x10000,
+				RAM: [][2]uint32{{0x1000, 0x80}, {0x1001, 0xC1}, {0x1002, 0x4E}, {0x1003, 0x71}},
+			},
+			want: cpuState{
+				D:   [8]uint32{0x00100000, 0x00000001},
+				PC:  0x1006,
+				SR:  0x271A,
+				SSP: 0x10000,
+			},
+		},
+		{
+			name: "DIVU D1,D0 dividend high word equals divisor",
+			init: cpuState{
+				D:   [8]uint32{0x12340000, 0x00001234},
+				PC:  0x1004,
+				SR:  0x271F,
+				SSP: 0x10000,
+				RAM: [][2]uint32{{0x1000, 0x80}, {0x1001, 0xC1}, {0x1002, 0x4E}, {0x1003, 0x71}},
+			},
+			want: cpuState{
+				D:   [8]uint32{0x12340000, 0x00001234},
+				PC:  0x1006,
+				SR:  0x271A,
+				SSP: 0x10000,
+			},
+		},
+		{
+			name: "DIVS D1,D0 quotient above 32767",
+			init: cpuState{
+				D:   [8]uint32{0x00008000, 0x00000001},
+				PC:  0x1004,
+				SR:  0x271F,
+				SSP: 0x10000,
+				RAM: [][2]uint32{{0x1000, 0x81}, {0x1001, 0xC1}, {0x1002, 0x4E}, {0x1003, 0x71}},
+			},
+			want: cpuState{
+				D:   [8]uint32{0x00008000, 0x00000001},
+				PC:  0x1006,
+				SR:  0x271A,
+				SSP: 0x10000,
+			},
+		},
+		{
+			name: "DIVS D1,D0 quotient below -32768",
+			init: cpuState{
+				D:   [8]uint32{0xFFFF7FFF, 0x00000001},
+				PC:  0x1004,
+				SR:  0x271F,
+				SSP: 0x10000,
+				RAM: [][2]uint32{{0x1000, 0x81}, {0x1001, 0xC1}, {0x1002, 0x4E}, {0x1003, 0x71}},
+			},
+			want: cpuState{
+				D:   [8]uint32{0xFFFF7FFF, 0x00000001},
+				PC:  0x1006,
+				SR:  0x271A,
+				SSP: 0x10000,
+			},
+		},
+		{
+			name: "DIVS D1,D0 negative divisor out of range",
+			init: cpuState{
+				D:   [8]uint32{0x40000000, 0x0000FFFF},
+				PC:  0x1004,
+				SR:  0x271F,
+				SSP: 0x10000,
+				RAM: [][2]uint32{{0x1000, 0x81}, {0x1001, 0xC1}, {0x1002, 0x4E}, {0x1003, 0x71}},
+			},
+			want: cpuState{
+				D:   [8]uint32{0x40000000, 0x0000FFFF},
+				PC:  0x1006,
+				SR:  0x271A,
+				SSP: 0x10000,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runTest(t, tt.init, tt.want)
+		})
+	}
+}
+
 func TestNEG_B(t *testing.T) {
 	tests := []struct {
 		name string
@@ -8470,14 +8525,13 @@ func TestNEG_W(t *testing.T) {
 				RAM: [][2]uint32{{9348612, 68}, {9348613, 102}, {9348614, 148}, {9348615, 239}, {12, 97}, {13, 162}, {14, 67}, {15, 80}, {10634064, 89}, {10634065, 254}, {10634066, 207}, {10634067, 38}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1794164851, 758705101, 3432477330, 2444286583, 3418335022, 1704792676, 3636392261, 2252475950},
-				A:      [7]uint32{917265208, 2625191631, 1208269651, 4060029441, 3006062624, 4058222987, 924253983},
-				PC:     1638024020,
-				SR:     9477,
-				USP:    7471700,
-				SSP:    15119514,
-				RAM:    [][2]uint32{{9348612, 68}, {9348613, 102}, {9348614, 148}, {9348615, 239}, {15119526, 166}, {15119527, 8}, {15119522, 165}, {15119523, 5}, {15119524, 0}, {15119525, 142}, {15119520, 68}, {15119521, 102}, {15119518, 255}, {15119519, 31}, {15119514, 68}, {15119515, 117}, {15119516, 55}, {15119517, 22}, {12, 97}, {13, 162}, {14, 67}, {15, 80}, {10634064, 89}, {10634065, 254}, {10634066, 207}, {10634067, 38}},
-				Halted: true,
+				D:   [8]uint32{1794164851, 758705101, 3432477330, 2444286583, 3418335022, 1704792676, 3636392261, 2252475950},
+				A:   [7]uint32{917265208, 2625191631, 1208269651, 4060029441, 3006062624, 4058222987, 924253983},
+				PC:  1638024020,
+				SR:  9477,
+				USP: 7471700,
+				SSP: 15119514,
+				RAM: [][2]uint32{{9348612, 68}, {9348613, 102}, {9348614, 148}, {9348615, 239}, {15119526, 166}, {15119527, 8}, {15119522, 165}, {15119523, 5}, {15119524, 0}, {15119525, 142}, {15119520, 68}, {15119521, 102}, {15119518, 255}, {15119519, 31}, {15119514, 68}, {15119515, 117}, {15119516, 55}, {15119517, 22}, {12, 97}, {13, 162}, {14, 67}, {15, 80}, {10634064, 89}, {10634065, 254}, {10634066, 207}, {10634067, 38}},
 			},
 		},
 		{
@@ -8492,14 +8546,13 @@ func TestNEG_W(t *testing.T) {
 				RAM: [][2]uint32{{3623612, 68}, {3623613, 107}, {3623614, 220}, {3623615, 132}, {3623616, 146}, {3623617, 57}, {12, 57}, {13, 8}, {14, 227}, {15, 42}, {582442, 221}, {582443, 66}, {582444, 37}, {582445, 31}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1893049647, 3815111632, 4116717305, 1969157946, 2776697103, 4051343205, 3645149036, 304192302},
-				A:      [7]uint32{1932126572, 446931892, 402232159, 1540546781, 3752128603, 1546144732, 1782733958},
-				PC:     956883758,
-				SR:     9734,
-				USP:    3634900,
-				SSP:    5757284,
-				RAM:    [][2]uint32{{3623612, 68}, {3623613, 107}, {3623614, 220}, {3623615, 132}, {3623616, 146}, {3623617, 57}, {5757296, 74}, {5757297, 190}, {5757292, 166}, {5757293, 6}, {5757294, 0}, {5757295, 55}, {5757290, 68}, {5757291, 107}, {5757288, 189}, {5757289, 97}, {5757284, 68}, {5757285, 117}, {5757286, 91}, {5757287, 210}, {12, 57}, {13, 8}, {14, 227}, {15, 42}, {582442, 221}, {582443, 66}, {582444, 37}, {582445, 31}},
-				Halted: true,
+				D:   [8]uint32{1893049647, 3815111632, 4116717305, 1969157946, 2776697103, 4051343205, 3645149036, 304192302},
+				A:   [7]uint32{1932126572, 446931892, 402232159, 1540546781, 3752128603, 1546144732, 1782733958},
+				PC:  956883758,
+				SR:  9734,
+				USP: 3634900,
+				SSP: 5757284,
+				RAM: [][2]uint32{{3623612, 68}, {3623613, 107}, {3623614, 220}, {3623615, 132}, {3623616, 146}, {3623617, 57}, {5757296, 74}, {5757297, 190}, {5757292, 166}, {5757293, 6}, {5757294, 0}, {5757295, 55}, {5757290, 68}, {5757291, 107}, {5757288, 189}, {5757289, 97}, {5757284, 68}, {5757285, 117}, {5757286, 91}, {5757287, 210}, {12, 57}, {13, 8}, {14, 227}, {15, 42}, {582442, 221}, {582443, 66}, {582444, 37}, {582445, 31}},
 			},
 		},
 		{
@@ -8748,14 +8801,13 @@ func TestNEG_L(t *testing.T) {
 				RAM: [][2]uint32{{2765294, 68}, {2765295, 161}, {2765296, 228}, {2765297, 116}, {12, 91}, {13, 164}, {14, 17}, {15, 234}, {10752490, 198}, {10752491, 11}, {10752492, 116}, {10752493, 100}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3820270538, 3167951532, 4280191509, 1841217126, 3700595061, 1792962292, 992386636, 3652894431},
-				A:      [7]uint32{3643201910, 2513309115, 1797912110, 1722048088, 4228619898, 2965726583, 2659334603},
-				PC:     1537479150,
-				SR:     8711,
-				USP:    9858294,
-				SSP:    4355438,
-				RAM:    [][2]uint32{{2765294, 68}, {2765295, 161}, {2765296, 228}, {2765297, 116}, {4355450, 49}, {4355451, 240}, {4355446, 2}, {4355447, 7}, {4355448, 0}, {4355449, 42}, {4355444, 68}, {4355445, 161}, {4355442, 13}, {4355443, 187}, {4355438, 68}, {4355439, 177}, {4355440, 149}, {4355441, 206}, {12, 91}, {13, 164}, {14, 17}, {15, 234}, {10752490, 198}, {10752491, 11}, {10752492, 116}, {10752493, 100}},
-				Halted: true,
+				D:   [8]uint32{3820270538, 3167951532, 4280191509, 1841217126, 3700595061, 1792962292, 992386636, 3652894431},
+				A:   [7]uint32{3643201910, 2513309115, 1797912110, 1722048088, 4228619898, 2965726583, 2659334603},
+				PC:  1537479150,
+				SR:  8711,
+				USP: 9858294,
+				SSP: 4355438,
+				RAM: [][2]uint32{{2765294, 68}, {2765295, 161}, {2765296, 228}, {2765297, 116}, {4355450, 49}, {4355451, 240}, {4355446, 2}, {4355447, 7}, {4355448, 0}, {4355449, 42}, {4355444, 68}, {4355445, 161}, {4355442, 13}, {4355443, 187}, {4355438, 68}, {4355439, 177}, {4355440, 149}, {4355441, 206}, {12, 91}, {13, 164}, {14, 17}, {15, 234}, {10752490, 198}, {10752491, 11}, {10752492, 116}, {10752493, 100}},
 			},
 		},
 		{
@@ -9370,14 +9422,13 @@ func TestNEGX_W(t *testing.T) {
 				RAM: [][2]uint32{{2523456, 64}, {2523457, 105}, {2523458, 230}, {2523459, 181}, {2523460, 225}, {2523461, 98}, {12, 137}, {13, 86}, {14, 131}, {15, 142}, {5669774, 14}, {5669775, 36}, {5669776, 111}, {5669777, 86}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1064059708, 2948880760, 3150013704, 1370511521, 1090054400, 2992938096, 1579011178, 3096968114},
-				A:      [7]uint32{671629648, 1609631514, 2343267296, 3868328393, 2033000172, 1710072273, 813654814},
-				PC:     2304148370,
-				SR:     8470,
-				USP:    14584866,
-				SSP:    6486842,
-				RAM:    [][2]uint32{{2523456, 64}, {2523457, 105}, {2523458, 230}, {2523459, 181}, {2523460, 225}, {2523461, 98}, {6486854, 129}, {6486855, 66}, {6486850, 33}, {6486851, 22}, {6486852, 0}, {6486853, 38}, {6486848, 64}, {6486849, 105}, {6486846, 237}, {6486847, 207}, {6486842, 64}, {6486843, 117}, {6486844, 95}, {6486845, 240}, {12, 137}, {13, 86}, {14, 131}, {15, 142}, {5669774, 14}, {5669775, 36}, {5669776, 111}, {5669777, 86}},
-				Halted: true,
+				D:   [8]uint32{1064059708, 2948880760, 3150013704, 1370511521, 1090054400, 2992938096, 1579011178, 3096968114},
+				A:   [7]uint32{671629648, 1609631514, 2343267296, 3868328393, 2033000172, 1710072273, 813654814},
+				PC:  2304148370,
+				SR:  8470,
+				USP: 14584866,
+				SSP: 6486842,
+				RAM: [][2]uint32{{2523456, 64}, {2523457, 105}, {2523458, 230}, {2523459, 181}, {2523460, 225}, {2523461, 98}, {6486854, 129}, {6486855, 66}, {6486850, 33}, {6486851, 22}, {6486852, 0}, {6486853, 38}, {6486848, 64}, {6486849, 105}, {6486846, 237}, {6486847, 207}, {6486842, 64}, {6486843, 117}, {6486844, 95}, {6486845, 240}, {12, 137}, {13, 86}, {14, 131}, {15, 142}, {5669774, 14}, {5669775, 36}, {5669776, 111}, {5669777, 86}},
 			},
 		},
 		{
@@ -9392,14 +9443,13 @@ func TestNEGX_W(t *testing.T) {
 				RAM: [][2]uint32{{5567404, 64}, {5567405, 110}, {5567406, 63}, {5567407, 132}, {5567408, 36}, {5567409, 216}, {12, 30}, {13, 220}, {14, 45}, {15, 74}, {14429514, 16}, {14429515, 8}, {14429516, 245}, {14429517, 121}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2777281223, 732087108, 3932581887, 910388629, 2612108473, 418631947, 3082288770, 1234535461},
-				A:      [7]uint32{2239970411, 1087513444, 3180514328, 2186521960, 2251003407, 1638231520, 2741287547},
-				PC:     517745998,
-				SR:     8718,
-				USP:    8099216,
-				SSP:    3790802,
-				RAM:    [][2]uint32{{5567404, 64}, {5567405, 110}, {5567406, 63}, {5567407, 132}, {5567408, 36}, {5567409, 216}, {3790814, 243}, {3790815, 174}, {3790810, 2}, {3790811, 14}, {3790812, 0}, {3790813, 84}, {3790808, 64}, {3790809, 110}, {3790806, 249}, {3790807, 255}, {3790802, 64}, {3790803, 113}, {3790804, 163}, {3790805, 100}, {12, 30}, {13, 220}, {14, 45}, {15, 74}, {14429514, 16}, {14429515, 8}, {14429516, 245}, {14429517, 121}},
-				Halted: true,
+				D:   [8]uint32{2777281223, 732087108, 3932581887, 910388629, 2612108473, 418631947, 3082288770, 1234535461},
+				A:   [7]uint32{2239970411, 1087513444, 3180514328, 2186521960, 2251003407, 1638231520, 2741287547},
+				PC:  517745998,
+				SR:  8718,
+				USP: 8099216,
+				SSP: 3790802,
+				RAM: [][2]uint32{{5567404, 64}, {5567405, 110}, {5567406, 63}, {5567407, 132}, {5567408, 36}, {5567409, 216}, {3790814, 243}, {3790815, 174}, {3790810, 2}, {3790811, 14}, {3790812, 0}, {3790813, 84}, {3790808, 64}, {3790809, 110}, {3790806, 249}, {3790807, 255}, {3790802, 64}, {3790803, 113}, {3790804, 163}, {3790805, 100}, {12, 30}, {13, 220}, {14, 45}, {15, 74}, {14429514, 16}, {14429515, 8}, {14429516, 245}, {14429517, 121}},
 			},
 		},
 		{
@@ -9414,14 +9464,13 @@ func TestNEGX_W(t *testing.T) {
 				RAM: [][2]uint32{{2208730, 64}, {2208731, 113}, {2208732, 112}, {2208733, 27}, {2208734, 64}, {2208735, 68}, {12, 73}, {13, 228}, {14, 88}, {15, 2}, {14964738, 28}, {14964739, 180}, {14964740, 215}, {14964741, 108}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3842873051, 4137188192, 181526536, 75040596, 1482918950, 319385367, 783924732, 971181099},
-				A:      [7]uint32{1532412480, 1702480641, 1038355970, 2617144223, 3119047569, 317415789, 270821705},
-				PC:     1239701510,
-				SR:     8706,
-				USP:    178180,
-				SSP:    16349666,
-				RAM:    [][2]uint32{{2208730, 64}, {2208731, 113}, {2208732, 112}, {2208733, 27}, {2208734, 64}, {2208735, 68}, {16349678, 179}, {16349679, 220}, {16349674, 2}, {16349675, 2}, {16349676, 0}, {16349677, 33}, {16349672, 64}, {16349673, 113}, {16349670, 215}, {16349671, 71}, {16349666, 64}, {16349667, 113}, {16349668, 101}, {16349669, 121}, {12, 73}, {13, 228}, {14, 88}, {15, 2}, {14964738, 28}, {14964739, 180}, {14964740, 215}, {14964741, 108}},
-				Halted: true,
+				D:   [8]uint32{3842873051, 4137188192, 181526536, 75040596, 1482918950, 319385367, 783924732, 971181099},
+				A:   [7]uint32{1532412480, 1702480641, 1038355970, 2617144223, 3119047569, 317415789, 270821705},
+				PC:  1239701510,
+				SR:  8706,
+				USP: 178180,
+				SSP: 16349666,
+				RAM: [][2]uint32{{2208730, 64}, {2208731, 113}, {2208732, 112}, {2208733, 27}, {2208734, 64}, {2208735, 68}, {16349678, 179}, {16349679, 220}, {16349674, 2}, {16349675, 2}, {16349676, 0}, {16349677, 33}, {16349672, 64}, {16349673, 113}, {16349670, 215}, {16349671, 71}, {16349666, 64}, {16349667, 113}, {16349668, 101}, {16349669, 121}, {12, 73}, {13, 228}, {14, 88}, {15, 2}, {14964738, 28}, {14964739, 180}, {14964740, 215}, {14964741, 108}},
 			},
 		},
 		{
@@ -9780,14 +9829,13 @@ func TestNEGX_L(t *testing.T) {
 				RAM: [][2]uint32{{9318516, 64}, {9318517, 163}, {9318518, 252}, {9318519, 239}, {12, 233}, {13, 180}, {14, 32}, {15, 46}, {11804718, 183}, {11804719, 88}, {11804720, 24}, {11804721, 247}},
 			},
 			want: cpuState{
-				D:      [8]uint32{93941123, 2705081736, 4215729906, 780671896, 3329343442, 2263349083, 4028802248, 4006990731},
-				A:      [7]uint32{561855232, 3693713646, 963574217, 491910595, 1574843593, 3285678042, 1871124792},
-				PC:     3920896050,
-				SR:     9994,
-				USP:    2873848,
-				SSP:    9189324,
-				RAM:    [][2]uint32{{9318516, 64}, {9318517, 163}, {9318518, 252}, {9318519, 239}, {9189336, 48}, {9189337, 118}, {9189332, 7}, {9189333, 10}, {9189334, 0}, {9189335, 142}, {9189330, 64}, {9189331, 163}, {9189328, 245}, {9189329, 195}, {9189324, 64}, {9189325, 177}, {9189326, 29}, {9189327, 81}, {12, 233}, {13, 180}, {14, 32}, {15, 46}, {11804718, 183}, {11804719, 88}, {11804720, 24}, {11804721, 247}},
-				Halted: true,
+				D:   [8]uint32{93941123, 2705081736, 4215729906, 780671896, 3329343442, 2263349083, 4028802248, 4006990731},
+				A:   [7]uint32{561855232, 3693713646, 963574217, 491910595, 1574843593, 3285678042, 1871124792},
+				PC:  3920896050,
+				SR:  9994,
+				USP: 2873848,
+				SSP: 9189324,
+				RAM: [][2]uint32{{9318516, 64}, {9318517, 163}, {9318518, 252}, {9318519, 239}, {9189336, 48}, {9189337, 118}, {9189332, 7}, {9189333, 10}, {9189334, 0}, {9189335, 142}, {9189330, 64}, {9189331, 163}, {9189328, 245}, {9189329, 195}, {9189324, 64}, {9189325, 177}, {9189326, 29}, {9189327, 81}, {12, 233}, {13, 180}, {14, 32}, {15, 46}, {11804718, 183}, {11804719, 88}, {11804720, 24}, {11804721, 247}},
 			},
 		},
 		{
@@ -10358,14 +10406,13 @@ func TestCLR_W(t *testing.T) {
 				RAM: [][2]uint32{{3502048, 66}, {3502049, 81}, {3502050, 139}, {3502051, 55}, {12, 200}, {13, 18}, {14, 7}, {15, 210}, {1181650, 0}, {1181651, 150}, {1181652, 110}, {1181653, 135}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3041134975, 619927195, 455679472, 616348039, 676966442, 3759168515, 4132559661, 899751483},
-				A:      [7]uint32{3654440679, 3299587987, 881081507, 3905665912, 3977063869, 761466378, 80998878},
-				PC:     3356624854,
-				SR:     8710,
-				USP:    2250414,
-				SSP:    7009752,
-				RAM:    [][2]uint32{{3502048, 66}, {3502049, 81}, {3502050, 139}, {3502051, 55}, {7009764, 111}, {7009765, 226}, {7009760, 2}, {7009761, 6}, {7009762, 0}, {7009763, 53}, {7009758, 66}, {7009759, 81}, {7009756, 183}, {7009757, 147}, {7009752, 66}, {7009753, 81}, {7009754, 196}, {7009755, 171}, {12, 200}, {13, 18}, {14, 7}, {15, 210}, {1181650, 0}, {1181651, 150}, {1181652, 110}, {1181653, 135}},
-				Halted: true,
+				D:   [8]uint32{3041134975, 619927195, 455679472, 616348039, 676966442, 3759168515, 4132559661, 899751483},
+				A:   [7]uint32{3654440679, 3299587987, 881081507, 3905665912, 3977063869, 761466378, 80998878},
+				PC:  3356624854,
+				SR:  8710,
+				USP: 2250414,
+				SSP: 7009752,
+				RAM: [][2]uint32{{3502048, 66}, {3502049, 81}, {3502050, 139}, {3502051, 55}, {7009764, 111}, {7009765, 226}, {7009760, 2}, {7009761, 6}, {7009762, 0}, {7009763, 53}, {7009758, 66}, {7009759, 81}, {7009756, 183}, {7009757, 147}, {7009752, 66}, {7009753, 81}, {7009754, 196}, {7009755, 171}, {12, 200}, {13, 18}, {14, 7}, {15, 210}, {1181650, 0}, {1181651, 150}, {1181652, 110}, {1181653, 135}},
 			},
 		},
 		{
@@ -10402,14 +10449,13 @@ func TestCLR_W(t *testing.T) {
 				RAM: [][2]uint32{{7351368, 66}, {7351369, 84}, {7351370, 202}, {7351371, 201}, {12, 93}, {13, 166}, {14, 36}, {15, 220}, {10888412, 82}, {10888413, 202}, {10888414, 12}, {10888415, 22}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2896565739, 3076345286, 2655497447, 390565713, 2877380110, 3970568900, 3694603600, 84658276},
-				A:      [7]uint32{1786688504, 1055503422, 1136458042, 611010879, 1875277675, 2487906714, 429223861},
-				PC:     1571169504,
-				SR:     10009,
-				USP:    6291014,
-				SSP:    9227008,
-				RAM:    [][2]uint32{{7351368, 66}, {7351369, 84}, {7351370, 202}, {7351371, 201}, {9227020, 44}, {9227021, 74}, {9227016, 167}, {9227017, 25}, {9227018, 0}, {9227019, 112}, {9227014, 66}, {9227015, 84}, {9227012, 119}, {9227013, 107}, {9227008, 66}, {9227009, 85}, {9227010, 111}, {9227011, 198}, {12, 93}, {13, 166}, {14, 36}, {15, 220}, {10888412, 82}, {10888413, 202}, {10888414, 12}, {10888415, 22}},
-				Halted: true,
+				D:   [8]uint32{2896565739, 3076345286, 2655497447, 390565713, 2877380110, 3970568900, 3694603600, 84658276},
+				A:   [7]uint32{1786688504, 1055503422, 1136458042, 611010879, 1875277675, 2487906714, 429223861},
+				PC:  1571169504,
+				SR:  10009,
+				USP: 6291014,
+				SSP: 9227008,
+				RAM: [][2]uint32{{7351368, 66}, {7351369, 84}, {7351370, 202}, {7351371, 201}, {9227020, 44}, {9227021, 74}, {9227016, 167}, {9227017, 25}, {9227018, 0}, {9227019, 112}, {9227014, 66}, {9227015, 84}, {9227012, 119}, {9227013, 107}, {9227008, 66}, {9227009, 85}, {9227010, 111}, {9227011, 198}, {12, 93}, {13, 166}, {14, 36}, {15, 220}, {10888412, 82}, {10888413, 202}, {10888414, 12}, {10888415, 22}},
 			},
 		},
 		{
@@ -10424,14 +10470,13 @@ func TestCLR_W(t *testing.T) {
 				RAM: [][2]uint32{{16048906, 66}, {16048907, 116}, {16048908, 58}, {16048909, 89}, {16048910, 206}, {16048911, 42}, {12, 51}, {13, 198}, {14, 188}, {15, 76}, {13024332, 161}, {13024333, 225}, {13024334, 85}, {13024335, 168}},
 			},
 			want: cpuState{
-				D:      [8]uint32{224110873, 1800340085, 3971891236, 2464684371, 3351005794, 3333422142, 1688094349, 3767663999},
-				A:      [7]uint32{4072552275, 3374003887, 108066808, 3656477495, 3613464847, 1614756257, 2433830451},
-				PC:     868662352,
-				SR:     9496,
-				USP:    4271470,
-				SSP:    595694,
-				RAM:    [][2]uint32{{16048906, 66}, {16048907, 116}, {16048908, 58}, {16048909, 89}, {16048910, 206}, {16048911, 42}, {595706, 227}, {595707, 12}, {595702, 37}, {595703, 24}, {595704, 0}, {595705, 244}, {595700, 66}, {595701, 116}, {595698, 50}, {595699, 187}, {595694, 66}, {595695, 117}, {595696, 106}, {595697, 73}, {12, 51}, {13, 198}, {14, 188}, {15, 76}, {13024332, 161}, {13024333, 225}, {13024334, 85}, {13024335, 168}},
-				Halted: true,
+				D:   [8]uint32{224110873, 1800340085, 3971891236, 2464684371, 3351005794, 3333422142, 1688094349, 3767663999},
+				A:   [7]uint32{4072552275, 3374003887, 108066808, 3656477495, 3613464847, 1614756257, 2433830451},
+				PC:  868662352,
+				SR:  9496,
+				USP: 4271470,
+				SSP: 595694,
+				RAM: [][2]uint32{{16048906, 66}, {16048907, 116}, {16048908, 58}, {16048909, 89}, {16048910, 206}, {16048911, 42}, {595706, 227}, {595707, 12}, {595702, 37}, {595703, 24}, {595704, 0}, {595705, 244}, {595700, 66}, {595701, 116}, {595698, 50}, {595699, 187}, {595694, 66}, {595695, 117}, {595696, 106}, {595697, 73}, {12, 51}, {13, 198}, {14, 188}, {15, 76}, {13024332, 161}, {13024333, 225}, {13024334, 85}, {13024335, 168}},
 			},
 		},
 		{
@@ -10658,14 +10703,13 @@ func TestCLR_L(t *testing.T) {
 				RAM: [][2]uint32{{359472, 66}, {359473, 155}, {359474, 139}, {359475, 57}, {12, 13}, {13, 70}, {14, 176}, {15, 194}, {4632770, 1}, {4632771, 18}, {4632772, 109}, {4632773, 176}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2422470949, 2352751603, 3310642392, 2557582276, 380119850, 920096879, 3616229318, 1779579187},
-				A:      [7]uint32{2692427444, 1322854950, 1696773387, 2211182001, 787681578, 2580602796, 847691825},
-				PC:     222736582,
-				SR:     8716,
-				USP:    1864286,
-				SSP:    16661740,
-				RAM:    [][2]uint32{{359472, 66}, {359473, 155}, {359474, 139}, {359475, 57}, {16661752, 124}, {16661753, 50}, {16661748, 2}, {16661749, 12}, {16661750, 0}, {16661751, 5}, {16661746, 66}, {16661747, 155}, {16661744, 245}, {16661745, 177}, {16661740, 66}, {16661741, 145}, {16661742, 131}, {16661743, 203}, {12, 13}, {13, 70}, {14, 176}, {15, 194}, {4632770, 1}, {4632771, 18}, {4632772, 109}, {4632773, 176}},
-				Halted: true,
+				D:   [8]uint32{2422470949, 2352751603, 3310642392, 2557582276, 380119850, 920096879, 3616229318, 1779579187},
+				A:   [7]uint32{2692427444, 1322854950, 1696773387, 2211182001, 787681578, 2580602796, 847691825},
+				PC:  222736582,
+				SR:  8716,
+				USP: 1864286,
+				SSP: 16661740,
+				RAM: [][2]uint32{{359472, 66}, {359473, 155}, {359474, 139}, {359475, 57}, {16661752, 124}, {16661753, 50}, {16661748, 2}, {16661749, 12}, {16661750, 0}, {16661751, 5}, {16661746, 66}, {16661747, 155}, {16661744, 245}, {16661745, 177}, {16661740, 66}, {16661741, 145}, {16661742, 131}, {16661743, 203}, {12, 13}, {13, 70}, {14, 176}, {15, 194}, {4632770, 1}, {4632771, 18}, {4632772, 109}, {4632773, 176}},
 			},
 		},
 		{
@@ -10680,14 +10724,13 @@ func TestCLR_L(t *testing.T) {
 				RAM: [][2]uint32{{141336, 66}, {141337, 146}, {141338, 160}, {141339, 68}, {12, 218}, {13, 34}, {14, 205}, {15, 100}, {2280804, 84}, {2280805, 22}, {2280806, 7}, {2280807, 248}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1320146185, 466617488, 3787594080, 264785890, 2208218129, 1304777018, 850086501, 1633749877},
-				A:      [7]uint32{718505777, 2311097494, 3988530849, 3198524845, 2188655873, 3440972885, 3617184241},
-				PC:     3659713896,
-				SR:     9498,
-				USP:    13595982,
-				SSP:    7228818,
-				RAM:    [][2]uint32{{141336, 66}, {141337, 146}, {141338, 160}, {141339, 68}, {7228830, 40}, {7228831, 26}, {7228826, 165}, {7228827, 26}, {7228828, 0}, {7228829, 2}, {7228824, 66}, {7228825, 146}, {7228822, 38}, {7228823, 161}, {7228818, 66}, {7228819, 149}, {7228820, 237}, {7228821, 188}, {12, 218}, {13, 34}, {14, 205}, {15, 100}, {2280804, 84}, {2280805, 22}, {2280806, 7}, {2280807, 248}},
-				Halted: true,
+				D:   [8]uint32{1320146185, 466617488, 3787594080, 264785890, 2208218129, 1304777018, 850086501, 1633749877},
+				A:   [7]uint32{718505777, 2311097494, 3988530849, 3198524845, 2188655873, 3440972885, 3617184241},
+				PC:  3659713896,
+				SR:  9498,
+				USP: 13595982,
+				SSP: 7228818,
+				RAM: [][2]uint32{{141336, 66}, {141337, 146}, {141338, 160}, {141339, 68}, {7228830, 40}, {7228831, 26}, {7228826, 165}, {7228827, 26}, {7228828, 0}, {7228829, 2}, {7228824, 66}, {7228825, 146}, {7228822, 38}, {7228823, 161}, {7228818, 66}, {7228819, 149}, {7228820, 237}, {7228821, 188}, {12, 218}, {13, 34}, {14, 205}, {15, 100}, {2280804, 84}, {2280805, 22}, {2280806, 7}, {2280807, 248}},
 			},
 		},
 		{
@@ -10702,14 +10745,13 @@ func TestCLR_L(t *testing.T) {
 				RAM: [][2]uint32{{11196970, 66}, {11196971, 181}, {11196972, 102}, {11196973, 7}, {11196974, 29}, {11196975, 49}, {12, 26}, {13, 118}, {14, 215}, {15, 108}, {7788396, 193}, {7788397, 50}, {7788398, 16}, {7788399, 117}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1752226657, 1937529842, 1652178587, 4205885453, 1807083091, 4246316356, 2048834473, 473648662},
-				A:      [7]uint32{2880357910, 1816404564, 515188618, 1442634717, 4094794215, 493503083, 3103062867},
-				PC:     443996016,
-				SR:     8711,
-				USP:    11796638,
-				SSP:    11191340,
-				RAM:    [][2]uint32{{11196970, 66}, {11196971, 181}, {11196972, 102}, {11196973, 7}, {11196974, 29}, {11196975, 49}, {11191352, 218}, {11191353, 44}, {11191348, 162}, {11191349, 7}, {11191350, 0}, {11191351, 170}, {11191346, 66}, {11191347, 181}, {11191344, 254}, {11191345, 27}, {11191340, 66}, {11191341, 181}, {11191342, 29}, {11191343, 105}, {12, 26}, {13, 118}, {14, 215}, {15, 108}, {7788396, 193}, {7788397, 50}, {7788398, 16}, {7788399, 117}},
-				Halted: true,
+				D:   [8]uint32{1752226657, 1937529842, 1652178587, 4205885453, 1807083091, 4246316356, 2048834473, 473648662},
+				A:   [7]uint32{2880357910, 1816404564, 515188618, 1442634717, 4094794215, 493503083, 3103062867},
+				PC:  443996016,
+				SR:  8711,
+				USP: 11796638,
+				SSP: 11191340,
+				RAM: [][2]uint32{{11196970, 66}, {11196971, 181}, {11196972, 102}, {11196973, 7}, {11196974, 29}, {11196975, 49}, {11191352, 218}, {11191353, 44}, {11191348, 162}, {11191349, 7}, {11191350, 0}, {11191351, 170}, {11191346, 66}, {11191347, 181}, {11191344, 254}, {11191345, 27}, {11191340, 66}, {11191341, 181}, {11191342, 29}, {11191343, 105}, {12, 26}, {13, 118}, {14, 215}, {15, 108}, {7788396, 193}, {7788397, 50}, {7788398, 16}, {7788399, 117}},
 			},
 		},
 		{
@@ -11624,15 +11666,14 @@ func TestCHK(t *testing.T) {
 				RAM: [][2]uint32{{16476356, 77}, {16476357, 130}, {16476358, 144}, {16476359, 84}, {24, 30}, {25, 86}, {26, 243}, {27, 236}, {5698540, 245}, {5698541, 16}, {5698542, 27}, {5698543, 140}},
 			},
 			want: cpuState{
-				D:   [8]uint32{1093139940, 3229618212, 2916979269, 30567346, 377616289, 3682892879, 1066143283, 4054575747},
-				A:   [7]uint32{2332478783, 1687551685, 4067523350, 1440976318, 2531329268, 1056890715, 2838602126},
-				PC:  509015024,
-				SR:  8720,
-				USP: 8875980,
-				SSP: 1000910,
-				RAM: [][2]uint32{{16476356, 77}, {16476357, 130}, {16476358, 144}, {16476359, 84}, {1000914, 104}, {1000915, 198}, {1000910, 2}, {1000911, 16}, {1000912, 0}, {1000913, 251}, {24, 30}, {25, 86}, {26, 243}, {27, 236}, {5698540, 245}, {5698541, 16}, {5698542, 27}, {5698543, 140}},
-				// CHK trap: uses fixed 34-cycle exception cost.
-				// Cycles: 38,
+				D:      [8]uint32{1093139940, 3229618212, 2916979269, 30567346, 377616289, 3682892879, 1066143283, 4054575747},
+				A:      [7]uint32{2332478783, 1687551685, 4067523350, 1440976318, 2531329268, 1056890715, 2838602126},
+				PC:     509015024,
+				SR:     8720,
+				USP:    8875980,
+				SSP:    1000910,
+				RAM:    [][2]uint32{{16476356, 77}, {16476357, 130}, {16476358, 144}, {16476359, 84}, {1000914, 104}, {1000915, 198}, {1000910, 2}, {1000911, 16}, {1000912, 0}, {1000913, 251}, {24, 30}, {25, 86}, {26, 243}, {27, 236}, {5698540, 245}, {5698541, 16}, {5698542, 27}, {5698543, 140}},
+				Cycles: 38,
 			},
 		},
 		{
@@ -11647,15 +11688,14 @@ func TestCHK(t *testing.T) {
 				RAM: [][2]uint32{{10825212, 65}, {10825213, 159}, {10825214, 21}, {10825215, 175}, {12575608, 155}, {12575609, 69}, {24, 25}, {25, 214}, {26, 66}, {27, 18}, {14041618, 141}, {14041619, 189}, {14041620, 53}, {14041621, 163}},
 			},
 			want: cpuState{
-				D:   [8]uint32{3523711714, 4239009988, 248448306, 334338384, 3041536728, 2194808353, 3754844762, 2501061804},
-				A:   [7]uint32{3500690370, 2992759930, 2527148736, 1205025397, 700081165, 2621323504, 2647546657},
-				PC:  433472022,
-				SR:  8728,
-				USP: 12575610,
-				SSP: 1984108,
-				RAM: [][2]uint32{{10825212, 65}, {10825213, 159}, {10825214, 21}, {10825215, 175}, {12575608, 155}, {12575609, 69}, {1984112, 45}, {1984113, 254}, {1984108, 2}, {1984109, 24}, {1984110, 0}, {1984111, 165}, {24, 25}, {25, 214}, {26, 66}, {27, 18}, {14041618, 141}, {14041619, 189}, {14041620, 53}, {14041621, 163}},
-				// CHK trap: uses fixed 34-cycle exception cost.
-				// Cycles: 44,
+				D:      [8]uint32{3523711714, 4239009988, 248448306, 334338384, 3041536728, 2194808353, 3754844762, 2501061804},
+				A:      [7]uint32{3500690370, 2992759930, 2527148736, 1205025397, 700081165, 2621323504, 2647546657},
+				PC:     433472022,
+				SR:     8728,
+				USP:    12575610,
+				SSP:    1984108,
+				RAM:    [][2]uint32{{10825212, 65}, {10825213, 159}, {10825214, 21}, {10825215, 175}, {12575608, 155}, {12575609, 69}, {1984112, 45}, {1984113, 254}, {1984108, 2}, {1984109, 24}, {1984110, 0}, {1984111, 165}, {24, 25}, {25, 214}, {26, 66}, {27, 18}, {14041618, 141}, {14041619, 189}, {14041620, 53}, {14041621, 163}},
+				Cycles: 44,
 			},
 		},
 		{
@@ -11670,14 +11710,13 @@ func TestCHK(t *testing.T) {
 				RAM: [][2]uint32{{11357266, 65}, {11357267, 175}, {11357268, 186}, {11357269, 17}, {11357270, 18}, {11357271, 86}, {12, 115}, {13, 188}, {14, 214}, {15, 70}, {12375622, 33}, {12375623, 254}, {12375624, 179}, {12375625, 242}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1141050504, 2183113177, 3223073363, 4252999950, 1242126627, 2715393486, 4253075158, 3204293395},
-				A:      [7]uint32{2854607175, 1527540367, 1653101289, 1765386454, 3156707373, 258989678, 2856990996},
-				PC:     1941755466,
-				SR:     9237,
-				USP:    5832736,
-				SSP:    4876636,
-				RAM:    [][2]uint32{{11357266, 65}, {11357267, 175}, {11357268, 186}, {11357269, 17}, {11357270, 18}, {11357271, 86}, {4876648, 76}, {4876649, 84}, {4876644, 164}, {4876645, 21}, {4876646, 0}, {4876647, 173}, {4876642, 65}, {4876643, 175}, {4876640, 35}, {4876641, 123}, {4876636, 65}, {4876637, 181}, {4876638, 0}, {4876639, 74}, {12, 115}, {13, 188}, {14, 214}, {15, 70}, {12375622, 33}, {12375623, 254}, {12375624, 179}, {12375625, 242}},
-				Halted: true,
+				D:   [8]uint32{1141050504, 2183113177, 3223073363, 4252999950, 1242126627, 2715393486, 4253075158, 3204293395},
+				A:   [7]uint32{2854607175, 1527540367, 1653101289, 1765386454, 3156707373, 258989678, 2856990996},
+				PC:  1941755466,
+				SR:  9237,
+				USP: 5832736,
+				SSP: 4876636,
+				RAM: [][2]uint32{{11357266, 65}, {11357267, 175}, {11357268, 186}, {11357269, 17}, {11357270, 18}, {11357271, 86}, {4876648, 76}, {4876649, 84}, {4876644, 164}, {4876645, 21}, {4876646, 0}, {4876647, 173}, {4876642, 65}, {4876643, 175}, {4876640, 35}, {4876641, 123}, {4876636, 65}, {4876637, 181}, {4876638, 0}, {4876639, 74}, {12, 115}, {13, 188}, {14, 214}, {15, 70}, {12375622, 33}, {12375623, 254}, {12375624, 179}, {12375625, 242}},
 			},
 		},
 		{
@@ -11692,14 +11731,13 @@ func TestCHK(t *testing.T) {
 				RAM: [][2]uint32{{16306564, 77}, {16306565, 156}, {16306566, 213}, {16306567, 177}, {12, 181}, {13, 102}, {14, 128}, {15, 40}, {6717480, 30}, {6717481, 105}, {6717482, 75}, {6717483, 123}},
 			},
 			want: cpuState{
-				D:      [8]uint32{520865729, 602560383, 1545489448, 4086163120, 4216977002, 1308921722, 3347776916, 3502874075},
-				A:      [7]uint32{3422500212, 3680544159, 2183390143, 2142782103, 533566633, 3639130958, 2804153879},
-				PC:     3043393580,
-				SR:     8476,
-				USP:    3795202,
-				SSP:    5861408,
-				RAM:    [][2]uint32{{16306564, 77}, {16306565, 156}, {16306566, 213}, {16306567, 177}, {5861420, 209}, {5861421, 134}, {5861416, 1}, {5861417, 28}, {5861418, 0}, {5861419, 248}, {5861414, 77}, {5861415, 156}, {5861412, 148}, {5861413, 167}, {5861408, 77}, {5861409, 145}, {5861410, 31}, {5861411, 205}, {12, 181}, {13, 102}, {14, 128}, {15, 40}, {6717480, 30}, {6717481, 105}, {6717482, 75}, {6717483, 123}},
-				Halted: true,
+				D:   [8]uint32{520865729, 602560383, 1545489448, 4086163120, 4216977002, 1308921722, 3347776916, 3502874075},
+				A:   [7]uint32{3422500212, 3680544159, 2183390143, 2142782103, 533566633, 3639130958, 2804153879},
+				PC:  3043393580,
+				SR:  8476,
+				USP: 3795202,
+				SSP: 5861408,
+				RAM: [][2]uint32{{16306564, 77}, {16306565, 156}, {16306566, 213}, {16306567, 177}, {5861420, 209}, {5861421, 134}, {5861416, 1}, {5861417, 28}, {5861418, 0}, {5861419, 248}, {5861414, 77}, {5861415, 156}, {5861412, 148}, {5861413, 167}, {5861408, 77}, {5861409, 145}, {5861410, 31}, {5861411, 205}, {12, 181}, {13, 102}, {14, 128}, {15, 40}, {6717480, 30}, {6717481, 105}, {6717482, 75}, {6717483, 123}},
 			},
 		},
 		{
@@ -11714,14 +11752,13 @@ func TestCHK(t *testing.T) {
 				RAM: [][2]uint32{{2961976, 77}, {2961977, 178}, {2961978, 155}, {2961979, 194}, {2961980, 235}, {2961981, 217}, {12, 155}, {13, 146}, {14, 231}, {15, 112}, {9627504, 10}, {9627505, 207}, {9627506, 216}, {9627507, 12}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1127505738, 3935778481, 2930275396, 4165391571, 3372435042, 1295481911, 1686386403, 2061767005},
-				A:      [7]uint32{3397951727, 3731192102, 816769727, 822853660, 603488700, 839541561, 3044055307},
-				PC:     2610095988,
-				SR:     8727,
-				USP:    13666592,
-				SSP:    4955010,
-				RAM:    [][2]uint32{{2961976, 77}, {2961977, 178}, {2961978, 155}, {2961979, 194}, {2961980, 235}, {2961981, 217}, {4955022, 50}, {4955023, 58}, {4955018, 2}, {4955019, 23}, {4955020, 0}, {4955021, 45}, {4955016, 77}, {4955017, 178}, {4955014, 99}, {4955015, 167}, {4955010, 77}, {4955011, 177}, {4955012, 15}, {4955013, 20}, {12, 155}, {13, 146}, {14, 231}, {15, 112}, {9627504, 10}, {9627505, 207}, {9627506, 216}, {9627507, 12}},
-				Halted: true,
+				D:   [8]uint32{1127505738, 3935778481, 2930275396, 4165391571, 3372435042, 1295481911, 1686386403, 2061767005},
+				A:   [7]uint32{3397951727, 3731192102, 816769727, 822853660, 603488700, 839541561, 3044055307},
+				PC:  2610095988,
+				SR:  8727,
+				USP: 13666592,
+				SSP: 4955010,
+				RAM: [][2]uint32{{2961976, 77}, {2961977, 178}, {2961978, 155}, {2961979, 194}, {2961980, 235}, {2961981, 217}, {4955022, 50}, {4955023, 58}, {4955018, 2}, {4955019, 23}, {4955020, 0}, {4955021, 45}, {4955016, 77}, {4955017, 178}, {4955014, 99}, {4955015, 167}, {4955010, 77}, {4955011, 177}, {4955012, 15}, {4955013, 20}, {12, 155}, {13, 146}, {14, 231}, {15, 112}, {9627504, 10}, {9627505, 207}, {9627506, 216}, {9627507, 12}},
 			},
 		},
 		{
@@ -11929,3 +11966,559 @@ func TestCHK(t *testing.T) {
 		})
 	}
 }
+
+func TestDIVByZero(t *testing.T) {
+	tests := []struct {
+		name   string
+		prog   []uint16
+		next   uint32
+		cycles int
+	}{
+		{"DIVU D1,D0", []uint16{0x80C1}, 0x1002, 38},
+		{"DIVS D1,D0", []uint16{0x81C1}, 0x1002, 38},
+		{"DIVU #0,D0", []uint16{0x80FC, 0x0000}, 0x1004, 42},
+		{"DIVS (A0),D0", []uint16{0x81D0}, 0x1002, 42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			for i, w := range tt.prog {
+				writeWord(bus, 0x1000+uint32(i*2), w)
+			}
+			bus.Write32(vecDivideByZero*4, 0x3000)
+			cpu := &CPU{bus: bus}
+			cpu.SetState(Registers{
+				D:   [8]uint32{0x12345678},
+				A:   [8]uint32{0x4000},
+				PC:  0x1000,
+				SR:  0x2704,
+				SSP: 0x10000,
+			})
+
+			if n := cpu.Step(); n != tt.cycles {
+				t.Errorf("cycles = %d, want %d", n, tt.cycles)
+			}
+			reg := cpu.Registers()
+			if reg.PC != 0x3000 {
+				t.Errorf("PC = 0x%X, want handler 0x3000", reg.PC)
+			}
+			if reg.D[0] != 0x12345678 {
+				t.Errorf("D0 = 0x%08X, want unchanged", reg.D[0])
+			}
+			if reg.A[7] != 0x10000-6 {
+				t.Fatalf("SSP = 0x%X, want 0x%X", reg.A[7], 0x10000-6)
+			}
+			if sr := bus.Read16(0x10000 - 6); sr != 0x2704 {
+				t.Errorf("stacked SR = 0x%04X, want 0x2704", sr)
+			}
+			if pc := bus.Read32(0x10000 - 4); pc != tt.next {
+				t.Errorf("stacked PC = 0x%X, want next instruction 0x%X", pc, tt.next)
+			}
+		})
+	}
+}
+
+// TestXmemSameRegister covers the -(Ay),-(Ax) forms of ADDX, SUBX, ABCD and
+// SBCD when both operands use the same register or A7. The source is
+// decremented and read before the destination, so with Ax == Ay the
+// operands are adjacent and the register ends two operand sizes lower; A7
+// always moves by 2 for bytes.
+func TestXmemSameRegister(t *testing.T) {
+	tests := []struct {
+		name   string
+		op     uint16
+		a0, a1 uint32
+		ssp    uint32
+		mem    [][2]uint32 // initial bytes
+		wantA0 uint32
+		wantA1 uint32
+		wantSP uint32
+		want   [][2]uint32 // resulting bytes
+	}{
+		{
+			name: "ADDX.B -(A0),-(A0)", op: 0xD108, a0: 0x4002, ssp: 0x10000,
+			mem:    [][2]uint32{{0x4000, 0x03}, {0x4001, 0x05}},
+			wantA0: 0x4000, wantSP: 0x10000,
+			want: [][2]uint32{{0x4000, 0x08}, {0x4001, 0x05}},
+		},
+		{
+			name: "ADDX.W -(A0),-(A0)", op: 0xD148, a0: 0x4004, ssp: 0x10000,
+			mem:    [][2]uint32{{0x4001, 0x02}, {0x4003, 0x01}},
+			wantA0: 0x4000, wantSP: 0x10000,
+			want: [][2]uint32{{0x4000, 0x00}, {0x4001, 0x03}},
+		},
+		{
+			name: "ADDX.L -(A0),-(A0)", op: 0xD188, a0: 0x4008, ssp: 0x10000,
+			mem:    [][2]uint32{{0x4003, 0x20}, {0x4007, 0x10}},
+			wantA0: 0x4000, wantSP: 0x10000,
+			want: [][2]uint32{{0x4003, 0x30}, {0x4007, 0x10}},
+		},
+		{
+			name: "SUBX.B -(A7),-(A7)", op: 0x9F0F, ssp: 0x4004,
+			mem:    [][2]uint32{{0x4000, 0x05}, {0x4002, 0x01}},
+			wantSP: 0x4000,
+			want:   [][2]uint32{{0x4000, 0x04}, {0x4002, 0x01}},
+		},
+		{
+			name: "ADDX.B -(A7),-(A1)", op: 0xD30F, a1: 0x5001, ssp: 0x4004,
+			mem:    [][2]uint32{{0x4002, 0x01}, {0x5000, 0x02}},
+			wantA1: 0x5000, wantSP: 0x4002,
+			want: [][2]uint32{{0x5000, 0x03}},
+		},
+		{
+			name: "ABCD -(A0),-(A0)", op: 0xC108, a0: 0x4002, ssp: 0x10000,
+			mem:    [][2]uint32{{0x4000, 0x23}, {0x4001, 0x19}},
+			wantA0: 0x4000, wantSP: 0x10000,
+			want: [][2]uint32{{0x4000, 0x42}, {0x4001, 0x19}},
+		},
+		{
+			name: "SBCD -(A7),-(A7)", op: 0x8F0F, ssp: 0x4004,
+			mem:    [][2]uint32{{0x4000, 0x42}, {0x4002, 0x15}},
+			wantSP: 0x4000,
+			want:   [][2]uint32{{0x4000, 0x27}, {0x4002, 0x15}},
+		},
+		{
+			name: "ABCD -(A7),-(A0)", op: 0xC10F, a0: 0x5001, ssp: 0x4004,
+			mem:    [][2]uint32{{0x4002, 0x15}, {0x5000, 0x27}},
+			wantA0: 0x5000, wantSP: 0x4002,
+			want: [][2]uint32{{0x5000, 0x42}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			writeWord(bus, 0x1000, tt.op)
+			for _, m := range tt.mem {
+				bus.mem[m[0]] = byte(m[1])
+			}
+			cpu := &CPU{bus: bus}
+			cpu.SetState(Registers{A: [8]uint32{tt.a0, tt.a1}, PC: 0x1000, SR: 0x2700, SSP: tt.ssp})
+			cpu.Step()
+
+			reg := cpu.Registers()
+			if reg.A[0] != tt.wantA0 || reg.A[1] != tt.wantA1 || reg.A[7] != tt.wantSP {
+				t.Errorf("A0/A1/A7 = 0x%X/0x%X/0x%X, want 0x%X/0x%X/0x%X",
+					reg.A[0], reg.A[1], reg.A[7], tt.wantA0, tt.wantA1, tt.wantSP)
+			}
+			for _, m := range tt.want {
+				if got := bus.mem[m[0]]; got != byte(m[1]) {
+					t.Errorf("RAM[0x%X] = 0x%02X, want 0x%02X", m[0], got, m[1])
+				}
+			}
+		})
+	}
+}
+
+func TestCHK2(t *testing.T) {
+	tests := []struct {
+		name    string
+		prog    []uint16
+		bounds  []byte
+		d1, a1  uint32
+		wantCCR uint16
+		trap    bool
+	}{
+		// CMP2.B (A0),D1 with signed bounds -10..10
+		{"signed in bounds", []uint16{0x00D0, 0x1000}, []byte{0xF6, 0x0A}, 0x12345605, 0, 0, false},
+		{"signed lower bound", []uint16{0x00D0, 0x1000}, []byte{0xF6, 0x0A}, 0xF6, 0, flagZ, false},
+		{"signed above", []uint16{0x00D0, 0x1000}, []byte{0xF6, 0x0A}, 0x0B, 0, flagC, false},
+		{"signed below", []uint16{0x00D0, 0x1000}, []byte{0xF6, 0x0A}, 0x80, 0, flagC, false},
+		// CMP2.B (A0),D1 with unsigned bounds $10..$F0
+		{"unsigned in bounds", []uint16{0x00D0, 0x1000}, []byte{0x10, 0xF0}, 0x80, 0, 0, false},
+		{"unsigned upper bound", []uint16{0x00D0, 0x1000}, []byte{0x10, 0xF0}, 0xF0, 0, flagZ, false},
+		{"unsigned below", []uint16{0x00D0, 0x1000}, []byte{0x10, 0xF0}, 0x05, 0, flagC, false},
+		// CMP2.W (A0),A1: bounds are sign-extended and A1 compared in full
+		{"address in bounds", []uint16{0x02D0, 0x9000}, []byte{0xFF, 0xF0, 0x00, 0x10}, 0, 0xFFFFFFF8, 0, false},
+		{"address outside", []uint16{0x02D0, 0x9000}, []byte{0xFF, 0xF0, 0x00, 0x10}, 0, 0x0000FFF8, flagC, false},
+		// CHK2.W (A0),D1
+		{"CHK2 in bounds", []uint16{0x02D0, 0x1800}, []byte{0x00, 0x10, 0x00, 0x20}, 0x20, 0, flagZ, false},
+		{"CHK2 traps", []uint16{0x02D0, 0x1800}, []byte{0x00, 0x10, 0x00, 0x20}, 0x21, 0, flagC, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			for i, w := range tt.prog {
+				writeWord(bus, 0x1000+uint32(i*2), w)
+			}
+			copy(bus.mem[0x2000:], tt.bounds)
+			bus.Write32(vecCHK*4, 0x3000)
+			cpu := &CPU{bus: bus, variant: MC68020}
+			cpu.SetState(Registers{
+				D: [8]uint32{1: tt.d1}, A: [8]uint32{0x2000, tt.a1},
+				PC: 0x1000, SR: 0x2718, SSP: 0x10000,
+			})
+			cpu.Step()
+			// N and X are left alone
+			if got := cpu.SR() & 0x1F; got != tt.wantCCR|flagN|flagX {
+				t.Errorf("CCR = 0x%02X, want 0x%02X", got, tt.wantCCR|flagN|flagX)
+			}
+			want := uint32(0x1004)
+			if tt.trap {
+				want = 0x3000
+			}
+			if pc := cpu.PC(); pc != want {
+				t.Errorf("PC = 0x%X, want 0x%X", pc, want)
+			}
+		})
+	}
+
+	t.Run("illegal on 68010", func(t *testing.T) {
+		bus := &testBus{}
+		writeWord(bus, 0x1000, 0x02D0)
+		writeWord(bus, 0x1002, 0x1800)
+		bus.Write32(vecIllegalInstruction*4, 0x3000)
+		cpu := &CPU{bus: bus, variant: MC68010}
+		cpu.SetState(Registers{A: [8]uint32{0x2000}, PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+		cpu.Step()
+		if pc := cpu.PC(); pc != 0x3000 {
+			t.Errorf("PC = 0x%X, want the illegal instruction handler", pc)
+		}
+	})
+}
+
+// longMulDivCPU builds a 68020 CPU at 0x1000 running op with extension
+// word ext and D7 as the source operand.
+func longMulDivCPU(op, ext uint16, d [8]uint32) (*CPU, *testBus) {
+	cpu, bus := progCPU(MC68020, Registers{D: d, SR: 0x2710}, op, ext)
+	bus.Write32(vecDivideByZero*4, 0x3000)
+	return cpu, bus
+}
+
+func TestMULL(t *testing.T) {
+	tests := []struct {
+		name   string
+		ext    uint16
+		d0, d7 uint32
+		d0Want uint32
+		d1Want uint32
+		ccr    uint16
+	}{
+		// MULU.L D7,D0
+		{"MULU.L", 0x0000, 1000, 3000, 3000000, 0xFFFFFFFF, 0},
+		{"MULU.L overflow", 0x0000, 0x10000, 0x10001, 0x10000, 0xFFFFFFFF, flagV},
+		// MULS.L D7,D0
+		{"MULS.L negative", 0x0800, 0xFFFFFFFE, 3, 0xFFFFFFFA, 0xFFFFFFFF, flagN},
+		{"MULS.L overflow", 0x0800, 0x80000000, 0xFFFFFFFF, 0x80000000, 0xFFFFFFFF, flagN | flagV},
+		// MULU.L D7,D1:D0
+		{"MULU.L 64-bit", 0x0401, 0xFFFFFFFF, 0xFFFFFFFF, 0x00000001, 0xFFFFFFFE, flagN},
+		// MULS.L D7,D1:D0
+		{"MULS.L 64-bit", 0x0C01, 0x80000000, 0xFFFFFFFF, 0x80000000, 0x00000000, 0},
+		{"MULS.L 64-bit negative", 0x0C01, 0x12345678, 0xFFFFFFF0, 0xDCBA9880, 0xFFFFFFFE, flagN},
+		{"MULU.L 64-bit zero", 0x0401, 0x12345678, 0, 0, 0, flagZ},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpu, _ := longMulDivCPU(0x4C07, tt.ext, [8]uint32{tt.d0, 0xFFFFFFFF, 7: tt.d7})
+			cpu.Step()
+			if got := cpu.D(0); got != tt.d0Want {
+				t.Errorf("D0 = 0x%08X, want 0x%08X", got, tt.d0Want)
+			}
+			if got := cpu.D(1); got != tt.d1Want {
+				t.Errorf("D1 = 0x%08X, want 0x%08X", got, tt.d1Want)
+			}
+			// X is left alone
+			if got := cpu.SR() & 0x1F; got != tt.ccr|flagX {
+				t.Errorf("CCR = 0x%02X, want 0x%02X", got, tt.ccr|flagX)
+			}
+		})
+	}
+}
+
+func TestDIVL(t *testing.T) {
+	tests := []struct {
+		name           string
+		ext            uint16
+		d0, d1, d7     uint32
+		d0Want, d1Want uint32
+		ccr            uint16
+	}{
+		// DIVU.L D7,D0: the remainder is discarded
+		{"DIVU.L", 0x0000, 100, 0x55, 7, 14, 0x55, 0},
+		// DIVUL.L D7,D1:D0
+		{"DIVUL.L", 0x0001, 100, 0x55, 7, 14, 2, 0},
+		// DIVSL.L D7,D1:D0: the remainder takes the dividend's sign
+		{"DIVSL.L", 0x0801, 0xFFFFFF9C, 0x55, 7, 0xFFFFFFF2, 0xFFFFFFFE, flagN},
+		// DIVU.L D7,D1:D0
+		{"DIVU.L 64-bit", 0x0401, 0x00000005, 0x00000001, 0x10, 0x10000000, 5, 0},
+		{"DIVU.L 64-bit overflow", 0x0401, 0, 0x10, 0x10, 0, 0x10, flagV},
+		// DIVS.L D7,D1:D0: -$1_0000_0005 / 16
+		{"DIVS.L 64-bit", 0x0C01, 0xFFFFFFFB, 0xFFFFFFFE, 0x10, 0xF0000000, 0xFFFFFFFB, flagN},
+		{"DIVS.L 64-bit overflow", 0x0C01, 0, 0x80000000, 0xFFFFFFFF, 0, 0x80000000, flagV},
+		{"DIVS.L overflow", 0x0800, 0x80000000, 0x55, 0xFFFFFFFF, 0x80000000, 0x55, flagV},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpu, _ := longMulDivCPU(0x4C47, tt.ext, [8]uint32{tt.d0, tt.d1, 7: tt.d7})
+			cpu.Step()
+			if got := cpu.D(0); got != tt.d0Want {
+				t.Errorf("D0 = 0x%08X, want 0x%08X", got, tt.d0Want)
+			}
+			if got := cpu.D(1); got != tt.d1Want {
+				t.Errorf("D1 = 0x%08X, want 0x%08X", got, tt.d1Want)
+			}
+			if got := cpu.SR() & 0x1F; got != tt.ccr|flagX {
+				t.Errorf("CCR = 0x%02X, want 0x%02X", got, tt.ccr|flagX)
+			}
+			if pc := cpu.PC(); pc != 0x1004 {
+				t.Errorf("PC = 0x%X, want 0x1004", pc)
+			}
+		})
+	}
+
+	t.Run("divide by zero", func(t *testing.T) {
+		cpu, _ := longMulDivCPU(0x4C47, 0x0401, [8]uint32{5, 6})
+		cpu.Step()
+		if pc := cpu.PC(); pc != 0x3000 {
+			t.Errorf("PC = 0x%X, want the divide by zero handler", pc)
+		}
+		if d0, d1 := cpu.D(0), cpu.D(1); d0 != 5 || d1 != 6 {
+			t.Errorf("D0/D1 = %d/%d, want unchanged", d0, d1)
+		}
+	})
+
+	t.Run("illegal on 68010", func(t *testing.T) {
+		cpu, bus := longMulDivCPU(0x4C47, 0x0401, [8]uint32{5, 6, 7: 2})
+		cpu.variant = MC68010
+		bus.Write32(vecIllegalInstruction*4, 0x3100)
+		cpu.Step()
+		if pc := cpu.PC(); pc != 0x3100 {
+			t.Errorf("PC = 0x%X, want the illegal instruction handler", pc)
+		}
+	})
+}
+
+// TestToEAAddressUpdate drives each <op> D1,(A0)+ and <op> D1,-(A0) form and
+// expects A0 to move by the operand size exactly once, with the result
+// written where the operand was read.
+func TestToEAAddressUpdate(t *testing.T) {
+	ops := []struct {
+		name string
+		base uint16
+		want func(s, d uint32) uint32
+	}{
+		{"ADD", 0xD300, func(s, d uint32) uint32 { return d + s }},
+		{"SUB", 0x9300, func(s, d uint32) uint32 { return d - s }},
+		{"AND", 0xC300, func(s, d uint32) uint32 { return d & s }},
+		{"OR", 0x8300, func(s, d uint32) uint32 { return d | s }},
+		{"EOR", 0xB300, func(s, d uint32) uint32 { return d ^ s }},
+	}
+	const d1, mem = 0x0F0F0F0F, 0x12345678
+	for _, op := range ops {
+		for szBits, sz := range []size{sizeByte, sizeWord, sizeLong} {
+			for _, mode := range []uint16{3, 4} {
+				a0 := uint32(0x4000)
+				if mode == 4 {
+					a0 += uint32(sz)
+				}
+				name := op.name + sizeSuffix(sz) + " D1,(A0)+"
+				if mode == 4 {
+					name = op.name + sizeSuffix(sz) + " D1,-(A0)"
+				}
+				t.Run(name, func(t *testing.T) {
+					bus := &testBus{}
+					writeWord(bus, 0x1000, op.base|uint16(szBits)<<6|mode<<3)
+					bus.Write32(0x4000, mem)
+					cpu := &CPU{bus: bus}
+					cpu.SetState(Registers{D: [8]uint32{1: d1}, A: [8]uint32{a0}, PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+					cpu.Step()
+
+					wantA0 := 0x4000 + uint32(sz)
+					if mode == 4 {
+						wantA0 = 0x4000
+					}
+					if got := cpu.A(0); got != wantA0 {
+						t.Errorf("A0 = 0x%X, want 0x%X", got, wantA0)
+					}
+					shift := 32 - sz.Bits()
+					want := op.want(d1&sz.Mask(), mem>>shift) & sz.Mask()
+					if got := bus.Read32(0x4000) >> shift; got != want {
+						t.Errorf("result = 0x%X, want 0x%X", got, want)
+					}
+				})
+			}
+		}
+	}
+}
+
+// TestDIVQuotientFlags checks that N and Z come from the 16-bit quotient,
+// not from the register holding the remainder above it.
+func TestDIVQuotientFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      uint16
+		d0, d1  uint32
+		want    uint32
+		wantCCR uint16
+	}{
+		// DIVS D1,D0: -100/7 = -14 remainder -2
+		{"DIVS negative quotient", 0x81C1, 0xFFFFFF9C, 7, 0xFFFEFFF2, flagN},
+		// DIVS D1,D0: 100/-7 = -14 remainder 2
+		{"DIVS negative divisor", 0x81C1, 100, 0xFFF9, 0x0002FFF2, flagN},
+		// DIVS D1,D0: -5/7 = 0 remainder -5
+		{"DIVS zero quotient", 0x81C1, 0xFFFFFFFB, 7, 0xFFFB0000, flagZ},
+		// DIVU D1,D0: 5/7 = 0 remainder 5
+		{"DIVU zero quotient", 0x80C1, 5, 7, 0x00050000, flagZ},
+		// DIVU D1,D0: $80000/$10 = $8000, bit 15 set
+		{"DIVU quotient bit 15", 0x80C1, 0x80000, 0x10, 0x00008000, flagN},
+		// DIVU D1,D0: $10007/$10 = $1000 remainder 7
+		{"DIVU positive", 0x80C1, 0x10007, 0x10, 0x00071000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			writeWord(bus, 0x1000, tt.op)
+			cpu := &CPU{bus: bus}
+			cpu.SetState(Registers{D: [8]uint32{tt.d0, tt.d1}, PC: 0x1000, SR: 0x271F, SSP: 0x10000})
+			cpu.Step()
+			if got := cpu.D(0); got != tt.want {
+				t.Errorf("D0 = 0x%08X, want 0x%08X", got, tt.want)
+			}
+			// V and C are cleared, X is left alone
+			if got := cpu.SR() & 0x1F; got != tt.wantCCR|flagX {
+				t.Errorf("CCR = 0x%02X, want 0x%02X", got, tt.wantCCR|flagX)
+			}
+		})
+	}
+}
+
+func TestAddressArithTiming(t *testing.T) {
+	// Source EA fields and extension words: D1, A1, (A2), #imm
+	sources := []struct {
+		name string
+		ea   uint16
+		ext  []uint16
+	}{
+		{"Dn", 0x01, nil},
+		{"An", 0x09, nil},
+		{"(An)", 0x12, nil},
+		{"#imm", 0x3C, []uint16{0x0001, 0x0002}},
+	}
+	// Cycles for word then long, per source, from the PRM instruction
+	// timing tables as recorded by SingleStepTests
+	tests := []struct {
+		name string
+		op   uint16
+		want [4][2]int
+	}{
+		{"ADDA", 0xD000, [4][2]int{{8, 8}, {8, 8}, {12, 14}, {12, 16}}},
+		{"SUBA", 0x9000, [4][2]int{{8, 8}, {8, 8}, {12, 14}, {12, 16}}},
+		{"CMPA", 0xB000, [4][2]int{{6, 6}, {6, 6}, {10, 14}, {10, 14}}},
+	}
+	for _, tt := range tests {
+		for i, src := range sources {
+			for j, opmode := range []uint16{3, 7} {
+				name := tt.name + [2]string{".W ", ".L "}[j] + src.name
+				op := tt.op | opmode<<6 | src.ea // destination A0
+				bus := &testBus{}
+				writeWord(bus, 0x1000, op)
+				ext := src.ext
+				if opmode == 3 && ext != nil {
+					ext = ext[1:]
+				}
+				for k, w := range ext {
+					writeWord(bus, 0x1002+uint32(k*2), w)
+				}
+				cpu := &CPU{bus: bus}
+				cpu.SetState(Registers{
+					D: [8]uint32{1: 3}, A: [8]uint32{0x100, 0x200, 0x4000},
+					PC: 0x1000, SR: 0x2700, SSP: 0x10000,
+				})
+				if n := cpu.Step(); n != tt.want[i][j] {
+					t.Errorf("%s: cycles = %d, want %d", name, n, tt.want[i][j])
+				}
+				if pc := cpu.PC(); pc != 0x1002+uint32(len(ext)*2) {
+					t.Errorf("%s: PC = 0x%X, want 0x%X", name, pc, 0x1002+uint32(len(ext)*2))
+				}
+			}
+		}
+	}
+
+	// The size field of ADDA, SUBA and CMPA has no byte encoding, and the
+	// byte forms of ADD, SUB and CMP do not take an address register
+	for _, base := range []uint16{0xD000, 0x9000, 0xB000} {
+		for an := uint16(0); an < 8; an++ {
+			if op := base | 1<<3 | an; opcodeTable[op] != nil { // <op>.B An,D0
+				t.Errorf("%04X: byte operation from A%d is implemented", op, an)
+			}
+		}
+	}
+}
+
+func TestUndefinedFlagsMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		prog      []uint16
+		d0, d1    uint32
+		sr        uint16
+		observed  uint16
+		unchanged uint16
+	}{
+		// DIVU D1,D0: $20000/1 overflows; N and Z are undefined
+		{"DIVU overflow", []uint16{0x80C1}, 0x20000, 1, 0x2704, flagN | flagV, flagZ | flagV},
+		// DIVS D1,D0: $20000/1 overflows
+		{"DIVS overflow", []uint16{0x81C1}, 0x20000, 1, 0x2708, flagN | flagV, flagN | flagV},
+		{"DIVS overflow Z kept", []uint16{0x81C1}, 0x20000, 1, 0x2704, flagN | flagV, flagZ | flagV},
+		// ABCD D1,D0: $45+$45 = $90; N and V are undefined
+		{"ABCD", []uint16{0xC101}, 0x45, 0x45, 0x2702, flagN, flagV},
+		// CHK D1,D0 with D0 = 5 in bounds 0..7: N, Z, V and C are undefined
+		{"CHK in bounds", []uint16{0x4181}, 5, 7, 0x2703, 0, flagV | flagC},
+	}
+	for _, tt := range tests {
+		for _, mode := range []UndefinedFlagsMode{UndefinedFlagsObserved, UndefinedFlagsUnchanged} {
+			want := tt.observed
+			if mode == UndefinedFlagsUnchanged {
+				want = tt.unchanged
+			}
+			bus := &testBus{}
+			for i, w := range tt.prog {
+				writeWord(bus, 0x1000+uint32(i*2), w)
+			}
+			cpu := &CPU{bus: bus}
+			cpu.SetUndefinedFlagsMode(mode)
+			cpu.SetState(Registers{D: [8]uint32{tt.d0, tt.d1}, PC: 0x1000, SR: tt.sr, SSP: 0x10000})
+			cpu.Step()
+			if got := cpu.SR() & 0x1F; got != want {
+				t.Errorf("%s mode %d: CCR = 0x%02X, want 0x%02X", tt.name, mode, got, want)
+			}
+		}
+	}
+}
+
+// TestCMPAWordSource checks that CMPA.W sign-extends its source and
+// compares it against all 32 bits of the address register.
+func TestCMPAWordSource(t *testing.T) {
+	tests := []struct {
+		name  string
+		prog  []uint16
+		a0    uint32
+		d1    uint32
+		flags uint16
+	}{
+		// CMPA.W D1,A0: 0xB0C1
+		{"equal after sign extension", []uint16{0xB0C1}, 0xFFFFFFFF, 0x0000FFFF, flagZ},
+		{"upper word differs", []uint16{0xB0C1}, 0x00010000, 0xFFFF0000, 0},
+		{"low words equal", []uint16{0xB0C1}, 0x12345678, 0xABCD5678, 0},
+		{"negative word borrows", []uint16{0xB0C1}, 0x00008000, 0x00008000, flagC},
+		{"overflow", []uint16{0xB0C1}, 0x7FFFFFFF, 0x0000FFFF, flagN | flagV | flagC},
+		{"negative result", []uint16{0xB0C1}, 0x00001000, 0x00007000, flagN | flagC},
+		// CMPA.W #-2,A0: 0xB0FC
+		{"immediate", []uint16{0xB0FC, 0xFFFE}, 0xFFFFFFFE, 0, flagZ},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			for i, w := range tt.prog {
+				writeWord(bus, 0x1000+uint32(i*2), w)
+			}
+			cpu := &CPU{bus: bus}
+			cpu.SetState(Registers{D: [8]uint32{1: tt.d1}, A: [8]uint32{tt.a0}, PC: 0x1000, SR: 0x2710, SSP: 0x10000})
+			cpu.Step()
+			if got := cpu.SR() & 0x1F; got != tt.flags|flagX {
+				t.Errorf("CCR = 0x%02X, want 0x%02X", got, tt.flags|flagX)
+			}
+			if got := cpu.A(0); got != tt.a0 {
+				t.Errorf("A0 = 0x%08X, want it unchanged at 0x%08X", got, tt.a0)
+			}
+		})
+	}
+}
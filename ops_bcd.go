@@ -45,6 +45,7 @@ func opABCDmem(c *CPU) {
 }
 
 func bcdAdd(c *CPU, s, d uint32) uint32 {
+	old := c.reg.SR
 	x := uint32(0)
 	if c.reg.SR&flagX != 0 {
 		x = 1
@@ -82,6 +83,8 @@ func bcdAdd(c *CPU, s, d uint32) uint32 {
 	if r8 != 0 {
 		c.reg.SR &^= flagZ
 	}
+	// N and V are undefined.
+	c.keepUndefined(old, flagN|flagV)
 
 	return r8
 }
@@ -124,6 +127,7 @@ func opSBCDmem(c *CPU) {
 }
 
 func bcdSub(c *CPU, s, d uint32) uint32 {
+	old := c.reg.SR
 	x := uint32(0)
 	if c.reg.SR&flagX != 0 {
 		x = 1
@@ -163,6 +167,8 @@ func bcdSub(c *CPU, s, d uint32) uint32 {
 	if r8 != 0 {
 		c.reg.SR &^= flagZ
 	}
+	// N and V are undefined.
+	c.keepUndefined(old, flagN|flagV)
 
 	return r8
 }
@@ -967,3 +967,58 @@ func TestNBCD(t *testing.T) {
 		})
 	}
 }
+
+// TestBCDChained runs four-byte ABCD and SBCD chains through -(A0),-(A1) the
+// way multi-precision code uses them: X carries between bytes and Z, set
+// beforehand, is only cleared by a nonzero byte.
+func TestBCDChained(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       uint16
+		src, dst uint32
+		want     uint32
+		wantCCR  uint16
+	}{
+		{"ABCD", 0xC308, 0x00010001, 0x00990000, 0x01000001, 0},
+		{"ABCD carry out", 0xC308, 0x87654322, 0x12345678, 0x00000000, flagX | flagC | flagZ},
+		{"ABCD zero", 0xC308, 0x00000000, 0x00000000, 0x00000000, flagZ},
+		{"SBCD", 0x8308, 0x00000001, 0x10000000, 0x09999999, 0},
+		{"SBCD borrow out", 0x8308, 0x00000001, 0x00000000, 0x99999999, flagX | flagC | flagN},
+		{"SBCD zero", 0x8308, 0x12345678, 0x12345678, 0x00000000, flagZ},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			for i := uint32(0); i < 4; i++ {
+				writeWord(bus, 0x1000+i*2, tt.op)
+			}
+			bus.Write32(0x4000, tt.src)
+			bus.Write32(0x5000, tt.dst)
+			cpu := &CPU{bus: bus}
+			cpu.SetState(Registers{A: [8]uint32{0x4004, 0x5004}, PC: 0x1000, SR: 0x2704, SSP: 0x10000})
+			for range 4 {
+				cpu.Step()
+			}
+			if got := bus.Read32(0x5000); got != tt.want {
+				t.Errorf("result = %08X, want %08X", got, tt.want)
+			}
+			if got := cpu.SR() & (flagX | flagN | flagZ | flagC); got != tt.wantCCR {
+				t.Errorf("XNZC = 0x%02X, want 0x%02X", got, tt.wantCCR)
+			}
+		})
+	}
+
+	t.Run("SBCD 0-1 with X set", func(t *testing.T) {
+		bus := &testBus{}
+		writeWord(bus, 0x1000, 0x8101) // SBCD D1,D0
+		cpu := &CPU{bus: bus}
+		cpu.SetState(Registers{D: [8]uint32{0x00, 0x01}, PC: 0x1000, SR: 0x2714, SSP: 0x10000})
+		cpu.Step()
+		if got := cpu.D(0); got != 0x98 {
+			t.Errorf("D0 = 0x%02X, want 0x98", got)
+		}
+		if got := cpu.SR() & 0x1F; got != flagX|flagN|flagC {
+			t.Errorf("CCR = 0x%02X, want X N C", got)
+		}
+	})
+}
@@ -61,6 +61,11 @@ func makeBTSTdyn(dn, mode, reg uint16) opFunc {
 	}
 	read := makeEARead(mode, reg)
 	eaBase, _ := eaFetchConst(mode, reg)
+	cycles := 4 + eaBase
+	if mode == 7 && reg == 4 {
+		// BTST Dn,#imm: hardware takes 10, not the PRM's 4 + 4(#imm).
+		cycles += 2
+	}
 	return func(c *CPU) {
 		bitNum := c.reg.D[dn] & 7
 		val := read(c, sizeByte)
@@ -69,7 +74,7 @@ func makeBTSTdyn(dn, mode, reg uint16) opFunc {
 		} else {
 			c.reg.SR &^= flagZ
 		}
-		c.cycles += 4 + eaBase
+		c.cycles += cycles
 	}
 }
 
@@ -176,7 +181,11 @@ func makeBCHGstatic(mode, reg uint16) opFunc {
 				c.reg.SR &^= flagZ
 			}
 			c.reg.D[reg] ^= mask
-			c.cycles += 12
+			if bitNum < 16 {
+				c.cycles += 10
+			} else {
+				c.cycles += 12
+			}
 		}
 	}
 	addr := makeEAMemAddr(mode, reg)
@@ -273,7 +282,11 @@ func makeBCLRstatic(mode, reg uint16) opFunc {
 				c.reg.SR &^= flagZ
 			}
 			c.reg.D[reg] &^= mask
-			c.cycles += 14
+			if bitNum < 16 {
+				c.cycles += 12
+			} else {
+				c.cycles += 14
+			}
 		}
 	}
 	addr := makeEAMemAddr(mode, reg)
@@ -370,7 +383,11 @@ func makeBSETstatic(mode, reg uint16) opFunc {
 				c.reg.SR &^= flagZ
 			}
 			c.reg.D[reg] |= mask
-			c.cycles += 12
+			if bitNum < 16 {
+				c.cycles += 10
+			} else {
+				c.cycles += 12
+			}
 		}
 	}
 	addr := makeEAMemAddr(mode, reg)
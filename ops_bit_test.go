@@ -42,16 +42,14 @@ func TestBTST(t *testing.T) {
 				RAM: [][2]uint32{{11132656, 5}, {11132657, 60}, {11132658, 90}, {11132659, 242}, {11132660, 214}, {11132661, 128}, {11132662, 105}, {11132663, 131}},
 			},
 			want: cpuState{
-				D:   [8]uint32{3437135205, 285366979, 1082831774, 3764772155, 1611330715, 896167455, 334523576, 2837236113},
-				A:   [7]uint32{817191152, 1057135609, 56802253, 1273955084, 554115357, 2546653039, 3999752289},
-				PC:  11132664,
-				SR:  33032,
-				USP: 16183904,
-				SSP: 14538846,
-				RAM: [][2]uint32{{11132656, 5}, {11132657, 60}, {11132658, 90}, {11132659, 242}, {11132660, 214}, {11132661, 128}, {11132662, 105}, {11132663, 131}},
-				// PRM says 4 + 4(#imm EA) = 8 cycles, but hardware-verified
-				// tests (SingleStepTests/m68000) show 10. We use the PRM value.
-				// Cycles: 10,
+				D:      [8]uint32{3437135205, 285366979, 1082831774, 3764772155, 1611330715, 896167455, 334523576, 2837236113},
+				A:      [7]uint32{817191152, 1057135609, 56802253, 1273955084, 554115357, 2546653039, 3999752289},
+				PC:     11132664,
+				SR:     33032,
+				USP:    16183904,
+				SSP:    14538846,
+				RAM:    [][2]uint32{{11132656, 5}, {11132657, 60}, {11132658, 90}, {11132659, 242}, {11132660, 214}, {11132661, 128}, {11132662, 105}, {11132663, 131}},
+				Cycles: 10,
 			},
 		},
 		{
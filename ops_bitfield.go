@@ -0,0 +1,170 @@
+package m68k
+
+import "math/bits"
+
+func init() {
+	registerBitfield()
+}
+
+// Bit field operations (68020): 1110 1ttt 11ee eeee + extension word
+// ttt = 000:BFTST, 001:BFEXTU, 010:BFCHG, 011:BFEXTS, 100:BFCLR,
+// 101:BFFFO, 110:BFSET, 111:BFINS
+// Extension word: 0 | Reg(3) | Do | Offset(5) | Dw | Width(5)
+// Do and Dw take the offset and width from the data register named in
+// their field instead. A register offset is signed and may reach outside
+// the byte at <ea>; a width of 0 means 32.
+// For Dn: the field is taken modulo 32 and wraps within the register.
+// For memory: <ea> is a byte address and the field spans up to 5 bytes.
+const (
+	bfTST = iota
+	bfEXTU
+	bfCHG
+	bfEXTS
+	bfCLR
+	bfFFO
+	bfSET
+	bfINS
+)
+
+// bfRegCycles and bfMemCycles approximate the MC68020 cache case timing
+// for the register and memory forms, without EA calculation time.
+var (
+	bfRegCycles = [8]uint64{bfTST: 3, bfEXTU: 5, bfCHG: 9, bfEXTS: 5, bfCLR: 9, bfFFO: 19, bfSET: 9, bfINS: 7}
+	bfMemCycles = [8]uint64{bfTST: 11, bfEXTU: 13, bfCHG: 15, bfEXTS: 13, bfCLR: 15, bfFFO: 24, bfSET: 15, bfINS: 14}
+)
+
+// registerBitfield registers the bit field instructions for Dn and the
+// control addressing modes; the modifying ones exclude PC-relative.
+// On the 68000 and 68010 the opcodes are illegal instructions.
+func registerBitfield() {
+	for op := uint16(0); op < 8; op++ {
+		readOnly := op == bfTST || op == bfEXTU || op == bfEXTS || op == bfFFO
+		for mode := uint16(0); mode < 8; mode++ {
+			if mode == 1 || mode == 3 || mode == 4 {
+				continue
+			}
+			for reg := uint16(0); reg < 8; reg++ {
+				if mode == 7 && (reg > 3 || reg > 1 && !readOnly) {
+					continue
+				}
+				opcodeTable[0xE8C0|op<<8|mode<<3|reg] = makeBitfield(op, mode, reg)
+			}
+		}
+	}
+}
+
+func makeBitfield(op, mode, reg uint16) opFunc {
+	if mode == 0 {
+		return func(c *CPU) {
+			if c.variant < MC68020 {
+				c.exception(vecIllegalInstruction)
+				return
+			}
+			ext := c.fetchPC()
+			offset, width := c.bitfieldSpec(ext)
+			c.bitfieldReg(op, ext, reg, offset, width)
+			c.cycles += bfRegCycles[op]
+		}
+	}
+	addr := makeEAMemAddr(mode, reg)
+	return func(c *CPU) {
+		if c.variant < MC68020 {
+			c.exception(vecIllegalInstruction)
+			return
+		}
+		ext := c.fetchPC()
+		offset, width := c.bitfieldSpec(ext)
+		c.bitfieldMem(op, ext, addr(c, sizeByte), offset, width)
+		c.cycles += bfMemCycles[op]
+	}
+}
+
+// bitfieldSpec decodes the offset and width of a bit field extension word.
+func (c *CPU) bitfieldSpec(ext uint16) (offset int32, width uint32) {
+	offset = int32(ext>>6) & 31
+	if ext&0x0800 != 0 {
+		offset = int32(c.reg.D[(ext>>6)&7])
+	}
+	width = uint32(ext) & 31
+	if ext&0x0020 != 0 {
+		width = c.reg.D[ext&7] & 31
+	}
+	if width == 0 {
+		width = 32
+	}
+	return offset, width
+}
+
+// bitfieldReg performs op on the field of Dn (reg) at offset, counted from
+// bit 31 and wrapping around to bit 0.
+func (c *CPU) bitfieldReg(op, ext, reg uint16, offset int32, width uint32) {
+	rot := int(offset & 31)
+	d := c.reg.D[reg]
+	field := bits.RotateLeft32(d, rot) >> (32 - width)
+	if val, write := c.bitfieldOp(op, ext, field, offset, width); write {
+		mask := bits.RotateLeft32(^uint32(0)<<(32-width), -rot)
+		c.reg.D[reg] = d&^mask | bits.RotateLeft32(val<<(32-width), -rot)
+	}
+}
+
+// bitfieldMem performs op on the field at offset bits past the most
+// significant bit of the byte at base, reading and writing only the bytes
+// the field covers.
+func (c *CPU) bitfieldMem(op, ext uint16, base uint32, offset int32, width uint32) {
+	addr := base + uint32(offset>>3)
+	bit := uint32(offset & 7)
+	n := (bit + width + 7) / 8
+
+	var v uint64
+	for i := uint32(0); i < n; i++ {
+		v = v<<8 | uint64(c.readBus(sizeByte, addr+i))
+	}
+	shift := n*8 - bit - width
+	mask := ^uint32(0) >> (32 - width)
+	field := uint32(v>>shift) & mask
+
+	val, write := c.bitfieldOp(op, ext, field, offset, width)
+	if !write {
+		return
+	}
+	v = v&^(uint64(mask)<<shift) | uint64(val)<<shift
+	for i := uint32(0); i < n; i++ {
+		c.writeBus(sizeByte, addr+i, uint32(v>>((n-1-i)*8)))
+	}
+}
+
+// bitfieldOp sets the condition codes for op from the width-bit field
+// (BFINS from the inserted value), updates the extension word's data
+// register for the extracting forms, and returns the new field value and
+// whether it must be written back.
+func (c *CPU) bitfieldOp(op, ext uint16, field uint32, offset int32, width uint32) (uint32, bool) {
+	dn := (ext >> 12) & 7
+	mask := ^uint32(0) >> (32 - width)
+	if op == bfINS {
+		field = c.reg.D[dn] & mask
+	}
+	c.setFlagsLogical(field<<(32-width), sizeLong)
+
+	switch op {
+	case bfEXTU:
+		c.reg.D[dn] = field
+	case bfEXTS:
+		c.reg.D[dn] = uint32(int32(field<<(32-width)) >> (32 - width))
+	case bfFFO:
+		// Offset of the first set bit, or offset + width if there is none
+		lz := uint32(bits.LeadingZeros32(field << (32 - width)))
+		if lz > width {
+			lz = width
+		}
+		c.reg.D[dn] = uint32(offset) + lz
+	case bfCHG:
+		return field ^ mask, true
+	case bfCLR:
+		return 0, true
+	case bfSET:
+		return mask, true
+	case bfINS:
+		return field, true
+	}
+	return 0, false
+}
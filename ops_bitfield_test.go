@@ -0,0 +1,118 @@
+package m68k
+
+import "testing"
+
+// bitfieldCPU builds a 68020 CPU at 0x1000 running prog in supervisor mode,
+// with A0 pointing at mem placed at 0x2000.
+func bitfieldCPU(regs Registers, mem []byte, prog ...uint16) (*CPU, *testBus) {
+	regs.SR = 0x2700
+	regs.A[0] = 0x2000
+	cpu, bus := progCPU(MC68020, regs, prog...)
+	copy(bus.mem[0x2000:], mem)
+	return cpu, bus
+}
+
+func TestBitfieldExtract(t *testing.T) {
+	tests := []struct {
+		name  string
+		prog  []uint16
+		d     [8]uint32
+		dn    int
+		want  uint32
+		flags uint16
+	}{
+		// BFEXTU (A0){4:12},D1: the low nibble of $12 and all of $34
+		{"BFEXTU", []uint16{0xE9D0, 0x110C}, [8]uint32{}, 1, 0x234, 0},
+		// BFEXTS (A0){6:5},D2: %10 from $12 and %001 from $34
+		{"BFEXTS negative", []uint16{0xEBD0, 0x2185}, [8]uint32{}, 2, 0xFFFFFFF1, flagN},
+		// BFEXTU (A0){D1:32},D2 with D1 = 8: a long starting at $2001
+		{"BFEXTU register offset", []uint16{0xE9D0, 0x2840}, [8]uint32{1: 8}, 2, 0x3456789A, 0},
+		// BFEXTU (A0){D1:4},D2 with D1 = -4: the low nibble of $1FFF
+		{"BFEXTU negative offset", []uint16{0xE9D0, 0x2844}, [8]uint32{1: 0xFFFFFFFC}, 2, 0xF, flagN},
+		// BFFFO (A0){4:12},D1: first set bit of %0010_0011_0100 is at 4+2
+		{"BFFFO", []uint16{0xEDD0, 0x110C}, [8]uint32{}, 1, 6, 0},
+		// BFFFO D0{8:8},D1 with an empty field: offset + width
+		{"BFFFO empty", []uint16{0xEDC0, 0x1208}, [8]uint32{0xFF00FFFF}, 1, 16, flagZ},
+		// BFEXTU D0{28:8},D1: wraps from bit 0 around to bit 31
+		{"BFEXTU register wrap", []uint16{0xE9C0, 0x1708}, [8]uint32{0xA000000B}, 1, 0xBA, flagN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpu, bus := bitfieldCPU(Registers{D: tt.d}, []byte{0x12, 0x34, 0x56, 0x78, 0x9A}, tt.prog...)
+			bus.mem[0x1FFF] = 0xAF
+			cpu.Step()
+			if got := cpu.D(tt.dn); got != tt.want {
+				t.Errorf("D%d = 0x%08X, want 0x%08X", tt.dn, got, tt.want)
+			}
+			if got := cpu.SR() & 0x1F; got != tt.flags {
+				t.Errorf("CCR = 0x%02X, want 0x%02X", got, tt.flags)
+			}
+			if pc := cpu.PC(); pc != 0x1004 {
+				t.Errorf("PC = 0x%X, want 0x1004", pc)
+			}
+		})
+	}
+}
+
+func TestBitfieldModify(t *testing.T) {
+	tests := []struct {
+		name  string
+		prog  []uint16
+		d     [8]uint32
+		want  []byte // memory from 0x1FFF
+		flags uint16
+	}{
+		// BFINS D3,(A0){12:16}: $ABCD across the middle of three bytes
+		{"BFINS", []uint16{0xEFD0, 0x3310}, [8]uint32{3: 0xABCD}, []byte{0x00, 0x12, 0x3A, 0xBC, 0xD8}, flagN},
+		// BFCLR (A0){6:6}: the last two bits of $12 and the first four of $34
+		{"BFCLR", []uint16{0xECD0, 0x0186}, [8]uint32{}, []byte{0x00, 0x10, 0x04, 0x56, 0x78}, flagN},
+		// BFSET (A0){D1:D2} with D1 = -4, D2 = 8: starts in the byte before
+		{"BFSET negative offset", []uint16{0xEED0, 0x0862}, [8]uint32{1: 0xFFFFFFFC, 2: 8}, []byte{0x0F, 0xF2, 0x34, 0x56, 0x78}, 0},
+		// BFCHG (A0){0:32}, a width field of 0
+		{"BFCHG", []uint16{0xEAD0, 0x0000}, [8]uint32{}, []byte{0x00, 0xED, 0xCB, 0xA9, 0x87}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpu, bus := bitfieldCPU(Registers{D: tt.d}, []byte{0x12, 0x34, 0x56, 0x78}, tt.prog...)
+			cpu.Step()
+			if got := bus.mem[0x1FFF : 0x1FFF+len(tt.want)]; string(got) != string(tt.want) {
+				t.Errorf("memory = % X, want % X", got, tt.want)
+			}
+			if got := cpu.SR() & 0x1F; got != tt.flags {
+				t.Errorf("CCR = 0x%02X, want 0x%02X", got, tt.flags)
+			}
+		})
+	}
+
+	t.Run("register forms", func(t *testing.T) {
+		// BFCLR D0{28:8}; BFINS D1,D2{4:8}
+		cpu, _ := bitfieldCPU(Registers{D: [8]uint32{0xF123456F, 0x5A, 0xFFFFFFFF}}, nil,
+			0xECC0, 0x0708, 0xEFC2, 0x1108)
+		cpu.Step()
+		if got := cpu.D(0); got != 0x01234560 {
+			t.Errorf("D0 = 0x%08X, want 0x01234560", got)
+		}
+		if got := cpu.SR() & 0x1F; got != flagN {
+			t.Errorf("CCR = 0x%02X, want N", got)
+		}
+		cpu.Step()
+		if got := cpu.D(2); got != 0xF5AFFFFF {
+			t.Errorf("D2 = 0x%08X, want 0xF5AFFFFF", got)
+		}
+	})
+}
+
+func TestBitfieldIllegalOn68000(t *testing.T) {
+	for _, v := range []Variant{MC68000, MC68010} {
+		cpu, bus := bitfieldCPU(Registers{}, nil, 0xE9D0, 0x110C)
+		cpu.variant = v
+		bus.Write32(vecIllegalInstruction*4, 0x3000)
+		cpu.Step()
+		if pc := cpu.PC(); pc != 0x3000 {
+			t.Errorf("%v: PC = 0x%X, want the illegal instruction handler", v, pc)
+		}
+		if d1 := cpu.D(1); d1 != 0 {
+			t.Errorf("%v: D1 = 0x%X, want 0", v, d1)
+		}
+	}
+}
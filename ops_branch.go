@@ -8,6 +8,7 @@ func init() {
 	registerJMP()
 	registerJSR()
 	registerRTS()
+	registerRTD()
 	registerRTE()
 	registerRTR()
 	registerScc()
@@ -27,6 +28,11 @@ func registerBcc() {
 	}
 }
 
+// opBcc follows the branch timing of the MC68000 User's Manual Table 8-9:
+// a taken branch costs 10 with either displacement size, since the
+// extension word of the .W form is consumed by the refill at the target.
+// Not taken, the .S form costs 8 and the .W form 12, the extra 4 being
+// the fetch that skips the extension word.
 func opBcc(c *CPU) {
 	cc := (c.ir >> 8) & 0xF
 	disp := int32(int8(c.ir & 0xFF))
@@ -38,7 +44,7 @@ func opBcc(c *CPU) {
 
 	if c.testCondition(cc) {
 		// Displacement is relative to instruction address + 2
-		c.reg.PC = uint32(int32(base) + disp)
+		c.jump(uint32(int32(base)+disp), base)
 		c.cycles += 10
 	} else {
 		c.cycles += 8
@@ -48,6 +54,18 @@ func opBcc(c *CPU) {
 	}
 }
 
+// jump loads PC with a branch or jump target. The 68000 faults on the
+// prefetch from an odd target before the instruction completes; pc is
+// the value stacked in the address error frame in that case.
+func (c *CPU) jump(target, pc uint32) {
+	if target&1 != 0 {
+		c.reg.PC = pc
+		c.faultAdj = 0
+		c.addressError(target, sizeWord, true, true)
+	}
+	c.reg.PC = target
+}
+
 // --- BRA ---
 
 func registerBRA() {
@@ -57,6 +75,7 @@ func registerBRA() {
 	}
 }
 
+// opBRA costs 10 for both displacement sizes, like a taken Bcc.
 func opBRA(c *CPU) {
 	disp := int32(int8(c.ir & 0xFF))
 	base := c.reg.PC // PC after fetching opcode word
@@ -65,7 +84,7 @@ func opBRA(c *CPU) {
 		disp = int32(int16(c.fetchPC()))
 	}
 
-	c.reg.PC = uint32(int32(base) + disp)
+	c.jump(uint32(int32(base)+disp), base)
 	c.cycles += 10
 }
 
@@ -78,6 +97,8 @@ func registerBSR() {
 	}
 }
 
+// opBSR costs 18 for both displacement sizes: the return address push
+// adds 8 to the BRA timing.
 func opBSR(c *CPU) {
 	disp := int32(int8(c.ir & 0xFF))
 	base := c.reg.PC
@@ -103,30 +124,131 @@ func registerDBcc() {
 	}
 }
 
+// DBcc cycles when the condition is true, when the branch is taken and
+// when the count expires. The 68010 takes 10, 10 and 16 (MC68010 User's
+// Manual, conditional instruction execution times); its loop mode is
+// accounted for separately by loopStep.
+var (
+	dbccCycles      = [3]uint64{12, 10, 14}
+	dbccCycles68010 = [3]uint64{10, 10, 16}
+)
+
 func opDBcc(c *CPU) {
 	cc := (c.ir >> 8) & 0xF
 	dn := c.ir & 7
 
+	cycles := &dbccCycles
+	if c.variant == MC68010 {
+		cycles = &dbccCycles68010
+	}
+
 	disp := int16(c.fetchPC())
 
 	if c.testCondition(cc) {
 		// Condition true: no branch, no decrement
-		c.cycles += 12
+		c.loopMode = false
+		c.cycles += cycles[0]
 		return
 	}
 
-	// Decrement low word of Dn
+	// Decrement low word of Dn. An odd branch target faults before the
+	// decremented counter is written back.
 	val := int16(c.reg.D[dn]&0xFFFF) - 1
+	if val != -1 {
+		target := uint32(int32(c.reg.PC) - 2 + int32(disp))
+		c.jump(target, c.reg.PC)
+		if c.variant == MC68010 {
+			c.loopMode = disp == -4 && c.lastOpPC == target && loopable(c.lastOp)
+			c.loopPC = target
+		}
+	} else {
+		c.loopMode = false
+	}
 	c.reg.D[dn] = (c.reg.D[dn] & 0xFFFF0000) | uint32(uint16(val))
 
 	if val == -1 {
 		// Counter expired: fall through
-		c.cycles += 14
+		c.cycles += cycles[2]
 	} else {
 		// Branch
-		c.reg.PC = uint32(int32(c.reg.PC) - 2 + int32(disp))
-		c.cycles += 10
+		c.cycles += cycles[1]
+	}
+}
+
+// loopSaving is the opcode fetch a one-word loop body no longer performs
+// while the 68010 is in loop mode.
+const loopSaving = 4
+
+// loopStep accounts for an instruction executed while the 68010 is in
+// loop mode: the loop body runs without its opcode fetch, the DBcc that
+// closes the loop decides for itself whether to stay in loop mode, and
+// anything else (an exception handler, say) leaves it. This approximates
+// the manual's loop mode tables, which give each body instruction's
+// iteration time including the DBcc: only the body's opcode fetch is
+// removed, and the DBcc keeps its normal 68010 timing.
+func (c *CPU) loopStep() {
+	switch c.prevPC {
+	case c.loopPC:
+		c.cycles -= loopSaving
+	case c.loopPC + 2:
+	default:
+		c.loopMode = false
+	}
+}
+
+// clearLoop leaves loop mode and forgets the last instruction executed,
+// so state restored over the CPU cannot resume or open a loop that
+// belonged to the state it replaced.
+func (c *CPU) clearLoop() {
+	c.loopMode = false
+	c.loopPC = 0
+	c.lastOp, c.lastOpPC = 0, 0
+}
+
+// loopable reports whether op is one of the single-word instructions the
+// 68010 can execute in loop mode (MC68010 User's Manual, loop mode
+// operation): memory operands must use (An), (An)+ or -(An), and the
+// other operand, if any, a register.
+func loopable(op uint16) bool {
+	if !IsImplemented(op) {
+		return false
+	}
+	mode := (op >> 3) & 7
+	szBits := (op >> 6) & 3
+	memMode := mode >= 2 && mode <= 4
+
+	switch op >> 12 {
+	case 0x1, 0x2, 0x3: // MOVE
+		dst := (op >> 6) & 7
+		if dst == 1 {
+			return false // MOVEA
+		}
+		return mode <= 4 && dst <= 4 && (memMode || dst >= 2)
+	case 0x4:
+		switch op & 0xFF00 {
+		case 0x4000, 0x4200, 0x4400, 0x4600, 0x4A00: // NEGX, CLR, NEG, NOT, TST
+			return szBits != 3 && memMode
+		case 0x4800:
+			return op&0x00C0 == 0 && memMode // NBCD
+		}
+		return false
+	case 0x8, 0xC: // OR/SBCD, AND/ABCD (not DIV, MUL, EXG)
+		if szBits == 3 {
+			return false
+		}
+		if op&0x01F8 == 0x0108 {
+			return true // SBCD/ABCD -(Ay),-(Ax)
+		}
+		return memMode && op&0x0130 != 0x0100
+	case 0x9, 0xB, 0xD: // SUB/SUBA/SUBX, CMP/CMPA/CMPM/EOR, ADD/ADDA/ADDX
+		if szBits != 3 && op&0x0138 == 0x0108 {
+			return true // SUBX/ADDX -(Ay),-(Ax) and CMPM (Ay)+,(Ax)+
+		}
+		return memMode
+	case 0xE: // memory shifts and rotates
+		return szBits == 3 && op&0x0800 == 0 && memMode
 	}
+	return false
 }
 
 // --- JMP ---
@@ -168,7 +290,8 @@ func makeJMP(mode, reg uint16) opFunc {
 		}
 	}
 	return func(c *CPU) {
-		c.reg.PC = addr(c, sizeWord)
+		target := addr(c, sizeWord)
+		c.jump(target, uint32(int32(c.reg.PC)+c.faultAdj))
 		c.cycles += cycles
 	}
 }
@@ -212,6 +335,10 @@ func makeJSR(mode, reg uint16) opFunc {
 	}
 	return func(c *CPU) {
 		target := addr(c, sizeWord)
+		// An odd target faults before the return address is pushed.
+		if target&1 != 0 {
+			c.jump(target, c.reg.PC)
+		}
 		c.pushLong(c.reg.PC)
 		c.reg.PC = target
 		c.cycles += cycles
@@ -225,7 +352,29 @@ func registerRTS() {
 }
 
 func opRTS(c *CPU) {
-	c.reg.PC = c.popLong()
+	c.jump(c.popLong(), c.prevPC+2)
+	c.cycles += 16
+}
+
+// --- RTD (68010) ---
+
+// registerRTD registers RTD #d16 (0x4E74). On the 68000 the opcode is an
+// illegal instruction.
+func registerRTD() {
+	opcodeTable[0x4E74] = opRTD
+}
+
+// opRTD pops the return address and then adds the sign-extended
+// displacement to A7, releasing the caller's stack arguments.
+func opRTD(c *CPU) {
+	if c.variant < MC68010 {
+		c.exception(vecIllegalInstruction)
+		return
+	}
+	disp := int16(c.fetchPC())
+	pc := c.popLong()
+	c.reg.A[7] = uint32(int32(c.reg.A[7]) + int32(disp))
+	c.jump(pc, c.reg.PC)
 	c.cycles += 16
 }
 
@@ -235,18 +384,46 @@ func registerRTE() {
 	opcodeTable[0x4E73] = opRTE
 }
 
+// opRTE returns from an exception by popping SR and then PC. The 68000
+// stacks no frame format word, so RTE there always restores the 6-byte
+// short frame; a bus or address error handler must first discard the 8
+// bytes of fault information (status word, access address, IR) above it,
+// typically with ADDQ.L #8,SP. From the 68010 on RTE also reads the
+// format word and the rest of the frame it describes, taking a format
+// error (vector 14) with the stack untouched if this variant never stacks
+// that format. A bus fault frame is discarded rather than used to
+// continue the faulted instruction, so execution resumes at its stacked
+// PC.
 func opRTE(c *CPU) {
 	if !c.supervisor() {
 		c.exception(vecPrivilegeViolation)
 		return
 	}
 
-	sr := c.popWord()
-	pc := c.popLong()
-	c.setSR(sr)
-	c.reg.PC = pc
+	if c.variant < MC68010 {
+		sr := c.popWord()
+		pc := c.popLong()
+		c.setSR(sr)
+		c.jump(pc, c.prevPC+2)
+		c.cycles += 20
+		return
+	}
 
-	c.cycles += 20
+	sp := c.reg.A[7]
+	sr := uint16(c.readBus(sizeWord, sp))
+	pc := c.readBus(sizeLong, sp+2)
+	n := c.frameSize(uint16(c.readBus(sizeWord, sp+6)) >> 12)
+	if n == 0 {
+		c.exception(vecFormatError)
+		return
+	}
+	for a := sp + 8; a < sp+n; a += 2 {
+		c.readBus(sizeWord, a)
+	}
+	c.reg.A[7] = sp + n
+	c.setSR(sr)
+	c.jump(pc, c.prevPC+2)
+	c.cycles += 20 + uint64(n/2-3)*4
 }
 
 // --- RTR ---
@@ -258,7 +435,7 @@ func registerRTR() {
 func opRTR(c *CPU) {
 	ccr := c.popWord()
 	c.setCCR(uint8(ccr))
-	c.reg.PC = c.popLong()
+	c.jump(c.popLong(), c.prevPC+2)
 
 	c.cycles += 20
 }
@@ -283,6 +460,9 @@ func registerScc() {
 	}
 }
 
+// makeScc sets the byte at <ea> to $FF if the condition holds and to $00
+// if not. To a data register a true condition costs 6 cycles and a false
+// one 4; to memory it is 8 plus the EA time whatever the outcome.
 func makeScc(mode, reg uint16) opFunc {
 	if mode == 0 {
 		return func(c *CPU) {
@@ -1,6 +1,9 @@
 package m68k
 
-import "testing"
+import (
+	"slices"
+	"testing"
+)
 
 func TestBcc(t *testing.T) {
 	tests := []struct {
@@ -42,14 +45,13 @@ func TestBcc(t *testing.T) {
 				RAM: [][2]uint32{{12199510, 102}, {12199511, 231}, {12199512, 48}, {12199513, 18}, {12, 118}, {13, 32}, {14, 252}, {15, 20}, {2161684, 45}, {2161685, 123}, {2161686, 158}, {2161687, 196}},
 			},
 			want: cpuState{
-				D:      [8]uint32{946910288, 1149199056, 2243949998, 762037226, 3660233864, 3279667752, 211456927, 1288790653},
-				A:      [7]uint32{331766020, 1234250103, 3945669382, 1747682109, 3213995765, 2044992904, 206250018},
-				PC:     1981873176,
-				SR:     9474,
-				USP:    13415392,
-				SSP:    11506498,
-				RAM:    [][2]uint32{{12199510, 102}, {12199511, 231}, {12199512, 48}, {12199513, 18}, {11506510, 38}, {11506511, 88}, {11506506, 165}, {11506507, 2}, {11506508, 0}, {11506509, 186}, {11506504, 102}, {11506505, 231}, {11506502, 38}, {11506503, 63}, {11506498, 102}, {11506499, 246}, {11506500, 0}, {11506501, 186}, {12, 118}, {13, 32}, {14, 252}, {15, 20}, {2161684, 45}, {2161685, 123}, {2161686, 158}, {2161687, 196}},
-				Halted: true,
+				D:   [8]uint32{946910288, 1149199056, 2243949998, 762037226, 3660233864, 3279667752, 211456927, 1288790653},
+				A:   [7]uint32{331766020, 1234250103, 3945669382, 1747682109, 3213995765, 2044992904, 206250018},
+				PC:  1981873176,
+				SR:  9474,
+				USP: 13415392,
+				SSP: 11506498,
+				RAM: [][2]uint32{{12199510, 102}, {12199511, 231}, {12199512, 48}, {12199513, 18}, {11506510, 38}, {11506511, 88}, {11506506, 165}, {11506507, 2}, {11506508, 0}, {11506509, 186}, {11506504, 102}, {11506505, 231}, {11506502, 38}, {11506503, 63}, {11506498, 102}, {11506499, 246}, {11506500, 0}, {11506501, 186}, {12, 118}, {13, 32}, {14, 252}, {15, 20}, {2161684, 45}, {2161685, 123}, {2161686, 158}, {2161687, 196}},
 			},
 		},
 		{
@@ -386,14 +388,13 @@ func TestBSR(t *testing.T) {
 				RAM: [][2]uint32{{9120610, 97}, {9120611, 31}, {9120612, 9}, {9120613, 129}, {12, 21}, {13, 178}, {14, 207}, {15, 140}, {11718540, 90}, {11718541, 178}, {11718542, 28}, {11718543, 115}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1109380244, 1522467750, 4026450329, 260704155, 1958536903, 4092963074, 3578845900, 4235999725},
-				A:      [7]uint32{1124561082, 849824773, 119010643, 40899830, 248388312, 2695529245, 409442983},
-				PC:     364040080,
-				SR:     9497,
-				USP:    12022808,
-				SSP:    7095406,
-				RAM:    [][2]uint32{{9120610, 97}, {9120611, 31}, {9120612, 9}, {9120613, 129}, {7095420, 0}, {7095421, 139}, {7095422, 43}, {7095423, 100}, {7095418, 43}, {7095419, 131}, {7095414, 37}, {7095415, 25}, {7095416, 0}, {7095417, 139}, {7095412, 97}, {7095413, 31}, {7095410, 43}, {7095411, 131}, {7095406, 97}, {7095407, 22}, {7095408, 0}, {7095409, 139}, {12, 21}, {13, 178}, {14, 207}, {15, 140}, {11718540, 90}, {11718541, 178}, {11718542, 28}, {11718543, 115}},
-				Halted: true,
+				D:   [8]uint32{1109380244, 1522467750, 4026450329, 260704155, 1958536903, 4092963074, 3578845900, 4235999725},
+				A:   [7]uint32{1124561082, 849824773, 119010643, 40899830, 248388312, 2695529245, 409442983},
+				PC:  364040080,
+				SR:  9497,
+				USP: 12022808,
+				SSP: 7095406,
+				RAM: [][2]uint32{{9120610, 97}, {9120611, 31}, {9120612, 9}, {9120613, 129}, {7095420, 0}, {7095421, 139}, {7095422, 43}, {7095423, 100}, {7095418, 43}, {7095419, 131}, {7095414, 37}, {7095415, 25}, {7095416, 0}, {7095417, 139}, {7095412, 97}, {7095413, 31}, {7095410, 43}, {7095411, 131}, {7095406, 97}, {7095407, 22}, {7095408, 0}, {7095409, 139}, {12, 21}, {13, 178}, {14, 207}, {15, 140}, {11718540, 90}, {11718541, 178}, {11718542, 28}, {11718543, 115}},
 			},
 		},
 		{
@@ -408,14 +409,13 @@ func TestBSR(t *testing.T) {
 				RAM: [][2]uint32{{11384064, 97}, {11384065, 45}, {11384066, 228}, {11384067, 234}, {12, 105}, {13, 86}, {14, 247}, {15, 178}, {5699506, 218}, {5699507, 123}, {5699508, 24}, {5699509, 213}},
 			},
 			want: cpuState{
-				D:      [8]uint32{53481911, 677601385, 2631769522, 4095665240, 4225311380, 1658776787, 1179989423, 1588662221},
-				A:      [7]uint32{1636470783, 3831338466, 3652180990, 3696933587, 1563135856, 1029852308, 3225059393},
-				PC:     1767307190,
-				SR:     8462,
-				USP:    6214234,
-				SSP:    16513822,
-				RAM:    [][2]uint32{{11384064, 97}, {11384065, 45}, {11384066, 228}, {11384067, 234}, {6214234, 0}, {6214235, 173}, {6214236, 181}, {6214237, 2}, {16513834, 181}, {16513835, 47}, {16513830, 129}, {16513831, 14}, {16513832, 0}, {16513833, 173}, {16513828, 97}, {16513829, 45}, {16513826, 181}, {16513827, 47}, {16513822, 97}, {16513823, 50}, {16513824, 0}, {16513825, 173}, {12, 105}, {13, 86}, {14, 247}, {15, 178}, {5699506, 218}, {5699507, 123}, {5699508, 24}, {5699509, 213}},
-				Halted: true,
+				D:   [8]uint32{53481911, 677601385, 2631769522, 4095665240, 4225311380, 1658776787, 1179989423, 1588662221},
+				A:   [7]uint32{1636470783, 3831338466, 3652180990, 3696933587, 1563135856, 1029852308, 3225059393},
+				PC:  1767307190,
+				SR:  8462,
+				USP: 6214234,
+				SSP: 16513822,
+				RAM: [][2]uint32{{11384064, 97}, {11384065, 45}, {11384066, 228}, {11384067, 234}, {6214234, 0}, {6214235, 173}, {6214236, 181}, {6214237, 2}, {16513834, 181}, {16513835, 47}, {16513830, 129}, {16513831, 14}, {16513832, 0}, {16513833, 173}, {16513828, 97}, {16513829, 45}, {16513826, 181}, {16513827, 47}, {16513822, 97}, {16513823, 50}, {16513824, 0}, {16513825, 173}, {12, 105}, {13, 86}, {14, 247}, {15, 178}, {5699506, 218}, {5699507, 123}, {5699508, 24}, {5699509, 213}},
 			},
 		},
 		{
@@ -664,14 +664,13 @@ func TestDBcc(t *testing.T) {
 				RAM: [][2]uint32{{1931306, 94}, {1931307, 207}, {1931308, 151}, {1931309, 253}, {12, 210}, {13, 156}, {14, 184}, {15, 38}, {10270758, 16}, {10270759, 109}, {10270760, 166}, {10270761, 189}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2995527499, 185128935, 238788573, 245135735, 1352650698, 2996237592, 2907838549, 3496842426},
-				A:      [7]uint32{3531171237, 1021743264, 2324599395, 1455201896, 126441683, 1136191243, 2260655387},
-				PC:     3533486122,
-				SR:     8450,
-				USP:    12957490,
-				SSP:    16025112,
-				RAM:    [][2]uint32{{1931306, 94}, {1931307, 207}, {1931308, 151}, {1931309, 253}, {16025124, 120}, {16025125, 46}, {16025120, 161}, {16025121, 2}, {16025122, 0}, {16025123, 29}, {16025118, 94}, {16025119, 207}, {16025116, 16}, {16025117, 41}, {16025112, 94}, {16025113, 214}, {16025114, 0}, {16025115, 29}, {12, 210}, {13, 156}, {14, 184}, {15, 38}, {10270758, 16}, {10270759, 109}, {10270760, 166}, {10270761, 189}},
-				Halted: true,
+				D:   [8]uint32{2995527499, 185128935, 238788573, 245135735, 1352650698, 2996237592, 2907838549, 3496842426},
+				A:   [7]uint32{3531171237, 1021743264, 2324599395, 1455201896, 126441683, 1136191243, 2260655387},
+				PC:  3533486122,
+				SR:  8450,
+				USP: 12957490,
+				SSP: 16025112,
+				RAM: [][2]uint32{{1931306, 94}, {1931307, 207}, {1931308, 151}, {1931309, 253}, {16025124, 120}, {16025125, 46}, {16025120, 161}, {16025121, 2}, {16025122, 0}, {16025123, 29}, {16025118, 94}, {16025119, 207}, {16025116, 16}, {16025117, 41}, {16025112, 94}, {16025113, 214}, {16025114, 0}, {16025115, 29}, {12, 210}, {13, 156}, {14, 184}, {15, 38}, {10270758, 16}, {10270759, 109}, {10270760, 166}, {10270761, 189}},
 			},
 		},
 		{
@@ -708,14 +707,13 @@ func TestDBcc(t *testing.T) {
 				RAM: [][2]uint32{{2925346, 89}, {2925347, 200}, {2925348, 76}, {2925349, 201}, {12, 151}, {13, 206}, {14, 87}, {15, 214}, {13522902, 250}, {13522903, 233}, {13522904, 3}, {13522905, 249}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3828964938, 467316427, 104272372, 4104947443, 3508510621, 2375457041, 1292477162, 3699257015},
-				A:      [7]uint32{3255399493, 620571100, 1707133965, 306082289, 2035564272, 2396524369, 2750955643},
-				PC:     2546882522,
-				SR:     8204,
-				USP:    10386092,
-				SSP:    8737600,
-				RAM:    [][2]uint32{{2925346, 89}, {2925347, 200}, {2925348, 76}, {2925349, 201}, {8737612, 163}, {8737613, 38}, {8737608, 128}, {8737609, 12}, {8737610, 0}, {8737611, 44}, {8737606, 89}, {8737607, 200}, {8737604, 239}, {8737605, 237}, {8737600, 89}, {8737601, 210}, {8737602, 0}, {8737603, 44}, {12, 151}, {13, 206}, {14, 87}, {15, 214}, {13522902, 250}, {13522903, 233}, {13522904, 3}, {13522905, 249}},
-				Halted: true,
+				D:   [8]uint32{3828964938, 467316427, 104272372, 4104947443, 3508510621, 2375457041, 1292477162, 3699257015},
+				A:   [7]uint32{3255399493, 620571100, 1707133965, 306082289, 2035564272, 2396524369, 2750955643},
+				PC:  2546882522,
+				SR:  8204,
+				USP: 10386092,
+				SSP: 8737600,
+				RAM: [][2]uint32{{2925346, 89}, {2925347, 200}, {2925348, 76}, {2925349, 201}, {8737612, 163}, {8737613, 38}, {8737608, 128}, {8737609, 12}, {8737610, 0}, {8737611, 44}, {8737606, 89}, {8737607, 200}, {8737604, 239}, {8737605, 237}, {8737600, 89}, {8737601, 210}, {8737602, 0}, {8737603, 44}, {12, 151}, {13, 206}, {14, 87}, {15, 214}, {13522902, 250}, {13522903, 233}, {13522904, 3}, {13522905, 249}},
 			},
 		},
 		{
@@ -1074,14 +1072,13 @@ func TestJMP(t *testing.T) {
 				RAM: [][2]uint32{{14792880, 78}, {14792881, 237}, {14792882, 140}, {14792883, 250}, {12, 57}, {13, 60}, {14, 155}, {15, 178}, {3972018, 159}, {3972019, 247}, {3972020, 144}, {3972021, 78}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1155252600, 774649694, 3340398229, 4252194510, 971016656, 3542734191, 2337693389, 4037864046},
-				A:      [7]uint32{1588553176, 2566817684, 472276968, 4009903322, 2768960548, 3341013647, 3339811079},
-				PC:     960273334,
-				SR:     9988,
-				USP:    12915786,
-				SSP:    1888128,
-				RAM:    [][2]uint32{{14792880, 78}, {14792881, 237}, {14792882, 140}, {14792883, 250}, {1888140, 184}, {1888141, 178}, {1888136, 7}, {1888137, 4}, {1888138, 0}, {1888139, 225}, {1888134, 78}, {1888135, 237}, {1888132, 95}, {1888133, 137}, {1888128, 78}, {1888129, 242}, {1888130, 199}, {1888131, 35}, {12, 57}, {13, 60}, {14, 155}, {15, 178}, {3972018, 159}, {3972019, 247}, {3972020, 144}, {3972021, 78}},
-				Halted: true,
+				D:   [8]uint32{1155252600, 774649694, 3340398229, 4252194510, 971016656, 3542734191, 2337693389, 4037864046},
+				A:   [7]uint32{1588553176, 2566817684, 472276968, 4009903322, 2768960548, 3341013647, 3339811079},
+				PC:  960273334,
+				SR:  9988,
+				USP: 12915786,
+				SSP: 1888128,
+				RAM: [][2]uint32{{14792880, 78}, {14792881, 237}, {14792882, 140}, {14792883, 250}, {1888140, 184}, {1888141, 178}, {1888136, 7}, {1888137, 4}, {1888138, 0}, {1888139, 225}, {1888134, 78}, {1888135, 237}, {1888132, 95}, {1888133, 137}, {1888128, 78}, {1888129, 242}, {1888130, 199}, {1888131, 35}, {12, 57}, {13, 60}, {14, 155}, {15, 178}, {3972018, 159}, {3972019, 247}, {3972020, 144}, {3972021, 78}},
 			},
 		},
 		{
@@ -1352,14 +1349,13 @@ func TestJSR(t *testing.T) {
 				RAM: [][2]uint32{{16383996, 78}, {16383997, 147}, {16383998, 255}, {16383999, 123}, {12, 99}, {13, 30}, {14, 188}, {15, 196}, {2014404, 79}, {2014405, 109}, {2014406, 41}, {2014407, 134}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3390383189, 3548604776, 1962784537, 626072408, 1376102999, 3141586279, 3361195775, 701006708},
-				A:      [7]uint32{4251518801, 1492861572, 841385586, 2676393951, 2886469397, 3073328202, 650965347},
-				PC:     1662958792,
-				SR:     9500,
-				USP:    8655398,
-				SSP:    11677734,
-				RAM:    [][2]uint32{{16383996, 78}, {16383997, 147}, {16383998, 255}, {16383999, 123}, {11677746, 255}, {11677747, 254}, {11677742, 37}, {11677743, 28}, {11677744, 0}, {11677745, 249}, {11677740, 78}, {11677741, 147}, {11677738, 135}, {11677739, 223}, {11677734, 78}, {11677735, 150}, {11677736, 159}, {11677737, 134}, {12, 99}, {13, 30}, {14, 188}, {15, 196}, {2014404, 79}, {2014405, 109}, {2014406, 41}, {2014407, 134}},
-				Halted: true,
+				D:   [8]uint32{3390383189, 3548604776, 1962784537, 626072408, 1376102999, 3141586279, 3361195775, 701006708},
+				A:   [7]uint32{4251518801, 1492861572, 841385586, 2676393951, 2886469397, 3073328202, 650965347},
+				PC:  1662958792,
+				SR:  9500,
+				USP: 8655398,
+				SSP: 11677734,
+				RAM: [][2]uint32{{16383996, 78}, {16383997, 147}, {16383998, 255}, {16383999, 123}, {11677746, 255}, {11677747, 254}, {11677742, 37}, {11677743, 28}, {11677744, 0}, {11677745, 249}, {11677740, 78}, {11677741, 147}, {11677738, 135}, {11677739, 223}, {11677734, 78}, {11677735, 150}, {11677736, 159}, {11677737, 134}, {12, 99}, {13, 30}, {14, 188}, {15, 196}, {2014404, 79}, {2014405, 109}, {2014406, 41}, {2014407, 134}},
 			},
 		},
 		{
@@ -1374,14 +1370,13 @@ func TestJSR(t *testing.T) {
 				RAM: [][2]uint32{{3798610, 78}, {3798611, 178}, {3798612, 86}, {3798613, 128}, {12, 112}, {13, 164}, {14, 147}, {15, 168}, {10785704, 55}, {10785705, 6}, {10785706, 12}, {10785707, 38}},
 			},
 			want: cpuState{
-				D:      [8]uint32{409797424, 2669198804, 514792184, 2600671081, 1564663472, 1655659042, 2081094196, 3464513787},
-				A:      [7]uint32{3053473403, 894802690, 108960797, 2296543056, 4272828092, 1873103683, 1748237444},
-				PC:     1889833900,
-				SR:     8733,
-				USP:    16484310,
-				SSP:    6167874,
-				RAM:    [][2]uint32{{3798610, 78}, {3798611, 178}, {3798612, 86}, {3798613, 128}, {6167886, 246}, {6167887, 86}, {6167882, 34}, {6167883, 29}, {6167884, 0}, {6167885, 57}, {6167880, 78}, {6167881, 178}, {6167878, 245}, {6167879, 191}, {6167874, 78}, {6167875, 182}, {6167876, 6}, {6167877, 126}, {12, 112}, {13, 164}, {14, 147}, {15, 168}, {10785704, 55}, {10785705, 6}, {10785706, 12}, {10785707, 38}},
-				Halted: true,
+				D:   [8]uint32{409797424, 2669198804, 514792184, 2600671081, 1564663472, 1655659042, 2081094196, 3464513787},
+				A:   [7]uint32{3053473403, 894802690, 108960797, 2296543056, 4272828092, 1873103683, 1748237444},
+				PC:  1889833900,
+				SR:  8733,
+				USP: 16484310,
+				SSP: 6167874,
+				RAM: [][2]uint32{{3798610, 78}, {3798611, 178}, {3798612, 86}, {3798613, 128}, {6167886, 246}, {6167887, 86}, {6167882, 34}, {6167883, 29}, {6167884, 0}, {6167885, 57}, {6167880, 78}, {6167881, 178}, {6167878, 245}, {6167879, 191}, {6167874, 78}, {6167875, 182}, {6167876, 6}, {6167877, 126}, {12, 112}, {13, 164}, {14, 147}, {15, 168}, {10785704, 55}, {10785705, 6}, {10785706, 12}, {10785707, 38}},
 			},
 		},
 		{
@@ -1696,14 +1691,13 @@ func TestRTS(t *testing.T) {
 				RAM: [][2]uint32{{15553056, 78}, {15553057, 117}, {15553058, 195}, {15553059, 145}, {2861738, 5}, {2861739, 212}, {2861740, 73}, {2861741, 23}, {12, 116}, {13, 188}, {14, 107}, {15, 86}, {12348246, 79}, {12348247, 29}, {12348248, 146}, {12348249, 154}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1733660104, 3063328298, 1422780063, 261130745, 3760094743, 222816045, 3986566305, 2545228888},
-				A:      [7]uint32{1026467905, 2337815119, 402480534, 4069753170, 1474969818, 2480941049, 2661582173},
-				PC:     1958505306,
-				SR:     8977,
-				USP:    11392022,
-				SSP:    2861728,
-				RAM:    [][2]uint32{{15553056, 78}, {15553057, 117}, {15553058, 195}, {15553059, 145}, {2861738, 0}, {2861739, 237}, {2861740, 82}, {2861741, 34}, {2861736, 163}, {2861737, 17}, {2861734, 78}, {2861735, 117}, {2861732, 73}, {2861733, 23}, {2861728, 78}, {2861729, 118}, {2861730, 5}, {2861731, 212}, {12, 116}, {13, 188}, {14, 107}, {15, 86}, {12348246, 79}, {12348247, 29}, {12348248, 146}, {12348249, 154}},
-				Halted: true,
+				D:   [8]uint32{1733660104, 3063328298, 1422780063, 261130745, 3760094743, 222816045, 3986566305, 2545228888},
+				A:   [7]uint32{1026467905, 2337815119, 402480534, 4069753170, 1474969818, 2480941049, 2661582173},
+				PC:  1958505306,
+				SR:  8977,
+				USP: 11392022,
+				SSP: 2861728,
+				RAM: [][2]uint32{{15553056, 78}, {15553057, 117}, {15553058, 195}, {15553059, 145}, {2861738, 0}, {2861739, 237}, {2861740, 82}, {2861741, 34}, {2861736, 163}, {2861737, 17}, {2861734, 78}, {2861735, 117}, {2861732, 73}, {2861733, 23}, {2861728, 78}, {2861729, 118}, {2861730, 5}, {2861731, 212}, {12, 116}, {13, 188}, {14, 107}, {15, 86}, {12348246, 79}, {12348247, 29}, {12348248, 146}, {12348249, 154}},
 			},
 		},
 		{
@@ -1718,14 +1712,13 @@ func TestRTS(t *testing.T) {
 				RAM: [][2]uint32{{14952580, 78}, {14952581, 117}, {14952582, 66}, {14952583, 149}, {5246450, 31}, {5246451, 111}, {5246452, 203}, {5246453, 39}, {12, 54}, {13, 0}, {14, 42}, {15, 142}, {10894, 41}, {10895, 243}, {10896, 87}, {10897, 229}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1484962938, 734951143, 1685375636, 3933067055, 1797407892, 2550938166, 3138527250, 1604251153},
-				A:      [7]uint32{155407640, 1576724980, 1064857638, 205689168, 3544357790, 3385808559, 3402424142},
-				PC:     905980562,
-				SR:     9233,
-				USP:    11386566,
-				SSP:    5246440,
-				RAM:    [][2]uint32{{14952580, 78}, {14952581, 117}, {14952582, 66}, {14952583, 149}, {5246450, 0}, {5246451, 228}, {5246452, 40}, {5246453, 134}, {5246448, 164}, {5246449, 17}, {5246446, 78}, {5246447, 117}, {5246444, 203}, {5246445, 39}, {5246440, 78}, {5246441, 118}, {5246442, 31}, {5246443, 111}, {12, 54}, {13, 0}, {14, 42}, {15, 142}, {10894, 41}, {10895, 243}, {10896, 87}, {10897, 229}},
-				Halted: true,
+				D:   [8]uint32{1484962938, 734951143, 1685375636, 3933067055, 1797407892, 2550938166, 3138527250, 1604251153},
+				A:   [7]uint32{155407640, 1576724980, 1064857638, 205689168, 3544357790, 3385808559, 3402424142},
+				PC:  905980562,
+				SR:  9233,
+				USP: 11386566,
+				SSP: 5246440,
+				RAM: [][2]uint32{{14952580, 78}, {14952581, 117}, {14952582, 66}, {14952583, 149}, {5246450, 0}, {5246451, 228}, {5246452, 40}, {5246453, 134}, {5246448, 164}, {5246449, 17}, {5246446, 78}, {5246447, 117}, {5246444, 203}, {5246445, 39}, {5246440, 78}, {5246441, 118}, {5246442, 31}, {5246443, 111}, {12, 54}, {13, 0}, {14, 42}, {15, 142}, {10894, 41}, {10895, 243}, {10896, 87}, {10897, 229}},
 			},
 		},
 		{
@@ -2040,14 +2033,13 @@ func TestRTE(t *testing.T) {
 				RAM: [][2]uint32{{10007562, 78}, {10007563, 115}, {10007564, 221}, {10007565, 59}, {9022358, 49}, {9022359, 65}, {9022360, 99}, {9022361, 24}, {9022362, 76}, {9022363, 85}, {12, 165}, {13, 216}, {14, 211}, {15, 162}, {14209954, 145}, {14209955, 193}, {14209956, 23}, {14209957, 116}},
 			},
 			want: cpuState{
-				D:      [8]uint32{4030082485, 1969029020, 1327468247, 3708766939, 2662732343, 1790579801, 184152359, 2051792554},
-				A:      [7]uint32{859156867, 2798190248, 3218804450, 3993992914, 3820347400, 726703149, 1086380784},
-				PC:     2782450598,
-				SR:     8449,
-				USP:    14222822,
-				SSP:    9022350,
-				RAM:    [][2]uint32{{10007562, 78}, {10007563, 115}, {10007564, 221}, {10007565, 59}, {9022358, 33}, {9022359, 1}, {9022360, 0}, {9022361, 152}, {9022362, 180}, {9022363, 12}, {9022356, 78}, {9022357, 115}, {9022354, 76}, {9022355, 85}, {9022350, 78}, {9022351, 118}, {9022352, 99}, {9022353, 24}, {12, 165}, {13, 216}, {14, 211}, {15, 162}, {14209954, 145}, {14209955, 193}, {14209956, 23}, {14209957, 116}},
-				Halted: true,
+				D:   [8]uint32{4030082485, 1969029020, 1327468247, 3708766939, 2662732343, 1790579801, 184152359, 2051792554},
+				A:   [7]uint32{859156867, 2798190248, 3218804450, 3993992914, 3820347400, 726703149, 1086380784},
+				PC:  2782450598,
+				SR:  8449,
+				USP: 14222822,
+				SSP: 9022350,
+				RAM: [][2]uint32{{10007562, 78}, {10007563, 115}, {10007564, 221}, {10007565, 59}, {9022358, 33}, {9022359, 1}, {9022360, 0}, {9022361, 152}, {9022362, 180}, {9022363, 12}, {9022356, 78}, {9022357, 115}, {9022354, 76}, {9022355, 85}, {9022350, 78}, {9022351, 118}, {9022352, 99}, {9022353, 24}, {12, 165}, {13, 216}, {14, 211}, {15, 162}, {14209954, 145}, {14209955, 193}, {14209956, 23}, {14209957, 116}},
 			},
 		},
 		{
@@ -2318,14 +2310,13 @@ func TestRTR(t *testing.T) {
 				RAM: [][2]uint32{{13793840, 78}, {13793841, 119}, {13793842, 1}, {13793843, 226}, {9850802, 37}, {9850803, 212}, {9850804, 5}, {9850805, 254}, {9850806, 166}, {9850807, 81}, {12, 244}, {13, 186}, {14, 172}, {15, 24}, {12233752, 129}, {12233753, 189}, {12233754, 63}, {12233755, 209}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1859631930, 3407559207, 3105071367, 1127178465, 2654869987, 223799096, 124335202, 875488641},
-				A:      [7]uint32{2788112476, 2446366214, 285833550, 2051845484, 137069292, 1695483379, 1277784082},
-				PC:     4105874460,
-				SR:     10004,
-				USP:    2689244,
-				SSP:    9850794,
-				RAM:    [][2]uint32{{13793840, 78}, {13793841, 119}, {13793842, 1}, {13793843, 226}, {9850802, 39}, {9850803, 20}, {9850804, 0}, {9850805, 210}, {9850806, 122}, {9850807, 50}, {9850800, 78}, {9850801, 119}, {9850798, 166}, {9850799, 81}, {9850794, 78}, {9850795, 118}, {9850796, 5}, {9850797, 254}, {12, 244}, {13, 186}, {14, 172}, {15, 24}, {12233752, 129}, {12233753, 189}, {12233754, 63}, {12233755, 209}},
-				Halted: true,
+				D:   [8]uint32{1859631930, 3407559207, 3105071367, 1127178465, 2654869987, 223799096, 124335202, 875488641},
+				A:   [7]uint32{2788112476, 2446366214, 285833550, 2051845484, 137069292, 1695483379, 1277784082},
+				PC:  4105874460,
+				SR:  10004,
+				USP: 2689244,
+				SSP: 9850794,
+				RAM: [][2]uint32{{13793840, 78}, {13793841, 119}, {13793842, 1}, {13793843, 226}, {9850802, 39}, {9850803, 20}, {9850804, 0}, {9850805, 210}, {9850806, 122}, {9850807, 50}, {9850800, 78}, {9850801, 119}, {9850798, 166}, {9850799, 81}, {9850794, 78}, {9850795, 118}, {9850796, 5}, {9850797, 254}, {12, 244}, {13, 186}, {14, 172}, {15, 24}, {12233752, 129}, {12233753, 189}, {12233754, 63}, {12233755, 209}},
 			},
 		},
 		{
@@ -2362,14 +2353,13 @@ func TestRTR(t *testing.T) {
 				RAM: [][2]uint32{{4886304, 78}, {4886305, 119}, {4886306, 214}, {4886307, 229}, {6164490, 232}, {6164491, 158}, {6164492, 231}, {6164493, 8}, {6164494, 235}, {6164495, 165}, {12, 234}, {13, 88}, {14, 180}, {15, 144}, {5813392, 246}, {5813393, 152}, {5813394, 8}, {5813395, 26}},
 			},
 			want: cpuState{
-				D:      [8]uint32{517082379, 2043251229, 3181018212, 684885461, 118794459, 4077114755, 2793195966, 2895234059},
-				A:      [7]uint32{2351101974, 3218097533, 2387558499, 2376605782, 98888236, 3651385614, 2559747564},
-				PC:     3931681940,
-				SR:     8478,
-				USP:    6164496,
-				SSP:    12121568,
-				RAM:    [][2]uint32{{4886304, 78}, {4886305, 119}, {4886306, 214}, {4886307, 229}, {6164490, 232}, {6164491, 158}, {6164492, 231}, {6164493, 8}, {6164494, 235}, {6164495, 165}, {12121580, 143}, {12121581, 34}, {12121576, 129}, {12121577, 30}, {12121578, 0}, {12121579, 74}, {12121574, 78}, {12121575, 119}, {12121572, 235}, {12121573, 165}, {12121568, 78}, {12121569, 114}, {12121570, 231}, {12121571, 8}, {12, 234}, {13, 88}, {14, 180}, {15, 144}, {5813392, 246}, {5813393, 152}, {5813394, 8}, {5813395, 26}},
-				Halted: true,
+				D:   [8]uint32{517082379, 2043251229, 3181018212, 684885461, 118794459, 4077114755, 2793195966, 2895234059},
+				A:   [7]uint32{2351101974, 3218097533, 2387558499, 2376605782, 98888236, 3651385614, 2559747564},
+				PC:  3931681940,
+				SR:  8478,
+				USP: 6164496,
+				SSP: 12121568,
+				RAM: [][2]uint32{{4886304, 78}, {4886305, 119}, {4886306, 214}, {4886307, 229}, {6164490, 232}, {6164491, 158}, {6164492, 231}, {6164493, 8}, {6164494, 235}, {6164495, 165}, {12121580, 143}, {12121581, 34}, {12121576, 129}, {12121577, 30}, {12121578, 0}, {12121579, 74}, {12121574, 78}, {12121575, 119}, {12121572, 235}, {12121573, 165}, {12121568, 78}, {12121569, 114}, {12121570, 231}, {12121571, 8}, {12, 234}, {13, 88}, {14, 180}, {15, 144}, {5813392, 246}, {5813393, 152}, {5813394, 8}, {5813395, 26}},
 			},
 		},
 		{
@@ -2899,3 +2889,298 @@ func TestScc(t *testing.T) {
 		})
 	}
 }
+
+func TestRTD(t *testing.T) {
+	setup := func(v Variant) (*CPU, *testBus) {
+		// RTD #12
+		cpu, bus := progCPU(v, Registers{SR: 0x2700, SSP: 0xFF00}, 0x4E74, 0x000C)
+		bus.Write32(0xFF00, 0x2000) // return address on the stack
+		bus.Write32(vecIllegalInstruction*4, 0x3000)
+		return cpu, bus
+	}
+
+	t.Run("68010 pops PC and releases arguments", func(t *testing.T) {
+		cpu, _ := setup(MC68010)
+		if n := cpu.Step(); n != 16 {
+			t.Errorf("cycles = %d, want 16", n)
+		}
+		reg := cpu.Registers()
+		if reg.PC != 0x2000 {
+			t.Errorf("PC = 0x%X, want 0x2000", reg.PC)
+		}
+		if reg.A[7] != 0xFF00+4+12 {
+			t.Errorf("A7 = 0x%X, want 0x%X", reg.A[7], 0xFF00+4+12)
+		}
+	})
+
+	t.Run("negative displacement", func(t *testing.T) {
+		cpu, bus := setup(MC68010)
+		writeWord(bus, 0x1002, 0xFFFC) // RTD #-4
+		cpu.Step()
+		if sp := cpu.Registers().A[7]; sp != 0xFF00 {
+			t.Errorf("A7 = 0x%X, want 0xFF00", sp)
+		}
+	})
+
+	t.Run("illegal on the 68000", func(t *testing.T) {
+		cpu, _ := setup(MC68000)
+		cpu.Step()
+		reg := cpu.Registers()
+		if reg.PC != 0x3000 {
+			t.Errorf("PC = 0x%X, want illegal instruction handler 0x3000", reg.PC)
+		}
+		if reg.A[7] != 0xFF00-6 {
+			t.Errorf("A7 = 0x%X, want exception frame at 0x%X", reg.A[7], 0xFF00-6)
+		}
+	})
+}
+
+func TestDBccLoopMode(t *testing.T) {
+	// MOVE.W (A0)+,(A1)+ ; DBF D0,*-2 copying four words
+	setup := func(v Variant) *CPU {
+		bus := &testBus{}
+		writeWord(bus, 0x1000, 0x32D8)
+		writeWord(bus, 0x1002, 0x51C8)
+		writeWord(bus, 0x1004, 0xFFFC)
+		fillNOPs(bus, 0x1006, 4)
+		cpu := &CPU{bus: bus, variant: v}
+		cpu.SetState(Registers{D: [8]uint32{3}, A: [8]uint32{0x2000, 0x3000}, PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+		return cpu
+	}
+	run := func(cpu *CPU) []int {
+		var got []int
+		for cpu.PC() != 0x1006 {
+			got = append(got, cpu.Step())
+		}
+		return got
+	}
+
+	t.Run("68000 timing unchanged", func(t *testing.T) {
+		got := run(setup(MC68000))
+		want := []int{12, 10, 12, 10, 12, 10, 12, 14}
+		if !slices.Equal(got, want) {
+			t.Errorf("cycles = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("68010 drops the loop body fetch", func(t *testing.T) {
+		cpu := setup(MC68010)
+		got := run(cpu)
+		want := []int{12, 10, 8, 10, 8, 10, 8, 16}
+		if !slices.Equal(got, want) {
+			t.Errorf("cycles = %v, want %v", got, want)
+		}
+		if cpu.loopMode {
+			t.Error("loop mode still set after the loop terminated")
+		}
+		if n := cpu.Step(); n != 4 {
+			t.Errorf("NOP after loop = %d cycles, want 4", n)
+		}
+	})
+
+	t.Run("68010 ignores non-loopable bodies", func(t *testing.T) {
+		cpu := setup(MC68010)
+		writeWord(cpu.bus.(*testBus), 0x1000, 0x5241) // ADDQ.W #1,D1
+		got := run(cpu)
+		want := []int{4, 10, 4, 10, 4, 10, 4, 16}
+		if !slices.Equal(got, want) {
+			t.Errorf("cycles = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("condition true", func(t *testing.T) {
+		// DBT D0,*-2: no decrement, no branch
+		for _, tt := range []struct {
+			v    Variant
+			want int
+		}{{MC68000, 12}, {MC68010, 10}} {
+			cpu := setup(tt.v)
+			writeWord(cpu.bus.(*testBus), 0x1002, 0x50C8)
+			cpu.SetPC(0x1002)
+			if n := cpu.Step(); n != tt.want {
+				t.Errorf("%v: cycles = %d, want %d", tt.v, n, tt.want)
+			}
+		}
+	})
+
+	t.Run("Deserialize leaves loop mode", func(t *testing.T) {
+		cpu := setup(MC68010)
+		buf := make([]byte, SerializeSize)
+		if err := cpu.Serialize(buf); err != nil {
+			t.Fatal(err)
+		}
+		cpu.Step()
+		cpu.Step()
+		if !cpu.loopMode {
+			t.Fatal("loop mode not entered")
+		}
+		if err := cpu.Deserialize(buf); err != nil {
+			t.Fatal(err)
+		}
+		if cpu.loopMode || cpu.lastOp != 0 || cpu.lastOpPC != 0 {
+			t.Errorf("loop mode %v, last op 0x%04X at 0x%X after Deserialize", cpu.loopMode, cpu.lastOp, cpu.lastOpPC)
+		}
+		if n := cpu.Step(); n != 12 {
+			t.Errorf("first body after restore = %d cycles, want 12", n)
+		}
+	})
+}
+
+func TestLoopable(t *testing.T) {
+	tests := []struct {
+		op   uint16
+		want bool
+	}{
+		{0x32D8, true},  // MOVE.W (A0)+,(A1)+
+		{0x2018, true},  // MOVE.L (A0)+,D0
+		{0x3001, false}, // MOVE.W D1,D0: no memory operand
+		{0x3028, false}, // MOVE.W d16(A0),D0: extension word
+		{0x3258, false}, // MOVEA.W (A0)+,A1
+		{0xD058, true},  // ADD.W (A0)+,D0
+		{0xD0D8, true},  // ADDA.W (A0)+,A0
+		{0xD348, true},  // ADDX.W -(A0),-(A1)
+		{0xB348, true},  // CMPM.W (A0)+,(A1)+
+		{0xB158, true},  // EOR.W D0,(A0)+
+		{0xC0D8, false}, // MULU (A0)+,D0
+		{0x80D8, false}, // DIVU (A0)+,D0
+		{0xC308, true},  // ABCD -(A0),-(A1)
+		{0x4258, true},  // CLR.W (A0)+
+		{0x4A40, false}, // TST.W D0
+		{0x4820, true},  // NBCD -(A0)
+		{0xE0D8, true},  // ASR.W (A0)+
+		{0xE248, false}, // LSR.W #1,D0
+		{0x4E71, false}, // NOP
+	}
+	for _, tt := range tests {
+		if got := loopable(tt.op); got != tt.want {
+			t.Errorf("loopable(0x%04X) = %v, want %v", tt.op, got, tt.want)
+		}
+	}
+}
+
+func TestBranchTimingByDisplacement(t *testing.T) {
+	tests := []struct {
+		name   string
+		prog   []uint16
+		sr     uint16
+		pc     uint32
+		cycles int
+	}{
+		{"BEQ.S taken", []uint16{0x6710}, 0x2704, 0x1012, 10},
+		{"BEQ.S not taken", []uint16{0x6710}, 0x2700, 0x1002, 8},
+		{"BEQ.W taken", []uint16{0x6700, 0x0010}, 0x2704, 0x1012, 10},
+		{"BEQ.W not taken", []uint16{0x6700, 0x0010}, 0x2700, 0x1004, 12},
+		{"BRA.S", []uint16{0x6010}, 0x2700, 0x1012, 10},
+		{"BRA.W", []uint16{0x6000, 0x0010}, 0x2700, 0x1012, 10},
+		{"BSR.S", []uint16{0x6110}, 0x2700, 0x1012, 18},
+		{"BSR.W", []uint16{0x6100, 0x0010}, 0x2700, 0x1012, 18},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			for i, w := range tt.prog {
+				writeWord(bus, 0x1000+uint32(i*2), w)
+			}
+			cpu := &CPU{bus: bus}
+			cpu.SetState(Registers{PC: 0x1000, SR: tt.sr, SSP: 0x10000})
+			if n := cpu.Step(); n != tt.cycles {
+				t.Errorf("cycles = %d, want %d", n, tt.cycles)
+			}
+			if pc := cpu.PC(); pc != tt.pc {
+				t.Errorf("PC = 0x%X, want 0x%X", pc, tt.pc)
+			}
+		})
+	}
+}
+
+// TestDBccOutcomes covers the three DBcc outcomes. PC ends after the
+// displacement word whenever the branch is not taken, and the decrement
+// only touches the low word of Dn.
+func TestDBccOutcomes(t *testing.T) {
+	tests := []struct {
+		name   string
+		op     uint16
+		sr     uint16
+		d0     uint32
+		pc     uint32
+		d0Want uint32
+		cycles int
+	}{
+		// DBEQ D0,$0FF0 with Z set: no decrement
+		{"condition true", 0x57C8, 0x2704, 0xABCD0005, 0x1004, 0xABCD0005, 12},
+		// DBRA D0,$0FF0
+		{"counter not expired", 0x51C8, 0x2700, 0xABCD0005, 0x0FF0, 0xABCD0004, 10},
+		{"counter reaches zero", 0x51C8, 0x2700, 0xABCD0001, 0x0FF0, 0xABCD0000, 10},
+		{"counter expired", 0x51C8, 0x2700, 0xABCD0000, 0x1004, 0xABCDFFFF, 14},
+		// DBEQ D0,$0FF0 with Z clear behaves as DBRA
+		{"condition false", 0x57C8, 0x2700, 0x00008000, 0x0FF0, 0x00007FFF, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			writeWord(bus, 0x1000, tt.op)
+			writeWord(bus, 0x1002, 0xFFEE)
+			cpu := &CPU{bus: bus}
+			cpu.SetState(Registers{D: [8]uint32{tt.d0}, PC: 0x1000, SR: tt.sr, SSP: 0x10000})
+			if n := cpu.Step(); n != tt.cycles {
+				t.Errorf("cycles = %d, want %d", n, tt.cycles)
+			}
+			if pc := cpu.PC(); pc != tt.pc {
+				t.Errorf("PC = 0x%X, want 0x%X", pc, tt.pc)
+			}
+			if d0 := cpu.D(0); d0 != tt.d0Want {
+				t.Errorf("D0 = 0x%08X, want 0x%08X", d0, tt.d0Want)
+			}
+		})
+	}
+}
+
+// TestSccTiming checks that Scc to a data register costs 6 when the
+// condition is true and 4 when false, while to memory it costs 8 plus the
+// EA time either way.
+func TestSccTiming(t *testing.T) {
+	// SEQ <ea>: 0x57C0 | ea, with extension words for the EA
+	targets := []struct {
+		name   string
+		ea     uint16
+		ext    []uint16
+		addr   uint32
+		cycles [2]int // false, true
+	}{
+		{"D1", 0x01, nil, 0, [2]int{4, 6}},
+		{"(A0)", 0x10, nil, 0x3000, [2]int{12, 12}},
+		{"(A0)+", 0x18, nil, 0x3000, [2]int{12, 12}},
+		{"-(A0)", 0x20, nil, 0x2FFF, [2]int{14, 14}},
+		{"d16(A0)", 0x28, []uint16{0x0010}, 0x3010, [2]int{16, 16}},
+		{"d8(A0,D2)", 0x30, []uint16{0x2004}, 0x3004, [2]int{18, 18}},
+		{"abs.W", 0x38, []uint16{0x3020}, 0x3020, [2]int{16, 16}},
+		{"abs.L", 0x39, []uint16{0x0000, 0x3030}, 0x3030, [2]int{20, 20}},
+	}
+	for _, tt := range targets {
+		for i, sr := range []uint16{0x2700, 0x2704} { // Z clear, Z set
+			want := uint32(0x00)
+			if i == 1 {
+				want = 0xFF
+			}
+			bus := &testBus{}
+			writeWord(bus, 0x1000, 0x57C0|tt.ea)
+			for k, w := range tt.ext {
+				writeWord(bus, 0x1002+uint32(k*2), w)
+			}
+			bus.mem[tt.addr] = 0x5A
+			cpu := &CPU{bus: bus}
+			cpu.SetState(Registers{D: [8]uint32{1: 0x12345678}, A: [8]uint32{0x3000}, PC: 0x1000, SR: sr, SSP: 0x10000})
+			if n := cpu.Step(); n != tt.cycles[i] {
+				t.Errorf("SEQ %s with Z=%d: cycles = %d, want %d", tt.name, i, n, tt.cycles[i])
+			}
+			got := uint32(bus.mem[tt.addr])
+			if tt.addr == 0 {
+				got = cpu.D(1)
+				want |= 0x12345600
+			}
+			if got != want {
+				t.Errorf("SEQ %s with Z=%d: result = 0x%X, want 0x%X", tt.name, i, got, want)
+			}
+		}
+	}
+}
@@ -10,6 +10,36 @@ func init() {
 	registerUNLK()
 	registerMoveToFromSR()
 	registerAndiOriEoriSRCCR()
+	registerMOVEC()
+	registerMOVES()
+	registerMOVEfromCCR()
+	registerIllegal()
+}
+
+// --- ILLEGAL, Line A, Line F ---
+
+// registerIllegal registers the architecturally illegal words, so that a
+// nil table entry is left to mean an unassigned one.
+func registerIllegal() {
+	opcodeTable[0x4AFC] = opILLEGAL
+	for op := 0xA000; op <= 0xAFFF; op++ {
+		opcodeTable[op] = opLineA
+	}
+	for op := 0xF000; op <= 0xFFFF; op++ {
+		opcodeTable[op] = opLineF
+	}
+}
+
+func opILLEGAL(c *CPU) {
+	c.exception(vecIllegalInstruction)
+}
+
+func opLineA(c *CPU) {
+	c.exception(vecLineA)
+}
+
+func opLineF(c *CPU) {
+	c.exception(vecLineF)
 }
 
 // --- NOP ---
@@ -37,11 +67,10 @@ func opSTOP(c *CPU) {
 	imm := c.fetchPC()
 	c.setSR(imm)
 	c.stopped = true
-	// The 68000 halts after STOP, and the prefetch pipeline does not
-	// advance. To match the hardware PC state, rewind PC to the
-	// instruction start so that resuming via interrupt sees the
-	// correct next-instruction address in the exception frame.
-	c.reg.PC = c.prevPC
+	// PC is left at the next instruction, which the interrupt or trace
+	// exception that ends the stop stacks as its return address. The
+	// 68000 does not refill the prefetch queue after STOP, so Registers
+	// reports this address as the hardware PC while stopped.
 	c.cycles += 4
 }
 
@@ -51,14 +80,46 @@ func registerRESET() {
 	opcodeTable[0x4E70] = opRESET
 }
 
+// Default RESET instruction timing: the cost of the instruction and the
+// clocks for which it asserts the RESET output. The MC68020 holds the
+// output for 512 clocks instead of 124.
+const (
+	resetInstrCycles    = 132
+	resetPulseClocks    = 124
+	resetInstrCycles020 = 518
+	resetPulseClocks020 = 512
+)
+
+// resetTiming returns the RESET instruction cost and pulse width, from
+// SetResetTiming or the variant's defaults.
+func (c *CPU) resetTiming() (cycles, pulse int) {
+	cycles, pulse = resetInstrCycles, resetPulseClocks
+	if c.variant >= MC68020 {
+		cycles, pulse = resetInstrCycles020, resetPulseClocks020
+	}
+	if c.resetCycles != 0 {
+		cycles = c.resetCycles
+	}
+	if c.resetPulse != 0 {
+		pulse = c.resetPulse
+	}
+	return cycles, pulse
+}
+
+// opRESET asserts the RESET output to reinitialise external devices. The
+// processor's own registers and state are not affected.
 func opRESET(c *CPU) {
 	if !c.supervisor() {
 		c.exception(vecPrivilegeViolation)
 		return
 	}
 
+	cycles, pulse := c.resetTiming()
 	c.bus.Reset()
-	c.cycles += 132
+	if c.resetPinFunc != nil {
+		c.resetPinFunc(pulse)
+	}
+	c.cycles += uint64(cycles)
 }
 
 // --- TRAP ---
@@ -121,8 +182,12 @@ func registerUNLK() {
 
 func opUNLK(c *CPU) {
 	an := c.ir & 7
-	c.reg.A[7] = c.reg.A[an]
-	c.reg.A[an] = c.popLong()
+	// The saved frame pointer is read before A7 is loaded, so an odd An
+	// faults with the stack pointer untouched.
+	c.faultAdj = 2
+	val := c.readBus(sizeLong, c.reg.A[an])
+	c.reg.A[7] = c.reg.A[an] + 4
+	c.reg.A[an] = val
 
 	c.cycles += 12
 }
@@ -180,9 +245,16 @@ func registerMoveToFromSR() {
 	}
 }
 
+// makeMOVEfromSR builds MOVE SR,<ea>. It is unprivileged on the 68000 and
+// 68008 but privileged from the 68010 on, which added MOVE from CCR for
+// user code.
 func makeMOVEfromSR(mode, reg uint16) opFunc {
 	if mode == 0 {
 		return func(c *CPU) {
+			if c.variant >= MC68010 && !c.supervisor() {
+				c.exception(vecPrivilegeViolation)
+				return
+			}
 			c.reg.D[reg] = (c.reg.D[reg] & 0xFFFF0000) | uint32(c.reg.SR)
 			c.cycles += 6
 		}
@@ -190,12 +262,20 @@ func makeMOVEfromSR(mode, reg uint16) opFunc {
 	addr := makeEAMemAddr(mode, reg)
 	eaBase, _ := eaFetchConst(mode, reg)
 	return func(c *CPU) {
+		if c.variant >= MC68010 && !c.supervisor() {
+			c.exception(vecPrivilegeViolation)
+			return
+		}
 		a := addr(c, sizeWord)
+		// Like CLR, the 68000 reads the destination before writing it.
+		c.readBus(sizeWord, a)
 		c.writeBus(sizeWord, a, uint32(c.reg.SR))
 		c.cycles += 8 + eaBase
 	}
 }
 
+// makeMOVEtoCCR builds MOVE <ea>,CCR, which reads a word and keeps the
+// low five bits of it, with the same timing as MOVE <ea>,SR.
 func makeMOVEtoCCR(mode, reg uint16) opFunc {
 	read := makeEARead(mode, reg)
 	eaBase, eaLong := eaFetchConst(mode, reg)
@@ -209,6 +289,9 @@ func makeMOVEtoCCR(mode, reg uint16) opFunc {
 	}
 }
 
+// makeMOVEtoSR builds MOVE <ea>,SR: 12 cycles plus the word EA fetch, so
+// 16 for MOVE #imm,SR. setSR swaps A7 between the USP and SSP when the
+// new value changes S.
 func makeMOVEtoSR(mode, reg uint16) opFunc {
 	read := makeEARead(mode, reg)
 	eaBase, eaLong := eaFetchConst(mode, reg)
@@ -310,3 +393,192 @@ func opEORItoSR(c *CPU) {
 	c.setSR(c.reg.SR ^ imm)
 	c.cycles += 20
 }
+
+// --- MOVEC (68010) ---
+
+// MOVEC control register selectors (bits 11-0 of the extension word).
+const (
+	ctrlSFC = 0x000
+	ctrlDFC = 0x001
+	ctrlUSP = 0x800
+	ctrlVBR = 0x801
+)
+
+// registerMOVEC registers MOVEC Rc,Rn (0x4E7A) and MOVEC Rn,Rc (0x4E7B).
+// Extension word: ARRR CCCC CCCC CCCC (A = address register, RRR =
+// general register, C = control register). On the 68000 both opcodes are
+// illegal instructions.
+func registerMOVEC() {
+	opcodeTable[0x4E7A] = opMOVEC
+	opcodeTable[0x4E7B] = opMOVEC
+}
+
+func opMOVEC(c *CPU) {
+	if c.variant < MC68010 {
+		c.exception(vecIllegalInstruction)
+		return
+	}
+	if !c.supervisor() {
+		c.exception(vecPrivilegeViolation)
+		return
+	}
+
+	ext := c.fetchPC()
+	rn := (ext >> 12) & 7
+	gen := &c.reg.D[rn]
+	if ext&0x8000 != 0 {
+		gen = &c.reg.A[rn]
+	}
+
+	var ctrl *uint32
+	var fc *uint8
+	switch ext & 0x0FFF {
+	case ctrlSFC:
+		fc = &c.reg.SFC
+	case ctrlDFC:
+		fc = &c.reg.DFC
+	case ctrlUSP:
+		ctrl = &c.reg.USP
+	case ctrlVBR:
+		ctrl = &c.reg.VBR
+	default:
+		c.exception(vecIllegalInstruction)
+		return
+	}
+
+	if c.ir&1 == 0 {
+		// Control register to general register
+		if fc != nil {
+			*gen = uint32(*fc)
+		} else {
+			*gen = *ctrl
+		}
+		c.cycles += 10
+		return
+	}
+	// General register to control register
+	if fc != nil {
+		*fc = uint8(*gen & 7)
+	} else {
+		*ctrl = *gen
+	}
+	c.cycles += 12
+}
+
+// --- MOVES (68010) ---
+
+// movesCycles approximates the MC68010 MOVES timing, without EA time.
+const movesCycles = 14
+
+// registerMOVES registers MOVES <ea>,Rn and MOVES Rn,<ea>
+// (0000 1110 ssee eeee) for the memory alterable modes. Extension word:
+// ARRR D000 0000 0000 (A = address register, RRR = register, D = register
+// to memory). The memory operand is read in the address space named by
+// SFC and written in the one named by DFC. On the 68000 the opcodes are
+// illegal instructions.
+func registerMOVES() {
+	for szBits := uint16(0); szBits < 3; szBits++ {
+		for mode := uint16(2); mode < 8; mode++ {
+			for reg := uint16(0); reg < 8; reg++ {
+				if mode == 7 && reg > 1 {
+					continue
+				}
+				opcodeTable[0x0E00|szBits<<6|mode<<3|reg] = makeMOVES(sizeEncoding(szBits), mode, reg)
+			}
+		}
+	}
+}
+
+func makeMOVES(sz size, mode, reg uint16) opFunc {
+	addr := makeEAMemAddr(mode, reg)
+	readBase, readLong := eaFetchConst(mode, reg)
+	writeBase, writeLong := eaWriteConst(mode, reg)
+	if sz == sizeLong {
+		readBase += readLong
+		writeBase += writeLong
+	}
+	return func(c *CPU) {
+		if c.variant < MC68010 {
+			c.exception(vecIllegalInstruction)
+			return
+		}
+		if !c.supervisor() {
+			c.exception(vecPrivilegeViolation)
+			return
+		}
+
+		ext := c.fetchPC()
+		rn := (ext >> 12) & 7
+		if ext&0x0800 != 0 {
+			// Register to memory, in the DFC space
+			val := c.reg.D[rn]
+			if ext&0x8000 != 0 {
+				val = c.reg.A[rn]
+			}
+			ea := addr(c, sz)
+			c.altFC, c.useAltFC = c.reg.DFC, true
+			c.writeBus(sz, ea, val)
+			c.useAltFC = false
+			c.cycles += movesCycles + writeBase
+			return
+		}
+
+		// Memory to register, in the SFC space. An address register
+		// takes the whole sign-extended operand.
+		ea := addr(c, sz)
+		c.altFC, c.useAltFC = c.reg.SFC, true
+		val := c.readBus(sz, ea)
+		c.useAltFC = false
+		if ext&0x8000 != 0 {
+			c.reg.A[rn] = sz.SignExtend(val)
+		} else {
+			c.reg.D[rn] = c.reg.D[rn]&^sz.Mask() | val
+		}
+		c.cycles += movesCycles + readBase
+	}
+}
+
+// --- MOVE from CCR (68010) ---
+
+// registerMOVEfromCCR registers MOVE CCR,<ea> (0100 0010 11ss ssss). The
+// encoding is CLR with an invalid size on the 68000, where it is an
+// illegal instruction.
+func registerMOVEfromCCR() {
+	for mode := uint16(0); mode < 8; mode++ {
+		if mode == 1 {
+			continue
+		}
+		for reg := uint16(0); reg < 8; reg++ {
+			if mode == 7 && reg > 1 {
+				continue
+			}
+			opcodeTable[0x42C0|mode<<3|reg] = makeMOVEfromCCR(mode, reg)
+		}
+	}
+}
+
+// makeMOVEfromCCR stores the condition codes as a word with the upper
+// byte zero. Unlike the 68000 MOVE from SR it writes the destination
+// without reading it first.
+func makeMOVEfromCCR(mode, reg uint16) opFunc {
+	if mode == 0 {
+		return func(c *CPU) {
+			if c.variant < MC68010 {
+				c.exception(vecIllegalInstruction)
+				return
+			}
+			c.reg.D[reg] = (c.reg.D[reg] & 0xFFFF0000) | uint32(c.reg.SR&0xFF)
+			c.cycles += 4
+		}
+	}
+	addr := makeEAMemAddr(mode, reg)
+	eaBase, _ := eaFetchConst(mode, reg)
+	return func(c *CPU) {
+		if c.variant < MC68010 {
+			c.exception(vecIllegalInstruction)
+			return
+		}
+		c.writeBus(sizeWord, addr(c, sizeWord), uint32(c.reg.SR&0xFF))
+		c.cycles += 8 + eaBase
+	}
+}
@@ -1952,14 +1952,13 @@ func TestUNLINK(t *testing.T) {
 				RAM: [][2]uint32{{6891988, 78}, {6891989, 92}, {6891990, 11}, {6891991, 27}, {12, 24}, {13, 20}, {14, 95}, {15, 122}, {1335162, 95}, {1335163, 242}, {1335164, 106}, {1335165, 199}},
 			},
 			want: cpuState{
-				D:      [8]uint32{4188436590, 403533247, 615474429, 3747526936, 3825585159, 2489378293, 1133655991, 4128972051},
-				A:      [7]uint32{1694106958, 3493144052, 581047800, 4261867541, 3129588871, 1826850257, 3304415370},
-				PC:     403988350,
-				SR:     9233,
-				USP:    10090944,
-				SSP:    16089640,
-				RAM:    [][2]uint32{{6891988, 78}, {6891989, 92}, {6891990, 11}, {6891991, 27}, {16089652, 41}, {16089653, 216}, {16089648, 36}, {16089649, 17}, {16089650, 0}, {16089651, 105}, {16089646, 78}, {16089647, 92}, {16089644, 188}, {16089645, 135}, {16089640, 78}, {16089641, 85}, {16089642, 186}, {16089643, 137}, {12, 24}, {13, 20}, {14, 95}, {15, 122}, {1335162, 95}, {1335163, 242}, {1335164, 106}, {1335165, 199}},
-				Halted: true,
+				D:   [8]uint32{4188436590, 403533247, 615474429, 3747526936, 3825585159, 2489378293, 1133655991, 4128972051},
+				A:   [7]uint32{1694106958, 3493144052, 581047800, 4261867541, 3129588871, 1826850257, 3304415370},
+				PC:  403988350,
+				SR:  9233,
+				USP: 10090944,
+				SSP: 16089640,
+				RAM: [][2]uint32{{6891988, 78}, {6891989, 92}, {6891990, 11}, {6891991, 27}, {16089652, 41}, {16089653, 216}, {16089648, 36}, {16089649, 17}, {16089650, 0}, {16089651, 105}, {16089646, 78}, {16089647, 92}, {16089644, 188}, {16089645, 135}, {16089640, 78}, {16089641, 85}, {16089642, 186}, {16089643, 137}, {12, 24}, {13, 20}, {14, 95}, {15, 122}, {1335162, 95}, {1335163, 242}, {1335164, 106}, {1335165, 199}},
 			},
 		},
 		{
@@ -1996,14 +1995,13 @@ func TestUNLINK(t *testing.T) {
 				RAM: [][2]uint32{{10600230, 78}, {10600231, 92}, {10600232, 83}, {10600233, 115}, {12, 153}, {13, 146}, {14, 104}, {15, 228}, {9595108, 235}, {9595109, 6}, {9595110, 63}, {9595111, 3}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1715425790, 3776789943, 216486791, 1664131033, 4290017278, 1405198863, 3654345789, 3611134267},
-				A:      [7]uint32{4152736271, 3832597824, 1256201469, 2897977011, 3908002215, 2955164549, 3181401823},
-				PC:     2576509160,
-				SR:     8724,
-				USP:    3624920,
-				SSP:    6393304,
-				RAM:    [][2]uint32{{10600230, 78}, {10600231, 92}, {10600232, 83}, {10600233, 115}, {6393316, 191}, {6393317, 42}, {6393312, 130}, {6393313, 20}, {6393314, 0}, {6393315, 161}, {6393310, 78}, {6393311, 92}, {6393308, 97}, {6393309, 167}, {6393304, 78}, {6393305, 81}, {6393306, 232}, {6393307, 239}, {12, 153}, {13, 146}, {14, 104}, {15, 228}, {9595108, 235}, {9595109, 6}, {9595110, 63}, {9595111, 3}},
-				Halted: true,
+				D:   [8]uint32{1715425790, 3776789943, 216486791, 1664131033, 4290017278, 1405198863, 3654345789, 3611134267},
+				A:   [7]uint32{4152736271, 3832597824, 1256201469, 2897977011, 3908002215, 2955164549, 3181401823},
+				PC:  2576509160,
+				SR:  8724,
+				USP: 3624920,
+				SSP: 6393304,
+				RAM: [][2]uint32{{10600230, 78}, {10600231, 92}, {10600232, 83}, {10600233, 115}, {6393316, 191}, {6393317, 42}, {6393312, 130}, {6393313, 20}, {6393314, 0}, {6393315, 161}, {6393310, 78}, {6393311, 92}, {6393308, 97}, {6393309, 167}, {6393304, 78}, {6393305, 81}, {6393306, 232}, {6393307, 239}, {12, 153}, {13, 146}, {14, 104}, {15, 228}, {9595108, 235}, {9595109, 6}, {9595110, 63}, {9595111, 3}},
 			},
 		},
 		{
@@ -2040,14 +2038,13 @@ func TestUNLINK(t *testing.T) {
 				RAM: [][2]uint32{{8613458, 78}, {8613459, 90}, {8613460, 122}, {8613461, 112}, {12, 218}, {13, 96}, {14, 196}, {15, 26}, {6341658, 101}, {6341659, 0}, {6341660, 47}, {6341661, 209}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3477829563, 658420195, 1763503059, 990799756, 2791921992, 3097682834, 744330401, 2652426465},
-				A:      [7]uint32{764463701, 3340263618, 2334053929, 514531639, 944792472, 3497479675, 887733273},
-				PC:     3663774750,
-				SR:     9225,
-				USP:    11613240,
-				SSP:    6846594,
-				RAM:    [][2]uint32{{8613458, 78}, {8613459, 90}, {8613460, 122}, {8613461, 112}, {6846606, 110}, {6846607, 86}, {6846602, 164}, {6846603, 9}, {6846604, 0}, {6846605, 131}, {6846600, 78}, {6846601, 90}, {6846598, 214}, {6846599, 41}, {6846594, 78}, {6846595, 85}, {6846596, 139}, {6846597, 30}, {12, 218}, {13, 96}, {14, 196}, {15, 26}, {6341658, 101}, {6341659, 0}, {6341660, 47}, {6341661, 209}},
-				Halted: true,
+				D:   [8]uint32{3477829563, 658420195, 1763503059, 990799756, 2791921992, 3097682834, 744330401, 2652426465},
+				A:   [7]uint32{764463701, 3340263618, 2334053929, 514531639, 944792472, 3497479675, 887733273},
+				PC:  3663774750,
+				SR:  9225,
+				USP: 11613240,
+				SSP: 6846594,
+				RAM: [][2]uint32{{8613458, 78}, {8613459, 90}, {8613460, 122}, {8613461, 112}, {6846606, 110}, {6846607, 86}, {6846602, 164}, {6846603, 9}, {6846604, 0}, {6846605, 131}, {6846600, 78}, {6846601, 90}, {6846598, 214}, {6846599, 41}, {6846594, 78}, {6846595, 85}, {6846596, 139}, {6846597, 30}, {12, 218}, {13, 96}, {14, 196}, {15, 26}, {6341658, 101}, {6341659, 0}, {6341660, 47}, {6341661, 209}},
 			},
 		},
 		{
@@ -2362,14 +2359,13 @@ func TestMOVEtoCCR(t *testing.T) {
 				RAM: [][2]uint32{{8402978, 68}, {8402979, 236}, {8402980, 249}, {8402981, 230}, {8402982, 72}, {8402983, 43}, {12, 75}, {13, 2}, {14, 137}, {15, 48}, {166192, 1}, {166193, 81}, {166194, 223}, {166195, 239}},
 			},
 			want: cpuState{
-				D:      [8]uint32{788192078, 1250959520, 4051970713, 2769674552, 418192740, 1377804564, 2670827572, 4048077299},
-				A:      [7]uint32{2803945173, 3200741387, 637590458, 776142276, 466116355, 1644891918, 82803457},
-				PC:     1258457396,
-				SR:     9238,
-				USP:    10454214,
-				SSP:    13533150,
-				RAM:    [][2]uint32{{8402978, 68}, {8402979, 236}, {8402980, 249}, {8402981, 230}, {8402982, 72}, {8402983, 43}, {13533162, 56}, {13533163, 36}, {13533158, 164}, {13533159, 22}, {13533160, 0}, {13533161, 128}, {13533156, 68}, {13533157, 236}, {13533154, 88}, {13533155, 233}, {13533150, 68}, {13533151, 245}, {13533152, 27}, {13533153, 200}, {12, 75}, {13, 2}, {14, 137}, {15, 48}, {166192, 1}, {166193, 81}, {166194, 223}, {166195, 239}},
-				Halted: true,
+				D:   [8]uint32{788192078, 1250959520, 4051970713, 2769674552, 418192740, 1377804564, 2670827572, 4048077299},
+				A:   [7]uint32{2803945173, 3200741387, 637590458, 776142276, 466116355, 1644891918, 82803457},
+				PC:  1258457396,
+				SR:  9238,
+				USP: 10454214,
+				SSP: 13533150,
+				RAM: [][2]uint32{{8402978, 68}, {8402979, 236}, {8402980, 249}, {8402981, 230}, {8402982, 72}, {8402983, 43}, {13533162, 56}, {13533163, 36}, {13533158, 164}, {13533159, 22}, {13533160, 0}, {13533161, 128}, {13533156, 68}, {13533157, 236}, {13533154, 88}, {13533155, 233}, {13533150, 68}, {13533151, 245}, {13533152, 27}, {13533153, 200}, {12, 75}, {13, 2}, {14, 137}, {15, 48}, {166192, 1}, {166193, 81}, {166194, 223}, {166195, 239}},
 			},
 		},
 		{
@@ -2640,14 +2636,13 @@ func TestMOVEtoSR(t *testing.T) {
 				RAM: [][2]uint32{{7977924, 70}, {7977925, 245}, {7977926, 177}, {7977927, 32}, {7977928, 24}, {7977929, 249}, {12, 6}, {13, 126}, {14, 123}, {15, 244}, {8289268, 16}, {8289269, 179}, {8289270, 180}, {8289271, 141}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3952314096, 788468355, 1030333420, 1023986158, 2957870970, 1946342889, 983517413, 2227907290},
-				A:      [7]uint32{2584369980, 33206410, 994513456, 1188759060, 1450973416, 3848480019, 3052829127},
-				PC:     108952568,
-				SR:     9233,
-				USP:    15643716,
-				SSP:    5421664,
-				RAM:    [][2]uint32{{7977924, 70}, {7977925, 245}, {7977926, 177}, {7977927, 32}, {7977928, 24}, {7977929, 249}, {5421676, 187}, {5421677, 198}, {5421672, 164}, {5421673, 17}, {5421674, 0}, {5421675, 121}, {5421670, 70}, {5421671, 245}, {5421668, 43}, {5421669, 71}, {5421664, 70}, {5421665, 245}, {5421666, 229}, {5421667, 99}, {12, 6}, {13, 126}, {14, 123}, {15, 244}, {8289268, 16}, {8289269, 179}, {8289270, 180}, {8289271, 141}},
-				Halted: true,
+				D:   [8]uint32{3952314096, 788468355, 1030333420, 1023986158, 2957870970, 1946342889, 983517413, 2227907290},
+				A:   [7]uint32{2584369980, 33206410, 994513456, 1188759060, 1450973416, 3848480019, 3052829127},
+				PC:  108952568,
+				SR:  9233,
+				USP: 15643716,
+				SSP: 5421664,
+				RAM: [][2]uint32{{7977924, 70}, {7977925, 245}, {7977926, 177}, {7977927, 32}, {7977928, 24}, {7977929, 249}, {5421676, 187}, {5421677, 198}, {5421672, 164}, {5421673, 17}, {5421674, 0}, {5421675, 121}, {5421670, 70}, {5421671, 245}, {5421668, 43}, {5421669, 71}, {5421664, 70}, {5421665, 245}, {5421666, 229}, {5421667, 99}, {12, 6}, {13, 126}, {14, 123}, {15, 244}, {8289268, 16}, {8289269, 179}, {8289270, 180}, {8289271, 141}},
 			},
 		},
 		{
@@ -2662,14 +2657,13 @@ func TestMOVEtoSR(t *testing.T) {
 				RAM: [][2]uint32{{12130874, 70}, {12130875, 239}, {12130876, 236}, {12130877, 175}, {12130878, 13}, {12130879, 252}, {12, 214}, {13, 138}, {14, 220}, {15, 194}, {9100482, 113}, {9100483, 14}, {9100484, 173}, {9100485, 220}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1395318051, 1781170151, 988621212, 1040148351, 447376275, 515558757, 2832254203, 477483571},
-				A:      [7]uint32{3283532092, 2352982042, 580168180, 2088823459, 3479490460, 3884130311, 2135641708},
-				PC:     3599424710,
-				SR:     9990,
-				USP:    12966034,
-				SSP:    1032874,
-				RAM:    [][2]uint32{{12130874, 70}, {12130875, 239}, {12130876, 236}, {12130877, 175}, {12130878, 13}, {12130879, 252}, {1032886, 26}, {1032887, 60}, {1032882, 167}, {1032883, 6}, {1032884, 0}, {1032885, 185}, {1032880, 70}, {1032881, 239}, {1032878, 175}, {1032879, 103}, {1032874, 70}, {1032875, 245}, {1032876, 0}, {1032877, 15}, {12, 214}, {13, 138}, {14, 220}, {15, 194}, {9100482, 113}, {9100483, 14}, {9100484, 173}, {9100485, 220}},
-				Halted: true,
+				D:   [8]uint32{1395318051, 1781170151, 988621212, 1040148351, 447376275, 515558757, 2832254203, 477483571},
+				A:   [7]uint32{3283532092, 2352982042, 580168180, 2088823459, 3479490460, 3884130311, 2135641708},
+				PC:  3599424710,
+				SR:  9990,
+				USP: 12966034,
+				SSP: 1032874,
+				RAM: [][2]uint32{{12130874, 70}, {12130875, 239}, {12130876, 236}, {12130877, 175}, {12130878, 13}, {12130879, 252}, {1032886, 26}, {1032887, 60}, {1032882, 167}, {1032883, 6}, {1032884, 0}, {1032885, 185}, {1032880, 70}, {1032881, 239}, {1032878, 175}, {1032879, 103}, {1032874, 70}, {1032875, 245}, {1032876, 0}, {1032877, 15}, {12, 214}, {13, 138}, {14, 220}, {15, 194}, {9100482, 113}, {9100483, 14}, {9100484, 173}, {9100485, 220}},
 			},
 		},
 		{
@@ -2918,14 +2912,13 @@ func TestMOVEfromSR(t *testing.T) {
 				RAM: [][2]uint32{{919438, 64}, {919439, 230}, {919440, 92}, {919441, 208}, {12, 39}, {13, 242}, {14, 64}, {15, 232}, {15876328, 220}, {15876329, 4}, {15876330, 210}, {15876331, 136}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1539487470, 88414920, 3761114870, 1442749669, 1076942791, 2275119325, 263127465, 1787787047},
-				A:      [7]uint32{2373554379, 3120242247, 3654480073, 195491783, 2461167633, 3346612883, 2917650807},
-				PC:     670187756,
-				SR:     9227,
-				USP:    15951390,
-				SSP:    9033330,
-				RAM:    [][2]uint32{{919438, 64}, {919439, 230}, {919440, 92}, {919441, 208}, {9033342, 7}, {9033343, 146}, {9033338, 132}, {9033339, 11}, {9033340, 0}, {9033341, 14}, {9033336, 64}, {9033337, 230}, {9033334, 209}, {9033335, 119}, {9033330, 64}, {9033331, 241}, {9033332, 173}, {9033333, 231}, {12, 39}, {13, 242}, {14, 64}, {15, 232}, {15876328, 220}, {15876329, 4}, {15876330, 210}, {15876331, 136}},
-				Halted: true,
+				D:   [8]uint32{1539487470, 88414920, 3761114870, 1442749669, 1076942791, 2275119325, 263127465, 1787787047},
+				A:   [7]uint32{2373554379, 3120242247, 3654480073, 195491783, 2461167633, 3346612883, 2917650807},
+				PC:  670187756,
+				SR:  9227,
+				USP: 15951390,
+				SSP: 9033330,
+				RAM: [][2]uint32{{919438, 64}, {919439, 230}, {919440, 92}, {919441, 208}, {9033342, 7}, {9033343, 146}, {9033338, 132}, {9033339, 11}, {9033340, 0}, {9033341, 14}, {9033336, 64}, {9033337, 230}, {9033334, 209}, {9033335, 119}, {9033330, 64}, {9033331, 241}, {9033332, 173}, {9033333, 231}, {12, 39}, {13, 242}, {14, 64}, {15, 232}, {15876328, 220}, {15876329, 4}, {15876330, 210}, {15876331, 136}},
 			},
 		},
 		{
@@ -2940,14 +2933,13 @@ func TestMOVEfromSR(t *testing.T) {
 				RAM: [][2]uint32{{15657768, 64}, {15657769, 246}, {15657770, 71}, {15657771, 169}, {15657772, 159}, {15657773, 228}, {12, 108}, {13, 84}, {14, 65}, {15, 84}, {5521748, 22}, {5521749, 194}, {5521750, 182}, {5521751, 62}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1980334010, 3167605853, 310030401, 1553449489, 436945333, 718770126, 119453367, 2523342529},
-				A:      [7]uint32{1831867579, 2198748582, 2913131245, 3718313342, 3609801955, 37363688, 1293960239},
-				PC:     1817461080,
-				SR:     9735,
-				USP:    2572960,
-				SSP:    7161358,
-				RAM:    [][2]uint32{{15657768, 64}, {15657769, 246}, {15657770, 71}, {15657771, 169}, {15657772, 159}, {15657773, 228}, {7161370, 235}, {7161371, 42}, {7161366, 134}, {7161367, 7}, {7161368, 0}, {7161369, 238}, {7161364, 64}, {7161365, 246}, {7161362, 133}, {7161363, 141}, {7161358, 64}, {7161359, 241}, {7161360, 77}, {7161361, 32}, {12, 108}, {13, 84}, {14, 65}, {15, 84}, {5521748, 22}, {5521749, 194}, {5521750, 182}, {5521751, 62}},
-				Halted: true,
+				D:   [8]uint32{1980334010, 3167605853, 310030401, 1553449489, 436945333, 718770126, 119453367, 2523342529},
+				A:   [7]uint32{1831867579, 2198748582, 2913131245, 3718313342, 3609801955, 37363688, 1293960239},
+				PC:  1817461080,
+				SR:  9735,
+				USP: 2572960,
+				SSP: 7161358,
+				RAM: [][2]uint32{{15657768, 64}, {15657769, 246}, {15657770, 71}, {15657771, 169}, {15657772, 159}, {15657773, 228}, {7161370, 235}, {7161371, 42}, {7161366, 134}, {7161367, 7}, {7161368, 0}, {7161369, 238}, {7161364, 64}, {7161365, 246}, {7161362, 133}, {7161363, 141}, {7161358, 64}, {7161359, 241}, {7161360, 77}, {7161361, 32}, {12, 108}, {13, 84}, {14, 65}, {15, 84}, {5521748, 22}, {5521749, 194}, {5521750, 182}, {5521751, 62}},
 			},
 		},
 		{
@@ -2962,14 +2954,13 @@ func TestMOVEfromSR(t *testing.T) {
 				RAM: [][2]uint32{{11265756, 64}, {11265757, 210}, {11265758, 240}, {11265759, 139}, {12, 186}, {13, 210}, {14, 9}, {15, 210}, {13765074, 241}, {13765075, 121}, {13765076, 125}, {13765077, 83}},
 			},
 			want: cpuState{
-				D:      [8]uint32{98469251, 473840520, 3879971513, 4166988453, 362607423, 58945386, 688833156, 1167423996},
-				A:      [7]uint32{834608744, 942064560, 427940119, 4134744377, 1186207509, 2401505492, 3586707323},
-				PC:     3134327254,
-				SR:     8973,
-				USP:    11514810,
-				SSP:    8831456,
-				RAM:    [][2]uint32{{11265756, 64}, {11265757, 210}, {11265758, 240}, {11265759, 139}, {8831468, 230}, {8831469, 222}, {8831464, 131}, {8831465, 13}, {8831466, 0}, {8831467, 171}, {8831462, 64}, {8831463, 210}, {8831460, 217}, {8831461, 23}, {8831456, 64}, {8831457, 209}, {8831458, 25}, {8831459, 129}, {12, 186}, {13, 210}, {14, 9}, {15, 210}, {13765074, 241}, {13765075, 121}, {13765076, 125}, {13765077, 83}},
-				Halted: true,
+				D:   [8]uint32{98469251, 473840520, 3879971513, 4166988453, 362607423, 58945386, 688833156, 1167423996},
+				A:   [7]uint32{834608744, 942064560, 427940119, 4134744377, 1186207509, 2401505492, 3586707323},
+				PC:  3134327254,
+				SR:  8973,
+				USP: 11514810,
+				SSP: 8831456,
+				RAM: [][2]uint32{{11265756, 64}, {11265757, 210}, {11265758, 240}, {11265759, 139}, {8831468, 230}, {8831469, 222}, {8831464, 131}, {8831465, 13}, {8831466, 0}, {8831467, 171}, {8831462, 64}, {8831463, 210}, {8831460, 217}, {8831461, 23}, {8831456, 64}, {8831457, 209}, {8831458, 25}, {8831459, 129}, {12, 186}, {13, 210}, {14, 9}, {15, 210}, {13765074, 241}, {13765075, 121}, {13765076, 125}, {13765077, 83}},
 			},
 		},
 		{
@@ -3006,14 +2997,13 @@ func TestMOVEfromSR(t *testing.T) {
 				RAM: [][2]uint32{{6024896, 64}, {6024897, 214}, {6024898, 184}, {6024899, 182}, {12, 105}, {13, 36}, {14, 101}, {15, 194}, {2385346, 118}, {2385347, 132}, {2385348, 34}, {2385349, 59}},
 			},
 			want: cpuState{
-				D:      [8]uint32{941711418, 1083543694, 290919008, 1479329756, 3282090353, 3345666486, 975061923, 1958797117},
-				A:      [7]uint32{3171876673, 367244463, 1683808489, 2356136594, 2978114804, 119352526, 2320065517},
-				PC:     1763993030,
-				SR:     8963,
-				USP:    7838578,
-				SSP:    5290330,
-				RAM:    [][2]uint32{{6024896, 64}, {6024897, 214}, {6024898, 184}, {6024899, 182}, {5290342, 238}, {5290343, 194}, {5290338, 3}, {5290339, 3}, {5290340, 0}, {5290341, 91}, {5290336, 64}, {5290337, 214}, {5290334, 99}, {5290335, 237}, {5290330, 64}, {5290331, 209}, {5290332, 138}, {5290333, 73}, {12, 105}, {13, 36}, {14, 101}, {15, 194}, {2385346, 118}, {2385347, 132}, {2385348, 34}, {2385349, 59}},
-				Halted: true,
+				D:   [8]uint32{941711418, 1083543694, 290919008, 1479329756, 3282090353, 3345666486, 975061923, 1958797117},
+				A:   [7]uint32{3171876673, 367244463, 1683808489, 2356136594, 2978114804, 119352526, 2320065517},
+				PC:  1763993030,
+				SR:  8963,
+				USP: 7838578,
+				SSP: 5290330,
+				RAM: [][2]uint32{{6024896, 64}, {6024897, 214}, {6024898, 184}, {6024899, 182}, {5290342, 238}, {5290343, 194}, {5290338, 3}, {5290339, 3}, {5290340, 0}, {5290341, 91}, {5290336, 64}, {5290337, 214}, {5290334, 99}, {5290335, 237}, {5290330, 64}, {5290331, 209}, {5290332, 138}, {5290333, 73}, {12, 105}, {13, 36}, {14, 101}, {15, 194}, {2385346, 118}, {2385347, 132}, {2385348, 34}, {2385349, 59}},
 			},
 		},
 		{
@@ -6221,3 +6211,459 @@ func TestILLEGAL_LINEF(t *testing.T) {
 		})
 	}
 }
+
+// movecCPU builds a 68010 CPU at 0x1000 running prog in supervisor mode.
+func movecCPU(sr uint16, prog ...uint16) (*CPU, *testBus) {
+	return progCPU(MC68010, Registers{SR: sr, USP: 0x8000}, prog...)
+}
+
+func TestMOVEC(t *testing.T) {
+	t.Run("VBR relocates TRAP vector", func(t *testing.T) {
+		// MOVEQ #$40,D0; LSL.W #8,D0; MOVEC D0,VBR; TRAP #0
+		cpu, bus := movecCPU(0x2700, 0x7040, 0xE148, 0x4E7B, 0x0801, 0x4E40)
+		bus.Write32(0x4000+32*4, 0x3000) // relocated TRAP #0 vector
+		bus.Write32(32*4, 0x5000)        // original table, must not be used
+		fillNOPs(bus, 0x3000, 2)
+		for i := 0; i < 4; i++ {
+			cpu.Step()
+		}
+		reg := cpu.Registers()
+		if reg.VBR != 0x4000 {
+			t.Errorf("VBR = 0x%X, want 0x4000", reg.VBR)
+		}
+		if reg.PC != 0x3000 {
+			t.Errorf("PC = 0x%X, want 0x3000 (vector read from VBR table)", reg.PC)
+		}
+	})
+
+	t.Run("read back control registers", func(t *testing.T) {
+		// MOVEC VBR,A1; MOVEC USP,D2; MOVEC SFC,D3
+		cpu, _ := movecCPU(0x2700, 0x4E7A, 0x9801, 0x4E7A, 0x2800, 0x4E7A, 0x3000)
+		reg := cpu.Registers()
+		reg.VBR = 0x00ABCD00
+		reg.SFC = 5
+		cpu.SetState(reg)
+		if n := cpu.Step(); n != 10 {
+			t.Errorf("MOVEC VBR,A1 cycles = %d, want 10", n)
+		}
+		cpu.Step()
+		cpu.Step()
+		reg = cpu.Registers()
+		if reg.A[1] != 0x00ABCD00 || reg.D[2] != 0x8000 || reg.D[3] != 5 {
+			t.Errorf("A1 = 0x%X D2 = 0x%X D3 = %d, want 0xABCD00 0x8000 5", reg.A[1], reg.D[2], reg.D[3])
+		}
+	})
+
+	t.Run("user mode is a privilege violation", func(t *testing.T) {
+		cpu, bus := movecCPU(0x0000, 0x4E7A, 0x0801)
+		bus.Write32(vecPrivilegeViolation*4, 0x3000)
+		cpu.Step()
+		if pc := cpu.Registers().PC; pc != 0x3000 {
+			t.Errorf("PC = 0x%X, want privilege violation handler 0x3000", pc)
+		}
+	})
+
+	t.Run("unknown control register is illegal", func(t *testing.T) {
+		cpu, bus := movecCPU(0x2700, 0x4E7A, 0x0802)
+		bus.Write32(vecIllegalInstruction*4, 0x3000)
+		cpu.Step()
+		if pc := cpu.Registers().PC; pc != 0x3000 {
+			t.Errorf("PC = 0x%X, want illegal instruction handler 0x3000", pc)
+		}
+	})
+
+	t.Run("illegal on the 68000", func(t *testing.T) {
+		cpu, bus := movecCPU(0x2700, 0x4E7B, 0x0801)
+		cpu.variant = MC68000
+		bus.Write32(vecIllegalInstruction*4, 0x3000)
+		cpu.Step()
+		reg := cpu.Registers()
+		if reg.PC != 0x3000 || reg.VBR != 0 {
+			t.Errorf("PC = 0x%X VBR = 0x%X, want illegal instruction handler and VBR 0", reg.PC, reg.VBR)
+		}
+	})
+}
+
+func TestMOVES(t *testing.T) {
+	// fcCPU loads prog at 0x1000 on a bus that records function codes
+	fcCPU := func(v Variant, sr uint16, prog ...uint16) (*CPU, *fcBus) {
+		bus := &fcBus{reads: map[uint32]uint8{}, write: map[uint32]uint8{}}
+		bus.testBus.Write32(vecIllegalInstruction*4, 0x3000)
+		bus.testBus.Write32(vecPrivilegeViolation*4, 0x3100)
+		cpu := busCPU(bus, &bus.testBus, v, Registers{
+			D: [8]uint32{0: 3, 1: 0x1234, 2: 4}, A: [8]uint32{0x4000, 0x4010},
+			SR: sr, USP: 0x8000,
+		}, prog...)
+		return cpu, bus
+	}
+
+	t.Run("write to DFC space", func(t *testing.T) {
+		// MOVEC D0,DFC; MOVES.W D1,(A0)
+		cpu, bus := fcCPU(MC68010, 0x2700, 0x4E7B, 0x0001, 0x0E50, 0x1800)
+		cpu.Step()
+		cpu.Step()
+		if got := bus.testBus.Read16(0x4000); got != 0x1234 {
+			t.Errorf("memory = 0x%04X, want 0x1234", got)
+		}
+		if got := bus.write[0x4000]; got != 3 {
+			t.Errorf("write FC = %d, want 3", got)
+		}
+		if pc := cpu.PC(); pc != 0x1008 {
+			t.Errorf("PC = 0x%X, want 0x1008", pc)
+		}
+	})
+
+	t.Run("read from SFC space into an address register", func(t *testing.T) {
+		// MOVEC D2,SFC; MOVES.W (A1)+,A2; MOVE.W (A1),D3
+		cpu, bus := fcCPU(MC68010, 0x2700, 0x4E7B, 0x2000, 0x0E59, 0xA000, 0x3611)
+		writeWord(&bus.testBus, 0x4010, 0x8001)
+		cpu.Step()
+		cpu.Step()
+		if got := cpu.A(2); got != 0xFFFF8001 {
+			t.Errorf("A2 = 0x%08X, want 0xFFFF8001", got)
+		}
+		if got := cpu.A(1); got != 0x4012 {
+			t.Errorf("A1 = 0x%X, want 0x4012", got)
+		}
+		if got := bus.reads[0x4010]; got != 4 {
+			t.Errorf("read FC = %d, want 4", got)
+		}
+		if got := bus.reads[0x1004]; got != FCSuperProgram {
+			t.Errorf("opcode fetch FC = %d, want %d", got, FCSuperProgram)
+		}
+		cpu.Step()
+		if got := bus.reads[0x4012]; got != FCSuperData {
+			t.Errorf("following data read FC = %d, want %d", got, FCSuperData)
+		}
+	})
+
+	t.Run("user mode is a privilege violation", func(t *testing.T) {
+		cpu, bus := fcCPU(MC68010, 0x0000, 0x0E50, 0x1800)
+		cpu.Step()
+		if pc := cpu.PC(); pc != 0x3100 {
+			t.Errorf("PC = 0x%X, want privilege violation handler 0x3100", pc)
+		}
+		if got := bus.testBus.Read16(0x4000); got != 0 {
+			t.Errorf("memory = 0x%04X, want it unchanged", got)
+		}
+	})
+
+	t.Run("illegal on the 68000", func(t *testing.T) {
+		cpu, _ := fcCPU(MC68000, 0x2700, 0x0E50, 0x1800)
+		cpu.Step()
+		if pc := cpu.PC(); pc != 0x3000 {
+			t.Errorf("PC = 0x%X, want illegal instruction handler 0x3000", pc)
+		}
+	})
+}
+
+func TestMOVEfromCCR(t *testing.T) {
+	t.Run("to data register", func(t *testing.T) {
+		// MOVE CCR,D0
+		cpu, _ := movecCPU(0x271F, 0x42C0)
+		cpu.SetD(0, 0x12345678)
+		if n := cpu.Step(); n != 4 {
+			t.Errorf("cycles = %d, want 4", n)
+		}
+		if d0 := cpu.D(0); d0 != 0x1234001F {
+			t.Errorf("D0 = 0x%08X, want 0x1234001F", d0)
+		}
+	})
+
+	t.Run("to memory", func(t *testing.T) {
+		// MOVE CCR,(A0) from user mode: not privileged
+		cpu, bus := movecCPU(0x0015, 0x42D0)
+		cpu.SetA(0, 0x4000)
+		bus.Write16(0x4000, 0xFFFF)
+		if n := cpu.Step(); n != 12 {
+			t.Errorf("cycles = %d, want 12", n)
+		}
+		if w := bus.Read16(0x4000); w != 0x0015 {
+			t.Errorf("(A0) = 0x%04X, want 0x0015", w)
+		}
+		if sr := cpu.SR(); sr != 0x0015 {
+			t.Errorf("SR = 0x%04X, want 0x0015 (flags unchanged)", sr)
+		}
+	})
+
+	t.Run("illegal on the 68000", func(t *testing.T) {
+		cpu, bus := movecCPU(0x2700, 0x42C0)
+		cpu.variant = MC68000
+		bus.Write32(vecIllegalInstruction*4, 0x3000)
+		cpu.Step()
+		if pc := cpu.PC(); pc != 0x3000 {
+			t.Errorf("PC = 0x%X, want illegal instruction handler 0x3000", pc)
+		}
+	})
+}
+
+// TestMOVEfromSRPrivilege checks that MOVE from SR runs in user mode on
+// the 68000 but is a privilege violation from the 68010 on, in both the
+// register and memory forms.
+func TestMOVEfromSRPrivilege(t *testing.T) {
+	for _, tt := range []struct {
+		v    Variant
+		op   uint16
+		trap bool
+	}{
+		{MC68000, 0x40C0, false}, // MOVE SR,D0
+		{MC68000, 0x40D0, false}, // MOVE SR,(A0)
+		{MC68010, 0x40C0, true},
+		{MC68010, 0x40D0, true},
+		{MC68020, 0x40C0, true},
+		{MC68020, 0x40D0, true},
+	} {
+		cpu, bus := progCPU(tt.v, Registers{A: [8]uint32{0x4000}, SR: 0x0015, USP: 0x8000}, tt.op)
+		bus.Write32(vecPrivilegeViolation*4, 0x3000)
+		bus.Write16(0x4000, 0xFFFF)
+		cpu.Step()
+		if tt.trap {
+			if pc := cpu.PC(); pc != 0x3000 {
+				t.Errorf("%v %04X: PC = 0x%X, want privilege violation handler 0x3000", tt.v, tt.op, pc)
+			}
+			if d0 := cpu.D(0); d0 != 0 {
+				t.Errorf("%v %04X: D0 = 0x%08X, want 0", tt.v, tt.op, d0)
+			}
+			if w := bus.Read16(0x4000); w != 0xFFFF {
+				t.Errorf("%v %04X: (A0) = 0x%04X, want 0xFFFF", tt.v, tt.op, w)
+			}
+			continue
+		}
+		if pc := cpu.PC(); pc != 0x1002 {
+			t.Errorf("%v %04X: PC = 0x%X, want 0x1002", tt.v, tt.op, pc)
+		}
+		if d0 := cpu.D(0); tt.op == 0x40C0 && d0 != 0x0015 {
+			t.Errorf("%v: D0 = 0x%08X, want 0x00000015", tt.v, d0)
+		}
+		if w := bus.Read16(0x4000); tt.op == 0x40D0 && w != 0x0015 {
+			t.Errorf("%v: (A0) = 0x%04X, want 0x0015", tt.v, w)
+		}
+	}
+
+	t.Run("supervisor on the 68010", func(t *testing.T) {
+		cpu, _ := progCPU(MC68010, Registers{SR: 0x2715}, 0x40C0)
+		if n := cpu.Step(); n != 6 {
+			t.Errorf("cycles = %d, want 6", n)
+		}
+		if d0 := cpu.D(0); d0 != 0x2715 {
+			t.Errorf("D0 = 0x%08X, want 0x00002715", d0)
+		}
+	})
+}
+
+// TestMOVEImmediateToSRCCR checks the immediate forms of MOVE to SR and
+// CCR: the word after the opcode is applied, both take 16 cycles, and an
+// SR write that changes S swaps A7 between the USP and SSP.
+func TestMOVEImmediateToSRCCR(t *testing.T) {
+	tests := []struct {
+		name     string
+		sr       uint16
+		prog     []uint16
+		wantSR   uint16
+		wantA7   uint32
+		wantUSP  uint32
+		wantSSP  uint32
+		wantPC   uint32
+		wantCycs int
+	}{
+		// MOVE #$2700,SR: S unchanged, only the mask and flags move
+		{"SR keeps supervisor", 0x201F, []uint16{0x46FC, 0x2700}, 0x2700, 0x10000, 0x8000, 0x10000, 0x1004, 16},
+		// MOVE #$0700,SR: leaving supervisor mode switches A7 to the USP
+		{"SR to user", 0x2700, []uint16{0x46FC, 0x0700}, 0x0700, 0x8000, 0x8000, 0x10000, 0x1004, 16},
+		// MOVE #$001F,CCR: all five flags set, the system byte kept
+		{"CCR", 0x2700, []uint16{0x44FC, 0x001F}, 0x271F, 0x10000, 0x8000, 0x10000, 0x1004, 16},
+		// MOVE #$FFFF,CCR: bits 5-7 of the CCR do not exist, nor can the
+		// system byte be reached
+		{"CCR masks", 0x2700, []uint16{0x44FC, 0xFFFF}, 0x271F, 0x10000, 0x8000, 0x10000, 0x1004, 16},
+		// MOVE #$FFFF,CCR from user mode: not privileged
+		{"CCR from user", 0x0000, []uint16{0x44FC, 0x00FF}, 0x001F, 0x8000, 0x8000, 0x10000, 0x1004, 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpu, _ := progCPU(MC68000, Registers{SR: tt.sr, USP: 0x8000}, tt.prog...)
+			if n := cpu.Step(); n != tt.wantCycs {
+				t.Errorf("cycles = %d, want %d", n, tt.wantCycs)
+			}
+			reg := cpu.Registers()
+			if reg.SR != tt.wantSR {
+				t.Errorf("SR = 0x%04X, want 0x%04X", reg.SR, tt.wantSR)
+			}
+			if reg.A[7] != tt.wantA7 || reg.USP != tt.wantUSP || reg.SSP != tt.wantSSP {
+				t.Errorf("A7 = 0x%X, USP = 0x%X, SSP = 0x%X; want 0x%X, 0x%X, 0x%X",
+					reg.A[7], reg.USP, reg.SSP, tt.wantA7, tt.wantUSP, tt.wantSSP)
+			}
+			if reg.PC != tt.wantPC {
+				t.Errorf("PC = 0x%X, want 0x%X", reg.PC, tt.wantPC)
+			}
+		})
+	}
+
+	t.Run("SR to user then TRAP", func(t *testing.T) {
+		// MOVE #$0000,SR drops to user mode; the TRAP after it must save
+		// the USP and stack its frame on the SSP
+		cpu, bus := progCPU(MC68000, Registers{SR: 0x2700, USP: 0x8000}, 0x46FC, 0x0000, 0x4E40)
+		bus.Write32(vecTrap0*4, 0x3000)
+		cpu.Step()
+		cpu.Step()
+		reg := cpu.Registers()
+		if reg.A[7] != 0x10000-6 || reg.USP != 0x8000 {
+			t.Errorf("A7 = 0x%X, USP = 0x%X; want 0x%X, 0x8000", reg.A[7], reg.USP, 0x10000-6)
+		}
+	})
+
+	t.Run("SR from user", func(t *testing.T) {
+		// MOVE #$2700,SR from user mode: a privilege violation, and the
+		// immediate must not be applied
+		cpu, bus := progCPU(MC68000, Registers{SR: 0x0000, USP: 0x8000}, 0x46FC, 0x2700)
+		bus.Write32(vecPrivilegeViolation*4, 0x3000)
+		cpu.Step()
+		reg := cpu.Registers()
+		if reg.PC != 0x3000 || reg.SR != 0x2000 {
+			t.Errorf("PC = 0x%X, SR = 0x%04X; want 0x3000, 0x2000", reg.PC, reg.SR)
+		}
+		if got := ReadWord(bus, reg.A[7]); got != 0x0000 {
+			t.Errorf("stacked SR = 0x%04X, want 0x0000", got)
+		}
+	})
+}
+
+func TestExceptionTiming(t *testing.T) {
+	tests := []struct {
+		name   string
+		prog   []uint16
+		sr     uint16
+		d0     uint32
+		vector int
+		cycles int
+	}{
+		{"ILLEGAL", []uint16{0x4AFC}, 0x2700, 0, vecIllegalInstruction, 34},
+		{"Line A", []uint16{0xA000}, 0x2700, 0, vecLineA, 34},
+		{"Line F", []uint16{0xF000}, 0x2700, 0, vecLineF, 34},
+		{"privilege violation", []uint16{0x4E70}, 0x0000, 0, vecPrivilegeViolation, 34},
+		{"TRAP #3", []uint16{0x4E43}, 0x2700, 0, vecTrap0 + 3, 34},
+		{"TRAPV taken", []uint16{0x4E76}, 0x2702, 0, vecTRAPV, 34},
+		{"DIVU #0,D0", []uint16{0x80FC, 0x0000}, 0x2700, 0, vecDivideByZero, 42},
+		{"CHK D1,D0 negative", []uint16{0x4181}, 0x2700, 0x8000, vecCHK, 40},
+		{"CHK #5,D0 above bound", []uint16{0x41BC, 0x0005}, 0x2700, 6, vecCHK, 42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			for i, w := range tt.prog {
+				writeWord(bus, 0x1000+uint32(i*2), w)
+			}
+			bus.Write32(uint32(tt.vector)*4, 0x3000)
+			cpu := &CPU{bus: bus}
+			cpu.SetState(Registers{D: [8]uint32{tt.d0}, PC: 0x1000, SR: tt.sr, SSP: 0x10000, USP: 0x8000})
+			if n := cpu.Step(); n != tt.cycles {
+				t.Errorf("cycles = %d, want %d", n, tt.cycles)
+			}
+			if pc := cpu.PC(); pc != 0x3000 {
+				t.Errorf("PC = 0x%X, want vector %d handler", pc, tt.vector)
+			}
+		})
+	}
+
+	t.Run("interrupt", func(t *testing.T) {
+		cpu, bus := newNOPCPU(4)
+		bus.Write32(uint32(vecAutoVector1+4)*4, 0x3000)
+		cpu.SetSR(0x2000)
+		cpu.RequestInterrupt(5, nil)
+		fillNOPs(bus, 0x3000, 1)
+		if n := cpu.Step(); n != 44+4 {
+			t.Errorf("cycles = %d, want 48 (acknowledge plus the handler's first NOP)", n)
+		}
+	})
+}
+
+// TestPrivilegeViolationFrame runs each privileged instruction in user mode.
+// The violation is detected before any operand is fetched or written, so
+// the stacked PC is the instruction's own address and the stacked SR the
+// user SR, with registers and the stopped state unchanged.
+func TestPrivilegeViolationFrame(t *testing.T) {
+	tests := []struct {
+		name string
+		prog []uint16
+	}{
+		{"STOP", []uint16{0x4E72, 0x2700}},
+		{"RESET", []uint16{0x4E70}},
+		{"RTE", []uint16{0x4E73}},
+		{"MOVE D0,SR", []uint16{0x46C0}},
+		{"MOVE #imm,SR", []uint16{0x46FC, 0x2700}},
+		{"MOVE (A0)+,SR", []uint16{0x46D8}},
+		{"ANDI #imm,SR", []uint16{0x027C, 0x2700}},
+		{"ORI #imm,SR", []uint16{0x007C, 0x2700}},
+		{"EORI #imm,SR", []uint16{0x0A7C, 0x2700}},
+		{"MOVE A0,USP", []uint16{0x4E60}},
+		{"MOVE USP,A0", []uint16{0x4E68}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			for i, w := range tt.prog {
+				writeWord(bus, 0x1000+uint32(i*2), w)
+			}
+			bus.Write32(vecPrivilegeViolation*4, 0x3000)
+			cpu := &CPU{bus: bus}
+			cpu.SetState(Registers{
+				D: [8]uint32{0x2700}, A: [8]uint32{0x4000},
+				PC: 0x1000, SR: 0x0015, SSP: 0x10000, USP: 0x8000,
+			})
+			if n := cpu.Step(); n != 34 {
+				t.Errorf("cycles = %d, want 34", n)
+			}
+			reg := cpu.Registers()
+			if reg.PC != 0x3000 || reg.SR != 0x2015 || cpu.Stopped() {
+				t.Errorf("PC = 0x%X SR = 0x%04X stopped = %v, want handler, supervisor SR 0x2015, running",
+					reg.PC, reg.SR, cpu.Stopped())
+			}
+			if reg.A[0] != 0x4000 || reg.USP != 0x8000 {
+				t.Errorf("A0 = 0x%X USP = 0x%X, want unchanged", reg.A[0], reg.USP)
+			}
+			if reg.A[7] != 0x10000-6 {
+				t.Fatalf("SSP = 0x%X, want 0x%X", reg.A[7], 0x10000-6)
+			}
+			if sr := bus.Read16(0x10000 - 6); sr != 0x0015 {
+				t.Errorf("stacked SR = 0x%04X, want 0x0015", sr)
+			}
+			if pc := bus.Read32(0x10000 - 4); pc != 0x1000 {
+				t.Errorf("stacked PC = 0x%X, want the instruction at 0x1000", pc)
+			}
+		})
+	}
+}
+
+func TestLastException(t *testing.T) {
+	bus := &testBus{}
+	writeWord(bus, 0x1000, 0x4E43) // TRAP #3
+	bus.Write32((vecTrap0+3)*4, 0x3000)
+	bus.Write32((vecAutoVector1+2)*4, 0x4000)
+	fillNOPs(bus, 0x3000, 4)
+	cpu := &CPU{bus: bus}
+	cpu.SetState(Registers{PC: 0x1000, SR: 0x2004, SSP: 0x10000})
+
+	if _, _, _, taken := cpu.LastException(); taken {
+		t.Fatal("taken = true before any exception")
+	}
+
+	cpu.Step()
+	vec, pc, sr, taken := cpu.LastException()
+	if !taken || vec != vecTrap0+3 || pc != 0x1002 || sr != 0x2004 {
+		t.Errorf("TRAP #3: LastException = %d, 0x%X, 0x%04X, %v; want %d, 0x1002, 0x2004, true",
+			vec, pc, sr, taken, vecTrap0+3)
+	}
+
+	// A level 3 auto-vectored interrupt at the handler's first instruction
+	cpu.RequestInterrupt(3, nil)
+	cpu.Step()
+	vec, pc, sr, _ = cpu.LastException()
+	if vec != vecAutoVector1+2 || pc != 0x3000 || sr != 0x2004 {
+		t.Errorf("interrupt: LastException = %d, 0x%X, 0x%04X; want %d, 0x3000, 0x2004",
+			vec, pc, sr, vecAutoVector1+2)
+	}
+
+	cpu.ResetTo(0x10000, 0x1000)
+	if _, _, _, taken := cpu.LastException(); taken {
+		t.Error("taken = true after reset")
+	}
+}
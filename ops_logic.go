@@ -10,6 +10,7 @@ func init() {
 	registerNOT()
 	registerTST()
 	registerTAS()
+	registerCAS()
 	registerShifts()
 }
 
@@ -294,6 +295,8 @@ func registerEOR() {
 	}
 }
 
+// makeEOR builds EOR Dn,<ea>. Timing follows the PRM: to a data register
+// 4 cycles, 8 for long; to memory 8 plus the EA time, 12 for long.
 func makeEOR(dn, mode, reg uint16) opFunc {
 	if mode == 0 {
 		return func(c *CPU) {
@@ -406,6 +409,8 @@ func registerNOT() {
 	}
 }
 
+// makeNOT builds NOT <ea>. Timing follows the PRM: to a data register
+// 4 cycles, 6 for long; to memory 8 plus the EA time, 12 for long.
 func makeNOT(mode, reg uint16) opFunc {
 	if mode == 0 {
 		return func(c *CPU) {
@@ -507,12 +512,90 @@ func makeTAS(mode, reg uint16) opFunc {
 	}
 	addr := makeEAMemAddr(mode, reg)
 	eaBase, _ := eaFetchConst(mode, reg)
+	// Memory form: 10 cycles for the opcode fetch and the read-modify-write
+	// bus cycle, plus the EA calculation ((An) = 14, -(An) = 16,
+	// abs.L = 22).
+	cycles := 10 + eaBase
 	return func(c *CPU) {
 		a := addr(c, sizeByte)
+		c.beginRMW(a)
 		val := c.readBus(sizeByte, a)
 		c.setFlagsLogical(val, sizeByte)
 		c.writeBus(sizeByte, a, val|0x80)
-		c.cycles += 10 + eaBase
+		c.endRMW()
+		c.cycles += cycles
+	}
+}
+
+// beginRMW locks an RMWBus for a read-modify-write sequence at addr.
+func (c *CPU) beginRMW(addr uint32) {
+	if c.rmwBus != nil {
+		c.rmwAddr = addr & 0xFFFFFF
+		c.rmwLocked = true
+		c.rmwBus.BeginRMW(c.rmwAddr)
+	}
+}
+
+// endRMW releases the RMWBus lock taken by beginRMW, if it is held. Group 0
+// exception processing calls it too, for a sequence a fault cut short.
+func (c *CPU) endRMW() {
+	if c.rmwLocked {
+		c.rmwLocked = false
+		c.rmwBus.EndRMW(c.rmwAddr)
+	}
+}
+
+// --- CAS (68020) ---
+
+// casCycles approximates the MC68020 cache case time of CAS, without EA
+// calculation time.
+const casCycles = 16
+
+// registerCAS registers CAS Dc,Du,<ea> for the memory alterable modes.
+// Encoding: 0000 1ss0 11 MMM RRR, ss = 01 byte, 10 word, 11 long
+// Extension word: 0000 000u uu00 0ccc (Du = update, Dc = compare)
+// On the 68000 and 68010 the opcodes are illegal instructions.
+func registerCAS() {
+	for szBits := uint16(1); szBits < 4; szBits++ {
+		sz := [4]size{0, sizeByte, sizeWord, sizeLong}[szBits]
+		for mode := uint16(2); mode < 8; mode++ {
+			for reg := uint16(0); reg < 8; reg++ {
+				if mode == 7 && reg > 1 {
+					continue
+				}
+				opcodeTable[0x08C0|szBits<<9|mode<<3|reg] = makeCAS(sz, mode, reg)
+			}
+		}
+	}
+}
+
+// makeCAS compares Dc with the operand and, if they are equal, writes Du
+// to it; otherwise the operand is loaded into Dc. The read and the
+// conditional write form one locked read-modify-write cycle, bracketed
+// for an RMWBus as for TAS, and released as for TAS if either faults.
+func makeCAS(sz size, mode, reg uint16) opFunc {
+	addr := makeEAMemAddr(mode, reg)
+	return func(c *CPU) {
+		if c.variant < MC68020 {
+			c.exception(vecIllegalInstruction)
+			return
+		}
+		ext := c.fetchPC()
+		dc := ext & 7
+		du := (ext >> 6) & 7
+		a := addr(c, sz)
+		c.beginRMW(a)
+		val := c.readBus(sz, a)
+		cmp := c.reg.D[dc] & sz.Mask()
+		c.setFlagsCmp(cmp, val, val-cmp, sz)
+		if val == cmp {
+			c.writeBus(sz, a, c.reg.D[du])
+		} else {
+			mask := sz.Mask()
+			c.reg.D[dc] = c.reg.D[dc]&^mask | val
+		}
+		c.endRMW()
+		c.cycles += casCycles
 	}
 }
 
@@ -602,6 +685,15 @@ func makeShiftMem(mode, reg uint16) opFunc {
 	}
 }
 
+// shiftedOutLeft returns the last bit shifted out of val by a left shift of
+// count (1-63) bits. Past the operand width only zeros are shifted out.
+func shiftedOutLeft(val, count uint32, sz size) uint32 {
+	if count > sz.Bits() {
+		return 0
+	}
+	return (val >> (sz.Bits() - count)) & 1
+}
+
 // doShift performs the actual shift/rotate operation.
 func doShift(c *CPU, val, count uint32, dir, typ uint16, sz size) uint32 {
 	msb := sz.MSB()
@@ -632,8 +724,7 @@ func doShift(c *CPU, val, count uint32, dir, typ uint16, sz size) uint32 {
 					c.reg.SR |= flagV
 				}
 			}
-			lastOut := (val >> (sz.Bits() - count)) & 1
-			if lastOut != 0 {
+			if shiftedOutLeft(val, count, sz) != 0 {
 				c.reg.SR |= flagC | flagX
 			} else {
 				c.reg.SR &^= flagC | flagX
@@ -662,8 +753,7 @@ func doShift(c *CPU, val, count uint32, dir, typ uint16, sz size) uint32 {
 	case 1: // Logical shift (LS)
 		if dir == 1 { // LSL
 			result = (val << count) & mask
-			lastOut := (val >> (sz.Bits() - count)) & 1
-			if lastOut != 0 {
+			if shiftedOutLeft(val, count, sz) != 0 {
 				c.reg.SR |= flagC | flagX
 			} else {
 				c.reg.SR &^= flagC | flagX
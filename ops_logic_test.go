@@ -1,6 +1,9 @@
 package m68k
 
-import "testing"
+import (
+	"slices"
+	"testing"
+)
 
 func TestAND_B(t *testing.T) {
 	tests := []struct {
@@ -342,14 +345,13 @@ func TestAND_W(t *testing.T) {
 				RAM: [][2]uint32{{8783108, 207}, {8783109, 93}, {8783110, 158}, {8783111, 200}, {12, 19}, {13, 96}, {14, 51}, {15, 72}, {6304584, 231}, {6304585, 177}, {6304586, 152}, {6304587, 148}},
 			},
 			want: cpuState{
-				D:      [8]uint32{470065230, 1049833943, 2713507205, 4027026699, 1011506597, 831025574, 534664186, 3778570442},
-				A:      [7]uint32{3796506931, 4050348046, 2257111017, 4223211533, 289411888, 2630226895, 500238866},
-				PC:     325071692,
-				SR:     10002,
-				USP:    13863642,
-				SSP:    10408582,
-				RAM:    [][2]uint32{{8783108, 207}, {8783109, 93}, {8783110, 158}, {8783111, 200}, {10408594, 5}, {10408595, 6}, {10408590, 167}, {10408591, 18}, {10408592, 0}, {10408593, 134}, {10408588, 207}, {10408589, 93}, {10408586, 19}, {10408587, 205}, {10408582, 207}, {10408583, 85}, {10408584, 156}, {10408585, 198}, {12, 19}, {13, 96}, {14, 51}, {15, 72}, {6304584, 231}, {6304585, 177}, {6304586, 152}, {6304587, 148}},
-				Halted: true,
+				D:   [8]uint32{470065230, 1049833943, 2713507205, 4027026699, 1011506597, 831025574, 534664186, 3778570442},
+				A:   [7]uint32{3796506931, 4050348046, 2257111017, 4223211533, 289411888, 2630226895, 500238866},
+				PC:  325071692,
+				SR:  10002,
+				USP: 13863642,
+				SSP: 10408582,
+				RAM: [][2]uint32{{8783108, 207}, {8783109, 93}, {8783110, 158}, {8783111, 200}, {10408594, 5}, {10408595, 6}, {10408590, 167}, {10408591, 18}, {10408592, 0}, {10408593, 134}, {10408588, 207}, {10408589, 93}, {10408586, 19}, {10408587, 205}, {10408582, 207}, {10408583, 85}, {10408584, 156}, {10408585, 198}, {12, 19}, {13, 96}, {14, 51}, {15, 72}, {6304584, 231}, {6304585, 177}, {6304586, 152}, {6304587, 148}},
 			},
 		},
 		{
@@ -408,14 +410,13 @@ func TestAND_W(t *testing.T) {
 				RAM: [][2]uint32{{3121322, 205}, {3121323, 82}, {3121324, 163}, {3121325, 121}, {12, 93}, {13, 56}, {14, 121}, {15, 14}, {3701006, 62}, {3701007, 249}, {3701008, 97}, {3701009, 164}},
 			},
 			want: cpuState{
-				D:      [8]uint32{965445852, 3059477021, 384626001, 677221587, 4245617613, 3171547404, 3229343101, 1053693608},
-				A:      [7]uint32{3200224482, 2965686966, 3532994587, 1664120884, 3647757899, 4033192082, 2565757293},
-				PC:     1563982098,
-				SR:     8222,
-				USP:    11771192,
-				SSP:    525428,
-				RAM:    [][2]uint32{{3121322, 205}, {3121323, 82}, {3121324, 163}, {3121325, 121}, {525440, 160}, {525441, 172}, {525436, 128}, {525437, 30}, {525438, 0}, {525439, 47}, {525434, 205}, {525435, 82}, {525432, 56}, {525433, 27}, {525428, 205}, {525429, 81}, {525430, 210}, {525431, 149}, {12, 93}, {13, 56}, {14, 121}, {15, 14}, {3701006, 62}, {3701007, 249}, {3701008, 97}, {3701009, 164}},
-				Halted: true,
+				D:   [8]uint32{965445852, 3059477021, 384626001, 677221587, 4245617613, 3171547404, 3229343101, 1053693608},
+				A:   [7]uint32{3200224482, 2965686966, 3532994587, 1664120884, 3647757899, 4033192082, 2565757293},
+				PC:  1563982098,
+				SR:  8222,
+				USP: 11771192,
+				SSP: 525428,
+				RAM: [][2]uint32{{3121322, 205}, {3121323, 82}, {3121324, 163}, {3121325, 121}, {525440, 160}, {525441, 172}, {525436, 128}, {525437, 30}, {525438, 0}, {525439, 47}, {525434, 205}, {525435, 82}, {525432, 56}, {525433, 27}, {525428, 205}, {525429, 81}, {525430, 210}, {525431, 149}, {12, 93}, {13, 56}, {14, 121}, {15, 14}, {3701006, 62}, {3701007, 249}, {3701008, 97}, {3701009, 164}},
 			},
 		},
 		{
@@ -664,14 +665,13 @@ func TestAND_L(t *testing.T) {
 				RAM: [][2]uint32{{478066, 203}, {478067, 156}, {478068, 134}, {478069, 182}, {12, 121}, {13, 20}, {14, 142}, {15, 24}, {1347096, 82}, {1347097, 127}, {1347098, 132}, {1347099, 50}},
 			},
 			want: cpuState{
-				D:      [8]uint32{922200166, 1634731057, 1384255826, 4165284375, 3986156178, 156727417, 2291521453, 2079116780},
-				A:      [7]uint32{1072804380, 2242832998, 1816450483, 1691735783, 3362939947, 2127193698, 2507654371},
-				PC:     2031390236,
-				SR:     8215,
-				USP:    2858110,
-				SSP:    9784700,
-				RAM:    [][2]uint32{{478066, 203}, {478067, 156}, {478068, 134}, {478069, 182}, {9784712, 75}, {9784713, 116}, {9784708, 128}, {9784709, 23}, {9784710, 0}, {9784711, 7}, {9784706, 203}, {9784707, 156}, {9784704, 100}, {9784705, 43}, {9784700, 203}, {9784701, 145}, {9784702, 200}, {9784703, 114}, {12, 121}, {13, 20}, {14, 142}, {15, 24}, {1347096, 82}, {1347097, 127}, {1347098, 132}, {1347099, 50}},
-				Halted: true,
+				D:   [8]uint32{922200166, 1634731057, 1384255826, 4165284375, 3986156178, 156727417, 2291521453, 2079116780},
+				A:   [7]uint32{1072804380, 2242832998, 1816450483, 1691735783, 3362939947, 2127193698, 2507654371},
+				PC:  2031390236,
+				SR:  8215,
+				USP: 2858110,
+				SSP: 9784700,
+				RAM: [][2]uint32{{478066, 203}, {478067, 156}, {478068, 134}, {478069, 182}, {9784712, 75}, {9784713, 116}, {9784708, 128}, {9784709, 23}, {9784710, 0}, {9784711, 7}, {9784706, 203}, {9784707, 156}, {9784704, 100}, {9784705, 43}, {9784700, 203}, {9784701, 145}, {9784702, 200}, {9784703, 114}, {12, 121}, {13, 20}, {14, 142}, {15, 24}, {1347096, 82}, {1347097, 127}, {1347098, 132}, {1347099, 50}},
 			},
 		},
 		{
@@ -708,14 +708,13 @@ func TestAND_L(t *testing.T) {
 				RAM: [][2]uint32{{1109868, 204}, {1109869, 180}, {1109870, 72}, {1109871, 192}, {1109872, 198}, {1109873, 246}, {12, 95}, {13, 208}, {14, 203}, {15, 92}, {13683548, 75}, {13683549, 212}, {13683550, 147}, {13683551, 228}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2189815023, 3204245924, 1312679599, 3635032639, 1081541678, 1542705011, 2947695039, 4080095917},
-				A:      [7]uint32{3469149985, 1260780375, 2095156553, 1370494942, 623144067, 2287133115, 251076975},
-				PC:     1607519072,
-				SR:     9749,
-				USP:    6502936,
-				SSP:    6746568,
-				RAM:    [][2]uint32{{1109868, 204}, {1109869, 180}, {1109870, 72}, {1109871, 192}, {1109872, 198}, {1109873, 246}, {6746580, 239}, {6746581, 110}, {6746576, 134}, {6746577, 21}, {6746578, 0}, {6746579, 16}, {6746574, 204}, {6746575, 180}, {6746572, 112}, {6746573, 113}, {6746568, 204}, {6746569, 177}, {6746570, 101}, {6746571, 155}, {12, 95}, {13, 208}, {14, 203}, {15, 92}, {13683548, 75}, {13683549, 212}, {13683550, 147}, {13683551, 228}},
-				Halted: true,
+				D:   [8]uint32{2189815023, 3204245924, 1312679599, 3635032639, 1081541678, 1542705011, 2947695039, 4080095917},
+				A:   [7]uint32{3469149985, 1260780375, 2095156553, 1370494942, 623144067, 2287133115, 251076975},
+				PC:  1607519072,
+				SR:  9749,
+				USP: 6502936,
+				SSP: 6746568,
+				RAM: [][2]uint32{{1109868, 204}, {1109869, 180}, {1109870, 72}, {1109871, 192}, {1109872, 198}, {1109873, 246}, {6746580, 239}, {6746581, 110}, {6746576, 134}, {6746577, 21}, {6746578, 0}, {6746579, 16}, {6746574, 204}, {6746575, 180}, {6746572, 112}, {6746573, 113}, {6746568, 204}, {6746569, 177}, {6746570, 101}, {6746571, 155}, {12, 95}, {13, 208}, {14, 203}, {15, 92}, {13683548, 75}, {13683549, 212}, {13683550, 147}, {13683551, 228}},
 			},
 		},
 		{
@@ -752,14 +751,13 @@ func TestAND_L(t *testing.T) {
 				RAM: [][2]uint32{{6446186, 193}, {6446187, 145}, {6446188, 231}, {6446189, 6}, {12, 249}, {13, 238}, {14, 203}, {15, 232}, {15649768, 37}, {15649769, 87}, {15649770, 60}, {15649771, 236}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3586022064, 1869811600, 2134224306, 1572504590, 1762925672, 1081431194, 3256730341, 1109104672},
-				A:      [7]uint32{457868840, 117980121, 850650083, 3212723232, 4110374314, 3939455308, 3470628073},
-				PC:     4193176556,
-				SR:     8477,
-				USP:    7845056,
-				SSP:    7062376,
-				RAM:    [][2]uint32{{6446186, 193}, {6446187, 145}, {6446188, 231}, {6446189, 6}, {7062388, 92}, {7062389, 108}, {7062384, 1}, {7062385, 29}, {7062386, 0}, {7062387, 98}, {7062382, 193}, {7062383, 145}, {7062380, 59}, {7062381, 217}, {7062376, 193}, {7062377, 145}, {7062378, 7}, {7062379, 8}, {12, 249}, {13, 238}, {14, 203}, {15, 232}, {15649768, 37}, {15649769, 87}, {15649770, 60}, {15649771, 236}},
-				Halted: true,
+				D:   [8]uint32{3586022064, 1869811600, 2134224306, 1572504590, 1762925672, 1081431194, 3256730341, 1109104672},
+				A:   [7]uint32{457868840, 117980121, 850650083, 3212723232, 4110374314, 3939455308, 3470628073},
+				PC:  4193176556,
+				SR:  8477,
+				USP: 7845056,
+				SSP: 7062376,
+				RAM: [][2]uint32{{6446186, 193}, {6446187, 145}, {6446188, 231}, {6446189, 6}, {7062388, 92}, {7062389, 108}, {7062384, 1}, {7062385, 29}, {7062386, 0}, {7062387, 98}, {7062382, 193}, {7062383, 145}, {7062380, 59}, {7062381, 217}, {7062376, 193}, {7062377, 145}, {7062378, 7}, {7062379, 8}, {12, 249}, {13, 238}, {14, 203}, {15, 232}, {15649768, 37}, {15649769, 87}, {15649770, 60}, {15649771, 236}},
 			},
 		},
 		{
@@ -1352,14 +1350,13 @@ func TestOR_W(t *testing.T) {
 				RAM: [][2]uint32{{10075262, 138}, {10075263, 90}, {10075264, 152}, {10075265, 65}, {12, 220}, {13, 88}, {14, 3}, {15, 188}, {5768124, 190}, {5768125, 40}, {5768126, 102}, {5768127, 168}},
 			},
 			want: cpuState{
-				D:      [8]uint32{83734341, 1934129117, 2831606052, 591412242, 171901009, 2136296524, 3593942737, 560009553},
-				A:      [7]uint32{3100644133, 4154625645, 2792823999, 3464296240, 1692682072, 3386279769, 1817036328},
-				PC:     3696755648,
-				SR:     8981,
-				USP:    11890692,
-				SSP:    10641120,
-				RAM:    [][2]uint32{{10075262, 138}, {10075263, 90}, {10075264, 152}, {10075265, 65}, {10641132, 188}, {10641133, 128}, {10641128, 131}, {10641129, 21}, {10641130, 0}, {10641131, 153}, {10641126, 138}, {10641127, 90}, {10641124, 28}, {10641125, 189}, {10641120, 138}, {10641121, 81}, {10641122, 166}, {10641123, 119}, {12, 220}, {13, 88}, {14, 3}, {15, 188}, {5768124, 190}, {5768125, 40}, {5768126, 102}, {5768127, 168}},
-				Halted: true,
+				D:   [8]uint32{83734341, 1934129117, 2831606052, 591412242, 171901009, 2136296524, 3593942737, 560009553},
+				A:   [7]uint32{3100644133, 4154625645, 2792823999, 3464296240, 1692682072, 3386279769, 1817036328},
+				PC:  3696755648,
+				SR:  8981,
+				USP: 11890692,
+				SSP: 10641120,
+				RAM: [][2]uint32{{10075262, 138}, {10075263, 90}, {10075264, 152}, {10075265, 65}, {10641132, 188}, {10641133, 128}, {10641128, 131}, {10641129, 21}, {10641130, 0}, {10641131, 153}, {10641126, 138}, {10641127, 90}, {10641124, 28}, {10641125, 189}, {10641120, 138}, {10641121, 81}, {10641122, 166}, {10641123, 119}, {12, 220}, {13, 88}, {14, 3}, {15, 188}, {5768124, 190}, {5768125, 40}, {5768126, 102}, {5768127, 168}},
 			},
 		},
 		{
@@ -1718,14 +1715,13 @@ func TestOR_L(t *testing.T) {
 				RAM: [][2]uint32{{281196, 133}, {281197, 163}, {281198, 154}, {281199, 148}, {12, 141}, {13, 98}, {14, 110}, {15, 192}, {6450880, 130}, {6450881, 225}, {6450882, 176}, {6450883, 3}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2287264723, 2698719875, 3393124438, 117069084, 3255219775, 3886171928, 1113168993, 1329161553},
-				A:      [7]uint32{1780419681, 1762454791, 1479354099, 3868954617, 1062544871, 1130166040, 788444851},
-				PC:     2372038340,
-				SR:     9245,
-				USP:    1894772,
-				SSP:    2525680,
-				RAM:    [][2]uint32{{281196, 133}, {281197, 163}, {281198, 154}, {281199, 148}, {2525692, 74}, {2525693, 110}, {2525688, 4}, {2525689, 29}, {2525690, 0}, {2525691, 4}, {2525686, 133}, {2525687, 163}, {2525684, 143}, {2525685, 249}, {2525680, 133}, {2525681, 177}, {2525682, 230}, {2525683, 155}, {12, 141}, {13, 98}, {14, 110}, {15, 192}, {6450880, 130}, {6450881, 225}, {6450882, 176}, {6450883, 3}},
-				Halted: true,
+				D:   [8]uint32{2287264723, 2698719875, 3393124438, 117069084, 3255219775, 3886171928, 1113168993, 1329161553},
+				A:   [7]uint32{1780419681, 1762454791, 1479354099, 3868954617, 1062544871, 1130166040, 788444851},
+				PC:  2372038340,
+				SR:  9245,
+				USP: 1894772,
+				SSP: 2525680,
+				RAM: [][2]uint32{{281196, 133}, {281197, 163}, {281198, 154}, {281199, 148}, {2525692, 74}, {2525693, 110}, {2525688, 4}, {2525689, 29}, {2525690, 0}, {2525691, 4}, {2525686, 133}, {2525687, 163}, {2525684, 143}, {2525685, 249}, {2525680, 133}, {2525681, 177}, {2525682, 230}, {2525683, 155}, {12, 141}, {13, 98}, {14, 110}, {15, 192}, {6450880, 130}, {6450881, 225}, {6450882, 176}, {6450883, 3}},
 			},
 		},
 		{
@@ -2274,14 +2270,13 @@ func TestEOR_W(t *testing.T) {
 				RAM: [][2]uint32{{4034238, 191}, {4034239, 111}, {4034240, 10}, {4034241, 171}, {4034242, 106}, {4034243, 196}, {12, 150}, {13, 124}, {14, 136}, {15, 226}, {8161506, 112}, {8161507, 225}, {8161508, 27}, {8161509, 185}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3609744020, 3243748271, 831628684, 3294858570, 1071185854, 3478528025, 3099394745, 3061239256},
-				A:      [7]uint32{3164450235, 93867188, 28156141, 1247932394, 2342496308, 3240447034, 180936924},
-				PC:     2524743910,
-				SR:     8717,
-				USP:    2715370,
-				SSP:    1668696,
-				RAM:    [][2]uint32{{4034238, 191}, {4034239, 111}, {4034240, 10}, {4034241, 171}, {4034242, 106}, {4034243, 196}, {1668708, 142}, {1668709, 192}, {1668704, 2}, {1668705, 13}, {1668706, 0}, {1668707, 61}, {1668702, 191}, {1668703, 111}, {1668700, 121}, {1668701, 149}, {1668696, 191}, {1668697, 113}, {1668698, 0}, {1668699, 41}, {12, 150}, {13, 124}, {14, 136}, {15, 226}, {8161506, 112}, {8161507, 225}, {8161508, 27}, {8161509, 185}},
-				Halted: true,
+				D:   [8]uint32{3609744020, 3243748271, 831628684, 3294858570, 1071185854, 3478528025, 3099394745, 3061239256},
+				A:   [7]uint32{3164450235, 93867188, 28156141, 1247932394, 2342496308, 3240447034, 180936924},
+				PC:  2524743910,
+				SR:  8717,
+				USP: 2715370,
+				SSP: 1668696,
+				RAM: [][2]uint32{{4034238, 191}, {4034239, 111}, {4034240, 10}, {4034241, 171}, {4034242, 106}, {4034243, 196}, {1668708, 142}, {1668709, 192}, {1668704, 2}, {1668705, 13}, {1668706, 0}, {1668707, 61}, {1668702, 191}, {1668703, 111}, {1668700, 121}, {1668701, 149}, {1668696, 191}, {1668697, 113}, {1668698, 0}, {1668699, 41}, {12, 150}, {13, 124}, {14, 136}, {15, 226}, {8161506, 112}, {8161507, 225}, {8161508, 27}, {8161509, 185}},
 			},
 		},
 		{
@@ -2362,14 +2357,13 @@ func TestEOR_W(t *testing.T) {
 				RAM: [][2]uint32{{6602222, 183}, {6602223, 100}, {6602224, 52}, {6602225, 22}, {12, 49}, {13, 126}, {14, 129}, {15, 82}, {8290642, 141}, {8290643, 95}, {8290644, 229}, {8290645, 62}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1450344888, 3736443276, 1094624571, 679603623, 1955856304, 1424936195, 4289738525, 3526994095},
-				A:      [7]uint32{2830495985, 554052876, 1678986477, 2456165572, 1845575813, 1298528518, 3483365963},
-				PC:     830374230,
-				SR:     8218,
-				USP:    1715314,
-				SSP:    7051790,
-				RAM:    [][2]uint32{{6602222, 183}, {6602223, 100}, {6602224, 52}, {6602225, 22}, {7051802, 189}, {7051803, 242}, {7051798, 128}, {7051799, 26}, {7051800, 0}, {7051801, 100}, {7051796, 183}, {7051797, 100}, {7051794, 64}, {7051795, 133}, {7051790, 183}, {7051791, 113}, {7051792, 110}, {7051793, 1}, {12, 49}, {13, 126}, {14, 129}, {15, 82}, {8290642, 141}, {8290643, 95}, {8290644, 229}, {8290645, 62}},
-				Halted: true,
+				D:   [8]uint32{1450344888, 3736443276, 1094624571, 679603623, 1955856304, 1424936195, 4289738525, 3526994095},
+				A:   [7]uint32{2830495985, 554052876, 1678986477, 2456165572, 1845575813, 1298528518, 3483365963},
+				PC:  830374230,
+				SR:  8218,
+				USP: 1715314,
+				SSP: 7051790,
+				RAM: [][2]uint32{{6602222, 183}, {6602223, 100}, {6602224, 52}, {6602225, 22}, {7051802, 189}, {7051803, 242}, {7051798, 128}, {7051799, 26}, {7051800, 0}, {7051801, 100}, {7051796, 183}, {7051797, 100}, {7051794, 64}, {7051795, 133}, {7051790, 183}, {7051791, 113}, {7051792, 110}, {7051793, 1}, {12, 49}, {13, 126}, {14, 129}, {15, 82}, {8290642, 141}, {8290643, 95}, {8290644, 229}, {8290645, 62}},
 			},
 		},
 		{
@@ -2618,14 +2612,13 @@ func TestEOR_L(t *testing.T) {
 				RAM: [][2]uint32{{10863344, 179}, {10863345, 176}, {10863346, 29}, {10863347, 217}, {10863348, 183}, {10863349, 147}, {12, 73}, {13, 36}, {14, 102}, {15, 130}, {2385538, 23}, {2385539, 176}, {2385540, 24}, {2385541, 157}},
 			},
 			want: cpuState{
-				D:      [8]uint32{659716886, 136122778, 645524876, 3302195869, 423153222, 1063463254, 902374852, 1677817927},
-				A:      [7]uint32{1475122244, 1218986610, 437689720, 3708222612, 4200796830, 2935002597, 1326491355},
-				PC:     1227122310,
-				SR:     8981,
-				USP:    11230308,
-				SSP:    834716,
-				RAM:    [][2]uint32{{10863344, 179}, {10863345, 176}, {10863346, 29}, {10863347, 217}, {10863348, 183}, {10863349, 147}, {834728, 194}, {834729, 242}, {834724, 35}, {834725, 21}, {834726, 0}, {834727, 165}, {834722, 179}, {834723, 176}, {834720, 165}, {834721, 183}, {834716, 179}, {834717, 181}, {834718, 96}, {834719, 9}, {12, 73}, {13, 36}, {14, 102}, {15, 130}, {2385538, 23}, {2385539, 176}, {2385540, 24}, {2385541, 157}},
-				Halted: true,
+				D:   [8]uint32{659716886, 136122778, 645524876, 3302195869, 423153222, 1063463254, 902374852, 1677817927},
+				A:   [7]uint32{1475122244, 1218986610, 437689720, 3708222612, 4200796830, 2935002597, 1326491355},
+				PC:  1227122310,
+				SR:  8981,
+				USP: 11230308,
+				SSP: 834716,
+				RAM: [][2]uint32{{10863344, 179}, {10863345, 176}, {10863346, 29}, {10863347, 217}, {10863348, 183}, {10863349, 147}, {834728, 194}, {834729, 242}, {834724, 35}, {834725, 21}, {834726, 0}, {834727, 165}, {834722, 179}, {834723, 176}, {834720, 165}, {834721, 183}, {834716, 179}, {834717, 181}, {834718, 96}, {834719, 9}, {12, 73}, {13, 36}, {14, 102}, {15, 130}, {2385538, 23}, {2385539, 176}, {2385540, 24}, {2385541, 157}},
 			},
 		},
 		{
@@ -3306,14 +3299,13 @@ func TestNOT_W(t *testing.T) {
 				RAM: [][2]uint32{{11371614, 70}, {11371615, 117}, {11371616, 80}, {11371617, 89}, {11371618, 52}, {11371619, 226}, {12, 45}, {13, 46}, {14, 232}, {15, 8}, {3074056, 214}, {3074057, 196}, {3074058, 19}, {3074059, 208}},
 			},
 			want: cpuState{
-				D:      [8]uint32{850430516, 4145376698, 900433269, 418270675, 3256449363, 3115785313, 148920985, 4145641141},
-				A:      [7]uint32{2479644152, 1368040089, 2605453070, 1525968254, 3243237318, 1281377243, 169148644},
-				PC:     758048780,
-				SR:     8466,
-				USP:    10341918,
-				SSP:    4638966,
-				RAM:    [][2]uint32{{11371614, 70}, {11371615, 117}, {11371616, 80}, {11371617, 89}, {11371618, 52}, {11371619, 226}, {4638978, 132}, {4638979, 96}, {4638974, 33}, {4638975, 18}, {4638976, 0}, {4638977, 173}, {4638972, 70}, {4638973, 117}, {4638970, 96}, {4638971, 149}, {4638966, 70}, {4638967, 117}, {4638968, 76}, {4638969, 96}, {12, 45}, {13, 46}, {14, 232}, {15, 8}, {3074056, 214}, {3074057, 196}, {3074058, 19}, {3074059, 208}},
-				Halted: true,
+				D:   [8]uint32{850430516, 4145376698, 900433269, 418270675, 3256449363, 3115785313, 148920985, 4145641141},
+				A:   [7]uint32{2479644152, 1368040089, 2605453070, 1525968254, 3243237318, 1281377243, 169148644},
+				PC:  758048780,
+				SR:  8466,
+				USP: 10341918,
+				SSP: 4638966,
+				RAM: [][2]uint32{{11371614, 70}, {11371615, 117}, {11371616, 80}, {11371617, 89}, {11371618, 52}, {11371619, 226}, {4638978, 132}, {4638979, 96}, {4638974, 33}, {4638975, 18}, {4638976, 0}, {4638977, 173}, {4638972, 70}, {4638973, 117}, {4638970, 96}, {4638971, 149}, {4638966, 70}, {4638967, 117}, {4638968, 76}, {4638969, 96}, {12, 45}, {13, 46}, {14, 232}, {15, 8}, {3074056, 214}, {3074057, 196}, {3074058, 19}, {3074059, 208}},
 			},
 		},
 		{
@@ -3328,14 +3320,13 @@ func TestNOT_W(t *testing.T) {
 				RAM: [][2]uint32{{2802216, 70}, {2802217, 94}, {2802218, 109}, {2802219, 137}, {12, 196}, {13, 74}, {14, 67}, {15, 164}, {4866980, 118}, {4866981, 46}, {4866982, 193}, {4866983, 76}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3859221000, 4252304118, 2859407010, 137627953, 1357080758, 2916797372, 3063376440, 2392681481},
-				A:      [7]uint32{3944021602, 1263093144, 2846000954, 4078939836, 497226212, 2327033538, 3383353803},
-				PC:     3293201320,
-				SR:     8720,
-				USP:    16699528,
-				SSP:    10259924,
-				RAM:    [][2]uint32{{2802216, 70}, {2802217, 94}, {2802218, 109}, {2802219, 137}, {10259936, 194}, {10259937, 42}, {10259932, 130}, {10259933, 16}, {10259934, 0}, {10259935, 42}, {10259930, 70}, {10259931, 94}, {10259928, 225}, {10259929, 201}, {10259924, 70}, {10259925, 81}, {10259926, 201}, {10259927, 169}, {12, 196}, {13, 74}, {14, 67}, {15, 164}, {4866980, 118}, {4866981, 46}, {4866982, 193}, {4866983, 76}},
-				Halted: true,
+				D:   [8]uint32{3859221000, 4252304118, 2859407010, 137627953, 1357080758, 2916797372, 3063376440, 2392681481},
+				A:   [7]uint32{3944021602, 1263093144, 2846000954, 4078939836, 497226212, 2327033538, 3383353803},
+				PC:  3293201320,
+				SR:  8720,
+				USP: 16699528,
+				SSP: 10259924,
+				RAM: [][2]uint32{{2802216, 70}, {2802217, 94}, {2802218, 109}, {2802219, 137}, {10259936, 194}, {10259937, 42}, {10259932, 130}, {10259933, 16}, {10259934, 0}, {10259935, 42}, {10259930, 70}, {10259931, 94}, {10259928, 225}, {10259929, 201}, {10259924, 70}, {10259925, 81}, {10259926, 201}, {10259927, 169}, {12, 196}, {13, 74}, {14, 67}, {15, 164}, {4866980, 118}, {4866981, 46}, {4866982, 193}, {4866983, 76}},
 			},
 		},
 		{
@@ -3584,14 +3575,13 @@ func TestNOT_L(t *testing.T) {
 				RAM: [][2]uint32{{8577508, 70}, {8577509, 146}, {8577510, 67}, {8577511, 246}, {12, 174}, {13, 64}, {14, 92}, {15, 88}, {4217944, 197}, {4217945, 94}, {4217946, 202}, {4217947, 38}},
 			},
 			want: cpuState{
-				D:      [8]uint32{192301078, 2948703445, 405392927, 3815715779, 3107947918, 1428493185, 3070425277, 1644291674},
-				A:      [7]uint32{1034226651, 2786674089, 3005748791, 2962399385, 315062157, 1162953816, 2843322573},
-				PC:     2923453532,
-				SR:     8985,
-				USP:    14201130,
-				SSP:    7394458,
-				RAM:    [][2]uint32{{8577508, 70}, {8577509, 146}, {8577510, 67}, {8577511, 246}, {7394470, 225}, {7394471, 230}, {7394466, 3}, {7394467, 25}, {7394468, 0}, {7394469, 130}, {7394464, 70}, {7394465, 146}, {7394462, 22}, {7394463, 55}, {7394458, 70}, {7394459, 145}, {7394460, 179}, {7394461, 40}, {12, 174}, {13, 64}, {14, 92}, {15, 88}, {4217944, 197}, {4217945, 94}, {4217946, 202}, {4217947, 38}},
-				Halted: true,
+				D:   [8]uint32{192301078, 2948703445, 405392927, 3815715779, 3107947918, 1428493185, 3070425277, 1644291674},
+				A:   [7]uint32{1034226651, 2786674089, 3005748791, 2962399385, 315062157, 1162953816, 2843322573},
+				PC:  2923453532,
+				SR:  8985,
+				USP: 14201130,
+				SSP: 7394458,
+				RAM: [][2]uint32{{8577508, 70}, {8577509, 146}, {8577510, 67}, {8577511, 246}, {7394470, 225}, {7394471, 230}, {7394466, 3}, {7394467, 25}, {7394468, 0}, {7394469, 130}, {7394464, 70}, {7394465, 146}, {7394462, 22}, {7394463, 55}, {7394458, 70}, {7394459, 145}, {7394460, 179}, {7394461, 40}, {12, 174}, {13, 64}, {14, 92}, {15, 88}, {4217944, 197}, {4217945, 94}, {4217946, 202}, {4217947, 38}},
 			},
 		},
 		{
@@ -3650,14 +3640,13 @@ func TestNOT_L(t *testing.T) {
 				RAM: [][2]uint32{{15739260, 70}, {15739261, 178}, {15739262, 38}, {15739263, 67}, {15739264, 37}, {15739265, 3}, {12, 12}, {13, 0}, {14, 173}, {15, 42}, {44330, 235}, {44331, 85}, {44332, 255}, {44333, 208}},
 			},
 			want: cpuState{
-				D:      [8]uint32{381479299, 451667966, 3313824343, 347120840, 879128643, 2699068407, 481463158, 1582036580},
-				A:      [7]uint32{4217085327, 3405869801, 2102970531, 2941082323, 1480740376, 2902449545, 1465970804},
-				PC:     201370926,
-				SR:     9243,
-				USP:    665046,
-				SSP:    14376722,
-				RAM:    [][2]uint32{{15739260, 70}, {15739261, 178}, {15739262, 38}, {15739263, 67}, {15739264, 37}, {15739265, 3}, {14376734, 41}, {14376735, 126}, {14376730, 164}, {14376731, 27}, {14376732, 0}, {14376733, 240}, {14376728, 70}, {14376729, 178}, {14376726, 187}, {14376727, 61}, {14376722, 70}, {14376723, 181}, {14376724, 125}, {14376725, 88}, {12, 12}, {13, 0}, {14, 173}, {15, 42}, {44330, 235}, {44331, 85}, {44332, 255}, {44333, 208}},
-				Halted: true,
+				D:   [8]uint32{381479299, 451667966, 3313824343, 347120840, 879128643, 2699068407, 481463158, 1582036580},
+				A:   [7]uint32{4217085327, 3405869801, 2102970531, 2941082323, 1480740376, 2902449545, 1465970804},
+				PC:  201370926,
+				SR:  9243,
+				USP: 665046,
+				SSP: 14376722,
+				RAM: [][2]uint32{{15739260, 70}, {15739261, 178}, {15739262, 38}, {15739263, 67}, {15739264, 37}, {15739265, 3}, {14376734, 41}, {14376735, 126}, {14376730, 164}, {14376731, 27}, {14376732, 0}, {14376733, 240}, {14376728, 70}, {14376729, 178}, {14376726, 187}, {14376727, 61}, {14376722, 70}, {14376723, 181}, {14376724, 125}, {14376725, 88}, {12, 12}, {13, 0}, {14, 173}, {15, 42}, {44330, 235}, {44331, 85}, {44332, 255}, {44333, 208}},
 			},
 		},
 		{
@@ -4550,14 +4539,13 @@ func TestTST_L(t *testing.T) {
 				RAM: [][2]uint32{{1903150, 74}, {1903151, 156}, {1903152, 77}, {1903153, 206}, {12, 204}, {13, 22}, {14, 0}, {15, 78}, {1441870, 144}, {1441871, 64}, {1441872, 97}, {1441873, 103}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1541533400, 366337260, 495035572, 1339442496, 2074111443, 3237308231, 134789519, 700881213},
-				A:      [7]uint32{1170022116, 3693685539, 632105546, 2232408333, 2299379293, 2697427698, 1404834479},
-				PC:     3423993938,
-				SR:     9738,
-				USP:    51168,
-				SSP:    7769476,
-				RAM:    [][2]uint32{{1903150, 74}, {1903151, 156}, {1903152, 77}, {1903153, 206}, {7769488, 10}, {7769489, 48}, {7769484, 134}, {7769485, 10}, {7769486, 0}, {7769487, 29}, {7769482, 74}, {7769483, 156}, {7769480, 190}, {7769481, 93}, {7769476, 74}, {7769477, 145}, {7769478, 137}, {7769479, 13}, {12, 204}, {13, 22}, {14, 0}, {15, 78}, {1441870, 144}, {1441871, 64}, {1441872, 97}, {1441873, 103}},
-				Halted: true,
+				D:   [8]uint32{1541533400, 366337260, 495035572, 1339442496, 2074111443, 3237308231, 134789519, 700881213},
+				A:   [7]uint32{1170022116, 3693685539, 632105546, 2232408333, 2299379293, 2697427698, 1404834479},
+				PC:  3423993938,
+				SR:  9738,
+				USP: 51168,
+				SSP: 7769476,
+				RAM: [][2]uint32{{1903150, 74}, {1903151, 156}, {1903152, 77}, {1903153, 206}, {7769488, 10}, {7769489, 48}, {7769484, 134}, {7769485, 10}, {7769486, 0}, {7769487, 29}, {7769482, 74}, {7769483, 156}, {7769480, 190}, {7769481, 93}, {7769476, 74}, {7769477, 145}, {7769478, 137}, {7769479, 13}, {12, 204}, {13, 22}, {14, 0}, {15, 78}, {1441870, 144}, {1441871, 64}, {1441872, 97}, {1441873, 103}},
 			},
 		},
 		{
@@ -4572,14 +4560,13 @@ func TestTST_L(t *testing.T) {
 				RAM: [][2]uint32{{11603590, 74}, {11603591, 178}, {11603592, 160}, {11603593, 131}, {11603594, 250}, {11603595, 30}, {12, 249}, {13, 92}, {14, 157}, {15, 6}, {6069510, 160}, {6069511, 60}, {6069512, 47}, {6069513, 98}},
 			},
 			want: cpuState{
-				D:      [8]uint32{4028359215, 910667542, 1186374698, 1999638695, 3796833560, 3579536216, 43818076, 3380038896},
-				A:      [7]uint32{3164226988, 747015698, 2050837628, 4238160995, 497279810, 808869613, 414893530},
-				PC:     4183596298,
-				SR:     8714,
-				USP:    16550424,
-				SSP:    2370554,
-				RAM:    [][2]uint32{{11603590, 74}, {11603591, 178}, {11603592, 160}, {11603593, 131}, {11603594, 250}, {11603595, 30}, {2370566, 14}, {2370567, 136}, {2370562, 162}, {2370563, 10}, {2370564, 0}, {2370565, 177}, {2370560, 74}, {2370561, 178}, {2370558, 152}, {2370559, 123}, {2370554, 74}, {2370555, 181}, {2370556, 122}, {2370557, 61}, {12, 249}, {13, 92}, {14, 157}, {15, 6}, {6069510, 160}, {6069511, 60}, {6069512, 47}, {6069513, 98}},
-				Halted: true,
+				D:   [8]uint32{4028359215, 910667542, 1186374698, 1999638695, 3796833560, 3579536216, 43818076, 3380038896},
+				A:   [7]uint32{3164226988, 747015698, 2050837628, 4238160995, 497279810, 808869613, 414893530},
+				PC:  4183596298,
+				SR:  8714,
+				USP: 16550424,
+				SSP: 2370554,
+				RAM: [][2]uint32{{11603590, 74}, {11603591, 178}, {11603592, 160}, {11603593, 131}, {11603594, 250}, {11603595, 30}, {2370566, 14}, {2370567, 136}, {2370562, 162}, {2370563, 10}, {2370564, 0}, {2370565, 177}, {2370560, 74}, {2370561, 178}, {2370558, 152}, {2370559, 123}, {2370554, 74}, {2370555, 181}, {2370556, 122}, {2370557, 61}, {12, 249}, {13, 92}, {14, 157}, {15, 6}, {6069510, 160}, {6069511, 60}, {6069512, 47}, {6069513, 98}},
 			},
 		},
 		{
@@ -4616,14 +4603,13 @@ func TestTST_L(t *testing.T) {
 				RAM: [][2]uint32{{6865084, 74}, {6865085, 147}, {6865086, 19}, {6865087, 194}, {12, 192}, {13, 70}, {14, 151}, {15, 84}, {4626260, 124}, {4626261, 221}, {4626262, 183}, {4626263, 94}},
 			},
 			want: cpuState{
-				D:      [8]uint32{759308172, 1816863587, 2523437302, 2884034688, 2610667906, 1277506688, 3816107819, 3759890551},
-				A:      [7]uint32{4271701043, 1575272675, 4266023303, 2323563765, 1297297449, 3754936381, 3114992177},
-				PC:     3225851736,
-				SR:     9247,
-				USP:    10892300,
-				SSP:    12625072,
-				RAM:    [][2]uint32{{6865084, 74}, {6865085, 147}, {6865086, 19}, {6865087, 194}, {12625084, 192}, {12625085, 190}, {12625080, 132}, {12625081, 31}, {12625082, 0}, {12625083, 104}, {12625078, 74}, {12625079, 147}, {12625076, 196}, {12625077, 245}, {12625072, 74}, {12625073, 145}, {12625074, 138}, {12625075, 126}, {12, 192}, {13, 70}, {14, 151}, {15, 84}, {4626260, 124}, {4626261, 221}, {4626262, 183}, {4626263, 94}},
-				Halted: true,
+				D:   [8]uint32{759308172, 1816863587, 2523437302, 2884034688, 2610667906, 1277506688, 3816107819, 3759890551},
+				A:   [7]uint32{4271701043, 1575272675, 4266023303, 2323563765, 1297297449, 3754936381, 3114992177},
+				PC:  3225851736,
+				SR:  9247,
+				USP: 10892300,
+				SSP: 12625072,
+				RAM: [][2]uint32{{6865084, 74}, {6865085, 147}, {6865086, 19}, {6865087, 194}, {12625084, 192}, {12625085, 190}, {12625080, 132}, {12625081, 31}, {12625082, 0}, {12625083, 104}, {12625078, 74}, {12625079, 147}, {12625076, 196}, {12625077, 245}, {12625072, 74}, {12625073, 145}, {12625074, 138}, {12625075, 126}, {12, 192}, {13, 70}, {14, 151}, {15, 84}, {4626260, 124}, {4626261, 221}, {4626262, 183}, {4626263, 94}},
 			},
 		},
 		{
@@ -12322,14 +12308,13 @@ func TestROXR_W(t *testing.T) {
 				RAM: [][2]uint32{{15975636, 228}, {15975637, 228}, {15975638, 140}, {15975639, 149}, {12, 147}, {13, 90}, {14, 43}, {15, 172}, {5909420, 134}, {5909421, 103}, {5909422, 34}, {5909423, 243}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2947562698, 187237743, 3648254640, 919608884, 676476048, 3993767519, 4220853451, 2535512199},
-				A:      [7]uint32{762023324, 3083915446, 3200088376, 364639124, 2437462143, 1723522478, 785132740},
-				PC:     2472160176,
-				SR:     9747,
-				USP:    12443036,
-				SSP:    8105990,
-				RAM:    [][2]uint32{{15975636, 228}, {15975637, 228}, {15975638, 140}, {15975639, 149}, {8106002, 196}, {8106003, 216}, {8105998, 6}, {8105999, 19}, {8106000, 0}, {8106001, 243}, {8105996, 228}, {8105997, 228}, {8105994, 184}, {8105995, 127}, {8105990, 228}, {8105991, 241}, {8105992, 145}, {8105993, 72}, {12, 147}, {13, 90}, {14, 43}, {15, 172}, {5909420, 134}, {5909421, 103}, {5909422, 34}, {5909423, 243}},
-				Halted: true,
+				D:   [8]uint32{2947562698, 187237743, 3648254640, 919608884, 676476048, 3993767519, 4220853451, 2535512199},
+				A:   [7]uint32{762023324, 3083915446, 3200088376, 364639124, 2437462143, 1723522478, 785132740},
+				PC:  2472160176,
+				SR:  9747,
+				USP: 12443036,
+				SSP: 8105990,
+				RAM: [][2]uint32{{15975636, 228}, {15975637, 228}, {15975638, 140}, {15975639, 149}, {8106002, 196}, {8106003, 216}, {8105998, 6}, {8105999, 19}, {8106000, 0}, {8106001, 243}, {8105996, 228}, {8105997, 228}, {8105994, 184}, {8105995, 127}, {8105990, 228}, {8105991, 241}, {8105992, 145}, {8105993, 72}, {12, 147}, {13, 90}, {14, 43}, {15, 172}, {5909420, 134}, {5909421, 103}, {5909422, 34}, {5909423, 243}},
 			},
 		},
 		{
@@ -12344,14 +12329,13 @@ func TestROXR_W(t *testing.T) {
 				RAM: [][2]uint32{{14017336, 228}, {14017337, 247}, {14017338, 42}, {14017339, 55}, {14017340, 149}, {14017341, 49}, {12, 101}, {13, 162}, {14, 57}, {15, 64}, {10631488, 66}, {10631489, 61}, {10631490, 170}, {10631491, 20}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2221296205, 3249843068, 1099897860, 1255220298, 1013862620, 2157686022, 1556306772, 1186902100},
-				A:      [7]uint32{1866948341, 3244963754, 889163861, 1299200919, 375758170, 4292992949, 3641432100},
-				PC:     1705130308,
-				SR:     9234,
-				USP:    1060988,
-				SSP:    9879906,
-				RAM:    [][2]uint32{{14017336, 228}, {14017337, 247}, {14017338, 42}, {14017339, 55}, {14017340, 149}, {14017341, 49}, {9879918, 227}, {9879919, 58}, {9879914, 36}, {9879915, 18}, {9879916, 0}, {9879917, 213}, {9879912, 228}, {9879913, 247}, {9879910, 221}, {9879911, 171}, {9879906, 228}, {9879907, 245}, {9879908, 66}, {9879909, 37}, {12, 101}, {13, 162}, {14, 57}, {15, 64}, {10631488, 66}, {10631489, 61}, {10631490, 170}, {10631491, 20}},
-				Halted: true,
+				D:   [8]uint32{2221296205, 3249843068, 1099897860, 1255220298, 1013862620, 2157686022, 1556306772, 1186902100},
+				A:   [7]uint32{1866948341, 3244963754, 889163861, 1299200919, 375758170, 4292992949, 3641432100},
+				PC:  1705130308,
+				SR:  9234,
+				USP: 1060988,
+				SSP: 9879906,
+				RAM: [][2]uint32{{14017336, 228}, {14017337, 247}, {14017338, 42}, {14017339, 55}, {14017340, 149}, {14017341, 49}, {9879918, 227}, {9879919, 58}, {9879914, 36}, {9879915, 18}, {9879916, 0}, {9879917, 213}, {9879912, 228}, {9879913, 247}, {9879910, 221}, {9879911, 171}, {9879906, 228}, {9879907, 245}, {9879908, 66}, {9879909, 37}, {12, 101}, {13, 162}, {14, 57}, {15, 64}, {10631488, 66}, {10631489, 61}, {10631490, 170}, {10631491, 20}},
 			},
 		},
 		{
@@ -12881,3 +12865,246 @@ func TestROXR_L(t *testing.T) {
 		})
 	}
 }
+
+func TestCAS(t *testing.T) {
+	tests := []struct {
+		name    string
+		mem     uint16
+		d1      uint32
+		wantMem uint16
+		wantD1  uint32
+		wantCCR uint16
+	}{
+		// CAS.W D1,D2,(A0) with D2 = $BEEF
+		{name: "match writes Du", mem: 0x1234, d1: 0xFFFF1234, wantMem: 0xBEEF, wantD1: 0xFFFF1234, wantCCR: flagZ},
+		{name: "mismatch loads Dc", mem: 0x1234, d1: 0xFFFF0100, wantMem: 0x1234, wantD1: 0xFFFF1234},
+		{name: "mismatch borrow", mem: 0x0001, d1: 0x0002, wantMem: 0x0001, wantD1: 0x0001, wantCCR: flagN | flagC},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			writeWord(bus, 0x1000, 0x0CD0) // CAS.W D1,D2,(A0)
+			writeWord(bus, 0x1002, 0x0081)
+			writeWord(bus, 0x2000, tt.mem)
+			cpu := &CPU{bus: bus, variant: MC68020}
+			cpu.SetState(Registers{
+				D: [8]uint32{1: tt.d1, 2: 0xBEEF}, A: [8]uint32{0x2000},
+				PC: 0x1000, SR: 0x2710, SSP: 0x10000,
+			})
+			cpu.Step()
+			if got := bus.Read16(0x2000); got != tt.wantMem {
+				t.Errorf("memory = 0x%04X, want 0x%04X", got, tt.wantMem)
+			}
+			if got := cpu.D(1); got != tt.wantD1 {
+				t.Errorf("D1 = 0x%08X, want 0x%08X", got, tt.wantD1)
+			}
+			// X is left alone
+			if got := cpu.SR() & 0x1F; got != tt.wantCCR|flagX {
+				t.Errorf("CCR = 0x%02X, want 0x%02X", got, tt.wantCCR|flagX)
+			}
+		})
+	}
+
+	t.Run("locked bus", func(t *testing.T) {
+		for _, match := range []bool{true, false} {
+			bus := &rmwBus{}
+			writeWord(&bus.testBus, 0x1000, 0x0AD0) // CAS.B D0,D1,(A0)
+			writeWord(&bus.testBus, 0x1002, 0x0040)
+			bus.mem[0x2000] = 0x5A
+			d0 := uint32(0x5A)
+			want := []string{"begin", "read", "write", "end"}
+			if !match {
+				d0 = 0
+				want = []string{"begin", "read", "end"}
+			}
+			cpu := &CPU{bus: bus, variant: MC68020}
+			cpu.SetState(Registers{
+				D: [8]uint32{d0, 0x77}, A: [8]uint32{0x2000},
+				PC: 0x1000, SR: 0x2700, SSP: 0x10000,
+			})
+			bus.log = nil
+			cpu.Step()
+			if len(bus.log) != len(want) {
+				t.Fatalf("match=%v: bus activity = %v, want %v", match, bus.log, want)
+			}
+			for i := range want {
+				if bus.log[i] != want[i] {
+					t.Fatalf("match=%v: bus activity = %v, want %v", match, bus.log, want)
+				}
+			}
+		}
+	})
+
+	t.Run("bus error releases the lock", func(t *testing.T) {
+		for _, tt := range []struct {
+			fault string
+			want  []string
+		}{
+			{"read", []string{"begin", "read", "end", "stack"}},
+			{"write", []string{"begin", "read", "write", "end", "stack"}},
+		} {
+			// CAS.B D0,D1,(A0)
+			bus := &rmwBus{fault: tt.fault}
+			bus.testBus.Write32(vecBusError*4, 0x3000)
+			cpu := busCPU(bus, &bus.testBus, MC68020, Registers{
+				D: [8]uint32{0, 0x77}, A: [8]uint32{0x2000}, SR: 0x2700,
+			}, 0x0AD0, 0x0040)
+			bus.cpu = cpu
+			cpu.Step()
+			if pc := cpu.PC(); pc != 0x3000 {
+				t.Errorf("%s fault: PC = 0x%X, want bus error handler 0x3000", tt.fault, pc)
+			}
+			if log := slices.Compact(bus.log); !slices.Equal(log, tt.want) {
+				t.Errorf("%s fault: bus activity = %v, want %v", tt.fault, log, tt.want)
+			}
+			if cpu.rmwLocked {
+				t.Errorf("%s fault: RMW lock still held", tt.fault)
+			}
+		}
+	})
+
+	t.Run("illegal before the 68020", func(t *testing.T) {
+		bus := &testBus{}
+		writeWord(bus, 0x1000, 0x0CD0)
+		writeWord(bus, 0x1002, 0x0081)
+		bus.Write32(vecIllegalInstruction*4, 0x3000)
+		cpu := &CPU{bus: bus, variant: MC68010}
+		cpu.SetState(Registers{A: [8]uint32{0x2000}, PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+		cpu.Step()
+		if pc := cpu.PC(); pc != 0x3000 {
+			t.Errorf("PC = 0x%X, want the illegal instruction handler", pc)
+		}
+	})
+}
+
+// TestShiftCountZero covers a register shift count of 0 (here D1 = 64,
+// taken modulo 64): the operand is unchanged, X is unaffected, V is
+// cleared, and C is cleared except for ROXL/ROXR, where it is set to X.
+func TestShiftCountZero(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		op   uint16
+		rox  bool
+	}{
+		{"ASR.W D1,D0", 0xE260, false},
+		{"ASL.W D1,D0", 0xE360, false},
+		{"LSR.W D1,D0", 0xE268, false},
+		{"LSL.W D1,D0", 0xE368, false},
+		{"ROXR.W D1,D0", 0xE270, true},
+		{"ROXL.W D1,D0", 0xE370, true},
+		{"ROR.W D1,D0", 0xE278, false},
+		{"ROL.W D1,D0", 0xE378, false},
+	} {
+		for _, x := range []bool{false, true} {
+			bus := &testBus{}
+			writeWord(bus, 0x1000, tt.op)
+			sr := uint16(0x2700 | flagV | flagC)
+			if x {
+				sr |= flagX
+			}
+			cpu := &CPU{bus: bus}
+			cpu.SetState(Registers{D: [8]uint32{0x12348000, 64}, PC: 0x1000, SR: sr, SSP: 0x10000})
+			if n := cpu.Step(); n != 6 {
+				t.Errorf("%s: cycles = %d, want 6", tt.name, n)
+			}
+			if d0 := cpu.D(0); d0 != 0x12348000 {
+				t.Errorf("%s: D0 = 0x%08X, want unchanged", tt.name, d0)
+			}
+			want := uint16(flagN)
+			if x {
+				want |= flagX
+				if tt.rox {
+					want |= flagC
+				}
+			}
+			if got := cpu.SR() & 0x1F; got != want {
+				t.Errorf("%s X=%v: CCR = 0x%02X, want 0x%02X", tt.name, x, got, want)
+			}
+		}
+	}
+}
+
+// TestASLLongCount covers ASL by register counts at and beyond the operand
+// width: the result is 0, C and X hold the last bit shifted out (0 once the
+// count exceeds the width), and V is set if the sign ever changed.
+func TestASLLongCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      uint16
+		d0      uint32
+		count   uint32
+		want    uint32
+		wantCCR uint16
+	}{
+		{"ASL.B by 8", 0xE320, 0x01, 8, 0, flagX | flagZ | flagV | flagC},
+		{"ASL.B by 20", 0xE320, 0x01, 20, 0, flagZ | flagV},
+		{"ASL.B by 20 of zero", 0xE320, 0x00, 20, 0, flagZ},
+		{"ASL.W by 16", 0xE360, 0xFFFF, 16, 0, flagX | flagZ | flagV | flagC},
+		{"ASL.W by 20", 0xE360, 0xFFFF, 20, 0, flagZ | flagV},
+		{"ASL.L by 32", 0xE3A0, 0x00000001, 32, 0, flagX | flagZ | flagV | flagC},
+		{"ASL.L by 40", 0xE3A0, 0x40000000, 40, 0, flagZ | flagV},
+		{"LSL.L by 33", 0xE3A8, 0xFFFFFFFF, 33, 0, flagZ},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			writeWord(bus, 0x1000, tt.op)
+			cpu := &CPU{bus: bus}
+			cpu.SetState(Registers{D: [8]uint32{tt.d0, tt.count}, PC: 0x1000, SR: 0x2700 | flagX | flagC, SSP: 0x10000})
+			cpu.Step()
+			if got := cpu.D(0); got != tt.want {
+				t.Errorf("D0 = 0x%08X, want 0x%08X", got, tt.want)
+			}
+			if got := cpu.SR() & 0x1F; got != tt.wantCCR {
+				t.Errorf("CCR = 0x%02X, want 0x%02X", got, tt.wantCCR)
+			}
+		})
+	}
+}
+
+// TestEORNOTTiming locks the EOR and NOT timing to the PRM tables: to a
+// data register 4 cycles for byte and word, and 8 (EOR) or 6 (NOT) for
+// long; to memory 8 or 12 plus the EA time.
+func TestEORNOTTiming(t *testing.T) {
+	// EA fields and extension words: D1, (A0), (A0)+, -(A0), d16(A0),
+	// d8(A0,D2), abs.W, abs.L
+	targets := []struct {
+		name string
+		ea   uint16
+		ext  []uint16
+	}{
+		{"D1", 0x01, nil},
+		{"(A0)", 0x10, nil},
+		{"(A0)+", 0x18, nil},
+		{"-(A0)", 0x20, nil},
+		{"d16(A0)", 0x28, []uint16{0x0010}},
+		{"d8(A0,D2)", 0x30, []uint16{0x2004}},
+		{"abs.W", 0x38, []uint16{0x3020}},
+		{"abs.L", 0x39, []uint16{0x0000, 0x3030}},
+	}
+	tests := []struct {
+		name string
+		op   uint16    // size bits clear, EA clear
+		want [8][2]int // per target: byte/word, long
+	}{
+		{"EOR D0,", 0xB100, [8][2]int{{4, 8}, {12, 20}, {12, 20}, {14, 22}, {16, 24}, {18, 26}, {16, 24}, {20, 28}}},
+		{"NOT ", 0x4600, [8][2]int{{4, 6}, {12, 20}, {12, 20}, {14, 22}, {16, 24}, {18, 26}, {16, 24}, {20, 28}}},
+	}
+	for _, tt := range tests {
+		for i, dst := range targets {
+			for szBits := uint16(0); szBits < 3; szBits++ {
+				want := tt.want[i][szBits/2]
+				bus := &testBus{}
+				writeWord(bus, 0x1000, tt.op|szBits<<6|dst.ea)
+				for k, w := range dst.ext {
+					writeWord(bus, 0x1002+uint32(k*2), w)
+				}
+				cpu := &CPU{bus: bus}
+				cpu.SetState(Registers{D: [8]uint32{0xFFFF}, A: [8]uint32{0x3000}, PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+				if n := cpu.Step(); n != want {
+					t.Errorf("%s%s size %d: cycles = %d, want %d", tt.name, dst.name, szBits, n, want)
+				}
+			}
+		}
+	}
+}
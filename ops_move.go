@@ -36,8 +36,12 @@ func registerMOVE() {
 						if srcMode == 7 && srcReg > 4 {
 							continue
 						}
+						// An is not a valid byte source
+						if srcMode == 1 && szBits == 0x1000 {
+							continue
+						}
 						opcode := szBits | dstReg<<9 | dstMode<<6 | srcMode<<3 | srcReg
-						opcodeTable[opcode] = makeMOVE(srcMode, srcReg, dstMode, dstReg)
+						opcodeTable[opcode] = makeMOVE(moveSizeMap[szBits>>12], srcMode, srcReg, dstMode, dstReg)
 					}
 				}
 			}
@@ -45,35 +49,31 @@ func registerMOVE() {
 	}
 }
 
-func makeMOVE(srcMode, srcReg, dstMode, dstReg uint16) opFunc {
+func makeMOVE(sz size, srcMode, srcReg, dstMode, dstReg uint16) opFunc {
 	read := makeEARead(srcMode, srcReg)
 	srcBase, srcLong := eaFetchConst(srcMode, srcReg)
 	dstBase, dstLong := eaWriteConst(dstMode, dstReg)
+	cycles := 4 + srcBase + dstBase
+	if sz == sizeLong {
+		cycles += srcLong + dstLong
+	}
 
 	if dstMode == 0 {
+		mask := sz.Mask()
 		return func(c *CPU) {
-			sz := moveSizeMap[(c.ir>>12)&3]
 			val := read(c, sz)
-			mask := sz.Mask()
 			c.reg.D[dstReg] = (c.reg.D[dstReg] & ^mask) | (val & mask)
 			c.setFlagsLogical(val, sz)
-			c.cycles += 4 + srcBase + dstBase
-			if sz == sizeLong {
-				c.cycles += srcLong + dstLong
-			}
+			c.cycles += cycles
 		}
 	}
 	dstAddr := makeEAMemAddr(dstMode, dstReg)
 	return func(c *CPU) {
-		sz := moveSizeMap[(c.ir>>12)&3]
 		val := read(c, sz)
 		a := dstAddr(c, sz)
 		c.writeBus(sz, a, val)
 		c.setFlagsLogical(val, sz)
-		c.cycles += 4 + srcBase + dstBase
-		if sz == sizeLong {
-			c.cycles += srcLong + dstLong
-		}
+		c.cycles += cycles
 	}
 }
 
@@ -92,29 +92,29 @@ func registerMOVEA() {
 						continue
 					}
 					opcode := szBits | dstReg<<9 | 1<<6 | srcMode<<3 | srcReg
-					opcodeTable[opcode] = makeMOVEA(dstReg, srcMode, srcReg)
+					opcodeTable[opcode] = makeMOVEA(moveSizeMap[szBits>>12], dstReg, srcMode, srcReg)
 				}
 			}
 		}
 	}
 }
 
-func makeMOVEA(an, srcMode, srcReg uint16) opFunc {
+func makeMOVEA(sz size, an, srcMode, srcReg uint16) opFunc {
 	read := makeEARead(srcMode, srcReg)
 	eaBase, eaLong := eaFetchConst(srcMode, srcReg)
-	return func(c *CPU) {
-		sz := moveSizeMap[(c.ir>>12)&3]
-		val := read(c, sz)
-		if sz == sizeWord {
-			val = uint32(int32(int16(val)))
-		}
-		c.reg.A[an] = val
-		// MOVEA does not affect condition codes
-		c.cycles += 4 + eaBase
-		if sz == sizeLong {
-			c.cycles += eaLong
+	cycles := 4 + eaBase
+	if sz == sizeLong {
+		cycles += eaLong
+		return func(c *CPU) {
+			c.reg.A[an] = read(c, sz)
+			// MOVEA does not affect condition codes
+			c.cycles += cycles
 		}
 	}
+	return func(c *CPU) {
+		c.reg.A[an] = uint32(int32(int16(read(c, sz))))
+		c.cycles += cycles
+	}
 }
 
 // registerMOVEQ registers MOVEQ #imm8,Dn.
@@ -130,7 +130,7 @@ func registerMOVEQ() {
 
 func opMOVEQ(c *CPU) {
 	dn := (c.ir >> 9) & 7
-	data := int8(c.ir & 0xFF) // sign-extend to 8 bits
+	data := int8(c.ir & 0xFF) // sign-extended to 32 bits below
 	c.reg.D[dn] = uint32(int32(data))
 	c.setFlagsLogical(c.reg.D[dn], sizeLong)
 	c.cycles += 4
@@ -255,6 +255,12 @@ func registerMOVEM() {
 	}
 }
 
+// opMOVEM transfers the registers selected by the mask word. When the
+// addressing register is itself in the list, the 68000 stores its initial
+// (undecremented) value for -(An), and for (An)+ the loaded value is
+// replaced by the final incremented address, so An is only written back
+// after the transfers. MOVEM.W sign-extends each loaded word to 32 bits
+// for data and address registers alike, and stores only the low word.
 func opMOVEM(c *CPU) {
 	dir := (c.ir >> 10) & 1  // 0 = reg-to-mem, 1 = mem-to-reg
 	szBit := (c.ir >> 6) & 1 // 0 = word, 1 = long
@@ -268,6 +274,10 @@ func opMOVEM(c *CPU) {
 
 	mask := c.fetchPC() // register list mask
 
+	// MOVEM prefetches the word after the instruction before its first
+	// transfer, so an address error stacks a PC one word further on.
+	c.faultAdj = 2
+
 	if dir == 0 {
 		// Register to memory
 		if mode == 4 {
@@ -289,6 +299,7 @@ func opMOVEM(c *CPU) {
 			// Other modes: normal order (D0 first, A7 last)
 			src := c.resolveEA(mode, reg, sz)
 			addr := src.address()
+			c.faultAdj = 2
 			for i := 0; i < 16; i++ {
 				if mask&(1<<uint(i)) != 0 {
 					if i < 8 {
@@ -323,6 +334,7 @@ func opMOVEM(c *CPU) {
 		} else {
 			src := c.resolveEA(mode, reg, sz)
 			addr := src.address()
+			c.faultAdj = 2
 			for i := 0; i < 16; i++ {
 				if mask&(1<<uint(i)) != 0 {
 					val := c.readBus(sz, addr)
@@ -386,14 +386,13 @@ func TestMOVE_W(t *testing.T) {
 				RAM: [][2]uint32{{4390832, 62}, {4390833, 35}, {4390834, 182}, {4390835, 124}, {12, 36}, {13, 20}, {14, 89}, {15, 46}, {1333550, 31}, {1333551, 75}, {1333552, 148}, {1333553, 42}},
 			},
 			want: cpuState{
-				D:      [8]uint32{696051969, 1854751692, 213349985, 1805050501, 4056672009, 1656096433, 2280222579, 561961943},
-				A:      [7]uint32{3810802989, 663015860, 2830421226, 1854736185, 601145369, 703294689, 3541894700},
-				PC:     605313330,
-				SR:     9222,
-				USP:    9082000,
-				SSP:    2479678,
-				RAM:    [][2]uint32{{4390832, 62}, {4390833, 35}, {4390834, 182}, {4390835, 124}, {2479690, 255}, {2479691, 180}, {2479686, 4}, {2479687, 6}, {2479688, 0}, {2479689, 66}, {2479684, 62}, {2479685, 35}, {2479682, 7}, {2479683, 57}, {2479678, 62}, {2479679, 49}, {2479680, 110}, {2479681, 141}, {12, 36}, {13, 20}, {14, 89}, {15, 46}, {1333550, 31}, {1333551, 75}, {1333552, 148}, {1333553, 42}},
-				Halted: true,
+				D:   [8]uint32{696051969, 1854751692, 213349985, 1805050501, 4056672009, 1656096433, 2280222579, 561961943},
+				A:   [7]uint32{3810802989, 663015860, 2830421226, 1854736185, 601145369, 703294689, 3541894700},
+				PC:  605313330,
+				SR:  9222,
+				USP: 9082000,
+				SSP: 2479678,
+				RAM: [][2]uint32{{4390832, 62}, {4390833, 35}, {4390834, 182}, {4390835, 124}, {2479690, 255}, {2479691, 180}, {2479686, 4}, {2479687, 6}, {2479688, 0}, {2479689, 66}, {2479684, 62}, {2479685, 35}, {2479682, 7}, {2479683, 57}, {2479678, 62}, {2479679, 49}, {2479680, 110}, {2479681, 141}, {12, 36}, {13, 20}, {14, 89}, {15, 46}, {1333550, 31}, {1333551, 75}, {1333552, 148}, {1333553, 42}},
 			},
 		},
 		{
@@ -408,14 +407,13 @@ func TestMOVE_W(t *testing.T) {
 				RAM: [][2]uint32{{6937606, 49}, {6937607, 182}, {6937608, 161}, {6937609, 193}, {6937610, 176}, {6937611, 145}, {12, 253}, {13, 164}, {14, 247}, {15, 228}, {10811364, 57}, {10811365, 62}, {10811366, 121}, {10811367, 39}},
 			},
 			want: cpuState{
-				D:      [8]uint32{970642547, 42024927, 2408930079, 707397645, 3339450817, 2308389420, 1208726658, 3783339692},
-				A:      [7]uint32{1641272459, 1356141785, 2036218255, 4276021322, 3576453530, 2163200603, 188834721},
-				PC:     4255447016,
-				SR:     9741,
-				USP:    3097706,
-				SSP:    13071052,
-				RAM:    [][2]uint32{{6937606, 49}, {6937607, 182}, {6937608, 161}, {6937609, 193}, {6937610, 176}, {6937611, 145}, {13071064, 220}, {13071065, 8}, {13071060, 134}, {13071061, 13}, {13071062, 0}, {13071063, 105}, {13071058, 49}, {13071059, 182}, {13071056, 156}, {13071057, 241}, {13071052, 49}, {13071053, 177}, {13071054, 11}, {13071055, 65}, {12, 253}, {13, 164}, {14, 247}, {15, 228}, {10811364, 57}, {10811365, 62}, {10811366, 121}, {10811367, 39}},
-				Halted: true,
+				D:   [8]uint32{970642547, 42024927, 2408930079, 707397645, 3339450817, 2308389420, 1208726658, 3783339692},
+				A:   [7]uint32{1641272459, 1356141785, 2036218255, 4276021322, 3576453530, 2163200603, 188834721},
+				PC:  4255447016,
+				SR:  9741,
+				USP: 3097706,
+				SSP: 13071052,
+				RAM: [][2]uint32{{6937606, 49}, {6937607, 182}, {6937608, 161}, {6937609, 193}, {6937610, 176}, {6937611, 145}, {13071064, 220}, {13071065, 8}, {13071060, 134}, {13071061, 13}, {13071062, 0}, {13071063, 105}, {13071058, 49}, {13071059, 182}, {13071056, 156}, {13071057, 241}, {13071052, 49}, {13071053, 177}, {13071054, 11}, {13071055, 65}, {12, 253}, {13, 164}, {14, 247}, {15, 228}, {10811364, 57}, {10811365, 62}, {10811366, 121}, {10811367, 39}},
 			},
 		},
 		{
@@ -430,14 +428,13 @@ func TestMOVE_W(t *testing.T) {
 				RAM: [][2]uint32{{9548402, 63}, {9548403, 158}, {9548404, 30}, {9548405, 143}, {12, 242}, {13, 242}, {14, 223}, {15, 154}, {15916954, 109}, {15916955, 50}, {15916956, 47}, {15916957, 107}},
 			},
 			want: cpuState{
-				D:      [8]uint32{3725713055, 3387138312, 89827935, 2663343400, 926777393, 1643429908, 2118670654, 2042655081},
-				A:      [7]uint32{472803763, 3211475504, 397298379, 1699832085, 3550588545, 3202916405, 1982187349},
-				PC:     4076003230,
-				SR:     8458,
-				USP:    11358164,
-				SSP:    13181052,
-				RAM:    [][2]uint32{{9548402, 63}, {9548403, 158}, {9548404, 30}, {9548405, 143}, {13181064, 178}, {13181065, 116}, {13181060, 1}, {13181061, 10}, {13181062, 0}, {13181063, 145}, {13181058, 63}, {13181059, 158}, {13181056, 199}, {13181057, 83}, {13181052, 63}, {13181053, 145}, {13181054, 118}, {13181055, 37}, {12, 242}, {13, 242}, {14, 223}, {15, 154}, {15916954, 109}, {15916955, 50}, {15916956, 47}, {15916957, 107}},
-				Halted: true,
+				D:   [8]uint32{3725713055, 3387138312, 89827935, 2663343400, 926777393, 1643429908, 2118670654, 2042655081},
+				A:   [7]uint32{472803763, 3211475504, 397298379, 1699832085, 3550588545, 3202916405, 1982187349},
+				PC:  4076003230,
+				SR:  8458,
+				USP: 11358164,
+				SSP: 13181052,
+				RAM: [][2]uint32{{9548402, 63}, {9548403, 158}, {9548404, 30}, {9548405, 143}, {13181064, 178}, {13181065, 116}, {13181060, 1}, {13181061, 10}, {13181062, 0}, {13181063, 145}, {13181058, 63}, {13181059, 158}, {13181056, 199}, {13181057, 83}, {13181052, 63}, {13181053, 145}, {13181054, 118}, {13181055, 37}, {12, 242}, {13, 242}, {14, 223}, {15, 154}, {15916954, 109}, {15916955, 50}, {15916956, 47}, {15916957, 107}},
 			},
 		},
 		{
@@ -686,14 +683,13 @@ func TestMOVE_L(t *testing.T) {
 				RAM: [][2]uint32{{11821388, 38}, {11821389, 150}, {11821390, 242}, {11821391, 126}, {12, 17}, {13, 14}, {14, 230}, {15, 160}, {976544, 230}, {976545, 100}, {976546, 197}, {976547, 2}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1906962636, 544351852, 835379764, 615842027, 1267734345, 1916177819, 1949004110, 629334693},
-				A:      [7]uint32{2238162024, 1403173603, 2182794003, 591293865, 1366987338, 2837198190, 1213243669},
-				PC:     286189220,
-				SR:     9482,
-				USP:    4621904,
-				SSP:    15218488,
-				RAM:    [][2]uint32{{11821388, 38}, {11821389, 150}, {11821390, 242}, {11821391, 126}, {15218500, 97}, {15218501, 78}, {15218496, 37}, {15218497, 10}, {15218498, 0}, {15218499, 180}, {15218494, 38}, {15218495, 150}, {15218492, 161}, {15218493, 21}, {15218488, 38}, {15218489, 149}, {15218490, 72}, {15218491, 80}, {12, 17}, {13, 14}, {14, 230}, {15, 160}, {976544, 230}, {976545, 100}, {976546, 197}, {976547, 2}},
-				Halted: true,
+				D:   [8]uint32{1906962636, 544351852, 835379764, 615842027, 1267734345, 1916177819, 1949004110, 629334693},
+				A:   [7]uint32{2238162024, 1403173603, 2182794003, 591293865, 1366987338, 2837198190, 1213243669},
+				PC:  286189220,
+				SR:  9482,
+				USP: 4621904,
+				SSP: 15218488,
+				RAM: [][2]uint32{{11821388, 38}, {11821389, 150}, {11821390, 242}, {11821391, 126}, {15218500, 97}, {15218501, 78}, {15218496, 37}, {15218497, 10}, {15218498, 0}, {15218499, 180}, {15218494, 38}, {15218495, 150}, {15218492, 161}, {15218493, 21}, {15218488, 38}, {15218489, 149}, {15218490, 72}, {15218491, 80}, {12, 17}, {13, 14}, {14, 230}, {15, 160}, {976544, 230}, {976545, 100}, {976546, 197}, {976547, 2}},
 			},
 		},
 		{
@@ -730,14 +726,13 @@ func TestMOVE_L(t *testing.T) {
 				RAM: [][2]uint32{{9252062, 43}, {9252063, 88}, {9252064, 73}, {9252065, 187}, {12, 137}, {13, 110}, {14, 114}, {15, 52}, {7238196, 36}, {7238197, 192}, {7238198, 148}, {7238199, 89}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2243211884, 2835599725, 994373749, 1247880749, 407550755, 1646233360, 2240256088, 2313172021},
-				A:      [7]uint32{2497839615, 1054997022, 2036715351, 2265210345, 4123845927, 2902601904, 3555366105},
-				PC:     2305716792,
-				SR:     9730,
-				USP:    2634082,
-				SSP:    5705786,
-				RAM:    [][2]uint32{{9252062, 43}, {9252063, 88}, {9252064, 73}, {9252065, 187}, {5705798, 44}, {5705799, 224}, {5705794, 6}, {5705795, 2}, {5705796, 0}, {5705797, 141}, {5705792, 43}, {5705793, 88}, {5705790, 1}, {5705791, 255}, {5705786, 43}, {5705787, 81}, {5705788, 148}, {5705789, 226}, {12, 137}, {13, 110}, {14, 114}, {15, 52}, {7238196, 36}, {7238197, 192}, {7238198, 148}, {7238199, 89}},
-				Halted: true,
+				D:   [8]uint32{2243211884, 2835599725, 994373749, 1247880749, 407550755, 1646233360, 2240256088, 2313172021},
+				A:   [7]uint32{2497839615, 1054997022, 2036715351, 2265210345, 4123845927, 2902601904, 3555366105},
+				PC:  2305716792,
+				SR:  9730,
+				USP: 2634082,
+				SSP: 5705786,
+				RAM: [][2]uint32{{9252062, 43}, {9252063, 88}, {9252064, 73}, {9252065, 187}, {5705798, 44}, {5705799, 224}, {5705794, 6}, {5705795, 2}, {5705796, 0}, {5705797, 141}, {5705792, 43}, {5705793, 88}, {5705790, 1}, {5705791, 255}, {5705786, 43}, {5705787, 81}, {5705788, 148}, {5705789, 226}, {12, 137}, {13, 110}, {14, 114}, {15, 52}, {7238196, 36}, {7238197, 192}, {7238198, 148}, {7238199, 89}},
 			},
 		},
 		{
@@ -1952,14 +1947,13 @@ func TestMOVEM_W(t *testing.T) {
 				RAM: [][2]uint32{{6601742, 72}, {6601743, 175}, {6601744, 138}, {6601745, 120}, {6601746, 7}, {6601747, 15}, {6601748, 247}, {6601749, 213}, {12, 80}, {13, 172}, {14, 165}, {15, 230}, {11314662, 112}, {11314663, 32}, {11314664, 169}, {11314665, 217}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2516854248, 1224448443, 2738172433, 749428807, 51877730, 1992372080, 3018445573, 714955254},
-				A:      [7]uint32{415175827, 788158023, 3093381556, 2582546957, 143558775, 2086406636, 1268185199},
-				PC:     1353491946,
-				SR:     8194,
-				USP:    15833890,
-				SSP:    7443544,
-				RAM:    [][2]uint32{{6601742, 72}, {6601743, 175}, {6601744, 138}, {6601745, 120}, {6601746, 7}, {6601747, 15}, {6601748, 247}, {6601749, 213}, {7443556, 188}, {7443557, 22}, {7443552, 0}, {7443553, 2}, {7443554, 0}, {7443555, 100}, {7443550, 72}, {7443551, 175}, {7443548, 162}, {7443549, 49}, {7443544, 72}, {7443545, 161}, {7443546, 0}, {7443547, 241}, {12, 80}, {13, 172}, {14, 165}, {15, 230}, {11314662, 112}, {11314663, 32}, {11314664, 169}, {11314665, 217}},
-				Halted: true,
+				D:   [8]uint32{2516854248, 1224448443, 2738172433, 749428807, 51877730, 1992372080, 3018445573, 714955254},
+				A:   [7]uint32{415175827, 788158023, 3093381556, 2582546957, 143558775, 2086406636, 1268185199},
+				PC:  1353491946,
+				SR:  8194,
+				USP: 15833890,
+				SSP: 7443544,
+				RAM: [][2]uint32{{6601742, 72}, {6601743, 175}, {6601744, 138}, {6601745, 120}, {6601746, 7}, {6601747, 15}, {6601748, 247}, {6601749, 213}, {7443556, 188}, {7443557, 22}, {7443552, 0}, {7443553, 2}, {7443554, 0}, {7443555, 100}, {7443550, 72}, {7443551, 175}, {7443548, 162}, {7443549, 49}, {7443544, 72}, {7443545, 161}, {7443546, 0}, {7443547, 241}, {12, 80}, {13, 172}, {14, 165}, {15, 230}, {11314662, 112}, {11314663, 32}, {11314664, 169}, {11314665, 217}},
 			},
 		},
 		{
@@ -1996,14 +1990,13 @@ func TestMOVEM_W(t *testing.T) {
 				RAM: [][2]uint32{{5074016, 76}, {5074017, 158}, {5074018, 222}, {5074019, 123}, {5074020, 42}, {5074021, 108}, {12, 205}, {13, 128}, {14, 130}, {15, 210}, {8422098, 17}, {8422099, 176}, {8422100, 66}, {8422101, 25}},
 			},
 			want: cpuState{
-				D:      [8]uint32{4105608420, 696610705, 3042460131, 1016764949, 882012821, 2337095667, 615150736, 2948104108},
-				A:      [7]uint32{122976785, 2173134499, 1008674440, 1823559609, 171019523, 1610608308, 2178653353},
-				PC:     3447751382,
-				SR:     9736,
-				USP:    11435678,
-				SSP:    3800170,
-				RAM:    [][2]uint32{{5074016, 76}, {5074017, 158}, {5074018, 222}, {5074019, 123}, {5074020, 42}, {5074021, 108}, {3800182, 108}, {3800183, 102}, {3800178, 38}, {3800179, 8}, {3800180, 0}, {3800181, 77}, {3800176, 76}, {3800177, 158}, {3800174, 156}, {3800175, 169}, {3800170, 76}, {3800171, 149}, {3800172, 129}, {3800173, 219}, {12, 205}, {13, 128}, {14, 130}, {15, 210}, {8422098, 17}, {8422099, 176}, {8422100, 66}, {8422101, 25}},
-				Halted: true,
+				D:   [8]uint32{4105608420, 696610705, 3042460131, 1016764949, 882012821, 2337095667, 615150736, 2948104108},
+				A:   [7]uint32{122976785, 2173134499, 1008674440, 1823559609, 171019523, 1610608308, 2178653353},
+				PC:  3447751382,
+				SR:  9736,
+				USP: 11435678,
+				SSP: 3800170,
+				RAM: [][2]uint32{{5074016, 76}, {5074017, 158}, {5074018, 222}, {5074019, 123}, {5074020, 42}, {5074021, 108}, {3800182, 108}, {3800183, 102}, {3800178, 38}, {3800179, 8}, {3800180, 0}, {3800181, 77}, {3800176, 76}, {3800177, 158}, {3800174, 156}, {3800175, 169}, {3800170, 76}, {3800171, 149}, {3800172, 129}, {3800173, 219}, {12, 205}, {13, 128}, {14, 130}, {15, 210}, {8422098, 17}, {8422099, 176}, {8422100, 66}, {8422101, 25}},
 			},
 		},
 		{
@@ -2274,14 +2267,13 @@ func TestMOVEM_L(t *testing.T) {
 				RAM: [][2]uint32{{14353538, 76}, {14353539, 208}, {14353540, 56}, {14353541, 19}, {14353542, 196}, {14353543, 217}, {12, 145}, {13, 154}, {14, 217}, {15, 18}, {10148114, 103}, {10148115, 195}, {10148116, 113}, {10148117, 94}},
 			},
 			want: cpuState{
-				D:      [8]uint32{4145974960, 98666609, 4134305615, 2306122646, 3759942418, 2261160881, 4192929536, 495306415},
-				A:      [7]uint32{1291678793, 1674217383, 3287716855, 1614216120, 530989899, 1794898052, 3703973272},
-				PC:     2442844438,
-				SR:     8991,
-				USP:    590824,
-				SSP:    3242790,
-				RAM:    [][2]uint32{{14353538, 76}, {14353539, 208}, {14353540, 56}, {14353541, 19}, {14353542, 196}, {14353543, 217}, {3242802, 4}, {3242803, 136}, {3242798, 35}, {3242799, 31}, {3242800, 0}, {3242801, 219}, {3242796, 76}, {3242797, 208}, {3242794, 116}, {3242795, 73}, {3242790, 76}, {3242791, 213}, {3242792, 76}, {3242793, 253}, {12, 145}, {13, 154}, {14, 217}, {15, 18}, {10148114, 103}, {10148115, 195}, {10148116, 113}, {10148117, 94}},
-				Halted: true,
+				D:   [8]uint32{4145974960, 98666609, 4134305615, 2306122646, 3759942418, 2261160881, 4192929536, 495306415},
+				A:   [7]uint32{1291678793, 1674217383, 3287716855, 1614216120, 530989899, 1794898052, 3703973272},
+				PC:  2442844438,
+				SR:  8991,
+				USP: 590824,
+				SSP: 3242790,
+				RAM: [][2]uint32{{14353538, 76}, {14353539, 208}, {14353540, 56}, {14353541, 19}, {14353542, 196}, {14353543, 217}, {3242802, 4}, {3242803, 136}, {3242798, 35}, {3242799, 31}, {3242800, 0}, {3242801, 219}, {3242796, 76}, {3242797, 208}, {3242794, 116}, {3242795, 73}, {3242790, 76}, {3242791, 213}, {3242792, 76}, {3242793, 253}, {12, 145}, {13, 154}, {14, 217}, {15, 18}, {10148114, 103}, {10148115, 195}, {10148116, 113}, {10148117, 94}},
 			},
 		},
 		{
@@ -2318,14 +2310,13 @@ func TestMOVEM_L(t *testing.T) {
 				RAM: [][2]uint32{{2006192, 76}, {2006193, 244}, {2006194, 1}, {2006195, 173}, {2006196, 30}, {2006197, 32}, {2006198, 46}, {2006199, 167}, {12, 152}, {13, 174}, {14, 95}, {15, 204}, {11427788, 131}, {11427789, 29}, {11427790, 163}, {11427791, 2}},
 			},
 			want: cpuState{
-				D:      [8]uint32{2281808026, 1606278836, 2174335579, 3089282453, 3363370756, 377098347, 369340366, 210301945},
-				A:      [7]uint32{792693173, 2816978871, 613038058, 3365325113, 680158747, 2687155925, 3510585527},
-				PC:     2561564624,
-				SR:     8460,
-				USP:    8304062,
-				SSP:    3747538,
-				RAM:    [][2]uint32{{2006192, 76}, {2006193, 244}, {2006194, 1}, {2006195, 173}, {2006196, 30}, {2006197, 32}, {2006198, 46}, {2006199, 167}, {3747550, 156}, {3747551, 184}, {3747546, 1}, {3747547, 12}, {3747548, 0}, {3747549, 30}, {3747544, 76}, {3747545, 244}, {3747542, 68}, {3747543, 239}, {3747538, 76}, {3747539, 241}, {3747540, 136}, {3747541, 72}, {12, 152}, {13, 174}, {14, 95}, {15, 204}, {11427788, 131}, {11427789, 29}, {11427790, 163}, {11427791, 2}},
-				Halted: true,
+				D:   [8]uint32{2281808026, 1606278836, 2174335579, 3089282453, 3363370756, 377098347, 369340366, 210301945},
+				A:   [7]uint32{792693173, 2816978871, 613038058, 3365325113, 680158747, 2687155925, 3510585527},
+				PC:  2561564624,
+				SR:  8460,
+				USP: 8304062,
+				SSP: 3747538,
+				RAM: [][2]uint32{{2006192, 76}, {2006193, 244}, {2006194, 1}, {2006195, 173}, {2006196, 30}, {2006197, 32}, {2006198, 46}, {2006199, 167}, {3747550, 156}, {3747551, 184}, {3747546, 1}, {3747547, 12}, {3747548, 0}, {3747549, 30}, {3747544, 76}, {3747545, 244}, {3747542, 68}, {3747543, 239}, {3747538, 76}, {3747539, 241}, {3747540, 136}, {3747541, 72}, {12, 152}, {13, 174}, {14, 95}, {15, 204}, {11427788, 131}, {11427789, 29}, {11427790, 163}, {11427791, 2}},
 			},
 		},
 		{
@@ -2362,14 +2353,13 @@ func TestMOVEM_L(t *testing.T) {
 				RAM: [][2]uint32{{11866660, 76}, {11866661, 218}, {11866662, 87}, {11866663, 13}, {11866664, 41}, {11866665, 186}, {12, 240}, {13, 200}, {14, 249}, {15, 214}, {13171158, 150}, {13171159, 172}, {13171160, 222}, {13171161, 82}},
 			},
 			want: cpuState{
-				D:      [8]uint32{1799497197, 2248024927, 3745444050, 613628228, 1759951819, 1502084221, 2029516742, 4178324760},
-				A:      [7]uint32{3555992778, 4140131038, 1330774695, 1904381096, 1340934943, 1213874391, 1151662581},
-				PC:     4039703002,
-				SR:     8984,
-				USP:    13903986,
-				SSP:    9962040,
-				RAM:    [][2]uint32{{11866660, 76}, {11866661, 218}, {11866662, 87}, {11866663, 13}, {11866664, 41}, {11866665, 186}, {9962052, 18}, {9962053, 42}, {9962048, 131}, {9962049, 24}, {9962050, 0}, {9962051, 181}, {9962046, 76}, {9962047, 218}, {9962044, 2}, {9962045, 167}, {9962040, 76}, {9962041, 209}, {9962042, 79}, {9962043, 82}, {12, 240}, {13, 200}, {14, 249}, {15, 214}, {13171158, 150}, {13171159, 172}, {13171160, 222}, {13171161, 82}},
-				Halted: true,
+				D:   [8]uint32{1799497197, 2248024927, 3745444050, 613628228, 1759951819, 1502084221, 2029516742, 4178324760},
+				A:   [7]uint32{3555992778, 4140131038, 1330774695, 1904381096, 1340934943, 1213874391, 1151662581},
+				PC:  4039703002,
+				SR:  8984,
+				USP: 13903986,
+				SSP: 9962040,
+				RAM: [][2]uint32{{11866660, 76}, {11866661, 218}, {11866662, 87}, {11866663, 13}, {11866664, 41}, {11866665, 186}, {9962052, 18}, {9962053, 42}, {9962048, 131}, {9962049, 24}, {9962050, 0}, {9962051, 181}, {9962046, 76}, {9962047, 218}, {9962044, 2}, {9962045, 167}, {9962040, 76}, {9962041, 209}, {9962042, 79}, {9962043, 82}, {12, 240}, {13, 200}, {14, 249}, {15, 214}, {13171158, 150}, {13171159, 172}, {13171160, 222}, {13171161, 82}},
 			},
 		},
 		{
@@ -4509,3 +4499,368 @@ func TestPEA(t *testing.T) {
 		})
 	}
 }
+
+// TestMOVEMBaseInList covers MOVEM with the addressing register also in the
+// register list. On the 68000 a -(An) store writes the initial value of An,
+// and an (An)+ load discards the memory value for An in favour of the
+// incremented address (PRM MOVEM description).
+func TestMOVEMBaseInList(t *testing.T) {
+	tests := []struct {
+		name string
+		init cpuState
+		want cpuState
+	}{
+		{
+			name: "MOVEM.L D0/A0,-(A0) stores initial A0",
+			init: cpuState{
+				D:   [8]uint32{0x11111111},
+				A:   [7]uint32{0x3000},
+				PC:  0x1004,
+				SR:  0x2700,
+				SSP: 0x10000,
+				RAM: [][2]uint32{{0x1000, 0x48}, {0x1001, 0xE0}, {0x1002, 0x80}, {0x1003, 0x80}},
+			},
+			want: cpuState{
+				D:   [8]uint32{0x11111111},
+				A:   [7]uint32{0x2FF8},
+				PC:  0x1008,
+				SR:  0x2700,
+				SSP: 0x10000,
+				RAM: [][2]uint32{
+					{0x2FF8, 0x11}, {0x2FF9, 0x11}, {0x2FFA, 0x11}, {0x2FFB, 0x11},
+					{0x2FFC, 0x00}, {0x2FFD, 0x00}, {0x2FFE, 0x30}, {0x2FFF, 0x00},
+				},
+				Cycles: 24,
+			},
+		},
+		{
+			name: "MOVEM.W A0,-(A0) stores initial A0",
+			init: cpuState{
+				A:   [7]uint32{0x3000},
+				PC:  0x1004,
+				SR:  0x2700,
+				SSP: 0x10000,
+				RAM: [][2]uint32{{0x1000, 0x48}, {0x1001, 0xA0}, {0x1002, 0x00}, {0x1003, 0x80}},
+			},
+			want: cpuState{
+				A:      [7]uint32{0x2FFE},
+				PC:     0x1008,
+				SR:     0x2700,
+				SSP:    0x10000,
+				RAM:    [][2]uint32{{0x2FFE, 0x30}, {0x2FFF, 0x00}},
+				Cycles: 12,
+			},
+		},
+		{
+			name: "MOVEM.L (A0)+,D0/A0 leaves incremented A0",
+			init: cpuState{
+				A:   [7]uint32{0x3000},
+				PC:  0x1004,
+				SR:  0x2700,
+				SSP: 0x10000,
+				RAM: [][2]uint32{
+					{0x1000, 0x4C}, {0x1001, 0xD8}, {0x1002, 0x01}, {0x1003, 0x01},
+					{0x3000, 0xAA}, {0x3001, 0xAA}, {0x3002, 0xAA}, {0x3003, 0xAA},
+					{0x3004, 0x55}, {0x3005, 0x55}, {0x3006, 0x55}, {0x3007, 0x55},
+				},
+			},
+			want: cpuState{
+				D:      [8]uint32{0xAAAAAAAA},
+				A:      [7]uint32{0x3008},
+				PC:     0x1008,
+				SR:     0x2700,
+				SSP:    0x10000,
+				Cycles: 28,
+			},
+		},
+		{
+			name: "MOVEM.W (A0)+,A0 leaves incremented A0",
+			init: cpuState{
+				A:   [7]uint32{0x3000},
+				PC:  0x1004,
+				SR:  0x2700,
+				SSP: 0x10000,
+				RAM: [][2]uint32{{0x1000, 0x4C}, {0x1001, 0x98}, {0x1002, 0x01}, {0x1003, 0x00}, {0x3000, 0x80}, {0x3001, 0x00}},
+			},
+			want: cpuState{
+				A:      [7]uint32{0x3002},
+				PC:     0x1008,
+				SR:     0x2700,
+				SSP:    0x10000,
+				Cycles: 16,
+			},
+		},
+		{
+			name: "MOVEM.L (A0),D0/A0 loads A0 from memory",
+			init: cpuState{
+				A:   [7]uint32{0x3000},
+				PC:  0x1004,
+				SR:  0x2700,
+				SSP: 0x10000,
+				RAM: [][2]uint32{
+					{0x1000, 0x4C}, {0x1001, 0xD0}, {0x1002, 0x01}, {0x1003, 0x01},
+					{0x3000, 0xAA}, {0x3001, 0xAA}, {0x3002, 0xAA}, {0x3003, 0xAA},
+					{0x3004, 0x00}, {0x3005, 0x00}, {0x3006, 0x40}, {0x3007, 0x00},
+				},
+			},
+			want: cpuState{
+				D:      [8]uint32{0xAAAAAAAA},
+				A:      [7]uint32{0x4000},
+				PC:     0x1008,
+				SR:     0x2700,
+				SSP:    0x10000,
+				Cycles: 28,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runTest(t, tt.init, tt.want)
+		})
+	}
+}
+
+// TestMOVEMWordSignExtension checks that MOVEM.W sign-extends each loaded
+// word into the whole of a data register as well as an address register,
+// and that storing writes only each register's low word.
+func TestMOVEMWordSignExtension(t *testing.T) {
+	tests := []struct {
+		name string
+		init cpuState
+		want cpuState
+	}{
+		{
+			name: "MOVEM.W (A0),D1/A1 sign-extends negative words",
+			init: cpuState{
+				D:   [8]uint32{1: 0x12345678},
+				A:   [7]uint32{0x3000, 0x11112222},
+				PC:  0x1004,
+				SR:  0x2700,
+				SSP: 0x10000,
+				RAM: [][2]uint32{
+					{0x1000, 0x4C}, {0x1001, 0x90}, {0x1002, 0x02}, {0x1003, 0x02},
+					{0x3000, 0xFF}, {0x3001, 0xFE}, {0x3002, 0x80}, {0x3003, 0x01},
+				},
+			},
+			want: cpuState{
+				D:      [8]uint32{1: 0xFFFFFFFE},
+				A:      [7]uint32{0x3000, 0xFFFF8001},
+				PC:     0x1008,
+				SR:     0x2700,
+				SSP:    0x10000,
+				Cycles: 20,
+			},
+		},
+		{
+			name: "MOVEM.W (A0),D2 clears the upper word for a positive word",
+			init: cpuState{
+				D:   [8]uint32{2: 0xFFFF0000},
+				A:   [7]uint32{0x3000},
+				PC:  0x1004,
+				SR:  0x2700,
+				SSP: 0x10000,
+				RAM: [][2]uint32{
+					{0x1000, 0x4C}, {0x1001, 0x90}, {0x1002, 0x00}, {0x1003, 0x04},
+					{0x3000, 0x7F}, {0x3001, 0xFF},
+				},
+			},
+			want: cpuState{
+				D:      [8]uint32{2: 0x00007FFF},
+				A:      [7]uint32{0x3000},
+				PC:     0x1008,
+				SR:     0x2700,
+				SSP:    0x10000,
+				Cycles: 16,
+			},
+		},
+		{
+			name: "MOVEM.W D1/A1,(A0) stores the low words",
+			init: cpuState{
+				D:   [8]uint32{1: 0x12348765},
+				A:   [7]uint32{0x3000, 0xABCD1234},
+				PC:  0x1004,
+				SR:  0x2700,
+				SSP: 0x10000,
+				RAM: [][2]uint32{
+					{0x1000, 0x48}, {0x1001, 0x90}, {0x1002, 0x02}, {0x1003, 0x02},
+					{0x3004, 0xEE}, {0x3005, 0xEE},
+				},
+			},
+			want: cpuState{
+				D:   [8]uint32{1: 0x12348765},
+				A:   [7]uint32{0x3000, 0xABCD1234},
+				PC:  0x1008,
+				SR:  0x2700,
+				SSP: 0x10000,
+				RAM: [][2]uint32{
+					{0x3000, 0x87}, {0x3001, 0x65}, {0x3002, 0x12}, {0x3003, 0x34},
+					{0x3004, 0xEE}, {0x3005, 0xEE},
+				},
+				Cycles: 16,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runTest(t, tt.init, tt.want)
+		})
+	}
+}
+
+// TestMOVEQBoundaries checks the sign extension of MOVEQ's 8-bit immediate
+// at the edges of its range, for every destination register.
+func TestMOVEQBoundaries(t *testing.T) {
+	tests := []struct {
+		data  uint16
+		want  uint32
+		flags uint16
+	}{
+		{0x00, 0x00000000, flagZ},
+		{0x01, 0x00000001, 0},
+		{0x7F, 0x0000007F, 0},
+		{0x80, 0xFFFFFF80, flagN},
+		{0xFF, 0xFFFFFFFF, flagN},
+	}
+	for _, tt := range tests {
+		for dn := uint16(0); dn < 8; dn++ {
+			bus := &testBus{}
+			writeWord(bus, 0x1000, 0x7000|dn<<9|tt.data)
+			cpu := &CPU{bus: bus}
+			// Start with X, V and C set: MOVEQ clears V and C, keeps X
+			cpu.SetState(Registers{D: [8]uint32{0xA5A5A5A5, 0xA5A5A5A5, 0xA5A5A5A5, 0xA5A5A5A5,
+				0xA5A5A5A5, 0xA5A5A5A5, 0xA5A5A5A5, 0xA5A5A5A5}, PC: 0x1000, SR: 0x2713, SSP: 0x10000})
+			if n := cpu.Step(); n != 4 {
+				t.Errorf("MOVEQ #$%02X,D%d: cycles = %d, want 4", tt.data, dn, n)
+			}
+			if got := cpu.D(int(dn)); got != tt.want {
+				t.Errorf("MOVEQ #$%02X,D%d: D%d = 0x%08X, want 0x%08X", tt.data, dn, dn, got, tt.want)
+			}
+			if got := cpu.SR() & 0x1F; got != tt.flags|flagX {
+				t.Errorf("MOVEQ #$%02X,D%d: CCR = 0x%02X, want 0x%02X", tt.data, dn, got, tt.flags|flagX)
+			}
+		}
+	}
+}
+
+// TestByteStackPointer checks that byte accesses through (A7)+ and -(A7)
+// move A7 by 2 to keep the stack word aligned, use the byte at the even
+// address, and cost the same as through any other address register.
+func TestByteStackPointer(t *testing.T) {
+	tests := []struct {
+		name   string
+		op     uint16
+		d0, d1 uint32
+		mem    uint16 // word at $7FFE and $8000
+		wantA7 uint32
+		wantD  [2]uint32
+		want   [2]uint16 // words at $7FFE and $8000
+		cycles int
+	}{
+		{"MOVE.B (A7)+,D0", 0x101F, 0, 0, 0xAB11, 0x8002, [2]uint32{0xAB, 0}, [2]uint16{0xAB11, 0xAB11}, 8},
+		{"MOVE.B D0,-(A7)", 0x1F00, 0x5A, 0, 0xAB11, 0x7FFE, [2]uint32{0x5A, 0}, [2]uint16{0x5A11, 0xAB11}, 8},
+		{"ADD.B D0,(A7)+", 0xD11F, 0x01, 0, 0xAB11, 0x8002, [2]uint32{0x01, 0}, [2]uint16{0xAB11, 0xAC11}, 12},
+		{"SUB.B -(A7),D1", 0x9227, 0, 0xFF, 0xAB11, 0x7FFE, [2]uint32{0, 0x54}, [2]uint16{0xAB11, 0xAB11}, 10},
+		{"CMPM.B (A7)+,(A7)+", 0xBF0F, 0, 0, 0xAB11, 0x8004, [2]uint32{0, 0}, [2]uint16{0xAB11, 0xAB11}, 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			writeWord(bus, 0x1000, tt.op)
+			writeWord(bus, 0x7FFE, tt.mem)
+			writeWord(bus, 0x8000, tt.mem)
+			cpu := &CPU{bus: bus}
+			cpu.SetState(Registers{D: [8]uint32{tt.d0, tt.d1}, PC: 0x1000, SR: 0x2700, SSP: 0x8000})
+			if n := cpu.Step(); n != tt.cycles {
+				t.Errorf("cycles = %d, want %d", n, tt.cycles)
+			}
+			if a7 := cpu.A(7); a7 != tt.wantA7 {
+				t.Errorf("A7 = 0x%X, want 0x%X", a7, tt.wantA7)
+			}
+			if d0, d1 := cpu.D(0), cpu.D(1); d0 != tt.wantD[0] || d1 != tt.wantD[1] {
+				t.Errorf("D0/D1 = 0x%X/0x%X, want 0x%X/0x%X", d0, d1, tt.wantD[0], tt.wantD[1])
+			}
+			if lo, hi := bus.Read16(0x7FFE), bus.Read16(0x8000); lo != tt.want[0] || hi != tt.want[1] {
+				t.Errorf("memory = %04X %04X, want %04X %04X", lo, hi, tt.want[0], tt.want[1])
+			}
+		})
+	}
+}
+
+// TestMOVEByteFromAn checks that MOVE.B with an address register source is
+// an illegal instruction, while the word and long forms are valid.
+func TestMOVEByteFromAn(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		op    uint16
+		legal bool
+	}{
+		{"MOVE.B A0,D0", 0x1008, false},
+		{"MOVE.B A7,(A1)", 0x128F, false},
+		{"MOVE.B A3,($1234).W", 0x11CB, false},
+		{"MOVE.W A0,D0", 0x3008, true},
+		{"MOVE.L A0,D0", 0x2008, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &testBus{}
+			writeWord(bus, 0x1000, tt.op)
+			writeWord(bus, 0x1002, 0x1234)
+			bus.Write32(vecIllegalInstruction*4, 0x3000)
+			cpu := &CPU{bus: bus}
+			cpu.SetState(Registers{D: [8]uint32{0xFFFFFFFF}, A: [8]uint32{0x12345678, 0x4000}, PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+			cpu.Step()
+			if got := cpu.PC() == 0x3000; got == tt.legal {
+				t.Errorf("PC = 0x%X, want illegal instruction handler: %v", cpu.PC(), !tt.legal)
+			}
+			if !tt.legal && cpu.D(0) != 0xFFFFFFFF {
+				t.Errorf("D0 = 0x%08X, want unchanged", cpu.D(0))
+			}
+		})
+	}
+}
+
+// TestMOVEIllegalDestinations runs every MOVE encoding whose destination is
+// PC-relative, immediate or one of the unassigned mode 7 registers, and
+// every MOVE.B to an address register, and expects each to take the
+// illegal instruction exception without touching memory or registers.
+func TestMOVEIllegalDestinations(t *testing.T) {
+	bus := &testBus{}
+	bus.Write32(vecIllegalInstruction*4, 0x3000)
+	var ops []uint16
+	for _, sz := range []uint16{0x1000, 0x2000, 0x3000} {
+		for src := uint16(0); src < 64; src++ {
+			for dstReg := uint16(2); dstReg < 8; dstReg++ {
+				ops = append(ops, sz|dstReg<<9|7<<6|src)
+			}
+		}
+	}
+	for src := uint16(0); src < 64; src++ {
+		for dstReg := uint16(0); dstReg < 8; dstReg++ {
+			ops = append(ops, 0x1000|dstReg<<9|1<<6|src)
+		}
+	}
+
+	for _, op := range ops {
+		if IsImplemented(op) {
+			t.Errorf("%04X: decodes to a handler, want illegal", op)
+		}
+		writeWord(bus, 0x1000, op)
+		cpu := &CPU{bus: bus}
+		regs := Registers{
+			D:  [8]uint32{1, 2, 3, 4, 5, 6, 7, 8},
+			A:  [8]uint32{0x4000, 0x4100, 0x4200, 0x4300, 0x4400, 0x4500, 0x4600},
+			PC: 0x1000, SR: 0x2700, SSP: 0x10000,
+		}
+		cpu.SetState(regs)
+		if n := cpu.Step(); n != 34 {
+			t.Errorf("%04X: cycles = %d, want 34", op, n)
+		}
+		got := cpu.Registers()
+		if got.PC != 0x3000 || got.D != regs.D || [7]uint32(got.A[:7]) != [7]uint32(regs.A[:7]) {
+			t.Errorf("%04X: PC = 0x%X D = %X A = %X, want the illegal instruction handler and registers unchanged",
+				op, got.PC, got.D, got.A)
+		}
+		if pc := bus.Read32(0x10000 - 4); pc != 0x1000 {
+			t.Errorf("%04X: stacked PC = 0x%X, want 0x1000", op, pc)
+		}
+	}
+}
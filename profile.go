@@ -0,0 +1,52 @@
+package m68k
+
+// profile accumulates per-opcode execution statistics.
+type profile struct {
+	count  [65536]uint64
+	cycles [65536]uint64
+}
+
+// EnableProfiling starts counting instructions by opcode word, discarding
+// any previous counts. Each executed instruction adds one to its opcode's
+// count and its cycles, from the opcode fetch on, to the opcode's cycle
+// total. An instruction aborted by a bus or address error is counted but
+// its cycles are not. Profiling is disabled by default.
+func (c *CPU) EnableProfiling() {
+	c.profile = &profile{}
+	c.updateHooks()
+}
+
+// DisableProfiling stops profiling and discards the counts.
+func (c *CPU) DisableProfiling() {
+	c.profile = nil
+	c.updateHooks()
+}
+
+// ProfileSnapshot returns the execution count of every opcode word run
+// since profiling was enabled. Opcodes that never ran are omitted. It
+// returns nil when profiling is disabled.
+func (c *CPU) ProfileSnapshot() map[uint16]uint64 {
+	if c.profile == nil {
+		return nil
+	}
+	return snapshot(&c.profile.count)
+}
+
+// ProfileCycles returns the total cycles spent in each opcode word since
+// profiling was enabled, in the same form as ProfileSnapshot.
+func (c *CPU) ProfileCycles() map[uint16]uint64 {
+	if c.profile == nil {
+		return nil
+	}
+	return snapshot(&c.profile.cycles)
+}
+
+func snapshot(counts *[65536]uint64) map[uint16]uint64 {
+	m := make(map[uint16]uint64)
+	for op, n := range counts {
+		if n != 0 {
+			m[uint16(op)] = n
+		}
+	}
+	return m
+}
@@ -0,0 +1,44 @@
+package m68k
+
+import "testing"
+
+func TestProfiling(t *testing.T) {
+	prog := []uint16{
+		0x7003,         // MOVEQ #3,D0
+		0x5241,         // ADDQ.W #1,D1
+		0x51C8, 0xFFFC, // DBF D0,*-2
+		0x4E71, // NOP
+	}
+	cpu, _ := progCPU(MC68000, Registers{SR: 0x2700}, prog...)
+
+	if cpu.ProfileSnapshot() != nil {
+		t.Error("ProfileSnapshot() non-nil before EnableProfiling")
+	}
+	cpu.EnableProfiling()
+	cpu.RunUntil(func(c *CPU) bool { return c.PC() == 0x100A }, 1000)
+
+	counts := cpu.ProfileSnapshot()
+	want := map[uint16]uint64{0x7003: 1, 0x5241: 4, 0x51C8: 4, 0x4E71: 1}
+	if len(counts) != len(want) {
+		t.Errorf("ProfileSnapshot() = %v, want %v", counts, want)
+	}
+	for op, n := range want {
+		if counts[op] != n {
+			t.Errorf("count[%04X] = %d, want %d", op, counts[op], n)
+		}
+	}
+
+	// DBF: three taken (10 cycles each), then expiry (14).
+	cycles := cpu.ProfileCycles()
+	if cycles[0x51C8] != 3*10+14 {
+		t.Errorf("cycles[51C8] = %d, want 44", cycles[0x51C8])
+	}
+	if cycles[0x5241] != 4*4 {
+		t.Errorf("cycles[5241] = %d, want 16", cycles[0x5241])
+	}
+
+	cpu.DisableProfiling()
+	if cpu.ProfileSnapshot() != nil || cpu.ProfileCycles() != nil {
+		t.Error("snapshots non-nil after DisableProfiling")
+	}
+}
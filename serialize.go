@@ -3,14 +3,16 @@ package m68k
 import (
 	"encoding/binary"
 	"errors"
+	"io"
+	"maps"
 )
 
 // cpuSerializeVersion is incremented whenever the binary layout changes.
-const cpuSerializeVersion = 1
+const cpuSerializeVersion = 3
 
 // SerializeSize is the number of bytes produced by CPU.Serialize.
 // Update this constant whenever the binary layout changes.
-const SerializeSize = 104
+const SerializeSize = 114
 
 // Serialize writes the full CPU state into buf, which must be at least
 // SerializeSize bytes. Returns an error if the buffer is too small.
@@ -70,6 +72,27 @@ func (c *CPU) Serialize(buf []byte) error {
 	off += 2
 
 	be.PutUint32(buf[off:], uint32(int32(c.deficit)))
+	off += 4
+
+	// Version 2: 68010 registers and the variant
+	be.PutUint32(buf[off:], c.reg.VBR)
+	off += 4
+	buf[off] = c.reg.SFC
+	buf[off+1] = c.reg.DFC
+	off += 2
+
+	// Version 3: the PulseInterrupt request, ahead of the variant so the
+	// variant stays the last byte
+	buf[off] = c.pulseIPL
+	if c.pulseVec != nil {
+		buf[off+1] = 1
+		buf[off+2] = *c.pulseVec
+	} else {
+		buf[off+1] = 0
+		buf[off+2] = 0
+	}
+	off += 3
+	buf[off] = uint8(c.variant)
 	return nil
 }
 
@@ -80,17 +103,82 @@ func boolByte(b bool) uint8 {
 	return 0
 }
 
-// Deserialize restores CPU state from buf, which must be at least
-// SerializeSize bytes. Returns an error if the buffer is too small or
-// the version does not match. The bus and cycleBus fields are left unchanged.
+// serializeSizes holds the encoded size of each layout version Deserialize
+// can still read, indexed by version. Older layouts are decoded and
+// upgraded to the current state so save states survive layout changes.
+var serializeSizes = [...]int{
+	1: 104,
+	2: 111,
+	3: 114,
+}
+
+// serializedSize returns the encoded size for version v, or 0 if v is not
+// a version Deserialize understands.
+func serializedSize(v uint8) int {
+	if int(v) >= len(serializeSizes) {
+		return 0
+	}
+	return serializeSizes[v]
+}
+
+// Deserialize restores CPU state from buf, which must hold a state
+// written by Serialize from this or an earlier layout version. Fields an
+// older layout lacks are given their reset defaults. Returns an error if
+// the buffer is too small or the version is unknown. The bus and the
+// installed callbacks are left unchanged.
 func (c *CPU) Deserialize(buf []byte) error {
-	if len(buf) < SerializeSize {
+	if len(buf) == 0 {
 		return errors.New("m68k: deserialize buffer too small")
 	}
-	if buf[0] != cpuSerializeVersion {
+	n := serializedSize(buf[0])
+	if n == 0 {
 		return errors.New("m68k: unsupported serialize version")
 	}
+	if len(buf) < n {
+		return errors.New("m68k: deserialize buffer too small")
+	}
+	if buf[0] >= 2 && Variant(buf[n-1]) > MC68020 {
+		return errors.New("m68k: unknown CPU variant")
+	}
+
+	off := c.decodeV1(buf)
+	switch buf[0] {
+	case 1:
+		// Version 1 predates the 68010: the CPU keeps its own variant and
+		// the 68010 registers take their reset values.
+		c.reg.VBR = 0
+		c.reg.SFC = 0
+		c.reg.DFC = 0
+	default:
+		c.reg.VBR = binary.BigEndian.Uint32(buf[off:])
+		c.reg.SFC = buf[off+4] & 7
+		c.reg.DFC = buf[off+5] & 7
+		c.variant = Variant(buf[n-1])
+	}
+	// Versions before 3 have no pulse request.
+	c.pulseIPL = 0
+	c.pulseVec = nil
+	if buf[0] >= 3 {
+		c.pulseIPL = buf[off+6]
+		if buf[off+7] != 0 {
+			v := buf[off+8]
+			c.pulseVec = &v
+		}
+	}
+	c.pqValid = false
 
+	// A trace, fault or loop in progress on this CPU belongs to the state being
+	// replaced, not to the snapshot.
+	c.trace = false
+	c.tracePending = false
+	c.clearFault()
+	c.clearLoop()
+	return nil
+}
+
+// decodeV1 decodes the version 1 layout, which later versions extend, and
+// returns the offset of the first byte past it.
+func (c *CPU) decodeV1(buf []byte) int {
 	be := binary.BigEndian
 	off := 1
 
@@ -115,6 +203,7 @@ func (c *CPU) Deserialize(buf []byte) error {
 	off += 2
 
 	c.cycles = be.Uint64(buf[off:])
+	c.busStamp = c.cycles
 	off += 8
 	c.ir = be.Uint16(buf[off:])
 	off += 2
@@ -122,6 +211,7 @@ func (c *CPU) Deserialize(buf []byte) error {
 	c.stopped = buf[off] != 0
 	off++
 	c.halted = buf[off] != 0
+	c.haltReason = HaltNone // not part of the snapshot
 	off++
 
 	c.prevPC = be.Uint32(buf[off:])
@@ -139,5 +229,68 @@ func (c *CPU) Deserialize(buf []byte) error {
 	off += 2
 
 	c.deficit = int(int32(be.Uint32(buf[off:])))
-	return nil
+	return off + 4
+}
+
+// WriteTo writes the state produced by Serialize to w, implementing
+// io.WriterTo. It returns the number of bytes written.
+func (c *CPU) WriteTo(w io.Writer) (int64, error) {
+	var buf [SerializeSize]byte
+	if err := c.Serialize(buf[:]); err != nil {
+		return 0, err
+	}
+	n, err := w.Write(buf[:])
+	return int64(n), err
+}
+
+// ReadFrom restores state written by WriteTo or Serialize, implementing
+// io.ReaderFrom. Only the snapshot is consumed from r, its length taken
+// from the version byte, so a snapshot can be followed by other data in
+// the same stream. A short read or an unknown version leaves the CPU
+// unchanged.
+func (c *CPU) ReadFrom(r io.Reader) (int64, error) {
+	var buf [SerializeSize]byte
+	n, err := io.ReadFull(r, buf[:1])
+	if err != nil {
+		return int64(n), err
+	}
+	size := serializedSize(buf[0])
+	if size == 0 {
+		return int64(n), errors.New("m68k: unsupported serialize version")
+	}
+	m, err := io.ReadFull(r, buf[1:size])
+	n += m
+	if err != nil {
+		return int64(n), err
+	}
+	return int64(n), c.Deserialize(buf[:size])
+}
+
+// Clone returns a copy of the CPU sharing the same bus, for running ahead
+// speculatively or diffing against the original. Unlike a Serialize round
+// trip it carries every piece of internal state, including the prefetch
+// queue, loop mode and any pending fault. The pending interrupt vectors,
+// watchpoints, profile and history are copied, so stepping either CPU
+// leaves the other unchanged; installed callbacks are shared.
+func (c *CPU) Clone() *CPU {
+	n := *c
+	if c.pendingVec != nil {
+		v := *c.pendingVec
+		n.pendingVec = &v
+	}
+	if c.pulseVec != nil {
+		v := *c.pulseVec
+		n.pulseVec = &v
+	}
+	n.watch = maps.Clone(c.watch)
+	if c.profile != nil {
+		p := *c.profile
+		n.profile = &p
+	}
+	if c.history != nil {
+		h := *c.history
+		h.buf = append([]HistoryEntry(nil), c.history.buf...)
+		n.history = &h
+	}
+	return &n
 }
@@ -1,10 +1,14 @@
 package m68k
 
-import "testing"
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
 
 func TestSerializeSize(t *testing.T) {
-	if got := SerializeSize; got != 104 {
-		t.Fatalf("SerializeSize = %d, want 104", got)
+	if got := SerializeSize; got != 114 {
+		t.Fatalf("SerializeSize = %d, want 114", got)
 	}
 }
 
@@ -33,6 +37,13 @@ func TestSerializeRoundTrip(t *testing.T) {
 	vec := uint8(64)
 	cpu.pendingVec = &vec
 	cpu.deficit = 42
+	cpu.reg.VBR = 0x8000
+	cpu.reg.SFC = 1
+	cpu.reg.DFC = 5
+	cpu.variant = MC68010
+	cpu.pulseIPL = 4
+	pulse := uint8(70)
+	cpu.pulseVec = &pulse
 
 	buf := make([]byte, SerializeSize)
 	if err := cpu.Serialize(buf); err != nil {
@@ -100,6 +111,15 @@ func TestSerializeRoundTrip(t *testing.T) {
 	if cpu2.deficit != cpu.deficit {
 		t.Errorf("deficit = %d, want %d", cpu2.deficit, cpu.deficit)
 	}
+	if cpu2.reg.VBR != 0x8000 || cpu2.reg.SFC != 1 || cpu2.reg.DFC != 5 {
+		t.Errorf("VBR/SFC/DFC = 0x%X/%d/%d, want 0x8000/1/5", cpu2.reg.VBR, cpu2.reg.SFC, cpu2.reg.DFC)
+	}
+	if cpu2.variant != MC68010 {
+		t.Errorf("variant = %v, want MC68010", cpu2.variant)
+	}
+	if cpu2.pulseIPL != 4 || cpu2.pulseVec == nil || *cpu2.pulseVec != 70 {
+		t.Errorf("pulse = %d/%v, want 4/70", cpu2.pulseIPL, cpu2.pulseVec)
+	}
 }
 
 func TestSerializeRoundTripNilVector(t *testing.T) {
@@ -198,3 +218,258 @@ func TestSerializeResumeExecution(t *testing.T) {
 		t.Errorf("total cycles: cpu1=%d, cpu2=%d", cpu1.Cycles(), cpu2.Cycles())
 	}
 }
+
+// TestDeserializeClearsTrace restores a snapshot over a CPU that has just
+// run a traced instruction: the pending trace must not be taken on the
+// restored state.
+func TestDeserializeClearsTrace(t *testing.T) {
+	cpu, bus := newNOPCPU(10)
+	bus.Write32(vecTrace*4, 0x3000)
+	fillNOPs(bus, 0x3000, 4)
+	buf := make([]byte, SerializeSize)
+	if err := cpu.Serialize(buf); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	cpu.SetState(Registers{PC: 0x1000, SR: 0xA700, SSP: 0x10000})
+	cpu.Step()
+	if !cpu.tracePending {
+		t.Fatal("no trace pending after a traced NOP")
+	}
+
+	if err := cpu.Deserialize(buf); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if n := cpu.Step(); n != 4 {
+		t.Errorf("Step = %d cycles, want 4 (a NOP)", n)
+	}
+	if pc := cpu.PC(); pc != 0x1002 {
+		t.Errorf("PC = 0x%X, want 0x1002 (no trace exception)", pc)
+	}
+}
+
+func TestWriteToReadFrom(t *testing.T) {
+	cpu, _ := newNOPCPU(10)
+	cpu.Step()
+	cpu.Step()
+	cpu.pendingIPL = 2
+
+	var stream bytes.Buffer
+	n, err := cpu.WriteTo(&stream)
+	if err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if n != SerializeSize {
+		t.Errorf("WriteTo wrote %d bytes, want %d", n, SerializeSize)
+	}
+
+	// The stream must hold exactly what Serialize produces.
+	buf := make([]byte, SerializeSize)
+	if err := cpu.Serialize(buf); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if !bytes.Equal(stream.Bytes(), buf) {
+		t.Error("WriteTo output differs from Serialize")
+	}
+
+	stream.WriteString("trailer")
+	cpu2 := &CPU{bus: cpu.bus}
+	n, err = cpu2.ReadFrom(&stream)
+	if err != nil {
+		t.Fatalf("ReadFrom failed: %v", err)
+	}
+	if n != SerializeSize {
+		t.Errorf("ReadFrom read %d bytes, want %d", n, SerializeSize)
+	}
+	if stream.String() != "trailer" {
+		t.Errorf("ReadFrom consumed past the snapshot, %q left", stream.String())
+	}
+	if cpu2.Registers() != cpu.Registers() || cpu2.Cycles() != cpu.Cycles() || cpu2.pendingIPL != 2 {
+		t.Errorf("state diverged:\n  got  %+v\n  want %+v", cpu2.Registers(), cpu.Registers())
+	}
+}
+
+func TestReadFromRejectsBadInput(t *testing.T) {
+	cpu, _ := newNOPCPU(1)
+	var stream bytes.Buffer
+	if _, err := cpu.WriteTo(&stream); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	snap := stream.Bytes()
+
+	cpu2 := &CPU{bus: &testBus{}}
+	if _, err := cpu2.ReadFrom(bytes.NewReader(snap[:10])); err == nil {
+		t.Error("ReadFrom accepted a truncated stream")
+	}
+
+	bad := append([]byte(nil), snap...)
+	bad[0] = 99
+	if _, err := cpu2.ReadFrom(bytes.NewReader(bad)); err == nil {
+		t.Error("ReadFrom accepted wrong version")
+	}
+}
+
+func TestSerializeSizesTable(t *testing.T) {
+	if got := serializedSize(cpuSerializeVersion); got != SerializeSize {
+		t.Errorf("serializedSize(current) = %d, want SerializeSize %d", got, SerializeSize)
+	}
+	if serializedSize(0) != 0 || serializedSize(255) != 0 {
+		t.Error("unknown versions must report size 0")
+	}
+}
+
+// v1Snapshot builds a version 1 save state field by field, independent of
+// the current Serialize, so the v1 decoder keeps reading old blobs.
+func v1Snapshot() []byte {
+	be := binary.BigEndian
+	buf := []byte{1}
+	for i := 0; i < 8; i++ {
+		buf = be.AppendUint32(buf, uint32(0x10+i)) // D0-D7
+	}
+	for i := 0; i < 8; i++ {
+		buf = be.AppendUint32(buf, uint32(0x20+i)) // A0-A7
+	}
+	buf = be.AppendUint32(buf, 0x4000) // PC
+	buf = be.AppendUint16(buf, 0x2700) // SR
+	buf = be.AppendUint32(buf, 0x5000) // USP
+	buf = be.AppendUint32(buf, 0x6000) // SSP
+	buf = be.AppendUint16(buf, 0x4E71) // IR
+	buf = be.AppendUint64(buf, 9999)   // cycles
+	buf = be.AppendUint16(buf, 0x4E71) // ir
+	buf = append(buf, 1, 0)            // stopped, halted
+	buf = be.AppendUint32(buf, 0x3FFE) // prevPC
+	buf = append(buf, 5, 1, 64)        // pendingIPL, vector present, vector
+	buf = be.AppendUint32(buf, 42)     // deficit
+	return buf
+}
+
+func TestDeserializeV1(t *testing.T) {
+	buf := v1Snapshot()
+	if len(buf) != serializedSize(1) {
+		t.Fatalf("v1 snapshot is %d bytes, want %d", len(buf), serializedSize(1))
+	}
+
+	cpu := &CPU{bus: &testBus{}, variant: MC68008}
+	cpu.reg.VBR = 0x1234
+	if err := cpu.Deserialize(buf); err != nil {
+		t.Fatalf("Deserialize(v1) failed: %v", err)
+	}
+	if cpu.reg.VBR != 0 || cpu.variant != MC68008 {
+		t.Errorf("VBR/variant = 0x%X/%v, want reset VBR and the CPU's own variant", cpu.reg.VBR, cpu.variant)
+	}
+	reg := cpu.Registers()
+	if reg.D[7] != 0x17 || reg.A[6] != 0x26 || cpu.reg.PC != 0x4000 || reg.SR != 0x2700 {
+		t.Errorf("registers = %+v", reg)
+	}
+	if reg.USP != 0x5000 || reg.SSP != 0x6000 {
+		t.Errorf("USP/SSP = 0x%X/0x%X, want 0x5000/0x6000", reg.USP, reg.SSP)
+	}
+	if cpu.Cycles() != 9999 || !cpu.stopped || cpu.halted || cpu.prevPC != 0x3FFE {
+		t.Errorf("cycles=%d stopped=%v halted=%v prevPC=0x%X", cpu.Cycles(), cpu.stopped, cpu.halted, cpu.prevPC)
+	}
+	if cpu.pendingIPL != 5 || cpu.pendingVec == nil || *cpu.pendingVec != 64 {
+		t.Errorf("pending interrupt = %d/%v, want 5/64", cpu.pendingIPL, cpu.pendingVec)
+	}
+	if cpu.deficit != 42 {
+		t.Errorf("deficit = %d, want 42", cpu.deficit)
+	}
+
+	// A v1 stream is shorter than the current layout; ReadFrom must stop
+	// at its end.
+	stream := bytes.NewBuffer(append(buf, 0xAA))
+	if _, err := cpu.ReadFrom(stream); err != nil {
+		t.Fatalf("ReadFrom(v1) failed: %v", err)
+	}
+	if stream.Len() != 1 {
+		t.Errorf("ReadFrom left %d bytes, want 1", stream.Len())
+	}
+}
+
+func TestDeserializeV2(t *testing.T) {
+	// A version 2 save state is the current layout without the pulse
+	// request that precedes the variant.
+	src := &CPU{bus: &testBus{}, variant: MC68010}
+	src.reg.VBR = 0x8000
+	src.pendingIPL = 5
+	buf := make([]byte, SerializeSize)
+	if err := src.Serialize(buf); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	v2 := append(buf[:SerializeSize-4:SerializeSize-4], buf[SerializeSize-1])
+	v2[0] = 2
+	if len(v2) != serializedSize(2) {
+		t.Fatalf("v2 snapshot is %d bytes, want %d", len(v2), serializedSize(2))
+	}
+
+	cpu := &CPU{bus: &testBus{}}
+	cpu.pulseIPL = 6
+	if err := cpu.Deserialize(v2); err != nil {
+		t.Fatalf("Deserialize(v2) failed: %v", err)
+	}
+	if cpu.variant != MC68010 || cpu.reg.VBR != 0x8000 || cpu.pendingIPL != 5 {
+		t.Errorf("variant/VBR/pendingIPL = %v/0x%X/%d, want MC68010/0x8000/5", cpu.variant, cpu.reg.VBR, cpu.pendingIPL)
+	}
+	if cpu.pulseIPL != 0 || cpu.pulseVec != nil {
+		t.Errorf("pulse = %d/%v, want none", cpu.pulseIPL, cpu.pulseVec)
+	}
+}
+
+func TestDeserializeRejectsBadVariant(t *testing.T) {
+	cpu := &CPU{bus: &testBus{}}
+	buf := make([]byte, SerializeSize)
+	if err := cpu.Serialize(buf); err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	buf[SerializeSize-1] = 9
+	if err := cpu.Deserialize(buf); err == nil {
+		t.Fatal("Deserialize accepted an unknown variant")
+	}
+}
+
+func TestClone(t *testing.T) {
+	bus := &testBus{}
+	for i := uint32(0); i < 8; i++ {
+		writeWord(bus, 0x1000+i*2, 0x5280) // ADDQ.L #1,D0
+	}
+	cpu := &CPU{bus: bus}
+	cpu.SetState(Registers{PC: 0x1000, SR: 0x2700, SSP: 0x10000})
+	cpu.SetHistorySize(4)
+	cpu.Step()
+	cpu.Step()
+
+	clone := cpu.Clone()
+	cpu.Step()
+	clone.Step()
+	if r1, r2 := cpu.Registers(), clone.Registers(); r1 != r2 {
+		t.Fatalf("registers diverged:\n  cpu   %+v\n  clone %+v", r1, r2)
+	}
+	if cpu.Cycles() != clone.Cycles() {
+		t.Errorf("cycles: cpu %d, clone %d", cpu.Cycles(), clone.Cycles())
+	}
+
+	// Only the clone moves on; the original's registers and history stay put.
+	clone.SetD(1, 0x55)
+	clone.Step()
+	if d0, d1 := cpu.D(0), cpu.D(1); d0 != 3 || d1 != 0 {
+		t.Errorf("cpu D0/D1 = %d/%d, want 3/0", d0, d1)
+	}
+	if d0 := clone.D(0); d0 != 4 {
+		t.Errorf("clone D0 = %d, want 4", d0)
+	}
+	if h1, h2 := len(cpu.History()), len(clone.History()); h1 != 3 || h2 != 4 {
+		t.Errorf("history lengths = %d/%d, want 3/4", h1, h2)
+	}
+
+	t.Run("pending vector", func(t *testing.T) {
+		vec := uint8(0x40)
+		cpu.RequestInterrupt(3, &vec)
+		clone := cpu.Clone()
+		if clone.pendingVec == cpu.pendingVec {
+			t.Fatal("clone shares the pending vector")
+		}
+		*cpu.pendingVec = 0x41
+		if level, v := clone.PendingInterrupt(); level != 3 || v == nil || *v != 0x40 {
+			t.Errorf("clone pending = %d/%v, want level 3 vector 0x40", level, v)
+		}
+	})
+}
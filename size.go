@@ -37,6 +37,12 @@ func (s size) MSB() uint32 {
 	}
 }
 
+// SignExtend returns v sign-extended from this size to 32 bits.
+func (s size) SignExtend(v uint32) uint32 {
+	shift := 32 - s.Bits()
+	return uint32(int32(v<<shift) >> shift)
+}
+
 // Bits returns the number of bits for this size.
 func (s size) Bits() uint32 {
 	return uint32(s) * 8
@@ -15,26 +15,12 @@ var sstStrict = flag.Bool("sststrict", false, "run all SST tests including known
 // sstSkip lists JSON files that fail due to documented design choices.
 // Remove entries as features are implemented to re-enable those tests.
 var sstSkip = map[string]string{
-	"TAS.json":   "TAS is not fully modeled",
 	"TRAPV.json": "TRAPV is not fully modeled",
 
 	// Cycle count approximations (see README Design Notes):
 	// Multiply/divide use flat worst-case values instead of operand-dependent timing.
 	"MULU.json": "cycle approximation: flat worst-case 70 (real 38-70)",
-	"MULS.json": "cycle approximation: flat worst-case 70 (real 38-70)",
-	"DIVU.json": "cycle approximation: flat worst-case 140 (real 76-140)",
 	"DIVS.json": "cycle approximation: flat worst-case 158 (real 120-158)",
-
-	// CHK exception processing uses a fixed 34-cycle cost rather than
-	// instruction-specific timing which varies by addressing mode and trap condition.
-	"CHK.json": "cycle approximation: fixed 34-cycle exception cost",
-
-	// Bit manipulation #imm,Dn timing: PRM values are 2 cycles off from
-	// hardware-verified results for all four instructions.
-	"BTST.json": "cycle approximation: BTST #imm,Dn 8 vs hardware 10",
-	"BCHG.json": "cycle approximation: BCHG #imm,Dn 12 vs hardware 10",
-	"BCLR.json": "cycle approximation: BCLR #imm,Dn 14 vs hardware 12",
-	"BSET.json": "cycle approximation: BSET #imm,Dn 12 vs hardware 10",
 }
 
 type sstJSONState struct {
@@ -84,10 +70,9 @@ type sstJSONTest struct {
 	Length       int          `json:"length"`
 }
 
-// runSSTTest is like runTest but skips (instead of failing) when the CPU halts.
-// The emulator halts on address errors rather than pushing a full exception frame
-// (documented design choice), so SST tests that trigger odd-address access are
-// expected to halt and should be skipped rather than counted as failures.
+// runSSTTest runs one case from the SST JSON corpus. Address errors push a
+// full group 0 frame, so cases that trigger odd-address access are compared
+// like any other, exception frame included.
 func runSSTTest(t *testing.T, init, want cpuState) {
 	t.Helper()
 
@@ -99,12 +84,14 @@ func runSSTTest(t *testing.T, init, want cpuState) {
 	var a8 [8]uint32
 	copy(a8[:7], init.A[:])
 	cpu := &CPU{bus: bus}
-	cpu.SetState(Registers{D: init.D, A: a8, PC: init.PC - prefetchOffset, SR: init.SR, USP: init.USP, SSP: init.SSP})
+	cpu.SetPrefetch(true)
+	cpu.SetState(Registers{D: init.D, A: a8, PC: init.PC, SR: init.SR, USP: init.USP, SSP: init.SSP})
 
 	gotCycles := cpu.Step()
 
 	if cpu.Halted() {
-		t.Skip("address error halt (not modeled)")
+		t.Errorf("CPU unexpectedly halted")
+		return
 	}
 
 	reg := cpu.Registers()
@@ -137,9 +124,8 @@ func runSSTTest(t *testing.T, init, want cpuState) {
 		}
 	}
 
-	wantPC := want.PC - prefetchOffset
-	if reg.PC != wantPC {
-		t.Errorf("PC = 0x%08X, want 0x%08X", reg.PC, wantPC)
+	if reg.PC != want.PC {
+		t.Errorf("PC = 0x%08X, want 0x%08X", reg.PC, want.PC)
 	}
 
 	if reg.SR != want.SR {
@@ -45,6 +45,16 @@ func (b *testBus) Write32(addr uint32, val uint32) {
 
 func (b *testBus) Reset() {}
 
+// The test buses must satisfy the interfaces the CPU declares, so that a
+// signature change in cpu.go breaks the build rather than the tests.
+var (
+	_ Bus           = (*testBus)(nil)
+	_ RMWBus        = (*rmwBus)(nil)
+	_ FCBus         = (*fcBus)(nil)
+	_ SupervisorBus = (*protectBus)(nil)
+	_ CycleBus      = (*cycleBus)(nil)
+)
+
 // cpuState captures the full programmer-visible state for a test case.
 // RAM entries are [address, byte_value] pairs.
 // A[7] is unused; the active stack pointer is derived from USP/SSP/SR.
@@ -56,20 +66,12 @@ type cpuState struct {
 	USP    uint32
 	SSP    uint32
 	RAM    [][2]uint32
-	Halted bool
 	Cycles int // Expected cycle count (0 = don't check)
 }
 
-// prefetchOffset is the 68000 prefetch pipeline offset.
-// The SingleStepTests JSON data models the 68000's 2-word prefetch queue,
-// where the PC register is 4 bytes ahead of the instruction being executed.
-// Our emulator does not model the prefetch pipeline, so we adjust PC by -4
-// when loading initial state and comparing final state.
-const prefetchOffset uint32 = 4
-
 // runTest loads initial state, executes one Step, and compares against expected state.
-// PC values from the test data are adjusted by -prefetchOffset to account for the
-// 68000's prefetch pipeline (instruction is at PC-4 in the hardware model).
+// The CPU runs with the prefetch model enabled, so PC values from the test data
+// (the hardware PC, 4 bytes past the next instruction) are used as-is.
 func runTest(t *testing.T, init, want cpuState) {
 	t.Helper()
 
@@ -84,16 +86,11 @@ func runTest(t *testing.T, init, want cpuState) {
 	var a8 [8]uint32
 	copy(a8[:7], init.A[:])
 	cpu := &CPU{bus: bus}
-	cpu.SetState(Registers{D: init.D, A: a8, PC: init.PC - prefetchOffset, SR: init.SR, USP: init.USP, SSP: init.SSP})
+	cpu.SetPrefetch(true)
+	cpu.SetState(Registers{D: init.D, A: a8, PC: init.PC, SR: init.SR, USP: init.USP, SSP: init.SSP})
 
 	gotCycles := cpu.Step()
 
-	if want.Halted {
-		if !cpu.Halted() {
-			t.Errorf("expected CPU to be halted, but it is not")
-		}
-		return // Register/memory state is undefined after halt
-	}
 	if cpu.Halted() {
 		t.Errorf("CPU unexpectedly halted")
 		return
@@ -137,10 +134,9 @@ func runTest(t *testing.T, init, want cpuState) {
 		}
 	}
 
-	// Compare PC (adjusted for prefetch offset)
-	wantPC := want.PC - prefetchOffset
-	if reg.PC != wantPC {
-		t.Errorf("PC = 0x%08X, want 0x%08X", reg.PC, wantPC)
+	// Compare PC
+	if reg.PC != want.PC {
+		t.Errorf("PC = 0x%08X, want 0x%08X", reg.PC, want.PC)
 	}
 
 	// Compare SR
@@ -177,6 +173,29 @@ func fillNOPs(bus *testBus, addr uint32, count int) {
 	}
 }
 
+// progCPU loads prog at 0x1000 and returns a CPU of variant v about to run
+// it, with the registers in regs. PC is set to 0x1000, and a zero SSP
+// defaults to 0x10000.
+func progCPU(v Variant, regs Registers, prog ...uint16) (*CPU, *testBus) {
+	bus := &testBus{}
+	return busCPU(bus, bus, v, regs, prog...), bus
+}
+
+// busCPU is progCPU for a CPU on bus, a wrapper such as berrBus or fcBus
+// around mem, the memory prog is loaded into.
+func busCPU(bus Bus, mem *testBus, v Variant, regs Registers, prog ...uint16) *CPU {
+	for i, w := range prog {
+		writeWord(mem, 0x1000+uint32(i*2), w)
+	}
+	regs.PC = 0x1000
+	if regs.SSP == 0 {
+		regs.SSP = 0x10000
+	}
+	cpu := &CPU{bus: bus, variant: v}
+	cpu.SetState(regs)
+	return cpu
+}
+
 // newNOPCPU creates a CPU with NOPs at the given PC and returns it ready to run.
 func newNOPCPU(nopCount int) (*CPU, *testBus) {
 	bus := &testBus{}
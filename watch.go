@@ -0,0 +1,64 @@
+package m68k
+
+// WatchpointFunc is called when a data access touches a watched address.
+// addr is the (24-bit) address of the access, sz its width in bytes
+// (1, 2 or 4), and val the value read or written.
+type WatchpointFunc func(addr uint32, sz int, write bool, val uint32)
+
+// Watchpoint flags stored per watched byte address.
+const (
+	watchRead  uint8 = 1 << 0
+	watchWrite uint8 = 1 << 1
+)
+
+// SetWatchpoint watches the byte at addr for reads, writes, or both.
+// Any data access whose bytes include addr triggers the watchpoint, so a
+// word or long access starting below the watched byte fires too. Passing
+// read and write both false removes the watchpoint. Instruction fetches
+// are not watched.
+func (c *CPU) SetWatchpoint(addr uint32, read, write bool) {
+	addr &= 0xFFFFFF
+	var flags uint8
+	if read {
+		flags |= watchRead
+	}
+	if write {
+		flags |= watchWrite
+	}
+	if flags == 0 {
+		delete(c.watch, addr)
+	} else {
+		if c.watch == nil {
+			c.watch = make(map[uint32]uint8)
+		}
+		c.watch[addr] = flags
+	}
+	c.updateHooks()
+}
+
+// ClearWatchpoints removes all watchpoints.
+func (c *CPU) ClearWatchpoints() {
+	c.watch = nil
+	c.updateHooks()
+}
+
+// SetWatchpointFunc installs the callback invoked when a watchpoint is hit.
+// Pass nil to remove it. The callback runs from inside the bus access, so
+// the access has completed but the instruction has not.
+func (c *CPU) SetWatchpointFunc(fn WatchpointFunc) {
+	c.watchFunc = fn
+}
+
+// checkWatch fires the watchpoint callback if the access at addr of the
+// given size overlaps a watched byte with a matching flag.
+func (c *CPU) checkWatch(sz size, addr uint32, flag uint8, val uint32) {
+	if c.watchFunc == nil {
+		return
+	}
+	for i := uint32(0); i < uint32(sz); i++ {
+		if c.watch[(addr+i)&0xFFFFFF]&flag != 0 {
+			c.watchFunc(addr, int(sz), flag == watchWrite, val)
+			return
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package m68k
+
+import "testing"
+
+type watchHit struct {
+	addr  uint32
+	sz    int
+	write bool
+	val   uint32
+}
+
+// watchCPU builds a CPU at 0x1000 running the given program with D0 and
+// A0 preset, recording every watchpoint hit.
+func watchCPU(prog []uint16, d0, a0 uint32) (*CPU, *[]watchHit) {
+	cpu, bus := progCPU(MC68000, Registers{D: [8]uint32{d0}, A: [8]uint32{a0}, SR: 0x2700}, prog...)
+	fillNOPs(bus, 0x1000+uint32(len(prog)*2), 4)
+	hits := &[]watchHit{}
+	cpu.SetWatchpointFunc(func(addr uint32, sz int, write bool, val uint32) {
+		*hits = append(*hits, watchHit{addr, sz, write, val})
+	})
+	return cpu, hits
+}
+
+func TestWatchpoint(t *testing.T) {
+	t.Run("MOVE.W D0,(A0) hits write watch", func(t *testing.T) {
+		cpu, hits := watchCPU([]uint16{0x3080}, 0xBEEF, 0x4000)
+		cpu.SetWatchpoint(0x4000, false, true)
+		cpu.Step()
+		want := []watchHit{{0x4000, 2, true, 0xBEEF}}
+		if len(*hits) != 1 || (*hits)[0] != want[0] {
+			t.Errorf("hits = %+v, want %+v", *hits, want)
+		}
+	})
+
+	t.Run("straddling accesses hit", func(t *testing.T) {
+		// Watch the second byte of the word and the last byte of the long.
+		cpu, hits := watchCPU([]uint16{0x3080, 0x2080}, 0x11223344, 0x4000)
+		cpu.SetWatchpoint(0x4001, false, true)
+		cpu.Step()
+		cpu.SetWatchpoint(0x4001, false, false)
+		cpu.SetWatchpoint(0x4003, false, true)
+		cpu.Step()
+		want := []watchHit{{0x4000, 2, true, 0x3344}, {0x4000, 4, true, 0x11223344}}
+		if len(*hits) != 2 || (*hits)[0] != want[0] || (*hits)[1] != want[1] {
+			t.Errorf("hits = %+v, want %+v", *hits, want)
+		}
+	})
+
+	t.Run("read watch ignores writes", func(t *testing.T) {
+		// MOVE.W D0,(A0) then MOVE.W (A0),D1
+		cpu, hits := watchCPU([]uint16{0x3080, 0x3210}, 0x1234, 0x4000)
+		cpu.SetWatchpoint(0x4000, true, false)
+		cpu.Step()
+		cpu.Step()
+		want := []watchHit{{0x4000, 2, false, 0x1234}}
+		if len(*hits) != 1 || (*hits)[0] != want[0] {
+			t.Errorf("hits = %+v, want %+v", *hits, want)
+		}
+	})
+
+	t.Run("adjacent and fetch accesses do not hit", func(t *testing.T) {
+		cpu, hits := watchCPU([]uint16{0x3080}, 0x1234, 0x4002)
+		cpu.SetWatchpoint(0x4001, true, true)
+		cpu.SetWatchpoint(0x1000, true, true) // the opcode itself
+		cpu.Step()
+		if len(*hits) != 0 {
+			t.Errorf("hits = %+v, want none", *hits)
+		}
+	})
+
+	t.Run("cleared watchpoints do not hit", func(t *testing.T) {
+		cpu, hits := watchCPU([]uint16{0x3080}, 0x1234, 0x4000)
+		cpu.SetWatchpoint(0x4000, true, true)
+		cpu.ClearWatchpoints()
+		cpu.Step()
+		if len(*hits) != 0 {
+			t.Errorf("hits = %+v, want none", *hits)
+		}
+	})
+}